@@ -316,12 +316,16 @@ func MayHaveSideEffects(pass *analysis.Pass, expr ast.Expr, purity purity.Result
 }
 
 func IsGoVersion(pass *analysis.Pass, minor int) bool {
+	return GoVersion(pass) >= minor
+}
+
+// GoVersion returns the minor version of the targeted Go release.
+func GoVersion(pass *analysis.Pass) int {
 	f, ok := pass.Analyzer.Flags.Lookup("go").Value.(flag.Getter)
 	if !ok {
 		panic("requested Go version, but analyzer has no version flag")
 	}
-	version := f.Get().(int)
-	return version >= minor
+	return f.Get().(int)
 }
 
 var integerLiteralQ = pattern.MustParse(`(IntegerLiteral tv)`)
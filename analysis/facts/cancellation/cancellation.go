@@ -0,0 +1,38 @@
+// Package cancellation provides analyzers with the context of the
+// analysis run, so that long analyses can be cancelled.
+//
+// Drivers that support cancellation, such as the runner of
+// lintcmd, don't run the analyzer but supply their own context as its
+// result. All other drivers run it, which results in a context that
+// is never cancelled.
+package cancellation
+
+import (
+	"context"
+	"reflect"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Context is the result of Analyzer. Drivers require results to be
+// of exactly the analyzer's ResultType, which can't be an interface,
+// so the context is wrapped in a struct.
+type Context struct {
+	context.Context
+}
+
+var Analyzer = &analysis.Analyzer{
+	Name: "cancellation",
+	Doc:  "provides the context of the analysis run",
+	Run: func(pass *analysis.Pass) (interface{}, error) {
+		return &Context{context.Background()}, nil
+	},
+	RunDespiteErrors: true,
+	ResultType:       reflect.TypeOf((*Context)(nil)),
+}
+
+// For returns the context of the analysis run of pass. Analyzers
+// should stop and return the context's error once it is done.
+func For(pass *analysis.Pass) context.Context {
+	return pass.ResultOf[Analyzer].(*Context).Context
+}
@@ -0,0 +1,16 @@
+package cancellation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResultType(t *testing.T) {
+	res, err := Analyzer.Run(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reflect.TypeOf(res); got != Analyzer.ResultType {
+		t.Errorf("got result of type %s, want %s", got, Analyzer.ResultType)
+	}
+}
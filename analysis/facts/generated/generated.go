@@ -5,8 +5,14 @@ import (
 	"bytes"
 	"io"
 	"os"
+	"path"
+	"path/filepath"
 	"reflect"
+	"regexp"
 	"strings"
+	"sync"
+
+	"honnef.co/go/tools/config"
 
 	"golang.org/x/tools/go/analysis"
 )
@@ -29,15 +35,90 @@ var (
 	suffix = []byte(" DO NOT EDIT.")
 	nl     = []byte("\n")
 	crnl   = []byte("\r\n")
+	pkg    = []byte("package ")
 )
 
-func isGenerated(path string) (Generator, bool) {
+// A Detector recognizes generated files that lack the standard
+// "Code generated ... DO NOT EDIT." header, such as the output of
+// in-house generators. Files that a detector recognizes are
+// generated by an Unknown generator.
+type Detector struct {
+	// Header, if not nil, matches the lines of generated files that
+	// precede their package clauses.
+	Header *regexp.Regexp
+	// Path, if not empty, is a pattern in the syntax of path.Match
+	// that matches the slash-separated paths of generated files, or
+	// their trailing elements. For example, "*_gen.go" matches files
+	// in any directory, and "internal/pb/*.go" matches the files of
+	// all internal/pb directories.
+	Path string
+}
+
+var detectors struct {
+	mu        sync.Mutex
+	detectors []Detector
+}
+
+// RegisterDetector registers d to be consulted for every analyzed
+// file, in addition to the generated_headers and generated_paths
+// options. Programs that embed the analyzers should call it before
+// running any analyses, typically from an init function.
+func RegisterDetector(d Detector) {
+	detectors.mu.Lock()
+	defer detectors.mu.Unlock()
+	detectors.detectors = append(detectors.detectors, d)
+}
+
+// configuredDetectors returns the registered detectors and those of
+// cfg.
+func configuredDetectors(cfg *config.Config) []Detector {
+	detectors.mu.Lock()
+	out := append([]Detector(nil), detectors.detectors...)
+	detectors.mu.Unlock()
+	for _, header := range cfg.GeneratedHeaders {
+		// The configuration has been validated when it was loaded.
+		out = append(out, Detector{Header: regexp.MustCompile(header)})
+	}
+	for _, pattern := range cfg.GeneratedPaths {
+		out = append(out, Detector{Path: pattern})
+	}
+	return out
+}
+
+// matchPath reports whether pattern matches name or one of its
+// trailing elements.
+func matchPath(pattern, name string) bool {
+	name = filepath.ToSlash(name)
+	for {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+		i := strings.IndexByte(name, '/')
+		if i == -1 {
+			return false
+		}
+		name = name[i+1:]
+	}
+}
+
+func isGenerated(path string, detectors []Detector) (Generator, bool) {
+	var headers []*regexp.Regexp
+	for _, d := range detectors {
+		if d.Path != "" && matchPath(d.Path, path) {
+			return Unknown, true
+		}
+		if d.Header != nil {
+			headers = append(headers, d.Header)
+		}
+	}
+
 	f, err := os.Open(path)
 	if err != nil {
 		return 0, false
 	}
 	defer f.Close()
 	br := bufio.NewReader(f)
+	inHeader := true
 	for {
 		s, err := br.ReadBytes('\n')
 		if err != nil && err != io.EOF {
@@ -71,6 +152,17 @@ func isGenerated(path string) (Generator, bool) {
 		if bytes.Equal(s, oldCgo) {
 			return Cgo, true
 		}
+		if inHeader {
+			if bytes.HasPrefix(s, pkg) {
+				inHeader = false
+			} else {
+				for _, header := range headers {
+					if header.Match(s) {
+						return Unknown, true
+					}
+				}
+			}
+		}
 		if err == io.EOF {
 			break
 		}
@@ -79,13 +171,15 @@ func isGenerated(path string) (Generator, bool) {
 }
 
 var Analyzer = &analysis.Analyzer{
-	Name: "isgenerated",
-	Doc:  "annotate file names that have been code generated",
+	Name:     "isgenerated",
+	Doc:      "annotate file names that have been code generated",
+	Requires: []*analysis.Analyzer{config.Analyzer},
 	Run: func(pass *analysis.Pass) (interface{}, error) {
 		m := map[string]Generator{}
+		detectors := configuredDetectors(config.For(pass))
 		for _, f := range pass.Files {
 			path := pass.Fset.PositionFor(f.Pos(), false).Filename
-			g, ok := isGenerated(path)
+			g, ok := isGenerated(path, detectors)
 			if ok {
 				m[path] = g
 			}
@@ -0,0 +1,49 @@
+package generated
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestDetectors(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"standard.go":         "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage pkg\n",
+		"custom.go":           "// Autogenerated by mkapi, edit api.yaml instead.\n\npackage pkg\n",
+		"late.go":             "package pkg\n\n// Autogenerated by mkapi, edit api.yaml instead.\n",
+		"plain.go":            "package pkg\n",
+		"types_gen.go":        "package pkg\n",
+		"internal/pb/msg.go":  "package pb\n",
+		"internal/api/msg.go": "package api\n",
+	}
+	for name, src := range files {
+		name = filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	detectors := []Detector{
+		{Header: regexp.MustCompile(`^// Autogenerated by `)},
+		{Path: "*_gen.go"},
+		{Path: "internal/pb/*.go"},
+	}
+	want := map[string]Generator{
+		"standard.go":        ProtocGenGo,
+		"custom.go":          Unknown,
+		"types_gen.go":       Unknown,
+		"internal/pb/msg.go": Unknown,
+	}
+	for name := range files {
+		g, ok := isGenerated(filepath.Join(dir, name), detectors)
+		wg, wok := want[name]
+		if ok != wok || g != wg {
+			t.Errorf("%s: got (%v, %t), want (%v, %t)", name, g, ok, wg, wok)
+		}
+	}
+}
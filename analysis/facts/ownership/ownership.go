@@ -0,0 +1,110 @@
+// Package ownership maps top-level declarations to the objects they
+// own, and objects to the declarations that own them.
+package ownership
+
+import (
+	"go/ast"
+	"go/types"
+	"reflect"
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// A Decl is a top-level declaration. Declarations using
+// parenthesized groups are split into their individual specs.
+type Decl struct {
+	File *ast.File
+	Decl ast.Decl
+	// Spec is nil for function declarations.
+	Spec ast.Spec
+}
+
+// Node returns the syntax of the declaration, which is either a
+// *ast.FuncDecl or an ast.Spec.
+func (d Decl) Node() ast.Node {
+	if d.Spec != nil {
+		return d.Spec
+	}
+	return d.Decl
+}
+
+// Index records which objects each top-level declaration owns. A
+// declaration owns all objects that are defined in it, including
+// struct fields, parameters and local variables, as well as the
+// implicit variables that type switches declare in each of their
+// clauses.
+type Index struct {
+	decls map[types.Object]Decl
+	owned map[ast.Node][]types.Object
+}
+
+// Decl returns the top-level declaration that owns obj.
+func (idx *Index) Decl(obj types.Object) (Decl, bool) {
+	d, ok := idx.decls[obj]
+	return d, ok
+}
+
+// Owned returns the objects owned by the declaration node, which has
+// to be a *ast.FuncDecl or an ast.Spec. The objects are sorted by
+// position.
+func (idx *Index) Owned(node ast.Node) []types.Object {
+	return idx.owned[node]
+}
+
+func (idx *Index) add(d Decl, info *types.Info) {
+	node := d.Node()
+	own := func(obj types.Object) {
+		idx.decls[obj] = d
+		idx.owned[node] = append(idx.owned[node], obj)
+	}
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Ident:
+			// The identifier of x := v.(type) doesn't define an object
+			// by itself.
+			if obj := info.Defs[n]; obj != nil {
+				own(obj)
+			}
+		case *ast.CaseClause:
+			if obj := info.Implicits[n]; obj != nil {
+				own(obj)
+			}
+		}
+		return true
+	})
+	// The implicit variables of type switch clauses are positioned at
+	// the switch's identifier, before the objects of earlier clauses.
+	owned := idx.owned[node]
+	sort.SliceStable(owned, func(i, j int) bool {
+		return owned[i].Pos() < owned[j].Pos()
+	})
+}
+
+func ownership(pass *analysis.Pass) (interface{}, error) {
+	idx := &Index{
+		decls: map[types.Object]Decl{},
+		owned: map[ast.Node][]types.Object{},
+	}
+	for _, f := range pass.Files {
+		for _, decl := range f.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				idx.add(Decl{File: f, Decl: decl}, pass.TypesInfo)
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					idx.add(Decl{File: f, Decl: decl, Spec: spec}, pass.TypesInfo)
+				}
+			}
+		}
+	}
+	return idx, nil
+}
+
+var Analyzer = &analysis.Analyzer{
+	Name:             "ownership",
+	Doc:              "maps top-level declarations to the objects they own",
+	Run:              ownership,
+	RunDespiteErrors: true,
+	ResultType:       reflect.TypeOf((*Index)(nil)),
+}
@@ -0,0 +1,68 @@
+package ownership
+
+import (
+	"go/ast"
+	"go/types"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestOwnership(t *testing.T) {
+	res := analysistest.Run(t, analysistest.TestData(), Analyzer, "example")
+	idx := res[0].Result.(*Index)
+	scope := res[0].Pass.Pkg.Scope()
+
+	names := func(objs []types.Object) []string {
+		var out []string
+		for _, obj := range objs {
+			out = append(out, obj.Name())
+		}
+		return out
+	}
+
+	T := scope.Lookup("T")
+	method, _, _ := types.LookupFieldOrMethod(T.Type(), false, T.Pkg(), "Method")
+	tests := []struct {
+		obj   types.Object
+		owned []string
+	}{
+		{T, []string{"T", "F"}},
+		{method, []string{"t", "Method", "x", "y"}},
+		{scope.Lookup("a"), []string{"a", "b"}},
+		{scope.Lookup("c"), []string{"c"}},
+		{scope.Lookup("d"), []string{"d"}},
+		// the parameter, and the variables of the three clauses of
+		// the type switch
+		{scope.Lookup("area"), []string{"area", "s", "s", "s", "s", "r"}},
+	}
+	for _, tt := range tests {
+		d, ok := idx.Decl(tt.obj)
+		if !ok {
+			t.Errorf("no declaration for %s", tt.obj)
+			continue
+		}
+		got := names(idx.Owned(d.Node()))
+		if len(got) != len(tt.owned) {
+			t.Errorf("%s owns %v, want %v", tt.obj.Name(), got, tt.owned)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.owned[i] {
+				t.Errorf("%s owns %v, want %v", tt.obj.Name(), got, tt.owned)
+				break
+			}
+		}
+		for _, obj := range idx.Owned(d.Node()) {
+			if od, _ := idx.Decl(obj); od.Node() != d.Node() {
+				t.Errorf("%s isn't owned by the declaration of %s", obj, tt.obj.Name())
+			}
+		}
+	}
+
+	if d, _ := idx.Decl(method); d.Spec != nil {
+		t.Errorf("got spec %v for function declaration", d.Spec)
+	} else if _, ok := d.Decl.(*ast.FuncDecl); !ok {
+		t.Errorf("got %T, want *ast.FuncDecl", d.Decl)
+	}
+}
@@ -0,0 +1,34 @@
+package pkg
+
+type T struct {
+	F int
+}
+
+func (t T) Method(x int) {
+	y := x
+	_ = y
+}
+
+var (
+	a, b = 1, 2
+	c    int
+)
+
+const d = 0
+
+type shape interface{}
+
+type circle struct{ r int }
+
+func area(s shape) int {
+	switch s := s.(type) {
+	case circle:
+		r := s.r
+		return r * r
+	case nil:
+		return 0
+	default:
+		_ = s
+		return -1
+	}
+}
@@ -27,7 +27,7 @@ func (a *Analyzer) initialize() {
 	a.Analyzer.Doc = a.Doc.String()
 	if a.Analyzer.Flags.Usage == nil {
 		fs := flag.NewFlagSet("", flag.PanicOnError)
-		fs.Var(newVersionFlag(), "go", "Target Go version")
+		fs.Var(NewVersionFlag(), "go", "Target Go version")
 		a.Analyzer.Flags = *fs
 	}
 }
@@ -190,7 +190,11 @@ func (doc *Documentation) String() string {
 	return doc.Format(true)
 }
 
-func newVersionFlag() flag.Getter {
+// NewVersionFlag returns a flag for the targeted Go version, which
+// defaults to the version of the Go release the program was built
+// with. Analyzers that don't get initialized by InitializeAnalyzers
+// can use it to provide the "go" flag that code.IsGoVersion consults.
+func NewVersionFlag() flag.Getter {
 	tags := build.Default.ReleaseTags
 	v := tags[len(tags)-1][2:]
 	version := new(VersionFlag)
@@ -7,6 +7,7 @@ import (
 	"go/format"
 	"go/token"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
 
@@ -226,6 +227,31 @@ func DisplayPosition(fset *token.FileSet, p token.Pos) token.Position {
 	return pos
 }
 
+// NormalizeFilename returns a canonical form of a file name, for
+// comparing the positions of directives and the objects they apply to.
+// File names are cleaned and, on platforms whose file systems are
+// usually case-insensitive, lowercased. On Windows, the long path
+// prefix \\?\ is removed.
+func NormalizeFilename(name string) string {
+	if name == "" {
+		return ""
+	}
+	return normalizeFilename(filepath.Clean(name), runtime.GOOS)
+}
+
+func normalizeFilename(name string, goos string) string {
+	switch goos {
+	case "windows":
+		name = strings.TrimPrefix(name, `\\?\`)
+		name = strings.ReplaceAll(name, "/", `\`)
+		return strings.ToLower(name)
+	case "darwin", "ios":
+		return strings.ToLower(name)
+	default:
+		return name
+	}
+}
+
 func Ordinal(n int) string {
 	suffix := "th"
 	if n < 10 || n > 20 {
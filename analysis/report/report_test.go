@@ -17,3 +17,22 @@ func TestOrdinal(t *testing.T) {
 		}
 	}
 }
+
+func TestNormalizeFilename(t *testing.T) {
+	tests := []struct {
+		name string
+		goos string
+		want string
+	}{
+		{"/home/user/Foo.go", "linux", "/home/user/Foo.go"},
+		{"/Users/user/Foo.go", "darwin", "/users/user/foo.go"},
+		{`C:\Users\User\Foo.go`, "windows", `c:\users\user\foo.go`},
+		{`\\?\C:\Users\User\Foo.go`, "windows", `c:\users\user\foo.go`},
+		{`C:/Users/User/Foo.go`, "windows", `c:\users\user\foo.go`},
+	}
+	for _, tt := range tests {
+		if got := normalizeFilename(tt.name, tt.goos); got != tt.want {
+			t.Errorf("normalizeFilename(%q, %q) = %q, want %q", tt.name, tt.goos, got, tt.want)
+		}
+	}
+}
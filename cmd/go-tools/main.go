@@ -0,0 +1,80 @@
+// go-tools bundles the tools of this repository into a single binary.
+//
+// Its check command runs all analyzers, including the detection of
+// unused code, with the same configuration files, caching and output
+// formats as staticcheck.
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"honnef.co/go/tools/lintcmd"
+	"honnef.co/go/tools/lintcmd/version"
+	"honnef.co/go/tools/simple"
+	"honnef.co/go/tools/staticcheck"
+	"honnef.co/go/tools/stylecheck"
+	"honnef.co/go/tools/unused"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: go-tools <command> [arguments]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	fmt.Fprintln(os.Stderr, "  check    check packages for bugs, style issues and unused code")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Use 'go-tools <command> -help' for more information about a command.")
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "check":
+		check(os.Args[2:])
+	case "help", "-h", "-help", "--help":
+		usage()
+	default:
+		fmt.Fprintf(os.Stderr, "go-tools: unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+}
+
+func check(args []string) {
+	cmd := lintcmd.NewCommand("go-tools check")
+	cmd.SetVersion(version.Version, version.MachineVersion)
+
+	fs := cmd.FlagSet()
+	debug := fs.String("debug.unused-graph", "", "Write unused's object graph to `file`")
+	skips := fs.String("debug.unused-skips", "", "Write a log of the constructs that unused ignored to `file`, as JSON lines")
+
+	cmd.ParseFlags(args)
+
+	cmd.AddAnalyzers(simple.Analyzers...)
+	cmd.AddAnalyzers(staticcheck.Analyzers...)
+	cmd.AddAnalyzers(stylecheck.Analyzers...)
+	cmd.AddAnalyzers(unused.Analyzer, unused.ForbiddenAnalyzer)
+
+	if *debug != "" {
+		f, err := os.OpenFile(*debug, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+		if err != nil {
+			log.Fatal(err)
+		}
+		unused.Debug = f
+	}
+
+	if *skips != "" {
+		f, err := os.OpenFile(*skips, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+		if err != nil {
+			log.Fatal(err)
+		}
+		unused.SkipLog = f
+	}
+
+	cmd.Run()
+}
@@ -20,6 +20,9 @@ func main() {
 
 	fs := cmd.FlagSet()
 	debug := fs.String("debug.unused-graph", "", "Write unused's object graph to `file`")
+	skips := fs.String("debug.unused-skips", "", "Write a log of the constructs that unused ignored to `file`, as JSON lines")
+	quick := fs.Bool("debug.unused-quick-scan", false, "Skip the bodies of unreachable functions in unused")
+	positions := fs.Bool("debug.unused-edge-positions", false, "Record the positions of the references in unused's object graph, at the cost of memory")
 	qf := fs.Bool("debug.run-quickfix-analyzers", false, "Run quickfix analyzers")
 
 	cmd.ParseFlags(os.Args[1:])
@@ -27,7 +30,7 @@ func main() {
 	cmd.AddAnalyzers(simple.Analyzers...)
 	cmd.AddAnalyzers(staticcheck.Analyzers...)
 	cmd.AddAnalyzers(stylecheck.Analyzers...)
-	cmd.AddAnalyzers(unused.Analyzer)
+	cmd.AddAnalyzers(unused.Analyzer, unused.ForbiddenAnalyzer)
 
 	if *qf {
 		cmd.AddAnalyzers(quickfix.Analyzers...)
@@ -41,5 +44,16 @@ func main() {
 		unused.Debug = f
 	}
 
+	if *skips != "" {
+		f, err := os.OpenFile(*skips, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+		if err != nil {
+			log.Fatal(err)
+		}
+		unused.SkipLog = f
+	}
+
+	unused.QuickScan = *quick
+	unused.EdgePositions = *positions
+
 	cmd.Run()
 }
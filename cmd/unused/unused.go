@@ -0,0 +1,48 @@
+// unused reports unused code. It is staticcheck with only the U1000
+// check, for running dead code checks without the rest of the suite.
+package main
+
+import (
+	"log"
+	"os"
+
+	"honnef.co/go/tools/lintcmd"
+	"honnef.co/go/tools/lintcmd/version"
+	"honnef.co/go/tools/unused"
+)
+
+func main() {
+	cmd := lintcmd.NewCommand("unused")
+	cmd.SetVersion(version.Version, version.MachineVersion)
+
+	fs := cmd.FlagSet()
+	debug := fs.String("debug.unused-graph", "", "Write unused's object graph to `file`")
+	skips := fs.String("debug.unused-skips", "", "Write a log of the constructs that unused ignored to `file`, as JSON lines")
+	quick := fs.Bool("debug.unused-quick-scan", false, "Skip the bodies of unreachable functions in unused")
+	positions := fs.Bool("debug.unused-edge-positions", false, "Record the positions of the references in unused's object graph, at the cost of memory")
+
+	cmd.ParseFlags(os.Args[1:])
+
+	cmd.AddAnalyzers(unused.Analyzer, unused.ForbiddenAnalyzer)
+
+	if *debug != "" {
+		f, err := os.OpenFile(*debug, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+		if err != nil {
+			log.Fatal(err)
+		}
+		unused.Debug = f
+	}
+
+	if *skips != "" {
+		f, err := os.OpenFile(*skips, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+		if err != nil {
+			log.Fatal(err)
+		}
+		unused.SkipLog = f
+	}
+
+	unused.QuickScan = *quick
+	unused.EdgePositions = *positions
+
+	cmd.Run()
+}
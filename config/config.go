@@ -6,9 +6,12 @@ import (
 	"go/ast"
 	"go/token"
 	"os"
+	"path"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"strings"
+	"text/template"
 
 	"github.com/BurntSushi/toml"
 	"golang.org/x/tools/go/analysis"
@@ -60,23 +63,54 @@ var Analyzer = &analysis.Analyzer{
 	Name: "config",
 	Doc:  "loads configuration for the current package tree",
 	Run: func(pass *analysis.Pass) (interface{}, error) {
-		dir := dirAST(pass.Files, pass.Fset)
-		if dir == "" {
-			cfg := DefaultConfig
-			return &cfg, nil
-		}
-		cfg, err := Load(dir)
-		if err != nil {
-			return nil, fmt.Errorf("error loading staticcheck.conf: %s", err)
-		}
-		return &cfg, nil
+		return loadFor(pass, DefaultConfig)
 	},
 	RunDespiteErrors: true,
 	ResultType:       reflect.TypeOf((*Config)(nil)),
 }
 
+// NewAnalyzer returns an analyzer like Analyzer that merges
+// staticcheck.conf files into defaults instead of DefaultConfig.
+// Analyzers that require it instead of Analyzer get its configuration
+// from For.
+func NewAnalyzer(defaults Config) *analysis.Analyzer {
+	return &analysis.Analyzer{
+		Name: "config",
+		Doc:  "loads configuration for the current package tree",
+		Run: func(pass *analysis.Pass) (interface{}, error) {
+			return loadFor(pass, defaults)
+		},
+		RunDespiteErrors: true,
+		ResultType:       Analyzer.ResultType,
+	}
+}
+
+func loadFor(pass *analysis.Pass, defaults Config) (*Config, error) {
+	dir := dirAST(pass.Files, pass.Fset)
+	if dir == "" {
+		cfg := defaults
+		return &cfg, nil
+	}
+	cfg, err := load(dir, defaults)
+	if err != nil {
+		return nil, fmt.Errorf("error loading staticcheck.conf: %s", err)
+	}
+	return &cfg, nil
+}
+
+// For returns the configuration of the pass's package, as loaded by
+// Analyzer or by the analyzer returned by NewAnalyzer that the pass's
+// analyzer requires.
 func For(pass *analysis.Pass) *Config {
-	return pass.ResultOf[Analyzer].(*Config)
+	if cfg, ok := pass.ResultOf[Analyzer]; ok {
+		return cfg.(*Config)
+	}
+	for _, req := range pass.Analyzer.Requires {
+		if req.ResultType == Analyzer.ResultType {
+			return pass.ResultOf[req].(*Config)
+		}
+	}
+	panic(fmt.Sprintf("analyzer %s doesn't require a config analyzer", pass.Analyzer.Name))
 }
 
 func mergeLists(a, b []string) []string {
@@ -128,6 +162,123 @@ func (cfg Config) Merge(ocfg Config) Config {
 	if ocfg.HTTPStatusCodeWhitelist != nil {
 		cfg.HTTPStatusCodeWhitelist = mergeLists(cfg.HTTPStatusCodeWhitelist, ocfg.HTTPStatusCodeWhitelist)
 	}
+	if ocfg.GeneratedHeaders != nil {
+		cfg.GeneratedHeaders = mergeLists(cfg.GeneratedHeaders, ocfg.GeneratedHeaders)
+	}
+	if ocfg.GeneratedPaths != nil {
+		cfg.GeneratedPaths = mergeLists(cfg.GeneratedPaths, ocfg.GeneratedPaths)
+	}
+	if ocfg.Severity != nil {
+		severity := make(map[string]string, len(cfg.Severity)+len(ocfg.Severity))
+		for k, v := range cfg.Severity {
+			severity[k] = v
+		}
+		for k, v := range ocfg.Severity {
+			severity[k] = v
+		}
+		cfg.Severity = severity
+	}
+	cfg.Unused = cfg.Unused.Merge(ocfg.Unused)
+	return cfg
+}
+
+func (cfg Unused) Merge(ocfg Unused) Unused {
+	if ocfg.MaxNodes != 0 {
+		cfg.MaxNodes = ocfg.MaxNodes
+	}
+	if ocfg.MaxEdges != 0 {
+		cfg.MaxEdges = ocfg.MaxEdges
+	}
+	if ocfg.SideEffectFunctions != nil {
+		cfg.SideEffectFunctions = mergeLists(cfg.SideEffectFunctions, ocfg.SideEffectFunctions)
+	}
+	if ocfg.Keep != nil {
+		cfg.Keep = mergeLists(cfg.Keep, ocfg.Keep)
+	}
+	if ocfg.Generated != "" {
+		cfg.Generated = ocfg.Generated
+	}
+	if ocfg.SkimGenerated != 0 {
+		cfg.SkimGenerated = ocfg.SkimGenerated
+	}
+	if ocfg.GoGenerate != "" {
+		cfg.GoGenerate = ocfg.GoGenerate
+	}
+	if ocfg.Positions != "" {
+		cfg.Positions = ocfg.Positions
+	}
+	if ocfg.Profile != "" {
+		cfg.Profile = ocfg.Profile
+	}
+	if ocfg.IgnoreReasonMinLength != 0 {
+		cfg.IgnoreReasonMinLength = ocfg.IgnoreReasonMinLength
+	}
+	if ocfg.IgnoreReasonPattern != "" {
+		cfg.IgnoreReasonPattern = ocfg.IgnoreReasonPattern
+	}
+	if ocfg.WholeProgram {
+		cfg.WholeProgram = true
+	}
+	if ocfg.VerifyFixes {
+		cfg.VerifyFixes = true
+	}
+	if ocfg.Ownership {
+		cfg.Ownership = true
+	}
+	if ocfg.Reasons {
+		cfg.Reasons = true
+	}
+	if ocfg.MockPackages != nil {
+		cfg.MockPackages = mergeLists(cfg.MockPackages, ocfg.MockPackages)
+	}
+	if ocfg.TestSupportPackages != nil {
+		cfg.TestSupportPackages = mergeLists(cfg.TestSupportPackages, ocfg.TestSupportPackages)
+	}
+	if ocfg.Impact != nil {
+		cfg.Impact = mergeLists(cfg.Impact, ocfg.Impact)
+	}
+	if ocfg.Conversions != nil {
+		cfg.Conversions = mergeLists(cfg.Conversions, ocfg.Conversions)
+	}
+	if ocfg.Routes != nil {
+		cfg.Routes = mergeLists(cfg.Routes, ocfg.Routes)
+	}
+	if ocfg.RuntimeFunctions != nil {
+		cfg.RuntimeFunctions = mergeLists(cfg.RuntimeFunctions, ocfg.RuntimeFunctions)
+	}
+	if ocfg.Forbid != nil {
+		cfg.Forbid = append(cfg.Forbid[:len(cfg.Forbid):len(cfg.Forbid)], ocfg.Forbid...)
+	}
+	if ocfg.ExternalAPI != nil {
+		api := make(map[string]bool, len(cfg.ExternalAPI)+len(ocfg.ExternalAPI))
+		for k, v := range cfg.ExternalAPI {
+			api[k] = v
+		}
+		for k, v := range ocfg.ExternalAPI {
+			api[k] = v
+		}
+		cfg.ExternalAPI = api
+	}
+	if ocfg.MessageTemplates != nil {
+		templates := make(map[string]string, len(cfg.MessageTemplates)+len(ocfg.MessageTemplates))
+		for k, v := range cfg.MessageTemplates {
+			templates[k] = v
+		}
+		for k, v := range ocfg.MessageTemplates {
+			templates[k] = v
+		}
+		cfg.MessageTemplates = templates
+	}
+	if ocfg.Rules != nil {
+		rules := make(map[string]bool, len(cfg.Rules)+len(ocfg.Rules))
+		for k, v := range cfg.Rules {
+			rules[k] = v
+		}
+		for k, v := range ocfg.Rules {
+			rules[k] = v
+		}
+		cfg.Rules = rules
+	}
 	return cfg
 }
 
@@ -143,6 +294,430 @@ type Config struct {
 	Initialisms             []string `toml:"initialisms"`
 	DotImportWhitelist      []string `toml:"dot_import_whitelist"`
 	HTTPStatusCodeWhitelist []string `toml:"http_status_code_whitelist"`
+
+	// GeneratedHeaders and GeneratedPaths recognize generated files
+	// that lack the standard "Code generated ... DO NOT EDIT." header,
+	// in addition to the detectors registered with the generated
+	// package. GeneratedHeaders are regular expressions that match
+	// lines preceding the package clause, GeneratedPaths are patterns
+	// that match the files' paths or their trailing elements.
+	GeneratedHeaders []string `toml:"generated_headers"`
+	GeneratedPaths   []string `toml:"generated_paths"`
+
+	// Severity maps categories of findings to the severities they
+	// get reported with, one of SeverityError, SeverityWarning and
+	// SeverityInfo. See SeverityOf for the possible categories.
+	// Severities are merged key by key.
+	Severity map[string]string `toml:"severity"`
+
+	Unused Unused `toml:"unused"`
+}
+
+const (
+	// SeverityError reports findings as errors, which cause a
+	// non-zero exit status.
+	SeverityError = "error"
+	// SeverityWarning reports findings as warnings.
+	SeverityWarning = "warning"
+	// SeverityInfo reports findings as informational.
+	SeverityInfo = "info"
+)
+
+// SeverityStaleIgnore is the category of ignore directives that
+// didn't match any findings.
+const SeverityStaleIgnore = "stale_ignore"
+
+// SeverityIgnoreReason is the category of ignore directives for U1000
+// whose reasons don't satisfy Unused.IgnoreReasonMinLength and
+// Unused.IgnoreReasonPattern.
+const SeverityIgnoreReason = "ignore_reason"
+
+// SeverityOf returns the configured severity of a finding. Categories
+// are tried in order, from the most to the least specific, for example
+// "U1000.assigned", "U1000.field" and "U1000". It returns false if
+// none of the categories have a configured severity.
+func (c Config) SeverityOf(categories ...string) (string, bool) {
+	for _, cat := range categories {
+		if sev, ok := c.Severity[cat]; ok {
+			return sev, true
+		}
+	}
+	return "", false
+}
+
+// MatchTree reports whether pattern, in the syntax of path.Match,
+// matches name, a slash-separated path such as an import path. A
+// pattern ending in /... also matches name if the rest of the pattern
+// matches any of name's parent directories, which is how the patterns
+// of ExternalAPI and TestSupportPackages match all packages below.
+func MatchTree(pattern, name string) bool {
+	prefix := strings.TrimSuffix(pattern, "/...")
+	for {
+		if ok, _ := path.Match(prefix, name); ok {
+			return true
+		}
+		if prefix == pattern {
+			return false
+		}
+		i := strings.LastIndexByte(name, '/')
+		if i < 0 {
+			return false
+		}
+		name = name[:i]
+	}
+}
+
+// Unused holds the options of the unused code analyzer (U1000).
+type Unused struct {
+	// MaxNodes and MaxEdges limit the size of the object graph that
+	// gets built for a single package. Packages that exceed either
+	// limit aren't analyzed, and no objects in them get reported. A
+	// value of zero means no limit.
+	MaxNodes int `toml:"max_nodes"`
+	MaxEdges int `toml:"max_edges"`
+
+	// SideEffectFunctions is a list of patterns of functions that are
+	// called for their side effects. Package-level variables whose
+	// initializers call such functions are never reported.
+	SideEffectFunctions []string `toml:"side_effect_functions"`
+
+	// Keep is a list of patterns of objects that are never reported.
+	Keep []string `toml:"keep"`
+
+	// Generated controls the handling of objects declared in
+	// generated files. It is one of GeneratedIgnore, GeneratedReport,
+	// GeneratedKeep and GeneratedStrict.
+	Generated string `toml:"generated"`
+
+	// SkimGenerated is the number of lines from which on generated
+	// files get skimmed: their objects are considered used, and the
+	// bodies of their functions only get searched for the objects
+	// they refer to. This speeds up the analysis of packages with
+	// huge generated files. It has no effect if Generated is
+	// GeneratedReport. A value of zero disables skimming.
+	SkimGenerated int `toml:"skim_generated"`
+
+	// GoGenerate controls the handling of objects that the command
+	// lines of //go:generate directives mention by name, such as Foo
+	// in //go:generate go run ./cmd/gen -impl Foo, which generators
+	// may look up by reflection. It is one of GoGenerateOff,
+	// GoGenerateMention and GoGenerateKeep.
+	GoGenerate string `toml:"go_generate"`
+
+	// Positions controls which positions of objects in files with
+	// line directives get reported. It is one of PositionsDisplay,
+	// PositionsRaw and PositionsAdjusted.
+	Positions string `toml:"positions"`
+
+	// Profile selects a set of rules at once. It is one of
+	// ProfileLenient, ProfileDefault, ProfileStrict and ProfileAudit.
+	// After loading, Rules contains the rules of the profile.
+	Profile string `toml:"profile"`
+
+	// Rules enables or disables individual rules, such as
+	// RuleConstGroups. Rules are merged key by key, so that a
+	// configuration file only needs to list the rules it changes.
+	// Rules set by configuration files take precedence over the
+	// rules of the profile, regardless of which file sets the
+	// profile.
+	Rules map[string]bool `toml:"rules"`
+
+	// WholeProgram treats the analyzed packages as a complete
+	// program. Exported objects are no longer considered used merely
+	// by being exported; they have to be used by one of the analyzed
+	// packages instead. Once enabled, it cannot be disabled by
+	// configuration files further down the tree.
+	WholeProgram bool `toml:"whole_program"`
+
+	// MockPackages is a list of patterns of import paths of packages
+	// that contain generated mocks. In whole-program mode, the uses
+	// of objects by mock packages don't keep these objects alive,
+	// while the mocks' own objects are still analyzed.
+	MockPackages []string `toml:"mock_packages"`
+
+	// TestSupportPackages is a list of patterns of import paths of
+	// packages that only tests import, such as
+	// example.com/internal/testutil/..., matched like the patterns of
+	// ExternalAPI. In whole-program mode, their exported objects are
+	// used merely by being exported, like those of test files, as the
+	// tests using them may not be analyzed. RuleTestSupportImports
+	// classifies more packages this way.
+	TestSupportPackages []string `toml:"test_support_packages"`
+
+	// ExternalAPI maps patterns of import paths to whether the
+	// matching packages are used by code that isn't analyzed, such as
+	// other repositories. In whole-program mode, the exported objects
+	// of such packages are used merely by being exported. Patterns
+	// set to false exempt packages from broader patterns set to true;
+	// the longest matching pattern wins. Patterns ending in /... also
+	// match all packages below. Patterns are merged key by key.
+	ExternalAPI map[string]bool `toml:"external_api"`
+
+	// MessageTemplates maps categories of findings, like Severity
+	// does, to text/template templates of their messages. Templates
+	// get executed with an unused.MessageContext. The template of the
+	// most specific category applies; findings without a template
+	// keep their default messages. Templates are merged key by key.
+	MessageTemplates map[string]string `toml:"message_templates"`
+
+	// VerifyFixes applies the suggested fixes to copies of each
+	// package's files and type-checks the result, dropping fixes
+	// that would break the build. Once enabled, it cannot be
+	// disabled by configuration files further down the tree.
+	VerifyFixes bool `toml:"verify_fixes"`
+
+	// Forbid lists references between packages and objects that
+	// aren't allowed, such as from HTTP handlers to the internals of
+	// the database layer. Rules accumulate across configuration
+	// files.
+	Forbid []Forbidden `toml:"forbid"`
+
+	// Impact is a list of patterns of objects, matched like the
+	// patterns of Keep, whose references from other packages get
+	// recorded. This tells maintainers what removing the objects
+	// would break.
+	Impact []string `toml:"impact"`
+
+	// Routes is a list of patterns of HTTP routes, matched with
+	// path.Match, that are known to be requested, such as the routes
+	// that a service's configuration refers to. RuleDeadRoutes
+	// considers the handlers of these routes live.
+	Routes []string `toml:"routes"`
+
+	// RuntimeFunctions is a list of names of functions of the runtime
+	// package that are called by the compiler or by assembly, in
+	// addition to the ones of the targeted Go release. This is useful
+	// when checking forks of the runtime, or releases newer than the
+	// analysis knows about.
+	RuntimeFunctions []string `toml:"runtime_functions"`
+
+	// IgnoreReasonMinLength is the minimum number of characters of
+	// the reasons of ignore directives that suppress U1000, such as
+	// //lint:ignore U1000 <reason>. Directives with shorter reasons
+	// get reported. A value of zero disables the check.
+	IgnoreReasonMinLength int `toml:"ignore_reason_min_length"`
+
+	// IgnoreReasonPattern is a regular expression that the reasons
+	// of ignore directives that suppress U1000 have to contain a
+	// match of, such as a ticket reference like [A-Z]+-[0-9]+.
+	// Directives whose reasons contain no match get reported.
+	IgnoreReasonPattern string `toml:"ignore_reason_pattern"`
+
+	// Ownership records the declaration tree of each package, for
+	// the -unused.ownership flag. It cannot be set by configuration
+	// files.
+	Ownership bool `toml:"-"`
+
+	// Reasons records why each used object is used, for the
+	// -unused.snapshot flag. It cannot be set by configuration files.
+	Reasons bool `toml:"-"`
+
+	// Conversions is a list of patterns of struct types, matched
+	// like the patterns of Keep, whose conversions to and from other
+	// struct types get recorded, for the -unused.conversions flag.
+	// It cannot be set by configuration files.
+	Conversions []string `toml:"-"`
+}
+
+// A Forbidden rule forbids the objects of some packages from
+// referencing certain objects.
+type Forbidden struct {
+	// From is a pattern of import paths of the packages that the
+	// rule applies to, such as example.com/app/handlers/*.
+	From string `toml:"from"`
+	// To is a pattern of the objects that these packages mustn't
+	// reference, matched like the patterns of Keep, such as
+	// example.com/app/db/internal.*.
+	To string `toml:"to"`
+	// Reason is included in the reported problems.
+	Reason string `toml:"reason"`
+}
+
+const (
+	// GeneratedIgnore analyzes generated code, but doesn't report
+	// unused objects declared in it.
+	GeneratedIgnore = "ignore"
+	// GeneratedReport reports unused objects in generated code like
+	// any other unused objects.
+	GeneratedReport = "report"
+	// GeneratedKeep considers all objects declared in generated code
+	// used.
+	GeneratedKeep = "keep"
+	// GeneratedStrict is like GeneratedIgnore, but doesn't let the
+	// init functions and package-level variables of generated files
+	// keep objects alive. Objects that only they use, such as
+	// constructors that generated code registers, are reported as
+	// only used by generated code.
+	GeneratedStrict = "strict"
+)
+
+const (
+	// GoGenerateOff doesn't look at //go:generate directives.
+	GoGenerateOff = "off"
+	// GoGenerateMention reports unused objects that //go:generate
+	// directives mention as such, without suggesting to delete them.
+	GoGenerateMention = "mention"
+	// GoGenerateKeep considers objects that //go:generate directives
+	// mention used.
+	GoGenerateKeep = "keep"
+)
+
+const (
+	// PositionsDisplay reports positions adjusted by line directives
+	// if they point to Go files, and raw positions otherwise. This
+	// points at the user's code for files rewritten by cgo, but not
+	// at grammar files for parsers generated by goyacc.
+	PositionsDisplay = "display"
+	// PositionsRaw reports positions in the files that were
+	// compiled, ignoring line directives.
+	PositionsRaw = "raw"
+	// PositionsAdjusted reports positions as adjusted by line
+	// directives.
+	PositionsAdjusted = "adjusted"
+)
+
+const (
+	// RuleConstGroups marks all constants in a group as used if any
+	// of them is used.
+	RuleConstGroups = "const_groups"
+	// RuleTestSinks considers assignments to package-level variables
+	// in tests as uses.
+	RuleTestSinks = "test_sinks"
+	// RuleReceiverNames flags named receivers that are never used.
+	RuleReceiverNames = "receiver_names"
+	// RuleIotaEnums exempts const declarations that use iota from
+	// RuleConstGroups, so that unused values of enumerations get
+	// reported.
+	RuleIotaEnums = "iota_enums"
+	// RuleTestedOnly reports functions that are only used by their
+	// own tests, benchmarks, fuzz tests and examples, instead of
+	// considering them used.
+	RuleTestedOnly = "tested_only"
+	// RuleDocLinks considers objects used if doc comments link to
+	// them, such as with [Name] or [Name.Method].
+	RuleDocLinks = "doc_links"
+	// RuleEscapeAnalysis only considers the exported methods of
+	// unexported types used if values of the types escape the
+	// package, instead of always.
+	RuleEscapeAnalysis = "escape_analysis"
+	// RuleNamedResults flags named results that are never used.
+	RuleNamedResults = "named_results"
+	// RuleComparisons considers the fields of structs used if the
+	// structs get compared with == or != or reflect.DeepEqual.
+	RuleComparisons = "comparisons"
+	// RuleInterfaceAssertions reports objects that are only used by
+	// compile-time interface assertions, such as
+	// var _ io.Reader = (*T)(nil), instead of considering them used.
+	RuleInterfaceAssertions = "interface_assertions"
+	// RuleDependencyInjection considers the constructors and types
+	// that calls of the wire, fx and dig dependency injection
+	// frameworks refer to used, including the members of the types.
+	RuleDependencyInjection = "dependency_injection"
+	// RuleDeadRoutes reports functions that are only referenced by
+	// their registrations as HTTP handlers, for routes that neither
+	// tests nor Routes mention.
+	RuleDeadRoutes = "dead_routes"
+	// RuleExportedFuncVars considers exported package-level variables
+	// of function types used, like exported functions. Disabling it
+	// treats such variables as configuration rather than API, which
+	// has to be used by the package itself.
+	RuleExportedFuncVars = "exported_func_vars"
+	// RuleFatalPaths reports objects that are only used by code paths
+	// that always panic or exit the program, such as calls to
+	// log.Fatal, instead of considering them used.
+	RuleFatalPaths = "fatal_paths"
+	// RuleIgnoredFiles reports objects that are only referred to by
+	// Go files excluded with //go:build ignore, such as helper
+	// scripts, as such, instead of as plainly unused.
+	RuleIgnoredFiles = "ignored_files"
+	// RuleTestSupportImports treats packages that only _test.go files
+	// import as test support packages, see TestSupportPackages.
+	RuleTestSupportImports = "test_support_imports"
+	// RuleUnusedPackages reports packages that no non-test code of
+	// the analyzed packages imports, directly or indirectly, in
+	// whole-program mode.
+	RuleUnusedPackages = "unused_packages"
+	// RuleUnformattedMethods reports String and Error methods of
+	// unexported types whose values never get converted to
+	// interfaces, escape the package or become type arguments, and
+	// thus never reach fmt or other code that formats values through
+	// fmt.Stringer or error, instead of considering them used for
+	// implementing these interfaces.
+	RuleUnformattedMethods = "unformatted_methods"
+	// RuleDuplicateFunctions points out unused unexported functions
+	// and methods whose bodies duplicate used ones, see
+	// unused.Result.Duplicates.
+	RuleDuplicateFunctions = "duplicate_functions"
+)
+
+const (
+	// ProfileLenient only reports objects that are unused beyond
+	// doubt, at the cost of missing some.
+	ProfileLenient = "lenient"
+	// ProfileDefault is the default set of rules.
+	ProfileDefault = "default"
+	// ProfileStrict also reports objects that are only used in ways
+	// that don't matter to the program, such as by their own tests
+	// or by interface assertions.
+	ProfileStrict = "strict"
+	// ProfileAudit enables every rule that reports more, for
+	// occasional cleanups rather than for continuous integration.
+	ProfileAudit = "audit"
+)
+
+// profiles maps each profile to the rules that it sets differently
+// from the default profile.
+var profiles = map[string]map[string]bool{
+	ProfileLenient: {
+		RuleDocLinks: true,
+	},
+	ProfileDefault: {},
+	ProfileStrict: {
+		RuleIotaEnums:           true,
+		RuleTestedOnly:          true,
+		RuleEscapeAnalysis:      true,
+		RuleInterfaceAssertions: true,
+	},
+	ProfileAudit: {
+		RuleConstGroups:         false,
+		RuleTestSinks:           false,
+		RuleReceiverNames:       true,
+		RuleIotaEnums:           true,
+		RuleTestedOnly:          true,
+		RuleEscapeAnalysis:      true,
+		RuleNamedResults:        true,
+		RuleInterfaceAssertions: true,
+		RuleDeadRoutes:          true,
+		RuleExportedFuncVars:    false,
+		RuleFatalPaths:          true,
+		RuleIgnoredFiles:        true,
+		RuleUnusedPackages:      true,
+		RuleUnformattedMethods:  true,
+		RuleDuplicateFunctions:  true,
+	},
+}
+
+// ProfileRules returns the rules of a profile. It returns false if
+// there is no such profile.
+func ProfileRules(profile string) (map[string]bool, bool) {
+	diff, ok := profiles[profile]
+	if !ok {
+		return nil, false
+	}
+	return profileRules(DefaultConfig.Unused.Rules, diff), true
+}
+
+// profileRules returns the rules that result from applying a profile's
+// changes to the default rules.
+func profileRules(defaults, diff map[string]bool) map[string]bool {
+	rules := make(map[string]bool, len(defaults))
+	for k, v := range defaults {
+		rules[k] = v
+	}
+	for k, v := range diff {
+		rules[k] = v
+	}
+	return rules
 }
 
 func (c Config) String() string {
@@ -151,7 +726,11 @@ func (c Config) String() string {
 	fmt.Fprintf(buf, "Checks: %#v\n", c.Checks)
 	fmt.Fprintf(buf, "Initialisms: %#v\n", c.Initialisms)
 	fmt.Fprintf(buf, "DotImportWhitelist: %#v\n", c.DotImportWhitelist)
-	fmt.Fprintf(buf, "HTTPStatusCodeWhitelist: %#v", c.HTTPStatusCodeWhitelist)
+	fmt.Fprintf(buf, "HTTPStatusCodeWhitelist: %#v\n", c.HTTPStatusCodeWhitelist)
+	fmt.Fprintf(buf, "GeneratedHeaders: %#v\n", c.GeneratedHeaders)
+	fmt.Fprintf(buf, "GeneratedPaths: %#v\n", c.GeneratedPaths)
+	fmt.Fprintf(buf, "Severity: %#v\n", c.Severity)
+	fmt.Fprintf(buf, "Unused: %#v", c.Unused)
 
 	return buf.String()
 }
@@ -179,6 +758,40 @@ var DefaultConfig = Config{
 		"github.com/mmcloughlin/avo/reg",
 	},
 	HTTPStatusCodeWhitelist: []string{"200", "400", "404", "500"},
+	GeneratedHeaders:        []string{},
+	GeneratedPaths:          []string{},
+	Severity:                map[string]string{},
+	Unused: Unused{
+		SideEffectFunctions: []string{},
+		Keep:                []string{},
+		MockPackages:        []string{},
+		TestSupportPackages: []string{},
+		Generated:           GeneratedIgnore,
+		GoGenerate:          GoGenerateOff,
+		Positions:           PositionsDisplay,
+		Profile:             ProfileDefault,
+		Rules: map[string]bool{
+			RuleConstGroups:         true,
+			RuleTestSinks:           true,
+			RuleReceiverNames:       false,
+			RuleIotaEnums:           false,
+			RuleTestedOnly:          false,
+			RuleDocLinks:            false,
+			RuleEscapeAnalysis:      false,
+			RuleNamedResults:        false,
+			RuleComparisons:         true,
+			RuleInterfaceAssertions: false,
+			RuleDependencyInjection: true,
+			RuleDeadRoutes:          false,
+			RuleExportedFuncVars:    true,
+			RuleFatalPaths:          false,
+			RuleIgnoredFiles:        false,
+			RuleTestSupportImports:  false,
+			RuleUnusedPackages:      false,
+			RuleUnformattedMethods:  false,
+			RuleDuplicateFunctions:  false,
+		},
+	},
 }
 
 const ConfigName = "staticcheck.conf"
@@ -188,7 +801,7 @@ type ParseError struct {
 	toml.ParseError
 }
 
-func parseConfigs(dir string) ([]Config, error) {
+func parseConfigs(dir string, defaults Config) ([]Config, error) {
 	var out []Config
 
 	// TODO(dh): consider stopping at the GOPATH/module boundary
@@ -224,7 +837,7 @@ func parseConfigs(dir string) ([]Config, error) {
 		}
 		dir = ndir
 	}
-	out = append(out, DefaultConfig)
+	out = append(out, defaults)
 	if len(out) < 2 {
 		return out, nil
 	}
@@ -251,7 +864,11 @@ func mergeConfigs(confs []Config) Config {
 }
 
 func Load(dir string) (Config, error) {
-	confs, err := parseConfigs(dir)
+	return load(dir, DefaultConfig)
+}
+
+func load(dir string, defaults Config) (Config, error) {
+	confs, err := parseConfigs(dir, defaults)
 	if err != nil {
 		return Config{}, err
 	}
@@ -261,6 +878,99 @@ func Load(dir string) (Config, error) {
 	conf.Initialisms = normalizeList(conf.Initialisms)
 	conf.DotImportWhitelist = normalizeList(conf.DotImportWhitelist)
 	conf.HTTPStatusCodeWhitelist = normalizeList(conf.HTTPStatusCodeWhitelist)
+	conf.GeneratedHeaders = normalizeList(conf.GeneratedHeaders)
+	conf.GeneratedPaths = normalizeList(conf.GeneratedPaths)
+	for _, header := range conf.GeneratedHeaders {
+		if _, err := regexp.Compile(header); err != nil {
+			return Config{}, fmt.Errorf("invalid regular expression %q in generated_headers: %s", header, err)
+		}
+	}
+	for _, pattern := range conf.GeneratedPaths {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return Config{}, fmt.Errorf("invalid pattern %q in generated_paths", pattern)
+		}
+	}
+	conf.Unused.SideEffectFunctions = normalizeList(conf.Unused.SideEffectFunctions)
+	conf.Unused.Keep = normalizeList(conf.Unused.Keep)
+	conf.Unused.MockPackages = normalizeList(conf.Unused.MockPackages)
+	conf.Unused.TestSupportPackages = normalizeList(conf.Unused.TestSupportPackages)
+	if err := conf.Unused.Validate(); err != nil {
+		return Config{}, err
+	}
+	// The first configuration is the default one, whose rules are
+	// those of the default profile.
+	rules := profileRules(defaults.Unused.Rules, profiles[conf.Unused.Profile])
+	for _, c := range confs[1:] {
+		for k, v := range c.Unused.Rules {
+			rules[k] = v
+		}
+	}
+	conf.Unused.Rules = rules
+	for cat, sev := range conf.Severity {
+		switch sev {
+		case SeverityError, SeverityWarning, SeverityInfo:
+		default:
+			return Config{}, fmt.Errorf("invalid severity %q for %s", sev, cat)
+		}
+	}
 
 	return conf, nil
 }
+
+// Validate checks that the options of the unused analyzer have valid
+// values.
+func (cfg Unused) Validate() error {
+	switch cfg.Generated {
+	case GeneratedIgnore, GeneratedReport, GeneratedKeep, GeneratedStrict:
+	default:
+		return fmt.Errorf("invalid value %q for unused.generated", cfg.Generated)
+	}
+	if cfg.SkimGenerated < 0 {
+		return fmt.Errorf("invalid value %d for unused.skim_generated", cfg.SkimGenerated)
+	}
+	switch cfg.GoGenerate {
+	case GoGenerateOff, GoGenerateMention, GoGenerateKeep:
+	default:
+		return fmt.Errorf("invalid value %q for unused.go_generate", cfg.GoGenerate)
+	}
+	switch cfg.Positions {
+	case PositionsDisplay, PositionsRaw, PositionsAdjusted:
+	default:
+		return fmt.Errorf("invalid value %q for unused.positions", cfg.Positions)
+	}
+	if cfg.IgnoreReasonMinLength < 0 {
+		return fmt.Errorf("invalid value %d for unused.ignore_reason_min_length", cfg.IgnoreReasonMinLength)
+	}
+	if _, err := regexp.Compile(cfg.IgnoreReasonPattern); err != nil {
+		return fmt.Errorf("invalid value %q for unused.ignore_reason_pattern: %s", cfg.IgnoreReasonPattern, err)
+	}
+	if _, ok := profiles[cfg.Profile]; !ok {
+		return fmt.Errorf("invalid value %q for unused.profile", cfg.Profile)
+	}
+	for _, rule := range cfg.Forbid {
+		if rule.From == "" || rule.To == "" {
+			return fmt.Errorf("unused.forbid rules need both from and to")
+		}
+		for _, pattern := range []string{rule.From, rule.To} {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid pattern %q in unused.forbid", pattern)
+			}
+		}
+	}
+	for _, pattern := range cfg.Routes {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q in unused.routes", pattern)
+		}
+	}
+	for cat, text := range cfg.MessageTemplates {
+		if _, err := template.New(cat).Parse(text); err != nil {
+			return fmt.Errorf("invalid template for %s in unused.message_templates: %s", cat, err)
+		}
+	}
+	for pattern := range cfg.ExternalAPI {
+		if _, err := path.Match(strings.TrimSuffix(pattern, "/..."), ""); err != nil {
+			return fmt.Errorf("invalid pattern %q in unused.external_api", pattern)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,401 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadUnused(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "sub")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(dir, data string) {
+		if err := os.WriteFile(filepath.Join(dir, ConfigName), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(root, `
+[unused]
+keep = ["foo"]
+generated = "report"
+positions = "raw"
+max_nodes = 10
+whole_program = true
+verify_fixes = true
+
+[unused.rules]
+const_groups = false
+
+[unused.external_api]
+"example.com/app/api/..." = true
+
+[[unused.forbid]]
+from = "example.com/app/handlers"
+to = "example.com/app/db/internal.*"
+reason = "use the repository"
+`)
+	write(sub, `
+[unused]
+keep = ["inherit", "bar"]
+whole_program = false
+mock_packages = ["example.com/mocks/*"]
+test_support_packages = ["example.com/internal/testutil/..."]
+impact = ["example.com/lib.Old*"]
+routes = ["/metrics", "/debug/*"]
+runtime_functions = ["morestack_abi0"]
+skim_generated = 5000
+go_generate = "mention"
+ignore_reason_min_length = 10
+ignore_reason_pattern = "[A-Z]+-[0-9]+"
+
+[unused.rules]
+test_sinks = false
+
+[unused.external_api]
+"example.com/app/api/internal/..." = false
+
+[[unused.forbid]]
+from = "example.com/app/*"
+to = "os.Exit"
+`)
+
+	cfg, err := Load(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Unused{
+		MaxNodes:              10,
+		SideEffectFunctions:   []string{},
+		Keep:                  []string{"foo", "bar"},
+		Generated:             GeneratedReport,
+		SkimGenerated:         5000,
+		GoGenerate:            GoGenerateMention,
+		Positions:             PositionsRaw,
+		Profile:               ProfileDefault,
+		WholeProgram:          true,
+		MockPackages:          []string{"example.com/mocks/*"},
+		TestSupportPackages:   []string{"example.com/internal/testutil/..."},
+		VerifyFixes:           true,
+		Impact:                []string{"example.com/lib.Old*"},
+		Routes:                []string{"/metrics", "/debug/*"},
+		RuntimeFunctions:      []string{"morestack_abi0"},
+		IgnoreReasonMinLength: 10,
+		IgnoreReasonPattern:   "[A-Z]+-[0-9]+",
+		Forbid: []Forbidden{
+			{From: "example.com/app/handlers", To: "example.com/app/db/internal.*", Reason: "use the repository"},
+			{From: "example.com/app/*", To: "os.Exit"},
+		},
+		ExternalAPI: map[string]bool{
+			"example.com/app/api/...":          true,
+			"example.com/app/api/internal/...": false,
+		},
+		Rules: map[string]bool{
+			RuleConstGroups:         false,
+			RuleTestSinks:           false,
+			RuleReceiverNames:       false,
+			RuleIotaEnums:           false,
+			RuleTestedOnly:          false,
+			RuleDocLinks:            false,
+			RuleEscapeAnalysis:      false,
+			RuleNamedResults:        false,
+			RuleComparisons:         true,
+			RuleInterfaceAssertions: false,
+			RuleDependencyInjection: true,
+			RuleDeadRoutes:          false,
+			RuleExportedFuncVars:    true,
+			RuleFatalPaths:          false,
+			RuleIgnoredFiles:        false,
+			RuleTestSupportImports:  false,
+			RuleUnusedPackages:      false,
+			RuleUnformattedMethods:  false,
+			RuleDuplicateFunctions:  false,
+		},
+	}
+	if !reflect.DeepEqual(cfg.Unused, want) {
+		t.Errorf("got %#v, want %#v", cfg.Unused, want)
+	}
+
+	cfg, err = Load(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Unused.Rules[RuleTestSinks] {
+		t.Errorf("rule %s should be inherited from the default configuration", RuleTestSinks)
+	}
+
+	write(sub, `
+[unused]
+generated = "bogus"
+`)
+	if _, err := Load(sub); err == nil {
+		t.Error("expected error for invalid value of unused.generated")
+	}
+
+	write(sub, `
+[unused]
+go_generate = "bogus"
+`)
+	if _, err := Load(sub); err == nil {
+		t.Error("expected error for invalid value of unused.go_generate")
+	}
+
+	write(sub, `
+[unused]
+positions = "bogus"
+`)
+	if _, err := Load(sub); err == nil {
+		t.Error("expected error for invalid value of unused.positions")
+	}
+
+	write(sub, `
+[unused]
+ignore_reason_pattern = "[A-Z"
+`)
+	if _, err := Load(sub); err == nil {
+		t.Error("expected error for invalid value of unused.ignore_reason_pattern")
+	}
+
+	write(sub, `
+[[unused.forbid]]
+from = "example.com/app/["
+to = "os.Exit"
+`)
+	if _, err := Load(sub); err == nil {
+		t.Error("expected error for invalid pattern in unused.forbid")
+	}
+
+	write(sub, `
+[unused]
+routes = ["/users/["]
+`)
+	if _, err := Load(sub); err == nil {
+		t.Error("expected error for invalid pattern in unused.routes")
+	}
+
+	write(sub, `
+[unused.external_api]
+"example.com/[/..." = true
+`)
+	if _, err := Load(sub); err == nil {
+		t.Error("expected error for invalid pattern in unused.external_api")
+	}
+
+	write(sub, `
+[unused.message_templates]
+U1000 = "{{.Name"
+`)
+	if _, err := Load(sub); err == nil {
+		t.Error("expected error for invalid template in unused.message_templates")
+	}
+}
+
+func TestLoadProfile(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "sub")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(dir, data string) {
+		if err := os.WriteFile(filepath.Join(dir, ConfigName), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(root, `
+[unused.rules]
+tested_only = false
+receiver_names = true
+`)
+	write(sub, `
+[unused]
+profile = "strict"
+`)
+
+	cfg, err := Load(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := ProfileRules(ProfileStrict)
+	// Explicitly configured rules take precedence over the profile,
+	// even if they are configured further up the tree.
+	want[RuleTestedOnly] = false
+	want[RuleReceiverNames] = true
+	if cfg.Unused.Profile != ProfileStrict {
+		t.Errorf("got profile %q, want %q", cfg.Unused.Profile, ProfileStrict)
+	}
+	if !reflect.DeepEqual(cfg.Unused.Rules, want) {
+		t.Errorf("got rules %v, want %v", cfg.Unused.Rules, want)
+	}
+	if !want[RuleIotaEnums] {
+		t.Errorf("rule %s should be enabled by the strict profile", RuleIotaEnums)
+	}
+
+	for profile := range profiles {
+		rules, ok := ProfileRules(profile)
+		if !ok {
+			t.Fatalf("no rules for profile %q", profile)
+		}
+		if len(rules) != len(DefaultConfig.Unused.Rules) {
+			t.Errorf("profile %q has %d rules, want %d", profile, len(rules), len(DefaultConfig.Unused.Rules))
+		}
+	}
+	if rules, _ := ProfileRules(ProfileDefault); !reflect.DeepEqual(rules, DefaultConfig.Unused.Rules) {
+		t.Errorf("got default profile rules %v, want %v", rules, DefaultConfig.Unused.Rules)
+	}
+
+	write(sub, `
+[unused]
+profile = "bogus"
+`)
+	if _, err := Load(sub); err == nil {
+		t.Error("expected error for invalid value of unused.profile")
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ConfigName), []byte(`
+[unused]
+keep = ["inherit", "b"]
+[unused.rules]
+named_results = false
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defaults := DefaultConfig
+	defaults.Unused = defaults.Unused.Merge(Unused{
+		Keep:  []string{"a"},
+		Rules: map[string]bool{RuleReceiverNames: true, RuleNamedResults: true},
+	})
+	cfg, err := load(dir, defaults)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "b"}; !reflect.DeepEqual(cfg.Unused.Keep, want) {
+		t.Errorf("got keep %v, want %v", cfg.Unused.Keep, want)
+	}
+	if !cfg.Unused.Rules[RuleReceiverNames] {
+		t.Errorf("rule %s of the defaults is disabled", RuleReceiverNames)
+	}
+	if cfg.Unused.Rules[RuleNamedResults] {
+		t.Errorf("rule %s is enabled, despite staticcheck.conf", RuleNamedResults)
+	}
+	if DefaultConfig.Unused.Rules[RuleReceiverNames] || len(DefaultConfig.Unused.Keep) != 0 {
+		t.Errorf("loading changed DefaultConfig")
+	}
+}
+
+func TestLoadSeverity(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "sub")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(dir, data string) {
+		if err := os.WriteFile(filepath.Join(dir, ConfigName), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(root, `
+[severity]
+U1000 = "warning"
+stale_ignore = "info"
+`)
+	write(sub, `
+[severity]
+"U1000.assigned" = "error"
+stale_ignore = "error"
+`)
+
+	cfg, err := Load(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"U1000":          SeverityWarning,
+		"U1000.assigned": SeverityError,
+		"stale_ignore":   SeverityError,
+	}
+	if !reflect.DeepEqual(cfg.Severity, want) {
+		t.Errorf("got %v, want %v", cfg.Severity, want)
+	}
+	if sev, ok := cfg.SeverityOf("U1000.assigned", "U1000.field", "U1000"); !ok || sev != SeverityError {
+		t.Errorf("got severity %q, %t for write-only field, want %q", sev, ok, SeverityError)
+	}
+	if sev, ok := cfg.SeverityOf("U1000.func", "U1000"); !ok || sev != SeverityWarning {
+		t.Errorf("got severity %q, %t for function, want %q", sev, ok, SeverityWarning)
+	}
+	if _, ok := cfg.SeverityOf("SA4006"); ok {
+		t.Error("SA4006 shouldn't have a configured severity")
+	}
+
+	write(sub, `
+[severity]
+U1000 = "fatal"
+`)
+	if _, err := Load(sub); err == nil {
+		t.Error("expected error for invalid severity")
+	}
+}
+
+func TestLoadGenerated(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "sub")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(dir, data string) {
+		if err := os.WriteFile(filepath.Join(dir, ConfigName), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(root, `
+generated_headers = ["^// Autogenerated by "]
+generated_paths = ["*_gen.go"]
+`)
+	write(sub, `
+generated_paths = ["inherit", "internal/pb/*.go"]
+`)
+
+	cfg, err := Load(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"^// Autogenerated by "}; !reflect.DeepEqual(cfg.GeneratedHeaders, want) {
+		t.Errorf("got generated_headers %v, want %v", cfg.GeneratedHeaders, want)
+	}
+	if want := []string{"*_gen.go", "internal/pb/*.go"}; !reflect.DeepEqual(cfg.GeneratedPaths, want) {
+		t.Errorf("got generated_paths %v, want %v", cfg.GeneratedPaths, want)
+	}
+
+	write(sub, `generated_headers = ["(unclosed"]`)
+	if _, err := Load(sub); err == nil {
+		t.Error("expected error for invalid regular expression in generated_headers")
+	}
+	write(sub, `generated_paths = ["[unclosed"]`)
+	if _, err := Load(sub); err == nil {
+		t.Error("expected error for invalid pattern in generated_paths")
+	}
+}
+
+func TestMatchTree(t *testing.T) {
+	for _, tt := range []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"example.com/pkg", "example.com/pkg", true},
+		{"example.com/pkg", "example.com/pkg/sub", false},
+		{"example.com/pkg/...", "example.com/pkg", true},
+		{"example.com/pkg/...", "example.com/pkg/sub/deeper", true},
+		{"example.com/pkg/...", "example.com/pkgx", false},
+		{"example.com/*/internal/...", "example.com/a/internal/b", true},
+		{"example.com/*", "example.com/a/b", false},
+	} {
+		if got := MatchTree(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("MatchTree(%q, %q) = %t, want %t", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
@@ -2027,8 +2027,65 @@ func (b *builder) rangeIndexed(fn *Function, x Value, tv types.Type, source ast.
 	return
 }
 
+// rangeInt emits to fn the header for a loop that counts from zero
+// to the integer x.
+// tk is the type of the k result, or nil if it is not wanted.
+//
+func (b *builder) rangeInt(fn *Function, x Value, tk types.Type, source ast.Node) (k Value, loop, done *BasicBlock) {
+	//
+	//      length = x
+	//      index = -1
+	// loop:                                   (target of continue)
+	//      index++
+	// 	if index < length goto body else done
+	// body:
+	//      k = index
+	//      ...body...
+	// 	jump loop
+	// done:                                   (target of break)
+
+	typ := x.Type()
+	if basic, ok := typ.Underlying().(*types.Basic); ok && basic.Info()&types.IsUntyped != 0 {
+		// The iteration variable determines the type of an
+		// untyped constant, if there is one.
+		typ = tInt
+		if tk != nil {
+			typ = tk
+		}
+		x = emitConv(fn, x, typ, source)
+	}
+
+	// We store the length in an Alloc and load it on each iteration so that lifting produces the necessary σ nodes
+	length := newVariable(fn, typ, source)
+	length.store(x)
+
+	index := fn.addLocal(typ, source)
+	emitStore(fn, index, emitConst(fn, NewConst(constant.MakeInt64(-1), typ)), source)
+
+	loop = fn.newBasicBlock("rangeint.loop")
+	emitJump(fn, loop, source)
+	fn.currentBlock = loop
+
+	incr := &BinOp{
+		Op: token.ADD,
+		X:  emitLoad(fn, index, source),
+		Y:  emitConst(fn, NewConst(constant.MakeInt64(1), typ)),
+	}
+	incr.setType(typ)
+	emitStore(fn, index, fn.emit(incr, source), source)
+
+	body := fn.newBasicBlock("rangeint.body")
+	done = fn.newBasicBlock("rangeint.done")
+	emitIf(fn, emitCompare(fn, token.LSS, incr, length.load(), source), body, done, source)
+	fn.currentBlock = body
+
+	k = emitLoad(fn, index, source)
+	return
+}
+
 // rangeIter emits to fn the header for a loop using
-// Range/Next/Extract to iterate over map or string value x.
+// Range/Next/Extract to iterate over map or string value x, or over
+// the values that the iterator function x yields.
 // tk and tv are the types of the key/value results k and v, or nil
 // if the respective component is not wanted.
 //
@@ -2193,7 +2250,15 @@ func (b *builder) rangeStmt(fn *Function, s *ast.RangeStmt, label *lblock, sourc
 	case *types.Chan:
 		k, loop, done = b.rangeChan(fn, x, tk, source)
 
-	case *types.Map, *types.Basic: // string
+	case *types.Basic:
+		if rt.Info()&types.IsInteger != 0 {
+			k, loop, done = b.rangeInt(fn, x, tk, source)
+		} else {
+			// string
+			k, v, loop, done = b.rangeIter(fn, x, tk, tv, source)
+		}
+
+	case *types.Map, *types.Signature:
 		k, v, loop, done = b.rangeIter(fn, x, tk, tv, source)
 
 	default:
@@ -2521,6 +2586,43 @@ func (b *builder) buildFuncDecl(pkg *Package, decl *ast.FuncDecl) {
 	b.buildFunction(fn)
 }
 
+// A BuildError records that building the IR of a function panicked,
+// such as because of a construct that the builder doesn't support.
+type BuildError struct {
+	Func *Function
+	// Pos is the position of the function's declaration.
+	Pos token.Pos
+	// Value is the value that the builder panicked with.
+	Value interface{}
+}
+
+func (err *BuildError) Error() string {
+	return fmt.Sprintf("building IR of %s: %v", err.Func, err.Value)
+}
+
+// tryBuildFuncDecl is like buildFuncDecl, but recovers from panics, so
+// that one function the builder chokes on doesn't prevent the rest of
+// the package from being built. The function is left without a body.
+//
+// Package-level variable initializers don't get the same treatment.
+// They share the package's init function, and there is no body to
+// discard that would leave init in a consistent state.
+func (b *builder) tryBuildFuncDecl(pkg *Package, decl *ast.FuncDecl) (err *BuildError) {
+	defer func() {
+		if r := recover(); r != nil {
+			fn := pkg.values[pkg.info.Defs[decl.Name]].(*Function)
+			fn.Blocks = nil
+			fn.Exit = nil
+			fn.Locals = nil
+			fn.AnonFuncs = nil
+			fn.functionBody = nil
+			err = &BuildError{Func: fn, Pos: decl.Pos(), Value: r}
+		}
+	}()
+	b.buildFuncDecl(pkg, decl)
+	return nil
+}
+
 // Build calls Package.Build for each package in prog.
 //
 // Build is intended for whole-program analysis; a typical compiler
@@ -2624,7 +2726,9 @@ func (p *Package) build() {
 	for _, file := range p.files {
 		for _, decl := range file.Decls {
 			if decl, ok := decl.(*ast.FuncDecl); ok {
-				b.buildFuncDecl(p, decl)
+				if err := b.tryBuildFuncDecl(p, decl); err != nil {
+					p.BuildErrors = append(p.BuildErrors, err)
+				}
 			}
 		}
 	}
@@ -487,3 +487,59 @@ func h(error)
 		t.Errorf("expected %d Phi nodes (for the range index, slice length and slice), got %d", expected, phis)
 	}
 }
+
+// TestBuildErrors checks that a function whose IR can't be built
+// doesn't prevent the rest of the package from being built.
+func TestBuildErrors(t *testing.T) {
+	const input = `package p
+
+func good() int { return 1 }
+
+func bad() int {
+	x := 2
+	f := func() int { return x }
+	return x * f()
+}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "<input>", input, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Implicits:  make(map[ast.Node]types.Object),
+		Scopes:     make(map[ast.Node]*types.Scope),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+	}
+	pkg, err := new(types.Config).Check("p", fset, []*ast.File{f}, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Make the builder panic on the multiplication.
+	ast.Inspect(f, func(n ast.Node) bool {
+		if expr, ok := n.(*ast.BinaryExpr); ok {
+			delete(info.Types, expr)
+		}
+		return true
+	})
+
+	prog := ir.NewProgram(fset, ir.SanityCheckFunctions)
+	irpkg := prog.CreatePackage(pkg, []*ast.File{f}, info, false)
+	irpkg.Build()
+
+	if len(irpkg.BuildErrors) != 1 {
+		t.Fatalf("got %d build errors, want 1", len(irpkg.BuildErrors))
+	}
+	if err := irpkg.BuildErrors[0]; err.Func != irpkg.Func("bad") {
+		t.Errorf("got build error for %s, want p.bad", err.Func)
+	}
+	if bad := irpkg.Func("bad"); !isEmpty(bad) || len(bad.AnonFuncs) != 0 {
+		t.Errorf("p.bad has %d blocks and %d anonymous functions, want none", len(bad.Blocks), len(bad.AnonFuncs))
+	}
+	if isEmpty(irpkg.Func("good")) {
+		t.Error("p.good has no blocks")
+	}
+}
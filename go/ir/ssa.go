@@ -63,6 +63,14 @@ type Package struct {
 	debug     bool                   // include full debug info in this package
 	printFunc string                 // which function to print in HTML form
 
+	// BuildErrors lists the functions whose IR couldn't be built,
+	// in source order. Their Blocks are nil, as if they were
+	// external functions. Only declared functions and methods are
+	// covered: the initializers of package-level variables are all
+	// built into the package's init function, and a panic while
+	// building them still aborts building the package.
+	BuildErrors []*BuildError
+
 	// The following fields are set transiently, then cleared
 	// after building.
 	buildOnce sync.Once   // ensures package building occurs once
@@ -1214,7 +1222,10 @@ type Select struct {
 }
 
 // The Range instruction yields an iterator over the domain and range
-// of X, which must be a string or map.
+// of X, which must be a string or map, or over the values that X
+// yields, if it is an iterator function. The calls of the yield
+// function that the Go compiler synthesizes aren't represented; the
+// body of the loop is treated like that of a loop over a map.
 //
 // Elements are accessed via Next.
 //
@@ -1227,11 +1238,11 @@ type Select struct {
 //
 type Range struct {
 	register
-	X Value // string or map
+	X Value // string, map or iterator function
 }
 
-// The Next instruction reads and advances the (map or string)
-// iterator Iter and returns a 3-tuple value (ok, k, v).  If the
+// The Next instruction reads and advances the (map, string or
+// function) iterator Iter and returns a 3-tuple value (ok, k, v).  If the
 // iterator is not exhausted, ok is true and k and v are the next
 // elements of the domain and range, respectively.  Otherwise ok is
 // false and k and v are undefined.
@@ -1239,8 +1250,8 @@ type Range struct {
 // Components of the tuple are accessed using Extract.
 //
 // The IsString field distinguishes iterators over strings from those
-// over maps, as the Type() alone is insufficient: consider
-// map[int]rune.
+// over maps and functions, as the Type() alone is insufficient:
+// consider map[int]rune.
 //
 // Type() returns a *types.Tuple for the triple (ok, k, v).
 // The types of k and/or v may be types.Invalid.
@@ -1252,7 +1263,7 @@ type Range struct {
 type Next struct {
 	register
 	Iter     Value
-	IsString bool // true => string iterator; false => map iterator.
+	IsString bool // true => string iterator; false => map or function iterator.
 }
 
 // The TypeAssert instruction tests whether interface value X has type
@@ -11,8 +11,8 @@ import (
 )
 
 // computeHash computes a package's hash. The hash is based on all Go
-// files that make up the package, as well as the hashes of imported
-// packages.
+// files that make up the package, the Go files that build constraints
+// exclude from it, as well as the hashes of imported packages.
 func computeHash(c *cache.Cache, pkg *PackageSpec) (cache.ActionID, error) {
 	key := c.NewHash("package " + pkg.PkgPath)
 	fmt.Fprintf(key, "goos %s goarch %s\n", runtime.GOOS, runtime.GOARCH)
@@ -42,6 +42,18 @@ func computeHash(c *cache.Cache, pkg *PackageSpec) (cache.ActionID, error) {
 		}
 	}
 
+	// U1000 may look at the names that ignored Go files refer to.
+	for _, f := range pkg.IgnoredFiles {
+		if !strings.HasSuffix(f, ".go") {
+			continue
+		}
+		h, err := cache.FileHash(f)
+		if err != nil {
+			return cache.ActionID{}, err
+		}
+		fmt.Fprintf(key, "ignored %s %x\n", f, h)
+	}
+
 	imps := make([]*PackageSpec, 0, len(pkg.Imports))
 	for _, v := range pkg.Imports {
 		imps = append(imps, v)
@@ -9,6 +9,9 @@ import (
 	"go/token"
 	"go/types"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"honnef.co/go/tools/config"
@@ -33,11 +36,15 @@ type PackageSpec struct {
 	GoFiles         []string
 	CompiledGoFiles []string
 	OtherFiles      []string
-	ExportFile      string
-	Imports         map[string]*PackageSpec
-	TypesSizes      types.Sizes
-	Hash            cache.ActionID
-	Module          *packages.Module
+	// IgnoredFiles are the files of the package's directory that
+	// build constraints exclude, such as helper scripts marked
+	// //go:build ignore.
+	IgnoredFiles []string
+	ExportFile   string
+	Imports      map[string]*PackageSpec
+	TypesSizes   types.Sizes
+	Hash         cache.ActionID
+	Module       *packages.Module
 
 	Config config.Config
 }
@@ -92,6 +99,7 @@ func Graph(c *cache.Cache, cfg *packages.Config, patterns ...string) ([]*Package
 			GoFiles:         pkg.GoFiles,
 			CompiledGoFiles: pkg.CompiledGoFiles,
 			OtherFiles:      pkg.OtherFiles,
+			IgnoredFiles:    pkg.IgnoredFiles,
 			ExportFile:      pkg.ExportFile,
 			Imports:         map[string]*PackageSpec{},
 			TypesSizes:      pkg.TypesSizes,
@@ -345,3 +353,131 @@ func convertError(err error) []packages.Error {
 type importerFunc func(path string) (*types.Package, error)
 
 func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
+
+// ReverseDependencies returns the packages in pkgs that are affected
+// by changes, as well as the packages in pkgs that transitively import
+// them. Changes are file names or import paths. A file name affects
+// the packages in the file's directory, so that deleted and newly
+// added files are accounted for; an import path affects the packages
+// with that path, including their test variants. File names have to
+// be absolute.
+//
+// Only pkgs can be affected, not their dependencies. The order of
+// pkgs is retained.
+func ReverseDependencies(pkgs []*PackageSpec, changes []string) []*PackageSpec {
+	changed := map[string]struct{}{}
+	changedDirs := map[string]struct{}{}
+	for _, c := range changes {
+		if filepath.IsAbs(c) {
+			changedDirs[filepath.Dir(c)] = struct{}{}
+		} else {
+			changed[c] = struct{}{}
+		}
+	}
+	isChanged := func(pkg *PackageSpec) bool {
+		if _, ok := changed[pkg.PkgPath]; ok {
+			return true
+		}
+		for _, files := range [][]string{pkg.GoFiles, pkg.CompiledGoFiles, pkg.OtherFiles} {
+			for _, f := range files {
+				if _, ok := changedDirs[filepath.Dir(f)]; ok {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	initial := map[*PackageSpec]struct{}{}
+	for _, pkg := range pkgs {
+		initial[pkg] = struct{}{}
+	}
+	affected := map[*PackageSpec]bool{}
+	var visit func(pkg *PackageSpec) bool
+	visit = func(pkg *PackageSpec) bool {
+		if ok, seen := affected[pkg]; seen {
+			return ok
+		}
+		// Imports can't be cyclic, but mark the package as visited
+		// anyway, so that we don't revisit shared dependencies.
+		affected[pkg] = false
+		ok := false
+		if _, isInitial := initial[pkg]; isInitial && isChanged(pkg) {
+			ok = true
+		}
+		for _, imp := range pkg.Imports {
+			if visit(imp) {
+				ok = true
+			}
+		}
+		affected[pkg] = ok
+		return ok
+	}
+
+	var out []*PackageSpec
+	for _, pkg := range pkgs {
+		if visit(pkg) {
+			out = append(out, pkg)
+		}
+	}
+	return out
+}
+
+// TestSupport returns the import paths of the packages that only
+// _test.go files import: packages that a test file in the directory of
+// one of pkgs imports, but that no package in the import graph of pkgs
+// imports, not counting test variants. Test files are read from disk,
+// so that this works whether or not pkgs include tests.
+func TestSupport(pkgs []*PackageSpec) map[string]bool {
+	isTestVariant := func(pkg *PackageSpec) bool {
+		return pkg.ID != pkg.PkgPath
+	}
+
+	imported := map[string]bool{}
+	seen := map[*PackageSpec]bool{}
+	var visit func(pkg *PackageSpec)
+	visit = func(pkg *PackageSpec) {
+		if seen[pkg] {
+			return
+		}
+		seen[pkg] = true
+		for _, imp := range pkg.Imports {
+			if !isTestVariant(pkg) {
+				imported[imp.PkgPath] = true
+			}
+			visit(imp)
+		}
+	}
+	for _, pkg := range pkgs {
+		visit(pkg)
+	}
+
+	// Packages' own external tests import them, which doesn't
+	// make them test support packages.
+	dirs := map[string]string{}
+	for _, pkg := range pkgs {
+		if len(pkg.GoFiles) > 0 {
+			dirs[filepath.Dir(pkg.GoFiles[0])] = strings.TrimSuffix(pkg.PkgPath, "_test")
+		}
+	}
+	out := map[string]bool{}
+	for dir, self := range dirs {
+		files, err := filepath.Glob(filepath.Join(dir, "*_test.go"))
+		if err != nil {
+			continue
+		}
+		for _, name := range files {
+			f, err := parser.ParseFile(token.NewFileSet(), name, nil, parser.ImportsOnly)
+			if err != nil {
+				continue
+			}
+			for _, imp := range f.Imports {
+				path, err := strconv.Unquote(imp.Path.Value)
+				if err == nil && path != self && !imported[path] {
+					out[path] = true
+				}
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,100 @@
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReverseDependencies(t *testing.T) {
+	spec := func(path string, imports ...*PackageSpec) *PackageSpec {
+		pkg := &PackageSpec{
+			ID:      path,
+			PkgPath: path,
+			GoFiles: []string{"/src/" + path + "/" + path + ".go"},
+			Imports: map[string]*PackageSpec{},
+		}
+		for _, imp := range imports {
+			pkg.Imports[imp.PkgPath] = imp
+		}
+		return pkg
+	}
+	// fmt isn't one of the analyzed packages; changes to it don't
+	// affect anything.
+	fmt := spec("fmt")
+	a := spec("a", fmt)
+	b := spec("b", a)
+	c := spec("c", b)
+	d := spec("d", fmt)
+	e := spec("e", d, a)
+	pkgs := []*PackageSpec{a, b, c, d, e}
+
+	names := func(pkgs []*PackageSpec) []string {
+		var out []string
+		for _, pkg := range pkgs {
+			out = append(out, pkg.PkgPath)
+		}
+		return out
+	}
+	tests := []struct {
+		changes []string
+		want    []string
+	}{
+		{[]string{"a"}, []string{"a", "b", "c", "e"}},
+		{[]string{"/src/b/b.go"}, []string{"b", "c"}},
+		// A deleted file belongs to no package, but to its directory.
+		{[]string{"/src/b/deleted.go"}, []string{"b", "c"}},
+		{[]string{"d", "/src/c/c.go"}, []string{"c", "d", "e"}},
+		{[]string{"fmt", "/src/fmt/fmt.go"}, nil},
+		{nil, nil},
+	}
+	for _, tt := range tests {
+		if got := names(ReverseDependencies(pkgs, tt.changes)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("changes %q: got %v, want %v", tt.changes, got, tt.want)
+		}
+	}
+}
+
+func TestTestSupport(t *testing.T) {
+	root := t.TempDir()
+	spec := func(path string, imports ...*PackageSpec) *PackageSpec {
+		dir := filepath.Join(root, path)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		pkg := &PackageSpec{
+			ID:      path,
+			PkgPath: path,
+			GoFiles: []string{filepath.Join(dir, "x.go")},
+			Imports: map[string]*PackageSpec{},
+		}
+		for _, imp := range imports {
+			pkg.Imports[imp.PkgPath] = imp
+		}
+		return pkg
+	}
+	writeTest := func(path, src string) {
+		if err := os.WriteFile(filepath.Join(root, path, "x_test.go"), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testutil := spec("testutil")
+	lib := spec("lib")
+	app := spec("app", lib)
+	// The test variant of app imports testutil, which doesn't make
+	// testutil a dependency of non-test code.
+	appTest := spec("app", lib, testutil)
+	appTest.ID = "app [app.test]"
+	// Only its own external test imports leaf.
+	leaf := spec("leaf")
+	writeTest("app", `package app_test; import ("app"; "lib"; "testutil")`)
+	writeTest("leaf", `package leaf_test; import "leaf"`)
+
+	got := TestSupport([]*PackageSpec{testutil, lib, app, appTest, leaf})
+	want := map[string]bool{"testutil": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
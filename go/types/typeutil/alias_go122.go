@@ -0,0 +1,11 @@
+//go:build go1.22
+
+package typeutil
+
+import "go/types"
+
+// Unalias returns t with all aliases removed. Before Go 1.22,
+// go/types didn't represent aliases as types of their own.
+func Unalias(t types.Type) types.Type {
+	return types.Unalias(t)
+}
@@ -0,0 +1,245 @@
+// runtimefuncs generates the table of runtime functions that the
+// unused code analysis considers used, because the compiler emits
+// calls to them or because assembly code calls them.
+//
+// It reads the sources of one or more Go releases, whose GOROOTs are
+// given as arguments, and records in which of the releases each
+// function is called:
+//
+//	go run ./internal/cmd/runtimefuncs -o unused/runtimefuncs_table.go ~/sdk/go1.19 ~/sdk/go1.20 ...
+//
+// The releases must be consecutive, as the table is used for the
+// releases between the ones given, too.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Locations of the compiler's runtimeDecls table, across releases.
+var builtinFiles = []string{
+	"src/cmd/compile/internal/typecheck/builtin.go",
+	"src/cmd/compile/internal/gc/builtin.go",
+}
+
+var asmReference = regexp.MustCompile(`runtime·([A-Za-z0-9_]+)`)
+
+func main() {
+	log.SetFlags(0)
+	out := flag.String("o", "", "write the table to `file` instead of standard output")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		log.Fatal("usage: runtimefuncs [-o file] goroot...")
+	}
+
+	// names of functions per minor version
+	found := map[int]map[string]bool{}
+	for _, root := range flag.Args() {
+		version, err := releaseVersion(root)
+		if err != nil {
+			log.Fatal(err)
+		}
+		names := map[string]bool{}
+		if err := compilerFuncs(root, names); err != nil {
+			log.Fatal(err)
+		}
+		if err := assemblyFuncs(root, names); err != nil {
+			log.Fatal(err)
+		}
+		found[version] = names
+	}
+
+	table, err := generate(found)
+	if err != nil {
+		log.Fatal(err)
+	}
+	src, err := format.Source(table)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *out == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := os.WriteFile(*out, src, 0666); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// releaseVersion returns the minor version of the Go release in root,
+// according to its VERSION file.
+func releaseVersion(root string) (int, error) {
+	b, err := os.ReadFile(filepath.Join(root, "VERSION"))
+	if err != nil {
+		return 0, err
+	}
+	line, _, _ := strings.Cut(string(b), "\n")
+	v := strings.TrimPrefix(strings.TrimSpace(line), "go1.")
+	if v == line {
+		return 0, fmt.Errorf("%s: not a Go release: %q", root, line)
+	}
+	v, _, _ = strings.Cut(v, ".")
+	minor, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: not a Go release: %q", root, line)
+	}
+	return minor, nil
+}
+
+// compilerFuncs adds the functions of the compiler's runtimeDecls
+// table to names.
+func compilerFuncs(root string, names map[string]bool) error {
+	var path string
+	for _, p := range builtinFiles {
+		if _, err := os.Stat(filepath.Join(root, p)); err == nil {
+			path = filepath.Join(root, p)
+			break
+		}
+	}
+	if path == "" {
+		return fmt.Errorf("%s: couldn't find the compiler's runtimeDecls", root)
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+	if err != nil {
+		return err
+	}
+	obj := f.Scope.Lookup("runtimeDecls")
+	if obj == nil {
+		return fmt.Errorf("%s: no runtimeDecls", path)
+	}
+	spec, ok := obj.Decl.(*ast.ValueSpec)
+	if !ok || len(spec.Values) != 1 {
+		return fmt.Errorf("%s: unexpected declaration of runtimeDecls", path)
+	}
+	lit, ok := spec.Values[0].(*ast.CompositeLit)
+	if !ok {
+		return fmt.Errorf("%s: unexpected declaration of runtimeDecls", path)
+	}
+	for _, elt := range lit.Elts {
+		decl, ok := elt.(*ast.CompositeLit)
+		if !ok || len(decl.Elts) < 2 {
+			continue
+		}
+		name, ok := decl.Elts[0].(*ast.BasicLit)
+		if !ok || name.Kind != token.STRING {
+			continue
+		}
+		if tag, ok := decl.Elts[1].(*ast.Ident); !ok || tag.Name != "funcTag" {
+			// variables such as writeBarrier
+			continue
+		}
+		s, err := strconv.Unquote(name.Value)
+		if err != nil {
+			return err
+		}
+		names[s] = true
+	}
+	return nil
+}
+
+// assemblyFuncs adds the runtime functions that the assembly files of
+// the standard library refer to, other than by defining them, to
+// names.
+func assemblyFuncs(root string, names map[string]bool) error {
+	src := filepath.Join(root, "src")
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == "testdata" || path == filepath.Join(src, "cmd") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".s") {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			line := strings.TrimSpace(sc.Text())
+			if strings.HasPrefix(line, "TEXT") || strings.HasPrefix(line, "//") {
+				continue
+			}
+			for _, m := range asmReference.FindAllStringSubmatch(line, -1) {
+				names[m[1]] = true
+			}
+		}
+		return sc.Err()
+	})
+}
+
+// generate returns the source of the table. Each function gets one
+// entry per range of consecutive versions that it was found in.
+func generate(found map[int]map[string]bool) ([]byte, error) {
+	var versions []int
+	all := map[string]bool{}
+	for v, names := range found {
+		versions = append(versions, v)
+		for name := range names {
+			all[name] = true
+		}
+	}
+	sort.Ints(versions)
+	for i := 1; i < len(versions); i++ {
+		if versions[i] != versions[i-1]+1 {
+			return nil, fmt.Errorf("missing the releases between go1.%d and go1.%d", versions[i-1], versions[i])
+		}
+	}
+	var sorted []string
+	for name := range all {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by internal/cmd/runtimefuncs. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package unused\n\n")
+	fmt.Fprintf(&buf, "// runtimeFuncsVersions are the minor versions of the Go releases that\n")
+	fmt.Fprintf(&buf, "// runtimeFuncTable was generated from.\n")
+	fmt.Fprintf(&buf, "var runtimeFuncsVersions = %#v\n\n", versions)
+	fmt.Fprintf(&buf, "var runtimeFuncTable = []runtimeFunc{\n")
+	for _, name := range sorted {
+		since := -1
+		for i, v := range versions {
+			if found[v][name] {
+				if since == -1 {
+					since = v
+					if i == 0 {
+						since = 0
+					}
+				}
+				continue
+			}
+			if since != -1 {
+				fmt.Fprintf(&buf, "\t{%q, %d, %d},\n", name, since, v)
+				since = -1
+			}
+		}
+		if since != -1 {
+			fmt.Fprintf(&buf, "\t{%q, %d, 0},\n", name, since)
+		}
+	}
+	fmt.Fprintf(&buf, "}\n")
+	return buf.Bytes(), nil
+}
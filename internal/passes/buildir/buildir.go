@@ -7,10 +7,15 @@
 // functions within it. It does not report any diagnostics itself but
 // may be used as an input to other analyzers.
 //
+// Analyzer fails for packages containing functions whose IR couldn't
+// be built, so that analyzers don't silently see empty bodies.
+// Analyzers that cope with such functions use PartialAnalyzer instead.
+//
 // THIS INTERFACE IS EXPERIMENTAL AND MAY BE SUBJECT TO INCOMPATIBLE CHANGE.
 package buildir
 
 import (
+	"fmt"
 	"go/ast"
 	"go/types"
 	"reflect"
@@ -29,11 +34,48 @@ func (*noReturn) AFact() {}
 var Analyzer = &analysis.Analyzer{
 	Name:       "buildir",
 	Doc:        "build IR for later passes",
+	Run:        runComplete,
+	Requires:   []*analysis.Analyzer{PartialAnalyzer},
+	ResultType: reflect.TypeOf(new(IR)),
+}
+
+// PartialAnalyzer is like Analyzer, but doesn't fail for packages
+// containing functions whose IR couldn't be built. Such functions have
+// no blocks, as if they were external functions, and are listed in the
+// BuildErrors of IR.Pkg. Panics while building the initializers of
+// package-level variables aren't recovered from.
+var PartialAnalyzer = &analysis.Analyzer{
+	Name:       "buildirpartial",
+	Doc:        "build IR for later passes, skipping functions whose IR can't be built",
 	Run:        run,
 	ResultType: reflect.TypeOf(new(IR)),
 	FactTypes:  []analysis.Fact{new(noReturn)},
 }
 
+// BuildErrors is the error of Analyzer for packages containing
+// functions whose IR couldn't be built.
+type BuildErrors []*ir.BuildError
+
+func (errs BuildErrors) Error() string {
+	if len(errs) == 1 {
+		return errs[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more)", errs[0], len(errs)-1)
+}
+
+func runComplete(pass *analysis.Pass) (interface{}, error) {
+	return complete(pass.ResultOf[PartialAnalyzer].(*IR))
+}
+
+// complete returns res, or an error if the IR of some of its
+// functions couldn't be built.
+func complete(res *IR) (*IR, error) {
+	if len(res.Pkg.BuildErrors) > 0 {
+		return nil, BuildErrors(res.Pkg.BuildErrors)
+	}
+	return res, nil
+}
+
 // IR provides intermediate representation for all the
 // non-blank source functions in the current package.
 type IR struct {
@@ -6,10 +6,16 @@ package buildir_test
 
 import (
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
 	"os"
 	"testing"
 
+	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/analysistest"
+	"honnef.co/go/tools/go/ir"
 	"honnef.co/go/tools/internal/passes/buildir"
 )
 
@@ -26,3 +32,54 @@ func Test(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildErrors(t *testing.T) {
+	const src = `package p
+
+func good() int { return 1 }
+
+func bad() int {
+	x := 2
+	return x * x
+}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Implicits:  make(map[ast.Node]types.Object),
+		Scopes:     make(map[ast.Node]*types.Scope),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+	}
+	pkg, err := new(types.Config).Check("p", fset, []*ast.File{f}, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Make the builder panic on the multiplication.
+	ast.Inspect(f, func(n ast.Node) bool {
+		if expr, ok := n.(*ast.BinaryExpr); ok {
+			delete(info.Types, expr)
+		}
+		return true
+	})
+	irpkg := ir.NewProgram(fset, 0).CreatePackage(pkg, []*ast.File{f}, info, false)
+	irpkg.Build()
+
+	// Analyzer fails, instead of giving analyzers a function
+	// without a body.
+	pass := &analysis.Pass{
+		ResultOf: map[*analysis.Analyzer]interface{}{
+			buildir.PartialAnalyzer: &buildir.IR{Pkg: irpkg},
+		},
+	}
+	_, err = buildir.Analyzer.Run(pass)
+	errs, ok := err.(buildir.BuildErrors)
+	if !ok || len(errs) != 1 || errs[0].Func != irpkg.Func("bad") {
+		t.Errorf("got error %v, want a build error for p.bad", err)
+	}
+}
@@ -0,0 +1,136 @@
+package lintcmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"honnef.co/go/tools/lintcmd/cache"
+)
+
+// ageFileName is the name of the file in the cache directory that
+// records when problems were first seen.
+const ageFileName = "ages.json"
+
+// agePruneAfter is how long problems that haven't been seen are
+// remembered. Runs that only check some packages don't see the
+// problems in the other packages, so forgetting problems as soon as
+// they disappear would reset their ages.
+const agePruneAfter = 90 * 24 * time.Hour
+
+// ageStore records when problems were first and last seen, keyed by
+// their fingerprints.
+type ageStore struct {
+	path    string
+	Entries map[string]ageEntry `json:"entries"`
+}
+
+type ageEntry struct {
+	FirstSeen int64 `json:"first_seen"`
+	LastSeen  int64 `json:"last_seen"`
+}
+
+// loadAgeStore loads the age store in the cache directory. A missing
+// store is empty.
+func loadAgeStore() (*ageStore, error) {
+	dir := cache.DefaultDir()
+	if dir == "" || dir == "off" {
+		return nil, errors.New("tracking the age of problems requires a cache directory")
+	}
+	return readAgeStore(filepath.Join(dir, ageFileName))
+}
+
+func readAgeStore(path string) (*ageStore, error) {
+	s := &ageStore{path: path, Entries: map[string]ageEntry{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("couldn't parse %s: %s", path, err)
+	}
+	if s.Entries == nil {
+		s.Entries = map[string]ageEntry{}
+	}
+	return s, nil
+}
+
+// fingerprint identifies a problem across runs. It deliberately
+// excludes the problem's line and column, so that editing other parts
+// of the file doesn't make the problem look new.
+func fingerprint(diag diagnostic) string {
+	name := diag.Position.Filename
+	if abs, err := filepath.Abs(name); err == nil && name != "" {
+		name = abs
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s", diag.Category, name, diag.Message)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// update records that the diagnostics were seen at now, sets their
+// first-seen times, and forgets problems that haven't been seen for a
+// long time.
+func (s *ageStore) update(diagnostics []diagnostic, now time.Time) {
+	for i, diag := range diagnostics {
+		key := fingerprint(diag)
+		e, ok := s.Entries[key]
+		if !ok {
+			e.FirstSeen = now.Unix()
+		}
+		e.LastSeen = now.Unix()
+		s.Entries[key] = e
+		diagnostics[i].firstSeen = time.Unix(e.FirstSeen, 0)
+	}
+	for key, e := range s.Entries {
+		if now.Sub(time.Unix(e.LastSeen, 0)) > agePruneAfter {
+			delete(s.Entries, key)
+		}
+	}
+}
+
+// save atomically writes the store back to disk.
+func (s *ageStore) save() error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(s.path, data)
+}
+
+// writeFileAtomically writes data to the named file via a temporary
+// file in the same directory, so that concurrent runs never see a
+// partially written file.
+func writeFileAtomically(name string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), name)
+}
+
+// age returns how long ago a problem was first seen, in whole days.
+func age(firstSeen, now time.Time) int {
+	return int(now.Sub(firstSeen) / (24 * time.Hour))
+}
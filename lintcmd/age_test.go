@@ -0,0 +1,59 @@
+package lintcmd
+
+import (
+	"go/token"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"honnef.co/go/tools/lintcmd/runner"
+)
+
+func TestAgeStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ageFileName)
+	diag := func(line int, msg string) diagnostic {
+		return diagnostic{
+			Diagnostic: runner.Diagnostic{
+				Position: token.Position{Filename: "/pkg/a.go", Line: line, Column: 1},
+				Category: "U1000",
+				Message:  msg,
+			},
+		}
+	}
+	day := 24 * time.Hour
+	t0 := time.Unix(1_600_000_000, 0)
+
+	s, err := readAgeStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := []diagnostic{diag(1, "func a is unused")}
+	s.update(first, t0)
+	if err := s.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = readAgeStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The problem moved to a different line, which mustn't reset its
+	// age.
+	second := []diagnostic{diag(5, "func a is unused"), diag(7, "func b is unused")}
+	s.update(second, t0.Add(31*day))
+	if !second[0].firstSeen.Equal(t0) {
+		t.Errorf("first seen at %s, want %s", second[0].firstSeen, t0)
+	}
+	if got := age(second[0].firstSeen, t0.Add(31*day)); got != 31 {
+		t.Errorf("got age %d, want 31", got)
+	}
+	if !second[1].firstSeen.Equal(t0.Add(31 * day)) {
+		t.Errorf("new problem first seen at %s, want %s", second[1].firstSeen, t0.Add(31*day))
+	}
+
+	// Problems that haven't been seen for a long time are forgotten.
+	s.update(nil, t0.Add(31*day+agePruneAfter+day))
+	if len(s.Entries) != 0 {
+		t.Errorf("got %d entries, want 0", len(s.Entries))
+	}
+}
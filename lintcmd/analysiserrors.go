@@ -0,0 +1,51 @@
+package lintcmd
+
+import (
+	"encoding/json"
+	"os"
+
+	"honnef.co/go/tools/unused"
+)
+
+// An analysisError is an internal failure of the unused code analysis
+// of a package.
+type analysisError struct {
+	pkg string
+	err unused.AnalysisError
+}
+
+// writeAnalysisErrors writes the internal failures of the unused code
+// analysis to the named file, as JSON, for filing bug reports.
+func writeAnalysisErrors(name string, errs []analysisError) error {
+	type location struct {
+		File   string `json:"file"`
+		Line   int    `json:"line"`
+		Column int    `json:"column"`
+	}
+	type entry struct {
+		Package   string    `json:"package"`
+		Kind      string    `json:"kind"`
+		Construct string    `json:"construct,omitempty"`
+		Position  *location `json:"position,omitempty"`
+		Message   string    `json:"message"`
+		Stack     string    `json:"stack"`
+	}
+	entries := make([]entry, len(errs))
+	for i, e := range errs {
+		entries[i] = entry{
+			Package:   e.pkg,
+			Kind:      string(e.err.Kind),
+			Construct: e.err.Construct,
+			Message:   e.err.Message,
+			Stack:     e.err.Stack,
+		}
+		if pos := e.err.Position; pos.IsValid() {
+			entries[i].Position = &location{pos.Filename, pos.Line, pos.Column}
+		}
+	}
+	data, err := json.MarshalIndent(entries, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, append(data, '\n'), 0666)
+}
@@ -0,0 +1,254 @@
+// Package apply applies the suggested fixes of diagnostics to files.
+//
+// Fixes may conflict with each other. For example, the fix of an
+// unused type deletes its declaration, and the fix of one of its
+// unused fields deletes a part of the same declaration. Conflicts are
+// resolved in favour of the outermost fix: fixes are considered from
+// the one that edits the most bytes to the one that edits the least,
+// and fixes whose edits lie entirely within the edits of an applied
+// fix are subsumed by it. All other fixes that overlap an applied fix
+// are rejected. Fixes are applied atomically: either all of their
+// edits are applied, or none of them.
+package apply
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"honnef.co/go/tools/lintcmd/runner"
+)
+
+// A Rejection describes a fix that wasn't applied.
+type Rejection struct {
+	Diagnostic runner.Diagnostic
+	Fix        runner.SuggestedFix
+	Reason     string
+}
+
+// Result is the result of applying fixes.
+type Result struct {
+	// Files maps the names of the modified files to their new
+	// contents.
+	Files map[string][]byte
+	// Applied lists the diagnostics whose fixes were applied,
+	// including the ones whose fixes were subsumed by enclosing
+	// fixes.
+	Applied []runner.Diagnostic
+	// Rejected lists the fixes that weren't applied because they
+	// conflicted with other fixes or didn't fit the files.
+	Rejected []Rejection
+}
+
+type edit struct {
+	file       string
+	start, end int
+	text       string
+}
+
+func (e edit) String() string {
+	return fmt.Sprintf("%s:#%d-#%d", e.file, e.start, e.end)
+}
+
+// overlaps reports whether two edits of the same file touch the same
+// bytes. Insertions only overlap edits that replace bytes on both
+// sides of them, and insertions at the same offset.
+func (e edit) overlaps(o edit) bool {
+	if e.start == e.end && o.start == o.end {
+		return e.start == o.start
+	}
+	if e.start == e.end {
+		return o.start < e.start && e.start < o.end
+	}
+	if o.start == o.end {
+		return e.start < o.start && o.start < e.end
+	}
+	return e.start < o.end && o.start < e.end
+}
+
+// within reports whether e lies within the bytes that o replaces.
+// Insertions at either end of o are outside of it.
+func (e edit) within(o edit) bool {
+	if e.start == e.end {
+		return o.start < e.start && e.start < o.end
+	}
+	return o.start < o.end && o.start <= e.start && e.end <= o.end
+}
+
+type candidate struct {
+	diag  runner.Diagnostic
+	fix   runner.SuggestedFix
+	edits []edit
+	// the number of bytes that the fix replaces
+	size int
+	// the position of the diagnostic among the input, for stable
+	// sorting
+	index int
+}
+
+// Fixes applies the first suggested fix of each diagnostic to the
+// files that the fixes edit, resolving conflicts between fixes as
+// described in the package documentation. readFile returns the
+// current contents of the named file; if it is nil, os.ReadFile is
+// used. Files are only read, not written; see Result.Write.
+func Fixes(diags []runner.Diagnostic, readFile func(name string) ([]byte, error)) (*Result, error) {
+	if readFile == nil {
+		readFile = os.ReadFile
+	}
+	res := &Result{Files: map[string][]byte{}}
+	sources := map[string][]byte{}
+	source := func(name string) ([]byte, error) {
+		if src, ok := sources[name]; ok {
+			return src, nil
+		}
+		src, err := readFile(name)
+		if err != nil {
+			return nil, err
+		}
+		sources[name] = src
+		return src, nil
+	}
+
+	var cands []candidate
+	for i, diag := range diags {
+		if len(diag.SuggestedFixes) == 0 {
+			continue
+		}
+		c := candidate{diag: diag, fix: diag.SuggestedFixes[0], index: i}
+		reason := ""
+		for _, te := range c.fix.TextEdits {
+			e := edit{
+				file:  te.Position.Filename,
+				start: te.Position.Offset,
+				end:   te.Position.Offset,
+				text:  string(te.NewText),
+			}
+			if te.End.IsValid() {
+				if te.End.Filename != e.file {
+					reason = "an edit spans several files"
+					break
+				}
+				e.end = te.End.Offset
+			}
+			src, err := source(e.file)
+			if err != nil {
+				return nil, err
+			}
+			if e.start < 0 || e.end < e.start || e.end > len(src) {
+				reason = fmt.Sprintf("the edit %s doesn't fit the contents of the file", e)
+				break
+			}
+			c.edits = append(c.edits, e)
+			c.size += e.end - e.start
+		}
+		if reason != "" {
+			res.Rejected = append(res.Rejected, Rejection{diag, c.fix, reason})
+			continue
+		}
+		cands = append(cands, c)
+	}
+
+	// Outermost fixes win.
+	sort.SliceStable(cands, func(i, j int) bool {
+		if cands[i].size != cands[j].size {
+			return cands[i].size > cands[j].size
+		}
+		return cands[i].index < cands[j].index
+	})
+
+	accepted := map[string][]edit{}
+	var applied []candidate
+	for _, c := range cands {
+		var fresh []edit
+		subsumed := false
+		reason := ""
+	edits:
+		for _, e := range c.edits {
+			dup := false
+			for _, a := range accepted[e.file] {
+				if e == a {
+					// Identical edits, such as the deletion of the
+					// same import by two fixes, are applied once.
+					dup = true
+					break
+				}
+				if e.within(a) {
+					subsumed = true
+					continue edits
+				}
+				if e.overlaps(a) {
+					reason = fmt.Sprintf("the edit %s overlaps the edit %s of another fix", e, a)
+					break edits
+				}
+			}
+			if !dup {
+				fresh = append(fresh, e)
+			}
+		}
+		if reason == "" && subsumed && len(fresh) > 0 {
+			// Applying only the edits outside the enclosing fix
+			// could leave the file in a state that neither fix
+			// intended.
+			reason = "the fix is partially enclosed by another fix"
+		}
+		if reason == "" {
+			// Edits of the same fix mustn't overlap each other,
+			// either.
+			for i, e := range fresh {
+				for _, o := range fresh[:i] {
+					if e.file == o.file && (e == o || e.overlaps(o)) {
+						reason = fmt.Sprintf("the edit %s overlaps the edit %s of the same fix", e, o)
+						break
+					}
+				}
+			}
+		}
+		if reason != "" {
+			res.Rejected = append(res.Rejected, Rejection{c.diag, c.fix, reason})
+			continue
+		}
+		for _, e := range fresh {
+			accepted[e.file] = append(accepted[e.file], e)
+		}
+		applied = append(applied, c)
+	}
+
+	// Report in the order of the input.
+	sort.Slice(applied, func(i, j int) bool {
+		return applied[i].index < applied[j].index
+	})
+	for _, c := range applied {
+		res.Applied = append(res.Applied, c.diag)
+	}
+
+	for name, edits := range accepted {
+		sort.Slice(edits, func(i, j int) bool {
+			return edits[i].start > edits[j].start
+		})
+		out := append([]byte(nil), sources[name]...)
+		for _, e := range edits {
+			out = append(out[:e.start:e.start], append([]byte(e.text), out[e.end:]...)...)
+		}
+		res.Files[name] = out
+	}
+	return res, nil
+}
+
+// Write writes the modified files, keeping their permissions.
+func (res *Result) Write() error {
+	names := make([]string, 0, len(res.Files))
+	for name := range res.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fi, err := os.Stat(name)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(name, res.Files[name], fi.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
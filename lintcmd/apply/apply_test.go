@@ -0,0 +1,106 @@
+package apply
+
+import (
+	"go/token"
+	"strings"
+	"testing"
+
+	"honnef.co/go/tools/lintcmd/runner"
+)
+
+func TestFixes(t *testing.T) {
+	const src = `package pkg
+
+type T struct {
+	a int
+	b int
+}
+
+func f() {}
+
+func g() {}
+`
+	// del returns a diagnostic whose fix deletes the text from the
+	// start of from to the end of to.
+	del := func(msg, file, from, to string) runner.Diagnostic {
+		start := strings.Index(src, from)
+		end := strings.Index(src, to) + len(to)
+		return runner.Diagnostic{
+			Message: msg,
+			SuggestedFixes: []runner.SuggestedFix{{
+				Message: msg,
+				TextEdits: []runner.TextEdit{{
+					Position: token.Position{Filename: file, Offset: start, Line: 1},
+					End:      token.Position{Filename: file, Offset: end, Line: 1},
+				}},
+			}},
+		}
+	}
+	diags := []runner.Diagnostic{
+		// nested within the deletion of T
+		del("field a", "a.go", "\ta int\n", "\ta int\n"),
+		del("type T", "a.go", "type T", "}\n\n"),
+		del("func f", "a.go", "func f", "{}\n\n"),
+		// overlaps the deletion of f without being nested in it
+		del("f and g", "a.go", "() {}\n\nfunc", "() {}\n\nfunc"),
+		// identical to the deletion of f
+		del("func f again", "a.go", "func f", "{}\n\n"),
+		{Message: "no fix"},
+		del("func g", "b.go", "func g", "g() {}\n"),
+	}
+	res, err := Fixes(diags, func(name string) ([]byte, error) {
+		return []byte(src), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var applied []string
+	for _, diag := range res.Applied {
+		applied = append(applied, diag.Message)
+	}
+	if got, want := strings.Join(applied, ", "), "field a, type T, func f, func f again, func g"; got != want {
+		t.Errorf("applied %s, want %s", got, want)
+	}
+	if len(res.Rejected) != 1 || res.Rejected[0].Diagnostic.Message != "f and g" {
+		t.Errorf("got rejections %v, want only the one of f and g", res.Rejected)
+	}
+
+	if got, want := string(res.Files["a.go"]), "package pkg\n\nfunc g() {}\n"; got != want {
+		t.Errorf("got a.go\n%s\nwant\n%s", got, want)
+	}
+	if got, want := string(res.Files["b.go"]), "package pkg\n\ntype T struct {\n\ta int\n\tb int\n}\n\nfunc f() {}\n\n"; got != want {
+		t.Errorf("got b.go\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestInsertionNextToDeletion(t *testing.T) {
+	const src = "a\nb\nc\n"
+	diags := []runner.Diagnostic{
+		{
+			Message: "delete b",
+			SuggestedFixes: []runner.SuggestedFix{{TextEdits: []runner.TextEdit{{
+				Position: token.Position{Filename: "a.go", Offset: 2, Line: 2},
+				End:      token.Position{Filename: "a.go", Offset: 4, Line: 3},
+			}}}},
+		},
+		{
+			// inserted where the deletion of b ends, which isn't
+			// within it
+			Message: "insert before c",
+			SuggestedFixes: []runner.SuggestedFix{{TextEdits: []runner.TextEdit{{
+				Position: token.Position{Filename: "a.go", Offset: 4, Line: 3},
+				NewText:  []byte("x\n"),
+			}}}},
+		},
+	}
+	res, err := Fixes(diags, func(name string) ([]byte, error) {
+		return []byte(src), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(res.Files["a.go"]), "a\nx\nc\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,144 @@
+package lintcmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/go/loader"
+	"honnef.co/go/tools/lintcmd/runner"
+)
+
+// binaryUses records the objects that the packages use directly, for
+// attributing used objects to the binaries of -unused.binaries. Main
+// packages and their test variants use objects on behalf of their
+// binaries. All other packages, including test binaries, use objects
+// on behalf of every binary.
+type binaryUses struct {
+	// patterns of the import paths of the binaries whose exclusive
+	// objects get reported, matched like the patterns of -report
+	patterns []string
+	// the objects that main packages use, by import path
+	byBinary map[string][]unusedKey
+	// the objects that other packages use
+	shared []unusedKey
+}
+
+func newBinaryUses(patterns []string) *binaryUses {
+	return &binaryUses{
+		patterns: patterns,
+		byBinary: map[string][]unusedKey{},
+	}
+}
+
+// use records that the package spec uses the object with the key.
+func (b *binaryUses) use(spec *loader.PackageSpec, key unusedKey) {
+	if spec.Name == "main" && !strings.HasSuffix(spec.PkgPath, ".test") {
+		b.byBinary[spec.PkgPath] = append(b.byBinary[spec.PkgPath], key)
+	} else {
+		b.shared = append(b.shared, key)
+	}
+}
+
+// reach returns the objects that the seeds use, directly or via the
+// dependencies of whole-program mode.
+func reach(seeds []unusedKey, dependencies map[unusedKey][]unusedKey) map[unusedKey]bool {
+	out := map[unusedKey]bool{}
+	queue := make([]unusedKey, 0, len(seeds))
+	for _, key := range seeds {
+		if !out[key] {
+			out[key] = true
+			queue = append(queue, key)
+		}
+	}
+	for len(queue) > 0 {
+		key := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		for _, dep := range dependencies[key] {
+			if !out[dep] {
+				out[dep] = true
+				queue = append(queue, dep)
+			}
+		}
+	}
+	return out
+}
+
+// binaryDiagnostics colors the objects separately for the root set of
+// every binary and reports the objects of other packages that only
+// binaries matching the patterns use, such as a function of a shared
+// library that only cmd/legacy calls. Only objects that are unused
+// within their own packages can be exclusive to binaries; everything
+// else is used by the packages themselves.
+func (st *unusedState) binaryDiagnostics() []diagnostic {
+	b := st.binaries
+	if b == nil || len(b.byBinary) == 0 {
+		return nil
+	}
+	shared := reach(b.shared, st.dependencies)
+	var binaries []string
+	for name := range b.byBinary {
+		binaries = append(binaries, name)
+	}
+	sort.Strings(binaries)
+	users := map[unusedKey][]string{}
+	for _, name := range binaries {
+		for key := range reach(b.byBinary[name], st.dependencies) {
+			if !shared[key] {
+				users[key] = append(users[key], name)
+			}
+		}
+	}
+
+	var out []diagnostic
+	// Test variants of packages report the same objects.
+	seen := map[unusedKey]bool{}
+	for _, uo := range st.unuseds {
+		if seen[uo.key] {
+			continue
+		}
+		seen[uo.key] = true
+		bins := users[uo.key]
+		if len(bins) == 0 || !b.exclusive(uo.key.pkgPath, bins) {
+			continue
+		}
+		out = append(out, diagnostic{
+			Diagnostic: runner.Diagnostic{
+				Position: uo.obj.DisplayPosition,
+				Message:  fmt.Sprintf("%s %s is only used by %s", uo.obj.Kind, uo.obj.Name, strings.Join(bins, ", ")),
+				Category: "binary",
+				Anchor:   runner.ObjectAnchor(uo.key.pkgPath, uo.obj.ObjectPath, uo.obj.Name),
+				Package:  uo.key.pkgPath,
+				Symbol:   unusedSymbol(uo.key.pkgPath, uo.obj),
+			},
+			severity:   severityInfo,
+			configured: true,
+			mergeIf:    lint.MergeIfAll,
+		})
+	}
+	return out
+}
+
+// exclusive reports whether the binaries using an object of the
+// package pkgPath all match the patterns. The objects of the binaries
+// themselves are trivially exclusive to them and aren't reported.
+func (b *binaryUses) exclusive(pkgPath string, bins []string) bool {
+	for _, bin := range bins {
+		if bin == pkgPath {
+			return false
+		}
+		matches := false
+		for _, pattern := range b.patterns {
+			if config.MatchTree(pattern, bin) {
+				matches = true
+				break
+			}
+		}
+		if !matches {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+package lintcmd
+
+import (
+	"go/token"
+	"reflect"
+	"testing"
+
+	"honnef.co/go/tools/go/loader"
+	"honnef.co/go/tools/unused"
+)
+
+func TestBinaryDiagnostics(t *testing.T) {
+	const lib = "example.com/app/lib"
+	key := func(name string, line int) unusedKey {
+		return unusedKey{pkgPath: lib, base: "lib.go", line: line, name: name}
+	}
+	shared, legacy, legacyHelper, both, tested, dead := key("Shared", 3), key("Legacy", 5), key("legacyHelper", 7), key("Both", 9), key("Tested", 11), key("Dead", 13)
+	own := unusedKey{pkgPath: "example.com/app/cmd/legacy", base: "main.go", line: 5, name: "run"}
+
+	st := newUnusedState()
+	st.binaries = newBinaryUses([]string{"example.com/app/cmd/..."})
+	uses := func(spec *loader.PackageSpec, keys ...unusedKey) {
+		for _, key := range keys {
+			st.used[key] = true
+			st.binaries.use(spec, key)
+		}
+	}
+	legacyPkg := &loader.PackageSpec{ID: "example.com/app/cmd/legacy", PkgPath: "example.com/app/cmd/legacy", Name: "main"}
+	legacyTest := &loader.PackageSpec{ID: "example.com/app/cmd/legacy [example.com/app/cmd/legacy.test]", PkgPath: "example.com/app/cmd/legacy", Name: "main"}
+	serverPkg := &loader.PackageSpec{ID: "example.com/app/cmd/server", PkgPath: "example.com/app/cmd/server", Name: "main"}
+	toolPkg := &loader.PackageSpec{ID: "example.com/app/tools/gen", PkgPath: "example.com/app/tools/gen", Name: "main"}
+	libTest := &loader.PackageSpec{ID: "example.com/app/lib [example.com/app/lib.test]", PkgPath: lib, Name: "lib"}
+	uses(legacyPkg, own, legacy, shared)
+	uses(legacyTest, own, legacy, shared)
+	uses(serverPkg, shared, both)
+	uses(toolPkg, both)
+	uses(libTest, tested)
+	uses(legacyPkg, tested)
+	// Legacy uses legacyHelper.
+	st.dependencies[legacy] = []unusedKey{legacyHelper}
+
+	for _, k := range []unusedKey{shared, legacy, legacyHelper, both, tested, dead} {
+		st.unuseds = append(st.unuseds, unusedPair{key: k, obj: unused.SerializedObject{
+			Name:            k.name,
+			Kind:            "func",
+			DisplayPosition: token.Position{Filename: "/app/lib/lib.go", Line: k.line, Column: 6},
+		}})
+	}
+	st.unuseds = append(st.unuseds, unusedPair{key: own, obj: unused.SerializedObject{Name: "run", Kind: "func"}})
+
+	var got []string
+	for _, diag := range st.binaryDiagnostics() {
+		got = append(got, diag.Message)
+		if diag.Category != "binary" || diag.severity != severityInfo {
+			t.Errorf("%s: got category %s and severity %s, want binary and info", diag.Message, diag.Category, diag.severity)
+		}
+	}
+	// Both is also used by tools/gen, which doesn't match the
+	// patterns, the tests of lib use Tested, and run belongs to
+	// cmd/legacy itself.
+	want := []string{
+		"func Shared is only used by example.com/app/cmd/legacy, example.com/app/cmd/server",
+		"func Legacy is only used by example.com/app/cmd/legacy",
+		"func legacyHelper is only used by example.com/app/cmd/legacy",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	st.binaries.patterns = []string{"example.com/app/cmd/server", "example.com/app/tools/gen"}
+	got = nil
+	for _, diag := range st.binaryDiagnostics() {
+		got = append(got, diag.Message)
+	}
+	if want := []string{"func Both is only used by example.com/app/cmd/server, example.com/app/tools/gen"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -4,16 +4,21 @@ package lintcmd
 
 import (
 	"bufio"
+	"context"
 	"encoding/gob"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"go/token"
 	"io"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"runtime/debug"
 	"runtime/pprof"
 	"runtime/trace"
 	"sort"
@@ -24,7 +29,10 @@ import (
 	"honnef.co/go/tools/analysis/lint"
 	"honnef.co/go/tools/config"
 	"honnef.co/go/tools/go/loader"
+	"honnef.co/go/tools/lintcmd/apply"
+	"honnef.co/go/tools/lintcmd/runner"
 	"honnef.co/go/tools/lintcmd/version"
+	"honnef.co/go/tools/unused"
 
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/buildutil"
@@ -40,6 +48,7 @@ type buildConfig struct {
 type Command struct {
 	name           string
 	analyzers      map[string]*lint.Analyzer
+	formatters     map[string]Formatter
 	version        string
 	machineVersion string
 
@@ -50,12 +59,15 @@ type Command struct {
 		tests       bool
 		showIgnored bool
 		formatter   string
+		formatOpts  list
 
 		// mutually exclusive mode flags
-		explain      string
-		printVersion bool
-		listChecks   bool
-		merge        bool
+		explain       string
+		printVersion  bool
+		listChecks    bool
+		merge         bool
+		unusedMerge   bool
+		unusedCompare bool
 
 		matrix bool
 
@@ -65,10 +77,36 @@ type Command struct {
 		debugNoCompileErrors  bool
 		debugMeasureAnalyzers string
 		debugTrace            string
+		debugGOGC             int
+		debugBallast          int
+		debugGCAdvice         bool
 
 		checks    list
 		fail      list
 		goVersion versionFlag
+
+		unusedWholeProgram bool
+		unusedShard        string
+		unusedSnapshot     string
+		changed            string
+		codeOwners         string
+		trackAge           bool
+		failAge            int
+		suppressions       string
+		coverProfile       string
+		progress           bool
+		impact             list
+		conversions        list
+		binaries           list
+		fingerprints       bool
+		ignoreFile         string
+		ownership          string
+		unusedErrors       string
+		fix                bool
+		interactive        bool
+		timeout            time.Duration
+		report             list
+		noReport           list
 	}
 }
 
@@ -77,6 +115,7 @@ func NewCommand(name string) *Command {
 	cmd := &Command{
 		name:           name,
 		analyzers:      map[string]*lint.Analyzer{},
+		formatters:     map[string]Formatter{},
 		version:        "devel",
 		machineVersion: "devel",
 	}
@@ -142,7 +181,8 @@ func (cmd *Command) initFlagSet(name string) {
 	flags.BoolVar(&cmd.flags.tests, "tests", true, "Include tests")
 	flags.BoolVar(&cmd.flags.printVersion, "version", false, "Print version and exit")
 	flags.BoolVar(&cmd.flags.showIgnored, "show-ignored", false, "Don't filter ignored diagnostics")
-	flags.StringVar(&cmd.flags.formatter, "f", "text", "Output `format` (valid choices are 'stylish', 'text' and 'json')")
+	flags.StringVar(&cmd.flags.formatter, "f", "text", "Output `format` (valid choices are 'stylish', 'text', 'json', 'sarif', 'junit', 'owners', 'exec:program' and the names of added formatters)")
+	flags.Var(&cmd.flags.formatOpts, "format-options", "Comma-separated list of `key=value` options to pass to the formatter of -f")
 	flags.StringVar(&cmd.flags.explain, "explain", "", "Print description of `check`")
 	flags.BoolVar(&cmd.flags.listChecks, "list-checks", false, "List all available checks")
 	flags.BoolVar(&cmd.flags.merge, "merge", false, "Merge results of multiple Staticcheck runs")
@@ -154,12 +194,39 @@ func (cmd *Command) initFlagSet(name string) {
 	flags.BoolVar(&cmd.flags.debugNoCompileErrors, "debug.no-compile-errors", false, "Don't print compile errors")
 	flags.StringVar(&cmd.flags.debugMeasureAnalyzers, "debug.measure-analyzers", "", "Write analysis measurements to `file`. `file` will be opened for appending if it already exists.")
 	flags.StringVar(&cmd.flags.debugTrace, "debug.trace", "", "Write trace to `file`")
+	flags.IntVar(&cmd.flags.debugGOGC, "debug.gogc", 0, "Set the garbage collection target `percentage`, like GOGC. Zero keeps GOGC's value")
+	flags.IntVar(&cmd.flags.debugBallast, "debug.ballast", 0, "Allocate a memory ballast of `MiB` to collect garbage less often")
+	flags.BoolVar(&cmd.flags.debugGCAdvice, "debug.gc-advice", false, "Recommend a memory ballast and GOGC value, based on the sizes of the graphs of the unused code analysis")
 
 	cmd.flags.checks = list{"inherit"}
 	cmd.flags.fail = list{"all"}
 	cmd.flags.goVersion = versionFlag("module")
 	flags.Var(&cmd.flags.checks, "checks", "Comma-separated list of `checks` to enable.")
 	flags.Var(&cmd.flags.fail, "fail", "Comma-separated list of `checks` that can cause a non-zero exit status.")
+	flags.BoolVar(&cmd.flags.unusedWholeProgram, "unused.whole-program", false, "Run unused in whole-program mode")
+	flags.StringVar(&cmd.flags.unusedShard, "unused.shard", "", "Instead of reporting unused objects, write the state of their analysis for the checked packages to `file`, for combining the shards of a program with -unused.merge")
+	flags.BoolVar(&cmd.flags.unusedMerge, "unused.merge", false, "Report the unused objects of a program from the shards written by -unused.shard, named by the arguments")
+	flags.StringVar(&cmd.flags.unusedSnapshot, "unused.snapshot", "", "Also write which objects are unused, and why the others are used, to `file`, for comparing the snapshots of two commits with -unused.compare")
+	flags.BoolVar(&cmd.flags.unusedCompare, "unused.compare", false, "Report the objects that became unused, the unused objects that got fixed and the objects that changed why they're used, between the old and new snapshots written by -unused.snapshot, named by the two arguments")
+	flags.StringVar(&cmd.flags.codeOwners, "codeowners", "", "Attribute problems to the owners listed in the CODEOWNERS `file`")
+	flags.BoolVar(&cmd.flags.trackAge, "track-age", false, "Record when problems were first seen, in the cache directory, and report their age")
+	flags.IntVar(&cmd.flags.failAge, "fail-age", 0, "Only let problems that were first seen at least `days` ago cause a non-zero exit status. Implies -track-age")
+	flags.StringVar(&cmd.flags.suppressions, "suppressions", "", "Write a JSON report of the problems and objects that ignore directives suppressed to `file`")
+	flags.Var(&cmd.flags.impact, "unused.impact", "Comma-separated list of `patterns` of objects whose references by other packages to report, such as example.com/pkg.Func")
+	flags.Var(&cmd.flags.binaries, "unused.binaries", "Comma-separated list of `patterns` of main packages, such as example.com/app/cmd/legacy; report the objects of other packages that only these binaries use. Implies -unused.whole-program")
+	flags.Var(&cmd.flags.conversions, "unused.conversions", "Comma-separated list of `patterns` of struct types, such as example.com/pkg.T, whose conversions to and from other structs to report, along with the fields that only these conversions keep alive")
+	flags.StringVar(&cmd.flags.ownership, "unused.ownership", "", "Write the declaration tree of the checked packages, with the positions and sizes of declarations, to `file` as JSON")
+	flags.StringVar(&cmd.flags.unusedErrors, "unused.errors", "", "Write a JSON report of internal failures of the unused code analysis, with stack traces for bug reports, to `file`")
+	flags.Var(&cmd.flags.report, "report", "Comma-separated list of `patterns` of packages, files and symbols; only report problems in code matching one of them. The analysis still covers all packages")
+	flags.Var(&cmd.flags.noReport, "no-report", "Comma-separated list of `patterns` of packages, files and symbols; never report problems in code matching one of them")
+	flags.StringVar(&cmd.flags.ignoreFile, "ignore-file", "", "Ignore the problems listed in `file` by path and fingerprint, until their expiry dates, like //lint:ignore directives")
+	flags.BoolVar(&cmd.flags.fingerprints, "fingerprints", false, "Include the fingerprints of problems in the text, stylish, owners and junit formats. The json and sarif formats always include them")
+	flags.BoolVar(&cmd.flags.fix, "fix", false, "Apply the first suggested fix of each problem to the files, skipping fixes that conflict with enclosing fixes, and only report the problems that remain")
+	flags.BoolVar(&cmd.flags.interactive, "interactive", false, "Step through the problems, showing their code, and choose whether to apply the fix of each, skip it, or ignore it with a linter directive. Skipped problems aren't asked about again")
+	flags.DurationVar(&cmd.flags.timeout, "timeout", 0, "Stop the analysis and exit with an error after `duration`, such as 10m. Zero means no limit")
+	flags.BoolVar(&cmd.flags.progress, "progress", false, "Report progress on stderr, including an estimate of the remaining time based on earlier runs")
+	flags.StringVar(&cmd.flags.coverProfile, "coverprofile", "", "Also flag functions that are used but that no test covers, according to the coverage profile in `file`, as written by go test -coverprofile")
+	flags.StringVar(&cmd.flags.changed, "changed", "", "Only check the packages affected by the changed files or import paths listed in `file`, one per line, in whole-program mode. Use - to read from stdin")
 	flags.Var(&cmd.flags.goVersion, "go", "Target Go `version` in the format '1.x', or the literal 'module' to use the module's Go version")
 }
 
@@ -285,6 +352,18 @@ func (cmd *Command) Run() {
 		trace.Start(f)
 	}
 
+	// Tune the garbage collector
+	if cmd.flags.debugGOGC != 0 {
+		debug.SetGCPercent(cmd.flags.debugGOGC)
+	}
+	var ballast []byte
+	if cmd.flags.debugBallast > 0 {
+		// The ballast is never touched, so it doesn't take up
+		// physical memory, but it counts towards the heap size that
+		// the collector's pacing is based on.
+		ballast = make([]byte, cmd.flags.debugBallast<<20)
+	}
+
 	// Update the default config's list of enabled checks
 	defaultChecks := []string{"all"}
 	for _, a := range cmd.analyzers {
@@ -307,6 +386,10 @@ func (cmd *Command) Run() {
 		exit = cmd.explain()
 	case cmd.flags.merge:
 		exit = cmd.merge()
+	case cmd.flags.unusedMerge:
+		exit = cmd.mergeShards()
+	case cmd.flags.unusedCompare:
+		exit = cmd.compareSnapshots()
 	default:
 		exit = cmd.lint()
 	}
@@ -326,6 +409,7 @@ func (cmd *Command) Run() {
 	if cmd.flags.debugTrace != "" {
 		trace.Stop()
 	}
+	runtime.KeepAlive(ballast)
 
 	// Exit with appropriate status
 	os.Exit(exit)
@@ -381,6 +465,11 @@ func (cmd *Command) explain() int {
 }
 
 func (cmd *Command) merge() int {
+	if cmd.flags.fix || cmd.flags.interactive {
+		// Merged runs don't record the offsets that fixes need.
+		fmt.Fprintln(os.Stderr, "cannot use -merge with -fix or -interactive")
+		return 2
+	}
 	var runs []run
 	if len(cmd.flags.fs.Args()) == 0 {
 		var err error
@@ -414,20 +503,116 @@ func (cmd *Command) merge() int {
 	return 0
 }
 
+// mergeShards reports the unused objects of the shards written by
+// -unused.shard, as well as the other findings that depend on which
+// objects are used.
+func (cmd *Command) mergeShards() int {
+	if len(cmd.flags.fs.Args()) == 0 {
+		fmt.Fprintln(os.Stderr, "-unused.merge needs the names of shard files as arguments")
+		return 2
+	}
+	st := newUnusedState()
+	if len(cmd.flags.binaries) > 0 {
+		st.binaries = newBinaryUses(cmd.flags.binaries)
+	}
+	for _, path := range cmd.flags.fs.Args() {
+		if err := readShard(path, st); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	var cov coverage
+	if cmd.flags.coverProfile != "" {
+		var err error
+		cov, err = readCoverage(cmd.flags.coverProfile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "couldn't read coverage profile:", err)
+			return 1
+		}
+	}
+	cs := cmd.analyzersAsSlice()
+	exit := cmd.printDiagnostics(cs, st.diagnostics(cov))
+	if cmd.flags.unusedSnapshot != "" {
+		if err := writeSnapshot(cmd.flags.unusedSnapshot, st); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	return exit
+}
+
+// compareSnapshots reports the difference between two snapshots
+// written by -unused.snapshot, as text or, with -f json, as JSON.
+func (cmd *Command) compareSnapshots() int {
+	args := cmd.flags.fs.Args()
+	if len(args) != 2 {
+		fmt.Fprintln(os.Stderr, "-unused.compare needs the names of the old and new snapshot files as arguments")
+		return 2
+	}
+	if cmd.flags.formatter != "text" && cmd.flags.formatter != "json" {
+		fmt.Fprintf(os.Stderr, "unsupported output format %q for -unused.compare\n", cmd.flags.formatter)
+		return 2
+	}
+	before, err := readSnapshot(args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	after, err := readSnapshot(args[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	diff := compareSnapshots(before, after)
+	if cmd.flags.formatter == "json" {
+		if err := json.NewEncoder(os.Stdout).Encode(diff); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
+	diff.writeText(os.Stdout)
+	return 0
+}
+
 func (cmd *Command) lint() int {
 	switch cmd.flags.formatter {
-	case "text", "stylish", "json", "sarif", "binary", "null":
+	case "text", "stylish", "json", "sarif", "junit", "owners", "binary", "null":
 	default:
+		if _, ok := cmd.lookupFormatter(cmd.flags.formatter); ok {
+			break
+		}
 		fmt.Fprintf(os.Stderr, "unsupported output format %q\n", cmd.flags.formatter)
 		return 2
 	}
 
+	if cmd.flags.fix && cmd.flags.formatter == "binary" {
+		fmt.Fprintln(os.Stderr, "cannot use -f binary and -fix together")
+		return 2
+	}
+	if cmd.flags.interactive && cmd.flags.formatter == "binary" {
+		fmt.Fprintln(os.Stderr, "cannot use -f binary and -interactive together")
+		return 2
+	}
+	if cmd.flags.fix && cmd.flags.interactive {
+		fmt.Fprintln(os.Stderr, "cannot use -fix and -interactive together")
+		return 2
+	}
+
 	var bconfs []buildConfig
 	if cmd.flags.matrix {
 		if cmd.flags.tags != "" {
 			fmt.Fprintln(os.Stderr, "cannot use -matrix and -tags together")
 			return 2
 		}
+		if cmd.flags.unusedShard != "" {
+			fmt.Fprintln(os.Stderr, "cannot use -matrix and -unused.shard together")
+			return 2
+		}
+		if cmd.flags.unusedSnapshot != "" {
+			fmt.Fprintln(os.Stderr, "cannot use -matrix and -unused.snapshot together")
+			return 2
+		}
 
 		var err error
 		bconfs, err = parseBuildConfigs(os.Stdin)
@@ -474,7 +659,46 @@ func (cmd *Command) lint() int {
 		}
 	}
 
+	// Shards always record why objects are used and which binaries
+	// use them; only the merge knows the whole program.
+	if cmd.flags.unusedShard != "" && cmd.flags.unusedSnapshot != "" {
+		fmt.Fprintln(os.Stderr, "cannot use -unused.shard and -unused.snapshot together, pass -unused.snapshot to -unused.merge instead")
+		return 2
+	}
+	if cmd.flags.unusedShard != "" && len(cmd.flags.binaries) > 0 {
+		fmt.Fprintln(os.Stderr, "cannot use -unused.shard and -unused.binaries together, pass -unused.binaries to -unused.merge instead")
+		return 2
+	}
+
+	var changed []string
+	if cmd.flags.changed != "" {
+		if cmd.flags.changed == "-" && cmd.flags.matrix {
+			fmt.Fprintln(os.Stderr, "cannot use -matrix and -changed=- together")
+			return 2
+		}
+		var err error
+		changed, err = readChanged(cmd.flags.changed)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	var cov coverage
+	if cmd.flags.coverProfile != "" {
+		var err error
+		cov, err = readCoverage(cmd.flags.coverProfile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "couldn't read coverage profile:", err)
+			return 1
+		}
+	}
+
 	var runs []run
+	var sups []suppression
+	var decls []unused.SerializedDeclaration
+	var aerrs []analysisError
+	var graphs []graphSize
 	cs := cmd.analyzersAsSlice()
 	opts := options{
 		analyzers: cs,
@@ -483,7 +707,27 @@ func (cmd *Command) lint() int {
 		goVersion: string(cmd.flags.goVersion),
 		config: config.Config{
 			Checks: cmd.flags.checks,
+			Unused: config.Unused{
+				// Whole-program mode is needed for -changed,
+				// because analyzing a set of packages and all of
+				// their reverse dependencies is the same as analyzing
+				// the whole program, as far as these packages are
+				// concerned, and for -unused.binaries, because
+				// attributing objects to binaries requires knowing
+				// which binaries use the exported objects.
+				WholeProgram: cmd.flags.unusedWholeProgram || changed != nil || len(cmd.flags.binaries) > 0,
+				Impact:       cmd.flags.impact,
+				Conversions:  cmd.flags.conversions,
+				Ownership:    cmd.flags.ownership != "",
+				Reasons:      cmd.flags.unusedSnapshot != "" || cmd.flags.unusedShard != "",
+			},
 		},
+		changed:                  changed,
+		coverage:                 cov,
+		shard:                    cmd.flags.unusedShard,
+		snapshot:                 cmd.flags.unusedSnapshot,
+		binaries:                 cmd.flags.binaries,
+		progress:                 cmd.flags.progress,
 		printAnalyzerMeasurement: measureAnalyzers,
 	}
 	l, err := newLinter(opts)
@@ -491,9 +735,24 @@ func (cmd *Command) lint() int {
 		fmt.Fprintln(os.Stderr, err)
 		return 1
 	}
+	// Interrupting the analysis lets it stop cleanly, without
+	// leaving partial results in the cache.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if cmd.flags.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cmd.flags.timeout)
+		defer cancel()
+	}
 	for _, bconf := range bconfs {
-		res, err := l.run(bconf)
-		if err != nil {
+		res, err := l.run(ctx, bconf)
+		if errors.Is(err, context.DeadlineExceeded) {
+			fmt.Fprintf(os.Stderr, "analysis timed out after %s\n", cmd.flags.timeout)
+			return 1
+		} else if errors.Is(err, context.Canceled) {
+			fmt.Fprintln(os.Stderr, "analysis interrupted")
+			return 1
+		} else if err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			return 1
 		}
@@ -549,12 +808,37 @@ func (cmd *Command) lint() int {
 			}
 		} else {
 			runs = append(runs, runFromLintResult(res))
+			sups = append(sups, res.suppressions...)
+			decls = append(decls, res.declarations...)
+			aerrs = append(aerrs, res.analysisErrors...)
+			graphs = append(graphs, res.graphs...)
 		}
 	}
 
 	l.cache.Trim()
 
 	if cmd.flags.formatter != "binary" {
+		if cmd.flags.suppressions != "" {
+			if err := writeSuppressions(cmd.flags.suppressions, mergeSuppressions(sups)); err != nil {
+				fmt.Fprintf(os.Stderr, "failed writing suppressions: %s\n", err)
+				return 2
+			}
+		}
+		if cmd.flags.ownership != "" {
+			if err := writeOwnership(cmd.flags.ownership, decls); err != nil {
+				fmt.Fprintf(os.Stderr, "failed writing declaration tree: %s\n", err)
+				return 2
+			}
+		}
+		if cmd.flags.unusedErrors != "" {
+			if err := writeAnalysisErrors(cmd.flags.unusedErrors, aerrs); err != nil {
+				fmt.Fprintf(os.Stderr, "failed writing unused analysis errors: %s\n", err)
+				return 2
+			}
+		}
+		if cmd.flags.debugGCAdvice {
+			computeGCAdvice(graphs, runtime.GOMAXPROCS(0)).write(os.Stderr)
+		}
 		diags := mergeRuns(runs)
 		return cmd.printDiagnostics(cs, diags)
 	}
@@ -646,13 +930,30 @@ func (cmd *Command) printDiagnostics(cs []*lint.Analyzer, diagnostics []diagnost
 		}
 		diagnostics = filtered
 	}
+	setFingerprints(diagnostics)
+
+	// Restrict the report after computing fingerprints, which
+	// mustn't depend on the problems that aren't reported.
+	window := reportWindow{include: cmd.flags.report, exclude: cmd.flags.noReport}
+	window.cwd, _ = os.Getwd()
+	diagnostics = window.filter(diagnostics)
+
+	now := time.Now()
+	if cmd.flags.ignoreFile != "" {
+		ig, err := loadIgnoreFile(cmd.flags.ignoreFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		ig.apply(diagnostics, now)
+	}
 
 	var f formatter
 	switch cmd.flags.formatter {
 	case "text":
-		f = textFormatter{W: os.Stdout}
+		f = textFormatter{W: os.Stdout, fingerprints: cmd.flags.fingerprints}
 	case "stylish":
-		f = &stylishFormatter{W: os.Stdout}
+		f = &stylishFormatter{W: os.Stdout, fingerprints: cmd.flags.fingerprints}
 	case "json":
 		f = jsonFormatter{W: os.Stdout}
 	case "sarif":
@@ -664,14 +965,92 @@ func (cmd *Command) printDiagnostics(cs []*lint.Analyzer, diagnostics []diagnost
 			f.(*sarifFormatter).driverName = "Staticcheck"
 			f.(*sarifFormatter).driverWebsite = "https://staticcheck.io"
 		}
+	case "junit":
+		f = junitFormatter{W: os.Stdout, name: cmd.name, fingerprints: cmd.flags.fingerprints}
+	case "owners":
+		if cmd.flags.codeOwners == "" {
+			fmt.Fprintln(os.Stderr, "'-f owners' requires the -codeowners flag")
+			return 2
+		}
+		f = ownersFormatter{W: os.Stdout, fingerprints: cmd.flags.fingerprints}
 	case "binary":
 		fmt.Fprintln(os.Stderr, "'-f binary' not supported in this context")
 		return 2
 	case "null":
 		f = nullFormatter{}
 	default:
-		fmt.Fprintf(os.Stderr, "unsupported output format %q\n", cmd.flags.formatter)
-		return 2
+		pf, ok := cmd.lookupFormatter(cmd.flags.formatter)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "unsupported output format %q\n", cmd.flags.formatter)
+			return 2
+		}
+		opts, err := parseFormatOptions(cmd.flags.formatOpts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		cfg := FormatterConfig{
+			ABIVersion: FormatterABIVersion,
+			Tool:       cmd.name,
+			Version:    cmd.version,
+			Options:    opts,
+		}
+		if err := pf.Init(cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "couldn't initialize output format %q: %s\n", cmd.flags.formatter, err)
+			return 2
+		}
+		f = &pluginFormatter{f: pf, w: os.Stdout}
+	}
+
+	if cmd.flags.codeOwners != "" {
+		co, err := loadCodeOwners(cmd.flags.codeOwners)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		for i := range diagnostics {
+			name := diagnostics[i].Position.Filename
+			if name == "" {
+				continue
+			}
+			if abs, err := filepath.Abs(name); err == nil {
+				name = abs
+			}
+			diagnostics[i].owners = co.owners(name)
+		}
+	}
+
+	if cmd.flags.trackAge || cmd.flags.failAge > 0 {
+		ages, err := loadAgeStore()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		ages.update(diagnostics, now)
+		if err := ages.save(); err != nil {
+			fmt.Fprintf(os.Stderr, "couldn't save the age of problems: %s\n", err)
+			return 2
+		}
+	}
+
+	if cmd.flags.fix {
+		var err error
+		diagnostics, err = applyFixes(diagnostics)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "couldn't apply fixes: %s\n", err)
+			return 2
+		}
+	} else if cmd.flags.interactive {
+		checks := map[string]bool{}
+		for _, a := range cs {
+			checks[a.Analyzer.Name] = true
+		}
+		var err error
+		diagnostics, err = cmd.interactive(diagnostics, checks)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "interactive mode failed: %s\n", err)
+			return 2
+		}
 	}
 
 	fail := cmd.flags.fail
@@ -697,7 +1076,17 @@ func (cmd *Command) printDiagnostics(cs []*lint.Analyzer, diagnostics []diagnost
 			numIgnored++
 			continue
 		}
-		if shouldExit[diag.Category] {
+		if diag.configured {
+			// The severity configuration takes precedence over -fail.
+			if diag.severity == severityError && cmd.inGracePeriod(diag, now) {
+				diag.severity = severityWarning
+			}
+			if diag.severity == severityError {
+				numErrors++
+			} else {
+				numWarnings++
+			}
+		} else if shouldExit[diag.Category] && !cmd.inGracePeriod(diag, now) {
 			numErrors++
 		} else {
 			diag.severity = severityWarning
@@ -707,6 +1096,10 @@ func (cmd *Command) printDiagnostics(cs []*lint.Analyzer, diagnostics []diagnost
 	}
 
 	f.Format(cs, notIgnored)
+	if pf, ok := f.(*pluginFormatter); ok && pf.err != nil {
+		fmt.Fprintf(os.Stderr, "output format %q failed: %s\n", cmd.flags.formatter, pf.err)
+		return 2
+	}
 	if f, ok := f.(statter); ok {
 		f.Stats(len(diagnostics), numErrors, numWarnings, numIgnored)
 	}
@@ -722,6 +1115,48 @@ func (cmd *Command) printDiagnostics(cs []*lint.Analyzer, diagnostics []diagnost
 	return 0
 }
 
+// inGracePeriod reports whether a problem is too new to cause a
+// non-zero exit status, according to the -fail-age flag.
+func (cmd *Command) inGracePeriod(diag diagnostic, now time.Time) bool {
+	return cmd.flags.failAge > 0 && age(diag.firstSeen, now) < cmd.flags.failAge
+}
+
+// applyFixes applies the fixes of the problems that aren't ignored,
+// for the -fix flag, and returns the problems that remain.
+func applyFixes(diagnostics []diagnostic) ([]diagnostic, error) {
+	var fixable []runner.Diagnostic
+	for _, diag := range diagnostics {
+		if diag.severity != severityIgnored {
+			fixable = append(fixable, diag.Diagnostic)
+		}
+	}
+	res, err := apply.Fixes(fixable, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := res.Write(); err != nil {
+		return nil, err
+	}
+	for _, rej := range res.Rejected {
+		fmt.Fprintf(os.Stderr, "warning: didn't apply the fix %q of the problem at %s: %s\n", rej.Fix.Message, rej.Diagnostic.Position, rej.Reason)
+	}
+	if len(res.Applied) > 0 {
+		fmt.Fprintf(os.Stderr, "fixed %d problems in %d files\n", len(res.Applied), len(res.Files))
+	}
+
+	fixed := map[diagnosticDescriptor]bool{}
+	for _, diag := range res.Applied {
+		fixed[diagnostic{Diagnostic: diag}.descriptor()] = true
+	}
+	out := diagnostics[:0]
+	for _, diag := range diagnostics {
+		if diag.severity == severityIgnored || !fixed[diag.descriptor()] {
+			out = append(out, diag)
+		}
+	}
+	return out, nil
+}
+
 func usage(name string, fs *flag.FlagSet) func() {
 	return func() {
 		fmt.Fprintf(os.Stderr, "Usage: %s [flags] [packages]\n", name)
@@ -790,3 +1225,55 @@ func printDefaults(fs *flag.FlagSet) {
 		fmt.Fprint(fs.Output(), b.String(), "\n")
 	})
 }
+
+// readChanged reads the list of changed files and import paths for
+// the -changed flag. Relative file names are made absolute. Entries
+// that name existing files, end in .go, or name a file in an existing
+// directory other than the current one are file names; the latter
+// catches deleted files that aren't Go files. All other entries are
+// import paths.
+func readChanged(path string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	changed := []string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if isChangedFile(line) {
+			if abs, err := filepath.Abs(line); err == nil {
+				line = abs
+			}
+		}
+		changed = append(changed, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("couldn't read list of changes: %s", err)
+	}
+	return changed, nil
+}
+
+func isChangedFile(name string) bool {
+	if fi, err := os.Stat(name); err == nil {
+		return !fi.IsDir()
+	}
+	if strings.HasSuffix(name, ".go") {
+		return true
+	}
+	dir := filepath.Dir(name)
+	if dir == "." {
+		return false
+	}
+	fi, err := os.Stat(dir)
+	return err == nil && fi.IsDir()
+}
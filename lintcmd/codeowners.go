@@ -0,0 +1,156 @@
+package lintcmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// codeOwners maps files to their owners, as described by a CODEOWNERS
+// file in the format used by GitHub and GitLab.
+//
+// Patterns follow the rules of .gitignore files: patterns containing a
+// slash other than a trailing one are relative to the root of the
+// repository, others match at any depth. Patterns ending in a slash
+// match directories and everything in them. The last matching rule
+// wins. GitLab's sections and optional approvals are not supported;
+// section headers are skipped.
+type codeOwners struct {
+	// root is the directory that patterns are relative to
+	root  string
+	rules []codeOwnersRule
+}
+
+type codeOwnersRule struct {
+	pattern string
+	re      *regexp.Regexp
+	owners  []string
+}
+
+// loadCodeOwners parses the CODEOWNERS file at path. Patterns are
+// relative to the root of the repository, which is the directory
+// containing the file, or its parent if the file is in a .github,
+// .gitlab or docs directory.
+func loadCodeOwners(name string) (*codeOwners, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return nil, err
+	}
+	root := filepath.Dir(abs)
+	switch filepath.Base(root) {
+	case ".github", ".gitlab", "docs":
+		root = filepath.Dir(root)
+	}
+
+	co, err := parseCodeOwners(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", name, err)
+	}
+	co.root = root
+	return co, nil
+}
+
+func parseCodeOwners(r io.Reader) (*codeOwners, error) {
+	co := &codeOwners{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || text[0] == '#' || text[0] == '[' || text[0] == '^' {
+			// Comments and GitLab section headers
+			continue
+		}
+		if idx := strings.Index(text, " #"); idx > -1 {
+			text = text[:idx]
+		}
+		fields := strings.Fields(text)
+		re, err := codeOwnersPattern(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid pattern %q: %s", line, fields[0], err)
+		}
+		co.rules = append(co.rules, codeOwnersRule{
+			pattern: fields[0],
+			re:      re,
+			owners:  fields[1:],
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return co, nil
+}
+
+// codeOwnersPattern translates a CODEOWNERS pattern into a regular
+// expression matching slash-separated paths relative to the root.
+func codeOwnersPattern(pattern string) (*regexp.Regexp, error) {
+	dir := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					// "**/" matches zero or more directories
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if dir {
+		b.WriteString("/")
+	} else if strings.HasSuffix(pattern, "/*") {
+		// Unlike in .gitignore files, dir/* only matches the files
+		// directly in dir.
+		b.WriteString("$")
+	} else {
+		// A pattern naming a directory matches everything in it.
+		b.WriteString("(?:/|$)")
+	}
+	return regexp.Compile(b.String())
+}
+
+// owners returns the owners of the file with the given name. It
+// returns nil if the file has no owners or is outside the repository.
+func (co *codeOwners) owners(name string) []string {
+	rel, err := filepath.Rel(co.root, name)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil
+	}
+	rel = path.Clean(filepath.ToSlash(rel))
+	for i := len(co.rules) - 1; i >= 0; i-- {
+		if co.rules[i].re.MatchString(rel) {
+			return co.rules[i].owners
+		}
+	}
+	return nil
+}
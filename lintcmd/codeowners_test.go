@@ -0,0 +1,88 @@
+package lintcmd
+
+import (
+	"bytes"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"honnef.co/go/tools/lintcmd/runner"
+)
+
+func TestCodeOwners(t *testing.T) {
+	const file = `
+# comment
+*           @everyone
+*.go        @gophers # trailing comment
+/docs/      @writers
+cmd/*       @cmd
+**/testdata @testers
+
+[Section]
+/vendor/    @nobody
+`
+	co, err := parseCodeOwners(strings.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	co.root = filepath.FromSlash("/repo")
+
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"README", []string{"@everyone"}},
+		{"pkg/a.go", []string{"@gophers"}},
+		{"docs/a.go", []string{"@writers"}},
+		{"pkg/docs/a.go", []string{"@gophers"}},
+		{"cmd/main.go", []string{"@cmd"}},
+		{"cmd/sub/main.go", []string{"@gophers"}},
+		{"pkg/testdata/a.go", []string{"@testers"}},
+		{"testdata/x/a.go", []string{"@testers"}},
+		{"vendor/a.go", []string{"@nobody"}},
+	}
+	for _, tt := range tests {
+		got := co.owners(filepath.FromSlash("/repo/" + tt.name))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("owners(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if got := co.owners(filepath.FromSlash("/elsewhere/a.go")); got != nil {
+		t.Errorf("got owners %v for file outside of repository", got)
+	}
+}
+
+func TestOwnersFormatter(t *testing.T) {
+	diag := func(file string, msg string, owners ...string) diagnostic {
+		return diagnostic{
+			Diagnostic: runner.Diagnostic{
+				Position: token.Position{Filename: file, Line: 1, Column: 1},
+				Category: "U1000",
+				Message:  msg,
+			},
+			owners: owners,
+		}
+	}
+	buf := &bytes.Buffer{}
+	ownersFormatter{W: buf}.Format(nil, []diagnostic{
+		diag("/a.go", "func a is unused", "@b", "@a"),
+		diag("/b.go", "func b is unused"),
+		diag("/c.go", "func c is unused", "@b"),
+	})
+	want := `@a (1 problem)
+  /a.go:1:1: func a is unused (U1000)
+
+@b (2 problems)
+  /a.go:1:1: func a is unused (U1000)
+  /c.go:1:1: func c is unused (U1000)
+
+(unowned) (1 problem)
+  /b.go:1:1: func b is unused (U1000)
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
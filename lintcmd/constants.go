@@ -0,0 +1,63 @@
+package lintcmd
+
+import (
+	"go/token"
+
+	"honnef.co/go/tools/unused"
+)
+
+// A constantCopy is an exported package-level constant, as seen by the
+// package declaring it, for finding the constants that several
+// packages declare in whole-program mode.
+type constantCopy struct {
+	key unusedKey
+	obj unused.SerializedObject
+}
+
+// isConstantCopy reports whether obj is an exported package-level
+// constant of the package pkgPath.
+func isConstantCopy(obj unused.SerializedObject, pkgPath string) bool {
+	return obj.Value != "" && (obj.PkgPath == "" || obj.PkgPath == pkgPath) && token.IsExported(obj.Name)
+}
+
+// duplicateConstants maps the unused copies of exported constants that
+// several packages declare with the same name and value, which tends
+// to happen when packages copy constants instead of importing them, to
+// the only copy that is used. Constants of which more than one copy or
+// none are used don't have a canonical copy to point to.
+func duplicateConstants(consts []constantCopy, used map[unusedKey]bool) map[unusedKey]constantCopy {
+	type nameValue struct{ name, value string }
+	groups := map[nameValue][]constantCopy{}
+	// Test variants of packages report the same constants.
+	seen := map[unusedKey]bool{}
+	for _, c := range consts {
+		if seen[c.key] {
+			continue
+		}
+		seen[c.key] = true
+		nv := nameValue{c.obj.Name, c.obj.Value}
+		groups[nv] = append(groups[nv], c)
+	}
+
+	out := map[unusedKey]constantCopy{}
+	for _, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		var canonical []constantCopy
+		for _, c := range group {
+			if used[c.key] {
+				canonical = append(canonical, c)
+			}
+		}
+		if len(canonical) != 1 {
+			continue
+		}
+		for _, c := range group {
+			if c.key != canonical[0].key && c.key.pkgPath != canonical[0].key.pkgPath {
+				out[c.key] = canonical[0]
+			}
+		}
+	}
+	return out
+}
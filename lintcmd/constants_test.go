@@ -0,0 +1,66 @@
+package lintcmd
+
+import (
+	"go/token"
+	"strings"
+	"testing"
+
+	"honnef.co/go/tools/unused"
+)
+
+func TestDuplicateConstants(t *testing.T) {
+	constant := func(pkgPath, name, value string, line int) unusedPair {
+		return unusedPair{
+			key: unusedKey{pkgPath: pkgPath, base: "consts.go", line: line, name: name},
+			obj: unused.SerializedObject{
+				Name:            name,
+				PkgPath:         pkgPath,
+				Kind:            "const",
+				Value:           value,
+				ObjectPath:      name,
+				DisplayPosition: token.Position{Filename: "/" + pkgPath + "/consts.go", Line: line, Column: 7},
+			},
+		}
+	}
+	canonical := constant("example.com/lib", "MaxRetries", "3", 3)
+	copied := constant("example.com/app", "MaxRetries", "3", 3)
+	// a constant with the same name but a different value
+	different := constant("example.com/tool", "MaxRetries", "5", 3)
+	// two copies that are both unused have no canonical copy
+	orphan1 := constant("example.com/app", "Timeout", "10", 5)
+	orphan2 := constant("example.com/tool", "Timeout", "10", 5)
+
+	st := newUnusedState()
+	st.used[canonical.key] = true
+	for _, c := range []unusedPair{copied, different, orphan1, orphan2} {
+		st.used[c.key] = false
+		st.unuseds = append(st.unuseds, c)
+	}
+	for _, c := range []unusedPair{canonical, copied, copied, different, orphan1, orphan2} {
+		st.constants = append(st.constants, constantCopy{c.key, c.obj})
+	}
+
+	want := map[string]string{
+		"example.com/app.MaxRetries":  "const MaxRetries is unused (possible duplicate of example.com/lib.MaxRetries)",
+		"example.com/tool.MaxRetries": "const MaxRetries is unused",
+		"example.com/app.Timeout":     "const Timeout is unused",
+		"example.com/tool.Timeout":    "const Timeout is unused",
+	}
+	diags := st.diagnostics(nil)
+	if len(diags) != len(want) {
+		t.Fatalf("got %d diagnostics, want %d", len(diags), len(want))
+	}
+	for _, diag := range diags {
+		key := diag.Package + "." + strings.Fields(diag.Message)[1]
+		if got := diag.Message; got != want[key] {
+			t.Errorf("%s: got message %q, want %q", key, got, want[key])
+		}
+		isCopy := key == "example.com/app.MaxRetries"
+		if isCopy && (len(diag.Related) != 1 || diag.Related[0].Position != canonical.obj.DisplayPosition) {
+			t.Errorf("got related information %v, want the canonical copy", diag.Related)
+		}
+		if !isCopy && len(diag.Related) != 0 {
+			t.Errorf("%s: got related information %v, want none", key, diag.Related)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package lintcmd
+
+import (
+	"fmt"
+	"strings"
+
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/lintcmd/runner"
+	"honnef.co/go/tools/unused"
+)
+
+// fieldLabel returns the name of a field of the struct type typ, as
+// the type and the field's name, or only the field's name for unnamed
+// struct types.
+func fieldLabel(typ string, field unused.SerializedObject) string {
+	if strings.HasPrefix(typ, "struct{") {
+		return field.Name
+	}
+	return typ + "." + field.Name
+}
+
+// conversionDiagnostics turns the conversions that U1000 recorded for
+// -unused.conversions into informational diagnostics. Their related
+// information points at the fields that only the coupling of fields
+// by conversions keeps alive, and at the coupled fields that are
+// unused regardless.
+func conversionDiagnostics(pkgPath string, convs []unused.SerializedConversion) []diagnostic {
+	var out []diagnostic
+	for _, c := range convs {
+		var related []runner.RelatedInformation
+		keptAlive, unusedPairs := 0, 0
+		for _, f := range c.Fields {
+			from, to := fieldLabel(c.From, f.From), fieldLabel(c.To, f.To)
+			if !f.Used {
+				unusedPairs++
+				related = append(related, runner.RelatedInformation{
+					Position: f.From.DisplayPosition,
+					Message:  fmt.Sprintf("%s and %s are unused, despite the coupling", from, to),
+				})
+				continue
+			}
+			if f.FromKeptAlive {
+				keptAlive++
+				related = append(related, runner.RelatedInformation{
+					Position: f.From.DisplayPosition,
+					Message:  fmt.Sprintf("%s is only used because it is coupled to %s", from, to),
+				})
+			}
+			if f.ToKeptAlive {
+				keptAlive++
+				related = append(related, runner.RelatedInformation{
+					Position: f.To.DisplayPosition,
+					Message:  fmt.Sprintf("%s is only used because it is coupled to %s", to, from),
+				})
+			}
+		}
+
+		pairs := fmt.Sprintf("%d pairs", len(c.Fields))
+		if len(c.Fields) == 1 {
+			pairs = "1 pair"
+		}
+		msg := fmt.Sprintf("conversion from %s to %s couples %s of fields", c.From, c.To, pairs)
+		var details []string
+		if keptAlive == 1 {
+			details = append(details, "1 field is only kept alive by the coupling")
+		} else if keptAlive > 1 {
+			details = append(details, fmt.Sprintf("%d fields are only kept alive by the coupling", keptAlive))
+		}
+		if unusedPairs == 1 {
+			details = append(details, "1 pair is unused")
+		} else if unusedPairs > 1 {
+			details = append(details, fmt.Sprintf("%d pairs are unused", unusedPairs))
+		}
+		if len(details) > 0 {
+			msg += " (" + strings.Join(details, ", ") + ")"
+		}
+
+		out = append(out, diagnostic{
+			Diagnostic: runner.Diagnostic{
+				Position: c.Position,
+				Message:  msg,
+				Category: "conversion",
+				Anchor:   runner.FileAnchor(c.Position.Filename),
+				Package:  pkgPath,
+				Related:  related,
+			},
+			severity:   severityInfo,
+			configured: true,
+			mergeIf:    lint.MergeIfAny,
+		})
+	}
+	return out
+}
@@ -0,0 +1,62 @@
+package lintcmd
+
+import (
+	"go/token"
+	"testing"
+
+	"honnef.co/go/tools/unused"
+)
+
+func TestConversionDiagnostics(t *testing.T) {
+	field := func(name string, line int) unused.SerializedObject {
+		return unused.SerializedObject{Name: name, Kind: "field", DisplayPosition: token.Position{Filename: "/app/a.go", Line: line, Column: 2}}
+	}
+	conv := unused.SerializedConversion{
+		From:     "wire",
+		To:       "example.com/model.Model",
+		Position: token.Position{Filename: "/app/a.go", Line: 20, Column: 7},
+		Fields: []unused.SerializedCoupledFields{
+			{From: field("a", 4), To: field("A", 30), Used: true, FromKeptAlive: true},
+			{From: field("b", 5), To: field("B", 31), Used: true},
+			{From: field("c", 6), To: field("C", 32)},
+		},
+	}
+	anon := unused.SerializedConversion{
+		From:     "struct{x int}",
+		To:       "wire2",
+		Position: token.Position{Filename: "/app/a.go", Line: 21, Column: 7},
+		Fields: []unused.SerializedCoupledFields{
+			{From: field("x", 21), To: field("x", 10), Used: true, FromKeptAlive: true, ToKeptAlive: true},
+		},
+	}
+	diags := conversionDiagnostics("example.com/app", []unused.SerializedConversion{conv, anon})
+	if len(diags) != 2 {
+		t.Fatalf("got %d diagnostics, want 2", len(diags))
+	}
+
+	if got, want := diags[0].Message, "conversion from wire to example.com/model.Model couples 3 pairs of fields (1 field is only kept alive by the coupling, 1 pair is unused)"; got != want {
+		t.Errorf("got message %q, want %q", got, want)
+	}
+	related := []string{
+		"wire.a is only used because it is coupled to example.com/model.Model.A",
+		"wire.c and example.com/model.Model.C are unused, despite the coupling",
+	}
+	if len(diags[0].Related) != len(related) {
+		t.Fatalf("got %d related entries, want %d", len(diags[0].Related), len(related))
+	}
+	for i, r := range diags[0].Related {
+		if r.Message != related[i] {
+			t.Errorf("got related message %q, want %q", r.Message, related[i])
+		}
+	}
+	if diags[0].severity != severityInfo || diags[0].Category != "conversion" {
+		t.Errorf("got severity %s and category %s, want info and conversion", diags[0].severity, diags[0].Category)
+	}
+
+	if got, want := diags[1].Message, "conversion from struct{x int} to wire2 couples 1 pair of fields (2 fields are only kept alive by the coupling)"; got != want {
+		t.Errorf("got message %q, want %q", got, want)
+	}
+	if got, want := diags[1].Related[0].Message, "x is only used because it is coupled to wire2.x"; got != want {
+		t.Errorf("got related message %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,110 @@
+package lintcmd
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+
+	"golang.org/x/tools/cover"
+)
+
+// coverage holds the blocks of a coverage profile, as written by go
+// test -coverprofile, keyed by the files' names in the profile, such
+// as example.com/pkg/file.go.
+type coverage map[string][]cover.ProfileBlock
+
+func readCoverage(name string) (coverage, error) {
+	profiles, err := cover.ParseProfiles(name)
+	if err != nil {
+		return nil, err
+	}
+	cov := coverage{}
+	for _, p := range profiles {
+		cov[p.FileName] = append(cov[p.FileName], p.Blocks...)
+	}
+	return cov, nil
+}
+
+// A lineCol is a position in a file, in the form used by coverage
+// profiles.
+type lineCol struct {
+	line, col int
+}
+
+func (a lineCol) before(b lineCol) bool {
+	return a.line < b.line || (a.line == b.line && a.col < b.col)
+}
+
+// A funcBody is the extent of a function's body.
+type funcBody struct {
+	start, end lineCol
+}
+
+// coverageChecker reports whether functions have been covered by
+// tests, parsing each file at most once to find the bodies of the
+// functions.
+type coverageChecker struct {
+	cov    coverage
+	bodies map[string]map[lineCol]funcBody
+}
+
+func newCoverageChecker(cov coverage) *coverageChecker {
+	return &coverageChecker{cov: cov, bodies: map[string]map[lineCol]funcBody{}}
+}
+
+// funcBodies returns the bodies of the functions declared in the named
+// file, keyed by the positions of the functions' names.
+func (c *coverageChecker) funcBodies(filename string) map[lineCol]funcBody {
+	if bodies, ok := c.bodies[filename]; ok {
+		return bodies
+	}
+	bodies := map[lineCol]funcBody{}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, nil, parser.SkipObjectResolution)
+	if err == nil {
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			name := fset.PositionFor(fn.Name.Pos(), false)
+			start := fset.PositionFor(fn.Body.Lbrace, false)
+			end := fset.PositionFor(fn.Body.End(), false)
+			bodies[lineCol{name.Line, name.Column}] = funcBody{
+				start: lineCol{start.Line, start.Column},
+				end:   lineCol{end.Line, end.Column},
+			}
+		}
+	}
+	c.bodies[filename] = bodies
+	return bodies
+}
+
+// uncovered reports whether the function whose name is at pos, in the
+// package at pkgPath, has statements that the profile knows about, none
+// of which ever ran. Functions in files that aren't part of the
+// profile are never uncovered, as we know nothing about them.
+func (c *coverageChecker) uncovered(pkgPath string, pos token.Position) bool {
+	blocks, ok := c.cov[pkgPath+"/"+filepath.Base(pos.Filename)]
+	if !ok {
+		return false
+	}
+	body, ok := c.funcBodies(pos.Filename)[lineCol{pos.Line, pos.Column}]
+	if !ok {
+		return false
+	}
+	known := false
+	for _, b := range blocks {
+		start := lineCol{b.StartLine, b.StartCol}
+		end := lineCol{b.EndLine, b.EndCol}
+		if b.NumStmt == 0 || start.before(body.start) || body.end.before(end) {
+			continue
+		}
+		if b.Count > 0 {
+			return false
+		}
+		known = true
+	}
+	return known
+}
@@ -0,0 +1,73 @@
+package lintcmd
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCoverage(t *testing.T) {
+	dir := t.TempDir()
+	src := `package pkg
+
+func covered() int {
+	return 1
+}
+
+func uncovered() int {
+	return 2
+}
+
+func unknown() {}
+
+func partially(b bool) int {
+	if b {
+		return 3
+	}
+	return 4
+}
+`
+	file := filepath.Join(dir, "a.go")
+	if err := os.WriteFile(file, []byte(src), 0666); err != nil {
+		t.Fatal(err)
+	}
+	profile := `mode: set
+example.com/pkg/a.go:3.20,5.2 1 1
+example.com/pkg/a.go:7.22,9.2 1 0
+example.com/pkg/a.go:11.16,11.17 0 0
+example.com/pkg/a.go:13.28,14.7 1 1
+example.com/pkg/a.go:14.7,16.3 1 0
+example.com/pkg/a.go:17.2,17.10 1 1
+example.com/other/b.go:1.1,2.2 1 0
+`
+	profilePath := filepath.Join(dir, "cover.out")
+	if err := os.WriteFile(profilePath, []byte(profile), 0666); err != nil {
+		t.Fatal(err)
+	}
+	cov, err := readCoverage(profilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checker := newCoverageChecker(cov)
+
+	tests := []struct {
+		pkgPath   string
+		line, col int
+		want      bool
+	}{
+		{"example.com/pkg", 3, 6, false},
+		{"example.com/pkg", 7, 6, true},
+		// no statements, so nothing to cover
+		{"example.com/pkg", 11, 6, false},
+		{"example.com/pkg", 13, 6, false},
+		// the file isn't part of the profile
+		{"example.com/elsewhere", 7, 6, false},
+	}
+	for _, tt := range tests {
+		pos := token.Position{Filename: file, Line: tt.line, Column: tt.col}
+		if got := checker.uncovered(tt.pkgPath, pos); got != tt.want {
+			t.Errorf("uncovered(%s, %d:%d) = %t, want %t", tt.pkgPath, tt.line, tt.col, got, tt.want)
+		}
+	}
+}
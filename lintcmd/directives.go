@@ -1,8 +1,14 @@
 package lintcmd
 
 import (
+	"fmt"
+	"go/token"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"unicode/utf8"
 
+	"honnef.co/go/tools/config"
 	"honnef.co/go/tools/lintcmd/runner"
 )
 
@@ -27,6 +33,20 @@ func parseDirectives(dirs []runner.SerializedDirective) ([]ignore, []diagnostic)
 				diagnostics = append(diagnostics, p)
 				continue
 			}
+		case "export-to":
+			if len(args) < 1 {
+				p := diagnostic{
+					Diagnostic: runner.Diagnostic{
+						Position: dir.NodePosition,
+						Message:  "malformed linter directive; missing the required consumer field?",
+						Category: "compile",
+					},
+					severity: severityError,
+				}
+				diagnostics = append(diagnostics, p)
+			}
+			// export-to directives are handled by unused
+			continue
 		default:
 			// unknown directive, ignore
 			continue
@@ -41,11 +61,15 @@ func parseDirectives(dirs []runner.SerializedDirective) ([]ignore, []diagnostic)
 				Line:   pos.Line,
 				Checks: checks,
 				Pos:    dir.DirectivePosition,
+				Alt:    []token.Position{dir.RawNodePosition, dir.AdjustedNodePosition},
+				dir:    dir,
 			}
 		case "file-ignore":
 			ig = &fileIgnore{
 				File:   pos.Filename,
 				Checks: checks,
+				Alt:    []string{dir.RawNodePosition.Filename, dir.AdjustedNodePosition.Filename},
+				dir:    dir,
 			}
 		}
 		ignores = append(ignores, ig)
@@ -53,3 +77,68 @@ func parseDirectives(dirs []runner.SerializedDirective) ([]ignore, []diagnostic)
 
 	return ignores, diagnostics
 }
+
+// directiveReason returns the reason of an ignore directive, which
+// follows the list of checks.
+func directiveReason(dir runner.SerializedDirective) string {
+	if len(dir.Arguments) < 2 {
+		return ""
+	}
+	return strings.TrimSpace(strings.Join(dir.Arguments[1:], " "))
+}
+
+// checkReasons flags the ignore directives for U1000 whose reasons
+// are shorter than unused.ignore_reason_min_length or don't contain a
+// match of unused.ignore_reason_pattern.
+func checkReasons(ignores []ignore, cfg config.Config) []diagnostic {
+	if cfg.Unused.IgnoreReasonMinLength == 0 && cfg.Unused.IgnoreReasonPattern == "" {
+		return nil
+	}
+
+	var out []diagnostic
+	for _, ig := range ignores {
+		dir := ig.directive()
+		suppressesU1000 := false
+		for _, c := range strings.Split(dir.Arguments[0], ",") {
+			if m, _ := filepath.Match(c, "U1000"); m {
+				suppressesU1000 = true
+				break
+			}
+		}
+		if !suppressesU1000 {
+			continue
+		}
+
+		msg := reasonProblem(directiveReason(dir), cfg.Unused)
+		if msg == "" {
+			continue
+		}
+		diag := diagnostic{
+			Diagnostic: runner.Diagnostic{
+				Position: dir.DirectivePosition,
+				Message:  msg,
+				Category: "staticcheck",
+			},
+		}
+		configureSeverity(&diag, cfg, config.SeverityIgnoreReason)
+		out = append(out, diag)
+	}
+	return out
+}
+
+// reasonProblem describes why reason, the reason of an ignore
+// directive for U1000, is shorter than unused.ignore_reason_min_length
+// or doesn't contain a match of unused.ignore_reason_pattern. It
+// returns the empty string if the reason is fine.
+func reasonProblem(reason string, cfg config.Unused) string {
+	if n := utf8.RuneCountInString(reason); n < cfg.IgnoreReasonMinLength {
+		return fmt.Sprintf("the reason of this linter directive has %d characters, but at least %d are required", n, cfg.IgnoreReasonMinLength)
+	}
+	if cfg.IgnoreReasonPattern != "" {
+		// The configuration has been validated when it was loaded.
+		if !regexp.MustCompile(cfg.IgnoreReasonPattern).MatchString(reason) {
+			return fmt.Sprintf("the reason of this linter directive doesn't match the required pattern %q", cfg.IgnoreReasonPattern)
+		}
+	}
+	return ""
+}
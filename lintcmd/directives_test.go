@@ -0,0 +1,55 @@
+package lintcmd
+
+import (
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/lintcmd/runner"
+)
+
+func TestCheckReasons(t *testing.T) {
+	dir := func(line int, text string) runner.SerializedDirective {
+		fields := strings.Split(text, " ")
+		pos := token.Position{Filename: "file.go", Line: line, Column: 1}
+		return runner.SerializedDirective{
+			Command:           fields[0],
+			Arguments:         fields[1:],
+			DirectivePosition: pos,
+			NodePosition:      token.Position{Filename: "file.go", Line: line + 1, Column: 1},
+		}
+	}
+	ignores, diags := parseDirectives([]runner.SerializedDirective{
+		dir(1, "ignore U1000 used by reflection, see JIRA-123"),
+		dir(3, "ignore U1000 used by reflection"),
+		dir(5, "ignore U1000,SA4006 wip"),
+		dir(7, "file-ignore U* generated, see JIRA-7"),
+		// other checks don't need justifications
+		dir(9, "ignore SA4006 wip"),
+	})
+	if len(diags) != 0 {
+		t.Fatalf("unexpected diagnostics %v", diags)
+	}
+
+	cfg := config.Config{Unused: config.Unused{
+		IgnoreReasonMinLength: 10,
+		IgnoreReasonPattern:   `[A-Z]+-[0-9]+`,
+	}}
+	var got []string
+	for _, diag := range checkReasons(ignores, cfg) {
+		got = append(got, relativePositionString(diag.Position)+": "+diag.Message)
+	}
+	want := []string{
+		`file.go:3:1: the reason of this linter directive doesn't match the required pattern "[A-Z]+-[0-9]+"`,
+		"file.go:5:1: the reason of this linter directive has 3 characters, but at least 10 are required",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if diags := checkReasons(ignores, config.Config{}); len(diags) != 0 {
+		t.Errorf("got %d diagnostics without requirements, want none", len(diags))
+	}
+}
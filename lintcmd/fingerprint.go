@@ -0,0 +1,43 @@
+package lintcmd
+
+import (
+	"sort"
+
+	"honnef.co/go/tools/lintcmd/runner"
+)
+
+// setFingerprints computes the fingerprints of the diagnostics, which
+// have to be deduplicated already. Diagnostics without anchors, such
+// as compile errors and stale ignore directives, are anchored to
+// their files.
+func setFingerprints(diags []diagnostic) {
+	idx := make([]int, len(diags))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		pi, pj := diags[idx[i]].Position, diags[idx[j]].Position
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		if pi.Line != pj.Line {
+			return pi.Line < pj.Line
+		}
+		return pi.Column < pj.Column
+	})
+
+	type key struct {
+		category, anchor, message string
+	}
+	ordinals := map[key]int{}
+	for _, i := range idx {
+		d := &diags[i]
+		anchor := d.Anchor
+		if anchor == "" {
+			anchor = runner.FileAnchor(d.Position.Filename)
+		}
+		k := key{d.Category, anchor, d.Message}
+		d.fingerprint = runner.Fingerprint(d.Category, anchor, d.Message, ordinals[k])
+		ordinals[k]++
+	}
+}
@@ -0,0 +1,54 @@
+package lintcmd
+
+import (
+	"go/token"
+	"testing"
+
+	"honnef.co/go/tools/lintcmd/runner"
+)
+
+func TestFingerprints(t *testing.T) {
+	diag := func(line int, anchor, message string) diagnostic {
+		return diagnostic{Diagnostic: runner.Diagnostic{
+			Position: token.Position{Filename: "/app/a.go", Line: line, Column: 2},
+			Category: "SA4006",
+			Message:  message,
+			Anchor:   anchor,
+		}}
+	}
+
+	before := []diagnostic{
+		diag(10, "example.com/app fn", "value is never used"),
+		diag(12, "example.com/app fn", "value is never used"),
+		diag(20, "example.com/app other", "value is never used"),
+		diag(1, "", "could not import"),
+	}
+	// The same problems, after inserting lines at the top of the file
+	// and reordering the diagnostics.
+	after := []diagnostic{
+		diag(5, "", "could not import"),
+		diag(30, "example.com/app other", "value is never used"),
+		diag(22, "example.com/app fn", "value is never used"),
+		diag(24, "example.com/app fn", "value is never used"),
+	}
+	setFingerprints(before)
+	setFingerprints(after)
+
+	seen := map[string]bool{}
+	for _, d := range before {
+		if d.fingerprint == "" {
+			t.Fatalf("%d: missing fingerprint", d.Position.Line)
+		}
+		if seen[d.fingerprint] {
+			t.Errorf("%d: duplicate fingerprint %s", d.Position.Line, d.fingerprint)
+		}
+		seen[d.fingerprint] = true
+	}
+	pairs := [][2]int{{0, 2}, {1, 3}, {2, 1}, {3, 0}}
+	for _, p := range pairs {
+		b, a := before[p[0]], after[p[1]]
+		if b.fingerprint != a.fingerprint {
+			t.Errorf("fingerprint of the problem at line %d changed after moving it to line %d", b.Position.Line, a.Position.Line)
+		}
+	}
+}
@@ -7,7 +7,10 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"text/tabwriter"
+	"time"
 
 	"honnef.co/go/tools/analysis/lint"
 )
@@ -47,11 +50,23 @@ type formatter interface {
 
 type textFormatter struct {
 	W io.Writer
+	// whether to print the fingerprints of problems
+	fingerprints bool
 }
 
 func (o textFormatter) Format(_ []*lint.Analyzer, ps []diagnostic) {
 	for _, p := range ps {
-		fmt.Fprintf(o.W, "%s: %s\n", relativePositionString(p.Position), p.String())
+		var extra string
+		if len(p.owners) > 0 {
+			extra += fmt.Sprintf(" [owners: %s]", strings.Join(p.owners, ", "))
+		}
+		if !p.firstSeen.IsZero() {
+			extra += fmt.Sprintf(" [first seen: %s]", p.firstSeen.Format("2006-01-02"))
+		}
+		if o.fingerprints {
+			extra += fmt.Sprintf(" [fingerprint: %s]", p.fingerprint)
+		}
+		fmt.Fprintf(o.W, "%s: %s%s\n", relativePositionString(p.Position), p.String(), extra)
 		for _, r := range p.Related {
 			fmt.Fprintf(o.W, "\t%s: %s\n", relativePositionString(r.Position), r.Message)
 		}
@@ -77,6 +92,11 @@ func (o jsonFormatter) Format(_ []*lint.Analyzer, ps []diagnostic) {
 		End      location `json:"end"`
 		Message  string   `json:"message"`
 	}
+	type metrics struct {
+		Complexity int `json:"complexity"`
+		Lines      int `json:"lines"`
+		Params     int `json:"params"`
+	}
 
 	enc := json.NewEncoder(o.W)
 	for _, p := range ps {
@@ -87,9 +107,16 @@ func (o jsonFormatter) Format(_ []*lint.Analyzer, ps []diagnostic) {
 			End      location  `json:"end"`
 			Message  string    `json:"message"`
 			Related  []related `json:"related,omitempty"`
+			Owners   []string  `json:"owners,omitempty"`
+			// size and complexity of unused functions
+			Metrics *metrics `json:"metrics,omitempty"`
+			// RFC 3339 time at which the problem was first seen
+			FirstSeen   string `json:"first_seen,omitempty"`
+			Fingerprint string `json:"fingerprint"`
 		}{
-			Code:     p.Category,
-			Severity: p.severity.String(),
+			Code:        p.Category,
+			Fingerprint: p.fingerprint,
+			Severity:    p.severity.String(),
 			Location: location{
 				File:   p.Position.Filename,
 				Line:   p.Position.Line,
@@ -101,6 +128,13 @@ func (o jsonFormatter) Format(_ []*lint.Analyzer, ps []diagnostic) {
 				Column: p.End.Column,
 			},
 			Message: p.Message,
+			Owners:  p.owners,
+		}
+		if m := p.metrics; m != nil {
+			jp.Metrics = &metrics{Complexity: m.Complexity, Lines: m.Lines, Params: m.Params}
+		}
+		if !p.firstSeen.IsZero() {
+			jp.FirstSeen = p.firstSeen.UTC().Format(time.RFC3339)
 		}
 		for _, r := range p.Related {
 			jp.Related = append(jp.Related, related{
@@ -123,6 +157,8 @@ func (o jsonFormatter) Format(_ []*lint.Analyzer, ps []diagnostic) {
 
 type stylishFormatter struct {
 	W io.Writer
+	// whether to print the fingerprints of problems
+	fingerprints bool
 
 	prevFile string
 	tw       *tabwriter.Writer
@@ -144,7 +180,11 @@ func (o *stylishFormatter) Format(_ []*lint.Analyzer, ps []diagnostic) {
 			o.prevFile = pos.Filename
 			o.tw = tabwriter.NewWriter(o.W, 0, 4, 2, ' ', 0)
 		}
-		fmt.Fprintf(o.tw, "  (%d, %d)\t%s\t%s\n", pos.Line, pos.Column, p.Category, p.Message)
+		if o.fingerprints {
+			fmt.Fprintf(o.tw, "  (%d, %d)\t%s\t%s\t%s\n", pos.Line, pos.Column, p.Category, p.Message, p.fingerprint)
+		} else {
+			fmt.Fprintf(o.tw, "  (%d, %d)\t%s\t%s\n", pos.Line, pos.Column, p.Category, p.Message)
+		}
 		for _, r := range p.Related {
 			fmt.Fprintf(o.tw, "    (%d, %d)\t\t  %s\n", r.Position.Line, r.Position.Column, r.Message)
 		}
@@ -159,3 +199,54 @@ func (o *stylishFormatter) Stats(total, errors, warnings, ignored int) {
 	fmt.Fprintf(o.W, " ✖ %d problems (%d errors, %d warnings, %d ignored)\n",
 		total, errors, warnings, ignored)
 }
+
+// ownersFormatter groups problems by the owners of the files they
+// were found in. Problems in files with multiple owners are listed
+// once for each owner.
+type ownersFormatter struct {
+	W io.Writer
+	// whether to print the fingerprints of problems
+	fingerprints bool
+}
+
+func (o ownersFormatter) Format(_ []*lint.Analyzer, ps []diagnostic) {
+	const unowned = "(unowned)"
+	byOwner := map[string][]diagnostic{}
+	for _, p := range ps {
+		if len(p.owners) == 0 {
+			byOwner[unowned] = append(byOwner[unowned], p)
+		}
+		for _, owner := range p.owners {
+			byOwner[owner] = append(byOwner[owner], p)
+		}
+	}
+	owners := make([]string, 0, len(byOwner))
+	for owner := range byOwner {
+		if owner != unowned {
+			owners = append(owners, owner)
+		}
+	}
+	sort.Strings(owners)
+	if _, ok := byOwner[unowned]; ok {
+		owners = append(owners, unowned)
+	}
+
+	for i, owner := range owners {
+		if i > 0 {
+			fmt.Fprintln(o.W)
+		}
+		ds := byOwner[owner]
+		noun := "problems"
+		if len(ds) == 1 {
+			noun = "problem"
+		}
+		fmt.Fprintf(o.W, "%s (%d %s)\n", owner, len(ds), noun)
+		for _, p := range ds {
+			if o.fingerprints {
+				fmt.Fprintf(o.W, "  %s: %s [fingerprint: %s]\n", relativePositionString(p.Position), p.String(), p.fingerprint)
+			} else {
+				fmt.Fprintf(o.W, "  %s: %s\n", relativePositionString(p.Position), p.String())
+			}
+		}
+	}
+}
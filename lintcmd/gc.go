@@ -0,0 +1,98 @@
+package lintcmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"honnef.co/go/tools/unused/refgraph"
+)
+
+// A graphSize is the size of the unused code analysis's graph of a
+// package.
+type graphSize struct {
+	pkg   string
+	nodes uint64
+	edges uint64
+}
+
+// baselineHeap approximates the live heap of a run besides the graphs
+// of the unused code analysis: the type-checked packages, their export
+// data and the results of other analyzers.
+const baselineHeap = 256 << 20
+
+// maxGOGC is the largest GOGC we recommend. Beyond it, memory usage
+// grows much faster than the time spent collecting garbage shrinks.
+const maxGOGC = 800
+
+// gcAdvice describes how to tune the garbage collector for the graphs
+// of a run.
+type gcAdvice struct {
+	largest graphSize
+	// the estimated memory of the largest graph
+	largestMemory uint64
+	// the estimated memory of as many of the largest graphs as can be
+	// built concurrently
+	peakMemory uint64
+	// the recommended ballast, in bytes
+	ballast uint64
+	// the recommended GOGC when not using a ballast
+	gogc int
+}
+
+// computeGCAdvice recommends a memory ballast and a GOGC value for
+// running the unused code analysis with the given number of workers,
+// based on the sizes of the graphs of an earlier run.
+//
+// The graphs of the packages being analyzed make up most of the
+// garbage of a run. With the default GOGC of 100, the collector runs
+// every time the heap has doubled, which for huge graphs means
+// collecting dozens of times while building a single graph. A ballast
+// as large as the graphs that are built at the same time lets the heap
+// grow by that much between collections. Alternatively, raising GOGC
+// by the ratio of the peak to the baseline heap has the same effect.
+func computeGCAdvice(sizes []graphSize, workers int) gcAdvice {
+	if len(sizes) == 0 || workers < 1 {
+		return gcAdvice{gogc: 100}
+	}
+	mems := make([]uint64, len(sizes))
+	var adv gcAdvice
+	for i, size := range sizes {
+		mems[i] = refgraph.EstimateMemory(size.nodes, size.edges)
+		if mems[i] > adv.largestMemory {
+			adv.largest = size
+			adv.largestMemory = mems[i]
+		}
+	}
+	sort.Slice(mems, func(i, j int) bool { return mems[i] > mems[j] })
+	if len(mems) > workers {
+		mems = mems[:workers]
+	}
+	for _, mem := range mems {
+		adv.peakMemory += mem
+	}
+
+	// round the ballast up to whole MiB
+	adv.ballast = (adv.peakMemory + 1<<20 - 1) &^ (1<<20 - 1)
+	gogc := 100 * (baselineHeap + adv.peakMemory) / baselineHeap
+	if gogc > maxGOGC {
+		gogc = maxGOGC
+	}
+	// round GOGC down to multiples of 10
+	adv.gogc = int(gogc) / 10 * 10
+	return adv
+}
+
+func (adv gcAdvice) write(w io.Writer) {
+	if adv.largest.pkg == "" {
+		fmt.Fprintln(w, "GC advice: no package was analyzed for unused code")
+		return
+	}
+	fmt.Fprintf(w, "GC advice: the largest graph, of %s, has %d nodes and %d edges, taking up about %d MiB; concurrently built graphs take up about %d MiB\n",
+		adv.largest.pkg, adv.largest.nodes, adv.largest.edges, adv.largestMemory>>20, adv.peakMemory>>20)
+	if adv.gogc <= 100 {
+		fmt.Fprintln(w, "GC advice: the graphs are small enough for the default settings")
+		return
+	}
+	fmt.Fprintf(w, "GC advice: use -debug.ballast %d, or set GOGC=%d\n", adv.ballast>>20, adv.gogc)
+}
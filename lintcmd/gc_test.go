@@ -0,0 +1,59 @@
+package lintcmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"honnef.co/go/tools/unused/refgraph"
+)
+
+func TestGCAdvice(t *testing.T) {
+	if adv := computeGCAdvice(nil, 4); adv.gogc != 100 || adv.ballast != 0 {
+		t.Errorf("got GOGC=%d and a ballast of %d bytes without any graphs, want the defaults", adv.gogc, adv.ballast)
+	}
+
+	sizes := []graphSize{
+		{"example.com/small", 1_000, 2_000},
+		{"example.com/huge", 4_000_000, 12_000_000},
+		{"example.com/large", 1_000_000, 3_000_000},
+		{"example.com/medium", 100_000, 300_000},
+	}
+	huge := refgraph.EstimateMemory(4_000_000, 12_000_000)
+	large := refgraph.EstimateMemory(1_000_000, 3_000_000)
+
+	adv := computeGCAdvice(sizes, 2)
+	if adv.largest.pkg != "example.com/huge" || adv.largestMemory != huge {
+		t.Errorf("got %s with %d bytes as the largest graph, want example.com/huge with %d", adv.largest.pkg, adv.largestMemory, huge)
+	}
+	// Only the two largest graphs are built at the same time.
+	if adv.peakMemory != huge+large {
+		t.Errorf("got a peak of %d bytes, want %d", adv.peakMemory, huge+large)
+	}
+	if adv.ballast < adv.peakMemory || adv.ballast%(1<<20) != 0 || adv.ballast-adv.peakMemory >= 1<<20 {
+		t.Errorf("got a ballast of %d bytes for a peak of %d, want the peak rounded up to MiB", adv.ballast, adv.peakMemory)
+	}
+	if adv.gogc <= 100 || adv.gogc > maxGOGC || adv.gogc%10 != 0 {
+		t.Errorf("got GOGC=%d", adv.gogc)
+	}
+
+	// A single worker builds one graph at a time.
+	if adv := computeGCAdvice(sizes, 1); adv.peakMemory != huge {
+		t.Errorf("got a peak of %d bytes with one worker, want %d", adv.peakMemory, huge)
+	}
+	// Tiny graphs don't warrant any tuning.
+	if adv := computeGCAdvice(sizes[:1], 8); adv.gogc != 100 {
+		t.Errorf("got GOGC=%d for a tiny graph, want 100", adv.gogc)
+	}
+
+	var buf bytes.Buffer
+	computeGCAdvice(sizes[:1], 8).write(&buf)
+	if out := buf.String(); !strings.Contains(out, "default settings") {
+		t.Errorf("unexpected advice for a tiny graph:\n%s", out)
+	}
+	buf.Reset()
+	adv.write(&buf)
+	if out := buf.String(); !strings.Contains(out, "example.com/huge, has 4000000 nodes and 12000000 edges") || !strings.Contains(out, "-debug.ballast") {
+		t.Errorf("unexpected advice:\n%s", out)
+	}
+}
@@ -0,0 +1,120 @@
+package lintcmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ignoreFile holds suppressions that work like //lint:ignore
+// directives, for code bases that don't allow linter directives in
+// their source. Each line that isn't empty or a comment has the form
+//
+//	path fingerprint expiry reason
+//
+// path is the file containing the problem, relative to the directory
+// of the ignore file. fingerprint is the problem's fingerprint, as
+// printed with -fingerprints. expiry is the last day the suppression
+// applies on, in the form 2006-01-02, or "never". Once a suppression
+// has expired, its problem gets reported again. reason is free-form
+// and required.
+type ignoreFile struct {
+	// root is the directory that paths are relative to
+	root    string
+	entries []ignoreEntry
+}
+
+type ignoreEntry struct {
+	path        string
+	fingerprint string
+	// the day after the last day the entry applies on, or the zero
+	// time if it never expires
+	expiry time.Time
+	reason string
+}
+
+// loadIgnoreFile parses the ignore file at name.
+func loadIgnoreFile(name string) (*ignoreFile, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return nil, err
+	}
+	ig, err := parseIgnoreFile(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", name, err)
+	}
+	ig.root = filepath.Dir(abs)
+	return ig, nil
+}
+
+func parseIgnoreFile(r io.Reader) (*ignoreFile, error) {
+	ig := &ignoreFile{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || text[0] == '#' {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) < 4 {
+			return nil, fmt.Errorf("line %d: expected path, fingerprint, expiry and reason", line)
+		}
+		entry := ignoreEntry{
+			path:        filepath.FromSlash(fields[0]),
+			fingerprint: fields[1],
+			reason:      strings.Join(fields[3:], " "),
+		}
+		if fields[2] != "never" {
+			day, err := time.ParseInLocation("2006-01-02", fields[2], time.Local)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid expiry %q, expected a date like 2006-01-02 or never", line, fields[2])
+			}
+			entry.expiry = day.AddDate(0, 0, 1)
+		}
+		ig.entries = append(ig.entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return ig, nil
+}
+
+// apply marks the diagnostics that unexpired entries match as
+// ignored. The diagnostics need to have their fingerprints set.
+func (ig *ignoreFile) apply(diags []diagnostic, now time.Time) {
+	type key struct {
+		path, fingerprint string
+	}
+	active := map[key]bool{}
+	for _, entry := range ig.entries {
+		if !entry.expiry.IsZero() && !now.Before(entry.expiry) {
+			continue
+		}
+		active[key{filepath.Join(ig.root, entry.path), entry.fingerprint}] = true
+	}
+	for i := range diags {
+		diag := &diags[i]
+		name := diag.Position.Filename
+		if name == "" {
+			continue
+		}
+		if abs, err := filepath.Abs(name); err == nil {
+			name = abs
+		}
+		if active[key{name, diag.fingerprint}] {
+			diag.severity = severityIgnored
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package lintcmd
+
+import (
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"honnef.co/go/tools/lintcmd/runner"
+)
+
+func TestIgnoreFile(t *testing.T) {
+	const file = `
+# comment
+pkg/a.go   aaaa 2021-03-01 waiting for the upstream fix
+pkg/a.go   bbbb 2021-01-31 expired
+pkg/b.go   cccc never      generated by a tool we don't control
+`
+	ig, err := parseIgnoreFile(strings.NewReader(file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ig.root = filepath.FromSlash("/repo")
+
+	diag := func(name, fingerprint string) diagnostic {
+		return diagnostic{
+			Diagnostic:  runner.Diagnostic{Position: token.Position{Filename: filepath.FromSlash(name)}},
+			fingerprint: fingerprint,
+			severity:    severityError,
+		}
+	}
+	diags := []diagnostic{
+		diag("/repo/pkg/a.go", "aaaa"),
+		diag("/repo/pkg/a.go", "bbbb"),
+		diag("/repo/pkg/b.go", "cccc"),
+		diag("/repo/pkg/b.go", "aaaa"),
+		diag("/repo/pkg/c.go", "dddd"),
+	}
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.Local)
+	ig.apply(diags, now)
+
+	want := []bool{true, false, true, false, false}
+	for i, diag := range diags {
+		if got := diag.severity == severityIgnored; got != want[i] {
+			t.Errorf("%s %s: got ignored = %t, want %t", diag.Position.Filename, diag.fingerprint, got, want[i])
+		}
+	}
+}
+
+func TestIgnoreFileErrors(t *testing.T) {
+	tests := []string{
+		"pkg/a.go aaaa 2021-03-01",
+		"pkg/a.go aaaa tomorrow some reason",
+	}
+	for _, test := range tests {
+		if _, err := parseIgnoreFile(strings.NewReader(test)); err == nil {
+			t.Errorf("%q: expected an error", test)
+		}
+	}
+}
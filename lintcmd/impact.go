@@ -0,0 +1,61 @@
+package lintcmd
+
+import (
+	"fmt"
+	"go/token"
+
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/lintcmd/runner"
+	"honnef.co/go/tools/unused"
+)
+
+// An impactReference is a reference to one of the objects of the
+// impact report, as found by U1000.
+type impactReference struct {
+	ref unused.SerializedReference
+	// the key of the object making the reference
+	from unusedKey
+}
+
+// impactDiagnostics turns references into informational diagnostics.
+// References made by objects that are unused are marked as such, as
+// removing the referenced objects only requires removing dead code.
+func impactDiagnostics(refs []impactReference, used map[unusedKey]bool) []diagnostic {
+	var out []diagnostic
+	// Test variants of packages report the same references.
+	seen := map[token.Position]bool{}
+	for _, r := range refs {
+		if seen[r.ref.Position] {
+			continue
+		}
+		seen[r.ref.Position] = true
+
+		msg := fmt.Sprintf("reference to %s.%s", r.ref.Target.PkgPath, r.ref.Target.Name)
+		if r.ref.From.Name != "" {
+			msg += fmt.Sprintf(" in %s %s", r.ref.From.Kind, r.ref.From.Name)
+			if ok, known := used[r.from]; known && !ok {
+				msg += ", which is unused"
+			}
+		}
+		anchor := runner.FileAnchor(r.ref.Position.Filename)
+		var symbol string
+		if from := r.ref.From; from.Name != "" {
+			anchor = runner.ObjectAnchor(from.PkgPath, from.ObjectPath, from.Name)
+			symbol = unusedSymbol(from.PkgPath, from)
+		}
+		out = append(out, diagnostic{
+			Diagnostic: runner.Diagnostic{
+				Position: r.ref.Position,
+				Message:  msg,
+				Category: "impact",
+				Anchor:   anchor,
+				Package:  r.from.pkgPath,
+				Symbol:   symbol,
+			},
+			severity:   severityInfo,
+			configured: true,
+			mergeIf:    lint.MergeIfAny,
+		})
+	}
+	return out
+}
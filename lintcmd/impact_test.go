@@ -0,0 +1,54 @@
+package lintcmd
+
+import (
+	"go/token"
+	"testing"
+
+	"honnef.co/go/tools/unused"
+)
+
+func TestImpact(t *testing.T) {
+	target := unused.SerializedObject{Name: "Old", PkgPath: "example.com/lib"}
+	caller := unused.SerializedObject{Name: "caller", Kind: "func"}
+	live := unusedKey{pkgPath: "example.com/app", base: "a.go", line: 3, name: "caller"}
+	dead := unusedKey{pkgPath: "example.com/app", base: "a.go", line: 7, name: "dead"}
+	ref := func(line int, from unused.SerializedObject, key unusedKey) impactReference {
+		return impactReference{
+			ref: unused.SerializedReference{
+				Target:   target,
+				From:     from,
+				Position: token.Position{Filename: "/app/a.go", Line: line, Column: 2},
+			},
+			from: key,
+		}
+	}
+	deadCaller := caller
+	deadCaller.Name = "dead"
+
+	refs := []impactReference{
+		ref(4, caller, live),
+		ref(8, deadCaller, dead),
+		// the same reference, as seen by the package's test variant
+		ref(4, caller, live),
+		ref(12, unused.SerializedObject{}, unusedKey{}),
+	}
+	used := map[unusedKey]bool{live: true, dead: false}
+	diags := impactDiagnostics(refs, used)
+
+	want := []string{
+		"reference to example.com/lib.Old in func caller",
+		"reference to example.com/lib.Old in func dead, which is unused",
+		"reference to example.com/lib.Old",
+	}
+	if len(diags) != len(want) {
+		t.Fatalf("got %d diagnostics, want %d", len(diags), len(want))
+	}
+	for i, diag := range diags {
+		if diag.Message != want[i] {
+			t.Errorf("got message %q, want %q", diag.Message, want[i])
+		}
+		if diag.severity != severityInfo {
+			t.Errorf("%s: got severity %s, want info", diag.Message, diag.severity)
+		}
+	}
+}
@@ -0,0 +1,376 @@
+package lintcmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/lintcmd/apply"
+	"honnef.co/go/tools/lintcmd/cache"
+	"honnef.co/go/tools/lintcmd/runner"
+)
+
+// decisionFileName is the name of the file in the cache directory that
+// records the problems that were skipped in interactive mode.
+const decisionFileName = "decisions.json"
+
+// reviewContext is the number of lines shown around the code of a
+// problem in interactive mode, and reviewMaxLines the maximum number
+// of lines of the code itself.
+const (
+	reviewContext  = 2
+	reviewMaxLines = 20
+)
+
+// decisionStore records the problems that were skipped in interactive
+// mode, keyed by their fingerprints, so that later sessions don't ask
+// about them again. Applied fixes and ignore directives change the
+// code, so they don't need to be recorded.
+type decisionStore struct {
+	path string
+	// Skipped maps fingerprints to when the problems were skipped.
+	Skipped map[string]int64 `json:"skipped"`
+}
+
+// loadDecisionStore loads the decision store in the cache directory.
+// A missing store is empty.
+func loadDecisionStore() (*decisionStore, error) {
+	dir := cache.DefaultDir()
+	if dir == "" || dir == "off" {
+		return nil, errors.New("interactive mode requires a cache directory")
+	}
+	return readDecisionStore(filepath.Join(dir, decisionFileName))
+}
+
+func readDecisionStore(path string) (*decisionStore, error) {
+	s := &decisionStore{path: path, Skipped: map[string]int64{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("couldn't parse %s: %s", path, err)
+	}
+	if s.Skipped == nil {
+		s.Skipped = map[string]int64{}
+	}
+	return s, nil
+}
+
+// save atomically writes the store back to disk.
+func (s *decisionStore) save() error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(s.path, data)
+}
+
+// A reviewer steps through problems, asking what to do about each of
+// them.
+type reviewer struct {
+	in  *bufio.Reader
+	out io.Writer
+	// readFile returns the contents of the named file.
+	readFile  func(name string) ([]byte, error)
+	decisions *decisionStore
+	// checks are the names of the checks whose problems can be
+	// ignored with linter directives.
+	checks map[string]bool
+	// reasonRules returns the options of the unused check that apply
+	// to the named file, whose ignore_reason_min_length and
+	// ignore_reason_pattern the reasons of ignore directives for U1000
+	// have to satisfy. If it is nil, any reason will do.
+	reasonRules func(name string) config.Unused
+	now         time.Time
+}
+
+// A review is the outcome of reviewing problems.
+type review struct {
+	// chosen are the problems whose fixes should be applied. The
+	// fixes of ignored problems insert ignore directives.
+	chosen []runner.Diagnostic
+	// previouslySkipped is the number of problems that earlier
+	// sessions skipped, which weren't asked about again.
+	previouslySkipped int
+}
+
+// review asks about each problem that isn't ignored, in the order of
+// their positions, whether to apply its fix, skip it, or ignore it
+// with a linter directive. Only problems that have fixes or that can be
+// ignored are asked about.
+func (r *reviewer) review(diags []diagnostic) (review, error) {
+	var out review
+	var candidates []diagnostic
+	for _, diag := range diags {
+		if diag.severity == severityIgnored || diag.Position.Filename == "" {
+			continue
+		}
+		if len(diag.SuggestedFixes) == 0 && !r.checks[diag.Category] {
+			continue
+		}
+		if _, ok := r.decisions.Skipped[diag.fingerprint]; ok {
+			out.previouslySkipped++
+			continue
+		}
+		candidates = append(candidates, diag)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		pi, pj := candidates[i].Position, candidates[j].Position
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		if pi.Line != pj.Line {
+			return pi.Line < pj.Line
+		}
+		return pi.Column < pj.Column
+	})
+
+	for i, diag := range candidates {
+		src, err := r.readFile(diag.Position.Filename)
+		if err != nil {
+			return out, err
+		}
+		fmt.Fprintf(r.out, "\n[%d/%d] %s: %s (%s)\n", i+1, len(candidates), relativePositionString(diag.Position), diag.Message, diag.Category)
+		r.show(src, diag)
+
+		var choices []string
+		if len(diag.SuggestedFixes) > 0 {
+			fmt.Fprintf(r.out, "fix: %s\n", diag.SuggestedFixes[0].Message)
+			choices = append(choices, "[a]pply")
+		}
+		choices = append(choices, "[s]kip")
+		if r.checks[diag.Category] {
+			choices = append(choices, "[i]gnore")
+		}
+		choices = append(choices, "[q]uit")
+
+		answer, err := r.ask(strings.Join(choices, ", ")+"? ", func(s string) bool {
+			switch s {
+			case "a":
+				return len(diag.SuggestedFixes) > 0
+			case "i":
+				return r.checks[diag.Category]
+			case "s", "q":
+				return true
+			}
+			return false
+		})
+		if err != nil {
+			return out, err
+		}
+		switch answer {
+		case "a":
+			out.chosen = append(out.chosen, diag.Diagnostic)
+		case "s":
+			r.decisions.Skipped[diag.fingerprint] = r.now.Unix()
+		case "i":
+			reason, err := r.ask("reason: ", func(s string) bool {
+				if s == "" {
+					return false
+				}
+				if diag.Category == "U1000" && r.reasonRules != nil {
+					if msg := reasonProblem(s, r.reasonRules(diag.Position.Filename)); msg != "" {
+						fmt.Fprintln(r.out, msg)
+						return false
+					}
+				}
+				return true
+			})
+			if err != nil {
+				return out, err
+			}
+			if reason == "" {
+				// The input ended without a valid reason.
+				return out, nil
+			}
+			ignored := diag.Diagnostic
+			ignored.SuggestedFixes = []runner.SuggestedFix{ignoreFix(src, diag, reason)}
+			out.chosen = append(out.chosen, ignored)
+		case "q", "":
+			// The problems that are left remain reported.
+			return out, nil
+		}
+	}
+	return out, nil
+}
+
+// ask prompts for an answer until valid accepts it. It returns the
+// empty string at the end of the input.
+func (r *reviewer) ask(prompt string, valid func(string) bool) (string, error) {
+	for {
+		fmt.Fprint(r.out, prompt)
+		line, err := r.in.ReadString('\n')
+		answer := strings.TrimSpace(line)
+		if err == io.EOF {
+			if answer == "" || !valid(answer) {
+				fmt.Fprintln(r.out)
+				return "", nil
+			}
+			return answer, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if valid(answer) {
+			return answer, nil
+		}
+	}
+}
+
+// show prints the code of a problem with line numbers, marking the
+// line of the problem. The code is what the edits of the problem's fix
+// that contain its line replace, such as the declaration that the fix
+// deletes, or else only the problem's line.
+func (r *reviewer) show(src []byte, diag diagnostic) {
+	lines := bytes.Split(src, []byte("\n"))
+	line := diag.Position.Line
+	first, last := line, line
+	if len(diag.SuggestedFixes) > 0 {
+		for _, e := range diag.SuggestedFixes[0].TextEdits {
+			end := e.End.Line
+			if !e.End.IsValid() {
+				end = e.Position.Line
+			}
+			if e.Position.Filename != diag.Position.Filename || e.Position.Line > line || end < line {
+				continue
+			}
+			if e.Position.Line < first {
+				first = e.Position.Line
+			}
+			if end > last {
+				last = end
+			}
+		}
+	}
+	truncated := false
+	if last-first+1 > reviewMaxLines {
+		last = first + reviewMaxLines - 1
+		truncated = true
+	}
+	first -= reviewContext
+	if first < 1 {
+		first = 1
+	}
+	if !truncated {
+		last += reviewContext
+	}
+	if last > len(lines) {
+		last = len(lines)
+	}
+	for l := first; l <= last; l++ {
+		marker := " "
+		if l == diag.Position.Line {
+			marker = ">"
+		}
+		fmt.Fprintf(r.out, "%s %5d | %s\n", marker, l, lines[l-1])
+	}
+	if truncated {
+		fmt.Fprintln(r.out, "        | ...")
+	}
+}
+
+// ignoreFix returns a fix that inserts a //lint:ignore directive for
+// the problem above its line, with the line's indentation.
+func ignoreFix(src []byte, diag diagnostic, reason string) runner.SuggestedFix {
+	offset := 0
+	for l := 1; l < diag.Position.Line && offset < len(src); l++ {
+		i := bytes.IndexByte(src[offset:], '\n')
+		if i == -1 {
+			offset = len(src)
+			break
+		}
+		offset += i + 1
+	}
+	indent := 0
+	for offset+indent < len(src) && (src[offset+indent] == ' ' || src[offset+indent] == '\t') {
+		indent++
+	}
+	text := fmt.Sprintf("%s//lint:ignore %s %s\n", src[offset:offset+indent], diag.Category, reason)
+	pos := diag.Position
+	pos.Offset, pos.Column = offset, 1
+	return runner.SuggestedFix{
+		Message:   "Ignore the problem",
+		TextEdits: []runner.TextEdit{{Position: pos, NewText: []byte(text)}},
+	}
+}
+
+// interactive lets the user review the problems for the -interactive
+// flag, applies the chosen fixes and ignore directives, and returns
+// the problems that remain.
+func (cmd *Command) interactive(diagnostics []diagnostic, checks map[string]bool) ([]diagnostic, error) {
+	decisions, err := loadDecisionStore()
+	if err != nil {
+		return nil, err
+	}
+	configs := map[string]config.Unused{}
+	r := &reviewer{
+		in:        bufio.NewReader(os.Stdin),
+		out:       os.Stderr,
+		readFile:  os.ReadFile,
+		decisions: decisions,
+		checks:    checks,
+		reasonRules: func(name string) config.Unused {
+			dir := filepath.Dir(name)
+			cfg, ok := configs[dir]
+			if !ok {
+				// Invalid configurations have already failed the
+				// analysis of their packages.
+				c, _ := config.Load(dir)
+				cfg = c.Unused
+				configs[dir] = cfg
+			}
+			return cfg
+		},
+		now: time.Now(),
+	}
+	rev, err := r.review(diagnostics)
+	if err != nil {
+		return nil, err
+	}
+	if err := decisions.save(); err != nil {
+		return nil, fmt.Errorf("couldn't save the decisions: %s", err)
+	}
+	if rev.previouslySkipped > 0 {
+		fmt.Fprintf(os.Stderr, "didn't ask about %d problems that were skipped before; delete %s to review them again\n", rev.previouslySkipped, decisions.path)
+	}
+
+	res, err := apply.Fixes(rev.chosen, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := res.Write(); err != nil {
+		return nil, err
+	}
+	for _, rej := range res.Rejected {
+		fmt.Fprintf(os.Stderr, "warning: didn't apply the fix %q of the problem at %s: %s\n", rej.Fix.Message, rej.Diagnostic.Position, rej.Reason)
+	}
+	if len(res.Applied) > 0 {
+		fmt.Fprintf(os.Stderr, "resolved %d problems in %d files\n", len(res.Applied), len(res.Files))
+	}
+
+	resolved := map[diagnosticDescriptor]bool{}
+	for _, diag := range res.Applied {
+		resolved[diagnostic{Diagnostic: diag}.descriptor()] = true
+	}
+	out := diagnostics[:0]
+	for _, diag := range diagnostics {
+		if diag.severity == severityIgnored || !resolved[diag.descriptor()] {
+			out = append(out, diag)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,163 @@
+package lintcmd
+
+import (
+	"bufio"
+	"bytes"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/lintcmd/apply"
+	"honnef.co/go/tools/lintcmd/runner"
+)
+
+func TestReview(t *testing.T) {
+	const src = `package pkg
+
+func f() {}
+
+func g() {
+	x := 1
+}
+
+func h() {}
+`
+	pos := func(line int) token.Position {
+		offset := 0
+		for l := 1; l < line; l++ {
+			offset += strings.IndexByte(src[offset:], '\n') + 1
+		}
+		return token.Position{Filename: "a.go", Line: line, Column: 1, Offset: offset}
+	}
+	// deletion returns a fix that deletes the lines from..to.
+	deletion := func(from, to int) []runner.SuggestedFix {
+		return []runner.SuggestedFix{{
+			Message:   "Remove it",
+			TextEdits: []runner.TextEdit{{Position: pos(from), End: pos(to + 1)}},
+		}}
+	}
+	diag := func(line int, msg, check, fp string, fixes []runner.SuggestedFix) diagnostic {
+		return diagnostic{
+			Diagnostic:  runner.Diagnostic{Position: pos(line), Message: msg, Category: check, SuggestedFixes: fixes},
+			fingerprint: fp,
+		}
+	}
+	diags := []diagnostic{
+		diag(9, "func h is unused", "U1000", "h", deletion(9, 10)),
+		diag(3, "func f is unused", "U1000", "f", deletion(3, 4)),
+		diag(6, "x declared and not used", "compile", "x", nil),
+		diag(5, "func g is unused", "U1000", "g", deletion(5, 8)),
+		diag(5, "skipped before", "SA4006", "old", nil),
+	}
+
+	decisions, err := readDecisionStore(filepath.Join(t.TempDir(), decisionFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decisions.Skipped["old"] = 1
+	var out bytes.Buffer
+	r := &reviewer{
+		// apply f, give an invalid answer and then ignore g, and skip h
+		in:        bufio.NewReader(strings.NewReader("a\nx\ni\nkept for the next release\ns\n")),
+		out:       &out,
+		readFile:  func(string) ([]byte, error) { return []byte(src), nil },
+		decisions: decisions,
+		checks:    map[string]bool{"U1000": true, "SA4006": true},
+		now:       time.Unix(100, 0),
+	}
+	rev, err := r.review(diags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rev.previouslySkipped != 1 {
+		t.Errorf("got %d previously skipped problems, want 1", rev.previouslySkipped)
+	}
+	if got := decisions.Skipped["h"]; got != 100 {
+		t.Errorf("h was skipped at %d, want 100", got)
+	}
+	for _, want := range []string{
+		"[1/3] a.go:3:1: func f is unused (U1000)",
+		">     5 | func g() {",
+		"      7 | }",
+		"fix: Remove it",
+		"[a]pply, [s]kip, [i]gnore, [q]uit? ",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output doesn't contain %q:\n%s", want, out.String())
+		}
+	}
+
+	res, err := apply.Fixes(rev.chosen, func(string) ([]byte, error) { return []byte(src), nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "package pkg\n\n//lint:ignore U1000 kept for the next release\nfunc g() {\n\tx := 1\n}\n\nfunc h() {}\n"
+	if got := string(res.Files["a.go"]); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	// Quitting, also by reaching the end of the input, leaves the
+	// remaining problems alone.
+	r.in = bufio.NewReader(strings.NewReader("q\n"))
+	rev, err = r.review(diags)
+	if err != nil || len(rev.chosen) != 0 {
+		t.Errorf("got %v and %d chosen problems after quitting, want none", err, len(rev.chosen))
+	}
+}
+
+func TestReviewReasonRules(t *testing.T) {
+	const src = "package pkg\n\nfunc f() {}\n"
+	decisions, err := readDecisionStore(filepath.Join(t.TempDir(), decisionFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	r := &reviewer{
+		// give a reason that is too short, then one without a ticket,
+		// and finally one that satisfies the rules
+		in:        bufio.NewReader(strings.NewReader("i\nwip\nkept for the next release\nkept for JIRA-123\n")),
+		out:       &out,
+		readFile:  func(string) ([]byte, error) { return []byte(src), nil },
+		decisions: decisions,
+		checks:    map[string]bool{"U1000": true},
+		reasonRules: func(string) config.Unused {
+			return config.Unused{IgnoreReasonMinLength: 10, IgnoreReasonPattern: `[A-Z]+-[0-9]+`}
+		},
+		now: time.Unix(100, 0),
+	}
+	diags := []diagnostic{{
+		Diagnostic: runner.Diagnostic{
+			Position: token.Position{Filename: "a.go", Line: 3, Column: 1, Offset: len("package pkg\n\n")},
+			Message:  "func f is unused",
+			Category: "U1000",
+		},
+		fingerprint: "f",
+	}}
+	rev, err := r.review(diags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"the reason of this linter directive has 3 characters, but at least 10 are required",
+		`the reason of this linter directive doesn't match the required pattern "[A-Z]+-[0-9]+"`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output doesn't contain %q:\n%s", want, out.String())
+		}
+	}
+	if got := strings.Count(out.String(), "reason: "); got != 3 {
+		t.Errorf("asked for a reason %d times, want 3", got)
+	}
+
+	res, err := apply.Fixes(rev.chosen, func(string) ([]byte, error) { return []byte(src), nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "package pkg\n\n//lint:ignore U1000 kept for JIRA-123\nfunc f() {}\n"
+	if got := string(res.Files["a.go"]); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,99 @@
+package lintcmd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"honnef.co/go/tools/analysis/lint"
+)
+
+// The JUnit XML format has no formal specification. We emit the
+// subset that is understood by Jenkins, TeamCity, GitLab and most
+// other CI systems: a single test suite with one test case per
+// package, where every problem found in a package is a failure of its
+// test case. Packages are identified by the directories of the files
+// that problems were found in.
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string         `xml:"name,attr"`
+	ClassName string         `xml:"classname,attr"`
+	Failures  []junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitFormatter struct {
+	W    io.Writer
+	name string
+	// whether to include the fingerprints of problems
+	fingerprints bool
+}
+
+func (o junitFormatter) Format(_ []*lint.Analyzer, ps []diagnostic) {
+	cases := map[string]*junitTestCase{}
+	for _, p := range ps {
+		dir := shortPath(filepath.Dir(p.Position.Filename))
+		if p.Position.Filename == "" {
+			dir = "-"
+		}
+		tc, ok := cases[dir]
+		if !ok {
+			tc = &junitTestCase{Name: filepath.ToSlash(dir), ClassName: o.name}
+			cases[dir] = tc
+		}
+		text := fmt.Sprintf("%s: %s", relativePositionString(p.Position), p.String())
+		for _, r := range p.Related {
+			text += fmt.Sprintf("\n\t%s: %s", relativePositionString(r.Position), r.Message)
+		}
+		if o.fingerprints {
+			text += fmt.Sprintf("\nfingerprint: %s", p.fingerprint)
+		}
+		tc.Failures = append(tc.Failures, junitFailure{
+			Message: p.Message,
+			Type:    p.Category,
+			Text:    text,
+		})
+	}
+
+	suite := junitTestSuite{Name: o.name}
+	for _, tc := range cases {
+		suite.Cases = append(suite.Cases, *tc)
+		suite.Failures += len(tc.Failures)
+	}
+	sort.Slice(suite.Cases, func(i, j int) bool {
+		return suite.Cases[i].Name < suite.Cases[j].Name
+	})
+	suite.Tests = len(suite.Cases)
+
+	out := junitTestSuites{
+		Suites:   []junitTestSuite{suite},
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+	}
+	fmt.Fprint(o.W, xml.Header)
+	enc := xml.NewEncoder(o.W)
+	enc.Indent("", "\t")
+	_ = enc.Encode(out)
+	fmt.Fprintln(o.W)
+}
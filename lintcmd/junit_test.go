@@ -0,0 +1,50 @@
+package lintcmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"go/token"
+	"testing"
+
+	"honnef.co/go/tools/lintcmd/runner"
+)
+
+func TestJUnitFormatter(t *testing.T) {
+	diag := func(file string, line int, category, msg string) diagnostic {
+		return diagnostic{
+			Diagnostic: runner.Diagnostic{
+				Position: token.Position{Filename: file, Line: line, Column: 1},
+				Category: category,
+				Message:  msg,
+			},
+		}
+	}
+	buf := &bytes.Buffer{}
+	f := junitFormatter{W: buf, name: "staticcheck"}
+	f.Format(nil, []diagnostic{
+		diag("/pkg/b/b.go", 3, "U1000", "func b is unused"),
+		diag("/pkg/a/a.go", 1, "U1000", "func a is unused"),
+		diag("/pkg/a/a2.go", 5, "SA4006", "this value of x is never used"),
+	})
+
+	var out junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("couldn't parse output: %s\n%s", err, buf)
+	}
+	if out.Tests != 2 || out.Failures != 3 {
+		t.Errorf("got %d tests and %d failures, want 2 and 3", out.Tests, out.Failures)
+	}
+	if len(out.Suites) != 1 {
+		t.Fatalf("got %d test suites, want 1", len(out.Suites))
+	}
+	cases := out.Suites[0].Cases
+	if len(cases) != 2 || cases[0].Name != "/pkg/a" || cases[1].Name != "/pkg/b" {
+		t.Fatalf("unexpected test cases %v", cases)
+	}
+	if n := len(cases[0].Failures); n != 2 {
+		t.Errorf("got %d failures for /pkg/a, want 2", n)
+	}
+	if fail := cases[1].Failures[0]; fail.Type != "U1000" || fail.Message != "func b is unused" {
+		t.Errorf("unexpected failure %v", fail)
+	}
+}
@@ -1,6 +1,7 @@
 package lintcmd
 
 import (
+	"context"
 	"crypto/sha256"
 	"fmt"
 	"go/build"
@@ -16,6 +17,7 @@ import (
 	"unicode"
 
 	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
 	"honnef.co/go/tools/config"
 	"honnef.co/go/tools/go/buildid"
 	"honnef.co/go/tools/go/loader"
@@ -85,6 +87,13 @@ type lintResult struct {
 	checkedFiles []string
 	diagnostics  []diagnostic
 	warnings     []string
+	suppressions []suppression
+	// declaration trees of the checked packages, if requested
+	declarations []unused.SerializedDeclaration
+	// internal failures of the unused code analysis
+	analysisErrors []analysisError
+	// sizes of the graphs of the unused code analysis
+	graphs []graphSize
 }
 
 type options struct {
@@ -93,10 +102,16 @@ type options struct {
 	patterns                 []string
 	lintTests                bool
 	goVersion                string
+	changed                  []string
+	coverage                 coverage
+	shard                    string
+	snapshot                 string
+	binaries                 []string
+	progress                 bool
 	printAnalyzerMeasurement func(analysis *analysis.Analyzer, pkg *loader.PackageSpec, d time.Duration)
 }
 
-func (l *linter) run(bconf buildConfig) (lintResult, error) {
+func (l *linter) run(ctx context.Context, bconf buildConfig) (lintResult, error) {
 	cfg := &packages.Config{}
 	if l.opts.lintTests {
 		cfg.Tests = true
@@ -111,7 +126,13 @@ func (l *linter) run(bconf buildConfig) (lintResult, error) {
 	}
 	r.FallbackGoVersion = defaultGoVersion()
 	r.GoVersion = l.opts.goVersion
+	r.Changed = l.opts.changed
 	r.Stats.PrintAnalyzerMeasurement = l.opts.printAnalyzerMeasurement
+	if l.opts.progress {
+		p := newProgressPrinter(os.Stderr)
+		r.Stats.OnProgress = p.update
+		defer p.done()
+	}
 
 	printStats := func() {
 		// Individual stats are read atomically, but overall there
@@ -147,26 +168,26 @@ func (l *linter) run(bconf buildConfig) (lintResult, error) {
 			}
 		}()
 	}
-	res, err := l.lint(r, cfg, l.opts.patterns)
+	res, err := l.lint(ctx, r, cfg, l.opts.patterns)
 	for i := range res.diagnostics {
 		res.diagnostics[i].buildName = bconf.Name
 	}
 	return res, err
 }
 
-func (l *linter) lint(r *runner.Runner, cfg *packages.Config, patterns []string) (lintResult, error) {
+func (l *linter) lint(ctx context.Context, r *runner.Runner, cfg *packages.Config, patterns []string) (lintResult, error) {
 	var out lintResult
 
 	as := make([]*analysis.Analyzer, 0, len(l.analyzers))
 	for _, a := range l.analyzers {
 		as = append(as, a.Analyzer)
 	}
-	results, err := r.Run(cfg, as, patterns)
+	results, err := r.RunContext(ctx, cfg, as, patterns)
 	if err != nil {
 		return out, err
 	}
 
-	if len(results) == 0 {
+	if len(results) == 0 && l.opts.changed == nil {
 		// TODO(dh): emulate Go's behavior more closely once we have
 		// access to go list's Match field.
 		for _, pattern := range patterns {
@@ -178,17 +199,30 @@ func (l *linter) lint(r *runner.Runner, cfg *packages.Config, patterns []string)
 	for name := range l.analyzers {
 		analyzerNames = append(analyzerNames, name)
 	}
-	used := map[unusedKey]bool{}
-	var unuseds []unusedPair
+	st := newUnusedState()
+	if len(l.opts.binaries) > 0 || l.opts.shard != "" {
+		st.binaries = newBinaryUses(l.opts.binaries)
+	}
 	for _, res := range results {
+		if err := ctx.Err(); err != nil {
+			return out, err
+		}
 		if len(res.Errors) > 0 && !res.Failed {
 			panic("package has errors but isn't marked as failed")
 		}
 		if res.Failed {
 			out.diagnostics = append(out.diagnostics, failed(res)...)
+			if res.Initial {
+				// We don't know which packages the package imports
+				// for real, nor whether it is used.
+				st.addPackage(res.Package, true, false, res.Config)
+			}
 		} else {
 			if res.Skipped {
 				out.warnings = append(out.warnings, fmt.Sprintf("skipped package %s because it is too large", res.Package))
+				if res.Initial {
+					st.addPackage(res.Package, true, false, res.Config)
+				}
 				continue
 			}
 
@@ -203,10 +237,26 @@ func (l *linter) lint(r *runner.Runner, cfg *packages.Config, patterns []string)
 				return out, err
 			}
 			ps := success(allowedAnalyzers, resd)
-			filtered, err := filterIgnored(ps, resd, allowedAnalyzers)
+			filtered, sups, err := filterIgnored(ps, resd, allowedAnalyzers, res.Config)
 			if err != nil {
 				return out, err
 			}
+			out.suppressions = append(out.suppressions, sups...)
+			out.suppressions = append(out.suppressions, unusedSuppressions(resd)...)
+			out.declarations = append(out.declarations, resd.Unused.Declarations...)
+			if stats := resd.Unused.Stats; stats.Nodes > 0 {
+				out.graphs = append(out.graphs, graphSize{res.Package.PkgPath, stats.Nodes, stats.Edges})
+			}
+			for _, berr := range resd.BuildErrors {
+				out.warnings = append(out.warnings, fmt.Sprintf("checks skipped package %s because its IR couldn't be built: %s: %s", res.Package, berr.Position, berr.Message))
+			}
+			for _, aerr := range resd.Unused.Errors {
+				out.warnings = append(out.warnings, fmt.Sprintf("unused code analysis of package %s failed, considering all of its objects used: %s", res.Package, aerr))
+				out.analysisErrors = append(out.analysisErrors, analysisError{res.Package.PkgPath, aerr})
+			}
+			for i := range filtered {
+				configureSeverity(&filtered[i], res.Config, filtered[i].Category)
+			}
 			// OPT move this code into the 'success' function.
 			for i, diag := range filtered {
 				a := l.analyzers[diag.Category]
@@ -217,15 +267,42 @@ func (l *linter) lint(r *runner.Runner, cfg *packages.Config, patterns []string)
 			}
 			out.diagnostics = append(out.diagnostics, filtered...)
 
-			for _, obj := range resd.Unused.Used {
+			for _, sym := range resd.Unused.Linknames {
+				st.linked[sym] = true
+			}
+			st.addPackage(res.Package, resd.Unused.Root, allowedAnalyzers["U1000"] && res.Config.Unused.Rules[config.RuleUnusedPackages], res.Config)
+
+			wholeProgram := res.Config.Unused.WholeProgram
+			keyOf := func(obj unused.SerializedObject) unusedKey {
 				// FIXME(dh): pick the object whose filename does not include $GOROOT
-				key := unusedKey{
-					pkgPath: res.Package.PkgPath,
+				pkgPath := res.Package.PkgPath
+				if wholeProgram && obj.PkgPath != "" {
+					// In whole-program mode, packages report the
+					// objects of other packages that they use.
+					pkgPath = obj.PkgPath
+				}
+				return unusedKey{
+					pkgPath: pkgPath,
 					base:    filepath.Base(obj.Position.Filename),
 					line:    obj.Position.Line,
 					name:    obj.Name,
 				}
-				used[key] = true
+			}
+			for _, obj := range resd.Unused.Used {
+				st.used[keyOf(obj)] = true
+				if st.binaries != nil {
+					st.binaries.use(res.Package, keyOf(obj))
+				}
+				if wholeProgram && isConstantCopy(obj, res.Package.PkgPath) {
+					st.constants = append(st.constants, constantCopy{keyOf(obj), obj})
+				}
+			}
+			for _, dep := range resd.Unused.Dependencies {
+				from := keyOf(dep.From)
+				st.dependencies[from] = append(st.dependencies[from], keyOf(dep.To))
+			}
+			for _, ref := range resd.Unused.References {
+				st.refs = append(st.refs, impactReference{ref, keyOf(ref.From)})
 			}
 
 			if allowedAnalyzers["U1000"] {
@@ -236,41 +313,298 @@ func (l *linter) lint(r *runner.Runner, cfg *packages.Config, patterns []string)
 						line:    obj.Position.Line,
 						name:    obj.Name,
 					}
-					unuseds = append(unuseds, unusedPair{key, obj})
-					if _, ok := used[key]; !ok {
-						used[key] = false
+					reportGenerated := res.Config.Unused.Generated == config.GeneratedReport
+					st.unuseds = append(st.unuseds, unusedPair{key, obj, reportGenerated, res.Config})
+					if wholeProgram && isConstantCopy(obj, res.Package.PkgPath) {
+						st.constants = append(st.constants, constantCopy{key, obj})
+					}
+					if _, ok := st.used[key]; !ok {
+						st.used[key] = false
 					}
 				}
+				for _, r := range resd.Unused.Routes {
+					st.routes = append(st.routes, handlerRoute{r, keyOf(r.Handler), res.Config})
+				}
+				out.diagnostics = append(out.diagnostics, conversionDiagnostics(res.Package.PkgPath, resd.Unused.Conversions)...)
+				if l.opts.snapshot != "" || l.opts.shard != "" {
+					for _, obj := range resd.Unused.Used {
+						if obj.Reason == "" || obj.ObjectPath == "" {
+							continue
+						}
+						st.addUsed(keyOf(obj), obj, obj.PkgPath == res.Package.PkgPath)
+					}
+				}
+				if l.opts.coverage != nil {
+					for _, obj := range resd.Unused.Used {
+						if obj.Kind != "func" || obj.InGenerated || strings.HasSuffix(obj.Position.Filename, "_test.go") {
+							continue
+						}
+						if obj.PkgPath != "" && obj.PkgPath != res.Package.PkgPath {
+							continue
+						}
+						st.uncovered = append(st.uncovered, unusedPair{key: keyOf(obj), obj: obj, cfg: res.Config})
+					}
+				}
+			}
+		}
+	}
+
+	if l.opts.shard != "" {
+		// The other shards may use the objects of this one, so only
+		// merging all shards tells which objects are unused.
+		return out, writeShard(l.opts.shard, st)
+	}
+	out.diagnostics = append(out.diagnostics, st.diagnostics(l.opts.coverage)...)
+	if l.opts.snapshot != "" {
+		return out, writeSnapshot(l.opts.snapshot, st)
+	}
+	return out, nil
+}
+
+// unusedState is what the driver collects from the results of
+// packages for reporting unused objects, which in whole-program mode
+// depends on all packages. It can be saved to shards and merged.
+type unusedState struct {
+	used map[unusedKey]bool
+	// symbols that other packages link to via go:linkname
+	linked map[string]bool
+	// in whole-program mode, the objects used by objects that are
+	// unused within their own packages
+	dependencies map[unusedKey][]unusedKey
+	unuseds      []unusedPair
+	// functions that are candidates for being flagged as uncovered,
+	// if they turn out to be used
+	uncovered []unusedPair
+	// references to the objects of the impact report, and the keys
+	// of the objects making them
+	refs []impactReference
+	// registrations of handlers of HTTP routes
+	routes []handlerRoute
+	// used objects and why they are used, if a snapshot was requested
+	useds map[unusedKey]usedObject
+	// the import graph of the checked packages
+	packages []importedPackage
+	// which packages use which objects, if -unused.binaries is set
+	binaries *binaryUses
+	// in whole-program mode, the exported constants of the packages,
+	// used and unused ones
+	constants []constantCopy
+}
+
+func newUnusedState() *unusedState {
+	return &unusedState{
+		used:         map[unusedKey]bool{},
+		linked:       map[string]bool{},
+		dependencies: map[unusedKey][]unusedKey{},
+	}
+}
+
+// diagnostics reports the unused objects, as well as the impact,
+// route and coverage findings that depend on which objects are used.
+func (st *unusedState) diagnostics(cov coverage) []diagnostic {
+	var out []diagnostic
+	used := st.propagate()
+
+	// Renaming objects that unused code of other packages refers to
+	// would break that code.
+	foreignRefs := foreignReferences(st.dependencies)
+	duplicates := duplicateConstants(st.constants, used)
+
+	for _, uo := range st.reported() {
+		canonical, duplicate := duplicates[uo.key]
+		if duplicate {
+			uo.obj.DuplicateOf = canonical.key.pkgPath + "." + canonical.obj.Name
+		}
+		diag := diagnostic{
+			Diagnostic: runner.Diagnostic{
+				Position:       uo.obj.DisplayPosition,
+				Category:       "U1000",
+				SuggestedFixes: unusedFixes(uo.obj, !foreignRefs[uo.key]),
+				Anchor:         runner.ObjectAnchor(uo.key.pkgPath, uo.obj.ObjectPath, uo.obj.Name),
+				Package:        uo.key.pkgPath,
+				Symbol:         unusedSymbol(uo.key.pkgPath, uo.obj),
+			},
+			mergeIf: lint.MergeIfAll,
+			metrics: uo.obj.Metrics,
+		}
+		var categories []string
+		if uo.obj.Category != "" {
+			categories = append(categories, "U1000."+string(uo.obj.Category))
+		}
+		categories = append(categories, "U1000."+strings.ReplaceAll(uo.obj.Kind, " ", "_"), "U1000")
+		diag.Message = unusedMessage(uo.obj, uo.cfg, categories)
+		if duplicate {
+			diag.Related = append(diag.Related, runner.RelatedInformation{
+				Position: canonical.obj.DisplayPosition,
+				Message:  fmt.Sprintf("%s is declared here", uo.obj.DuplicateOf),
+			})
+		}
+		if uo.obj.FixError != "" {
+			diag.Related = append(diag.Related, runner.RelatedInformation{
+				Position: uo.obj.DisplayPosition,
+				Message:  uo.obj.FixErrorMessage(),
+			})
+		}
+		configureSeverity(&diag, uo.cfg, categories...)
+		out = append(out, diag)
+	}
+
+	out = append(out, impactDiagnostics(st.refs, used)...)
+	out = append(out, routeDiagnostics(st.routes, used)...)
+	out = append(out, st.binaryDiagnostics()...)
+	out = append(out, packageDiagnostics(st.packages)...)
+
+	if len(st.uncovered) > 0 && cov != nil {
+		checker := newCoverageChecker(cov)
+		// Test variants of packages report the same functions.
+		seen := map[unusedKey]bool{}
+		for _, uo := range st.uncovered {
+			if seen[uo.key] || !used[uo.key] {
+				continue
+			}
+			seen[uo.key] = true
+			if !checker.uncovered(uo.key.pkgPath, uo.obj.Position) {
+				continue
+			}
+			diag := diagnostic{
+				Diagnostic: runner.Diagnostic{
+					Position: uo.obj.DisplayPosition,
+					Message:  fmt.Sprintf("%s %s is used but never covered by tests", uo.obj.Kind, uo.obj.Name),
+					Category: "U1000",
+					Anchor:   runner.ObjectAnchor(uo.key.pkgPath, "", uo.obj.Name),
+					Package:  uo.key.pkgPath,
+					Symbol:   unusedSymbol(uo.key.pkgPath, uo.obj),
+				},
+				mergeIf: lint.MergeIfAll,
+			}
+			configureSeverity(&diag, uo.cfg, "U1000.uncovered", "U1000")
+			out = append(out, diag)
+		}
+	}
+
+	return out
+}
+
+// unusedMessage returns the message of an unused object, using the
+// template that unused.message_templates configures for the most
+// specific of categories, if any. Templates that fail to execute
+// leave the default message in place, noting the failure.
+func unusedMessage(obj unused.SerializedObject, cfg config.Config, categories []string) string {
+	for _, cat := range categories {
+		text, ok := cfg.Unused.MessageTemplates[cat]
+		if !ok {
+			continue
+		}
+		msg, err := obj.FormatMessage(text)
+		if err != nil {
+			return fmt.Sprintf("%s (message template for %s failed: %s)", obj.Message(), cat, err)
+		}
+		return msg
+	}
+	return obj.Message()
+}
+
+// propagate marks the objects used that objects of other packages
+// use, as well as the objects they use in turn, and returns st.used.
+// Objects that are unused within their packages may be used by other
+// packages.
+func (st *unusedState) propagate() map[unusedKey]bool {
+	used := st.used
+	var queue []unusedKey
+	for key, ok := range used {
+		if ok {
+			queue = append(queue, key)
+		}
+	}
+	for len(queue) > 0 {
+		key := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		for _, dep := range st.dependencies[key] {
+			if !used[dep] {
+				used[dep] = true
+				queue = append(queue, dep)
 			}
 		}
 	}
+	return used
+}
+
+// reported returns the unused objects that get reported. It has to
+// run after propagate.
+func (st *unusedState) reported() []unusedPair {
+	// Functions that are only used by their own tests are merely
+	// unused in the package itself. Report them once, as tested.
+	tested := map[unusedKey]bool{}
+	for _, uo := range st.unuseds {
+		if uo.obj.Category == unused.CategoryTested {
+			tested[uo.key] = true
+		}
+	}
 
-	for _, uo := range unuseds {
+	var out []unusedPair
+	for _, uo := range st.unuseds {
+		if tested[uo.key] && uo.obj.Category != unused.CategoryTested {
+			continue
+		}
 		if uo.obj.Kind == "type param" {
 			// We don't currently flag unused type parameters on used objects, and flagging them on unused objects isn't
 			// useful.
 			continue
 		}
-		if used[uo.key] {
+		if st.used[uo.key] {
 			continue
 		}
-		if uo.obj.InGenerated {
+		if st.linked[uo.key.pkgPath+"."+uo.obj.Name] {
 			continue
 		}
-		out.diagnostics = append(out.diagnostics, diagnostic{
-			Diagnostic: runner.Diagnostic{
-				Position: uo.obj.DisplayPosition,
-				Message:  fmt.Sprintf("%s %s is unused", uo.obj.Kind, uo.obj.Name),
-				Category: "U1000",
-			},
-			mergeIf: lint.MergeIfAll,
-		})
+		if uo.obj.InGenerated && !uo.reportGenerated {
+			continue
+		}
+		out = append(out, uo)
 	}
+	return out
+}
 
-	return out, nil
+// foreignReferences returns the objects that objects of other
+// packages use, according to the dependencies of whole-program mode.
+func foreignReferences(dependencies map[unusedKey][]unusedKey) map[unusedKey]bool {
+	out := map[unusedKey]bool{}
+	for from, deps := range dependencies {
+		for _, dep := range deps {
+			if dep.pkgPath != from.pkgPath {
+				out[dep] = true
+			}
+		}
+	}
+	return out
+}
+
+// unusedFixes converts the serialized fixes of an unused object,
+// dropping the ones that rename it unless renames is set.
+func unusedFixes(obj unused.SerializedObject, renames bool) []runner.SuggestedFix {
+	var out []runner.SuggestedFix
+	for _, fix := range obj.Fixes {
+		if fix.Rename && !renames {
+			continue
+		}
+		rfix := runner.SuggestedFix{Message: fix.Message}
+		for _, e := range fix.Edits {
+			rfix.TextEdits = append(rfix.TextEdits, runner.TextEdit{
+				Position: e.Position,
+				End:      e.End,
+				NewText:  e.NewText,
+			})
+		}
+		out = append(out, rfix)
+	}
+	return out
 }
 
-func filterIgnored(diagnostics []diagnostic, res runner.ResultData, allowedAnalyzers map[string]bool) ([]diagnostic, error) {
+// filterIgnored marks the diagnostics that ignore directives match as
+// ignored and flags directives that didn't match anything. It also
+// returns the directives that matched diagnostics, along with these
+// diagnostics.
+func filterIgnored(diagnostics []diagnostic, res runner.ResultData, allowedAnalyzers map[string]bool, cfg config.Config) ([]diagnostic, []suppression, error) {
 	couldHaveMatched := func(ig *lineIgnore) bool {
 		for _, c := range ig.Checks {
 			if c == "U1000" {
@@ -298,14 +632,23 @@ func filterIgnored(diagnostics []diagnostic, res runner.ResultData, allowedAnaly
 	}
 
 	ignores, moreDiagnostics := parseDirectives(res.Directives)
+	if allowedAnalyzers["U1000"] {
+		moreDiagnostics = append(moreDiagnostics, checkReasons(ignores, cfg)...)
+	}
 
+	var sups []suppression
 	for _, ig := range ignores {
+		sup := suppression{directive: ig.directive()}
 		for i := range diagnostics {
 			diag := &diagnostics[i]
 			if ig.match(*diag) {
 				diag.severity = severityIgnored
+				sup.problems = append(sup.problems, *diag)
 			}
 		}
+		if len(sup.problems) > 0 {
+			sups = append(sups, sup)
+		}
 
 		if ig, ok := ig.(*lineIgnore); ok && !ig.Matched && couldHaveMatched(ig) {
 			diag := diagnostic{
@@ -315,15 +658,24 @@ func filterIgnored(diagnostics []diagnostic, res runner.ResultData, allowedAnaly
 					Category: "staticcheck",
 				},
 			}
+			configureSeverity(&diag, cfg, config.SeverityStaleIgnore)
 			moreDiagnostics = append(moreDiagnostics, diag)
 		}
 	}
 
-	return append(diagnostics, moreDiagnostics...), nil
+	return append(diagnostics, moreDiagnostics...), sups, nil
 }
 
 type ignore interface {
 	match(diag diagnostic) bool
+	directive() runner.SerializedDirective
+}
+
+// sameFile reports whether two file names refer to the same file,
+// accounting for differences in case and path separators on platforms
+// that don't distinguish them.
+func sameFile(a, b string) bool {
+	return a == b || report.NormalizeFilename(a) == report.NormalizeFilename(b)
 }
 
 type lineIgnore struct {
@@ -332,11 +684,28 @@ type lineIgnore struct {
 	Checks  []string
 	Matched bool
 	Pos     token.Position
+	// Alternative positions of the ignored line, which differ from
+	// File and Line in files with line directives.
+	Alt []token.Position
+	dir runner.SerializedDirective
+}
+
+func (li *lineIgnore) directive() runner.SerializedDirective { return li.dir }
+
+func (li *lineIgnore) matchPosition(pos token.Position) bool {
+	if sameFile(pos.Filename, li.File) && pos.Line == li.Line {
+		return true
+	}
+	for _, alt := range li.Alt {
+		if sameFile(pos.Filename, alt.Filename) && pos.Line == alt.Line {
+			return true
+		}
+	}
+	return false
 }
 
 func (li *lineIgnore) match(p diagnostic) bool {
-	pos := p.Position
-	if pos.Filename != li.File || pos.Line != li.Line {
+	if !li.matchPosition(p.Position) {
 		return false
 	}
 	for _, c := range li.Checks {
@@ -359,10 +728,28 @@ func (li *lineIgnore) String() string {
 type fileIgnore struct {
 	File   string
 	Checks []string
+	// Alternative names of the ignored file, which differ from File
+	// in files with line directives.
+	Alt []string
+	dir runner.SerializedDirective
+}
+
+func (fi *fileIgnore) directive() runner.SerializedDirective { return fi.dir }
+
+func (fi *fileIgnore) matchFile(name string) bool {
+	if sameFile(name, fi.File) {
+		return true
+	}
+	for _, alt := range fi.Alt {
+		if sameFile(name, alt) {
+			return true
+		}
+	}
+	return false
 }
 
 func (fi *fileIgnore) match(p diagnostic) bool {
-	if p.Position.Filename != fi.File {
+	if !fi.matchFile(p.Position.Filename) {
 		return false
 	}
 	for _, c := range fi.Checks {
@@ -379,6 +766,7 @@ const (
 	severityError severity = iota
 	severityWarning
 	severityIgnored
+	severityInfo
 )
 
 func (s severity) String() string {
@@ -389,6 +777,8 @@ func (s severity) String() string {
 		return "warning"
 	case severityIgnored:
 		return "ignored"
+	case severityInfo:
+		return "info"
 	default:
 		return fmt.Sprintf("Severity(%d)", s)
 	}
@@ -397,9 +787,47 @@ func (s severity) String() string {
 // diagnostic represents a diagnostic in some source code.
 type diagnostic struct {
 	runner.Diagnostic
-	severity  severity
-	mergeIf   lint.MergeStrategy
-	buildName string
+	severity severity
+	// whether the severity was set by the severity configuration,
+	// taking precedence over the -fail flag
+	configured bool
+	mergeIf    lint.MergeStrategy
+	buildName  string
+	// the owners of the file containing the diagnostic, if the
+	// -codeowners flag is set
+	owners []string
+	// when the problem was first seen, if the age of problems is
+	// being tracked
+	firstSeen time.Time
+	// identifies the problem across changes that move it around,
+	// see setFingerprints
+	fingerprint string
+	// the size and complexity of unused functions, if known
+	metrics *unused.FuncMetrics
+}
+
+// configureSeverity sets the severity of diag to the severity
+// configured for the first of categories that has one. Ignored
+// diagnostics stay ignored.
+func configureSeverity(diag *diagnostic, cfg config.Config, categories ...string) {
+	if diag.severity == severityIgnored || diag.configured {
+		return
+	}
+	sev, ok := cfg.SeverityOf(categories...)
+	if !ok {
+		return
+	}
+	switch sev {
+	case config.SeverityError:
+		diag.severity = severityError
+	case config.SeverityWarning:
+		diag.severity = severityWarning
+	case config.SeverityInfo:
+		diag.severity = severityInfo
+	default:
+		return
+	}
+	diag.configured = true
 }
 
 func (p diagnostic) equal(o diagnostic) bool {
@@ -408,6 +836,7 @@ func (p diagnostic) equal(o diagnostic) bool {
 		p.Message == o.Message &&
 		p.Category == o.Category &&
 		p.severity == o.severity &&
+		p.configured == o.configured &&
 		p.mergeIf == o.mergeIf &&
 		p.buildName == o.buildName
 }
@@ -420,6 +849,15 @@ func (p *diagnostic) String() string {
 	}
 }
 
+// unusedSymbol returns the symbol of the unused object obj of the
+// package at pkgPath. Fields don't have symbols of their own.
+func unusedSymbol(pkgPath string, obj unused.SerializedObject) string {
+	if obj.Kind == "field" {
+		return ""
+	}
+	return runner.ObjectSymbol(pkgPath, obj.Name)
+}
+
 func failed(res runner.Result) []diagnostic {
 	var diagnostics []diagnostic
 
@@ -460,6 +898,7 @@ func failed(res runner.Result) []diagnostic {
 					Position: posn,
 					Message:  msg,
 					Category: "compile",
+					Package:  res.Package.PkgPath,
 				},
 				severity: severityError,
 			}
@@ -470,6 +909,7 @@ func failed(res runner.Result) []diagnostic {
 					Position: token.Position{},
 					Message:  e.Error(),
 					Category: "compile",
+					Package:  res.Package.PkgPath,
 				},
 				severity: severityError,
 			}
@@ -490,6 +930,10 @@ type unusedKey struct {
 type unusedPair struct {
 	key unusedKey
 	obj unused.SerializedObject
+	// whether to report the object even if it is in a generated file
+	reportGenerated bool
+	// the configuration of the object's package
+	cfg config.Config
 }
 
 func success(allowedAnalyzers map[string]bool, res runner.ResultData) []diagnostic {
@@ -0,0 +1,79 @@
+package lintcmd
+
+import (
+	"testing"
+
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/unused"
+)
+
+func TestUnusedFixes(t *testing.T) {
+	key := func(pkgPath, name string) unusedKey {
+		return unusedKey{pkgPath: pkgPath, base: "file.go", line: 1, name: name}
+	}
+	dependencies := map[unusedKey][]unusedKey{
+		// unused code of another package refers to Client
+		key("example.com/app", "run"): {key("example.com/lib", "Client")},
+		// references within the package don't matter
+		key("example.com/lib", "Dial"): {key("example.com/lib", "Options")},
+	}
+	foreign := foreignReferences(dependencies)
+	if !foreign[key("example.com/lib", "Client")] || foreign[key("example.com/lib", "Options")] {
+		t.Errorf("got foreign references %v", foreign)
+	}
+
+	obj := unused.SerializedObject{
+		Name: "Client",
+		Fixes: []unused.SerializedFix{
+			{Message: "Remove type Client"},
+			{Message: "Unexport type Client as client", Rename: true},
+		},
+	}
+	if fixes := unusedFixes(obj, true); len(fixes) != 2 {
+		t.Errorf("got %d fixes, want 2", len(fixes))
+	}
+	fixes := unusedFixes(obj, false)
+	if len(fixes) != 1 || fixes[0].Message != "Remove type Client" {
+		t.Errorf("got fixes %v, want only the removal", fixes)
+	}
+}
+
+func TestUnusedMessage(t *testing.T) {
+	var cfg config.Config
+	cfg.Unused.MessageTemplates = map[string]string{
+		"U1000":      "[dead-code] {{.Message}}",
+		"U1000.func": "[dead-code] {{if .Receiver}}method {{.Name}} of {{.Receiver}}{{else}}{{.Kind}} {{.Name}}{{end}} is unused; {{.Action}}",
+		"U1000.var":  "{{.NoSuchField}}",
+	}
+	tests := []struct {
+		obj        unused.SerializedObject
+		categories []string
+		want       string
+	}{
+		{
+			unused.SerializedObject{Name: "(*T).m", Kind: "func", Fixes: []unused.SerializedFix{{Message: "Remove func m"}}},
+			[]string{"U1000.func", "U1000"},
+			"[dead-code] method (*T).m of *T is unused; Remove func m",
+		},
+		{
+			unused.SerializedObject{Name: "T", Kind: "type"},
+			[]string{"U1000.type", "U1000"},
+			"[dead-code] type T is unused",
+		},
+		{
+			unused.SerializedObject{Name: "v", Kind: "var"},
+			[]string{"U1000.var", "U1000"},
+			"var v is unused (message template for U1000.var failed: template: message:1:2: executing \"message\" at <.NoSuchField>: can't evaluate field NoSuchField in type unused.MessageContext)",
+		},
+	}
+	for _, tt := range tests {
+		if got := unusedMessage(tt.obj, cfg, tt.categories); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+
+	var none config.Config
+	if got, want := unusedMessage(tests[1].obj, none, tests[1].categories), "type T is unused"; got != want {
+		t.Errorf("got %q without templates, want %q", got, want)
+	}
+}
@@ -0,0 +1,103 @@
+package lintcmd
+
+import (
+	"encoding/json"
+	"go/token"
+	"os"
+	"sort"
+
+	"honnef.co/go/tools/unused"
+)
+
+// writeOwnership writes the declaration tree of the checked packages
+// to the named file, as JSON. Each declaration has an ID, which its
+// members refer to as their owner. Declarations are reported once per
+// package variant and build configuration, so duplicates get merged.
+func writeOwnership(name string, decls []unused.SerializedDeclaration) error {
+	type location struct {
+		File   string `json:"file"`
+		Line   int    `json:"line"`
+		Column int    `json:"column"`
+		Offset int    `json:"offset"`
+	}
+	type entry struct {
+		ID      int    `json:"id"`
+		Owner   *int   `json:"owner,omitempty"`
+		Package string `json:"package"`
+		Name    string `json:"name"`
+		Kind    string `json:"kind"`
+		// Symbol is the name of the object's linker symbol, for
+		// functions, methods and package-level variables.
+		Symbol string   `json:"symbol,omitempty"`
+		Start  location `json:"start"`
+		End    location `json:"end"`
+		// Size is the size of the declaration in bytes.
+		Size int `json:"size"`
+	}
+	type key struct {
+		pos  token.Position
+		name string
+	}
+	keyOf := func(obj unused.SerializedObject) key {
+		return key{obj.Position, obj.Name}
+	}
+	loc := func(pos token.Position) location {
+		return location{pos.Filename, pos.Line, pos.Column, pos.Offset}
+	}
+
+	seen := map[key]bool{}
+	var unique []unused.SerializedDeclaration
+	for _, decl := range decls {
+		k := keyOf(decl.Object)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		unique = append(unique, decl)
+	}
+	sort.SliceStable(unique, func(i, j int) bool {
+		pi, pj := unique[i].Object.Position, unique[j].Object.Position
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		return pi.Offset < pj.Offset
+	})
+
+	ids := map[key]int{}
+	for i, decl := range unique {
+		ids[keyOf(decl.Object)] = i
+	}
+	entries := make([]entry, len(unique))
+	for i, decl := range unique {
+		e := entry{
+			ID:      i,
+			Package: decl.Object.PkgPath,
+			Name:    decl.Object.Name,
+			Kind:    decl.Object.Kind,
+			Start:   loc(decl.Position),
+			End:     loc(decl.End),
+			Size:    decl.End.Offset - decl.Position.Offset,
+		}
+		// Members declared inside of their owners, such as fields
+		// and interface methods, don't have symbols of their own.
+		nested := false
+		if decl.Owner.Name != "" {
+			if id, ok := ids[keyOf(decl.Owner)]; ok {
+				e.Owner = &id
+				owner := unique[id]
+				nested = owner.Position.Filename == decl.Position.Filename &&
+					owner.Position.Offset <= decl.Position.Offset && decl.End.Offset <= owner.End.Offset
+			}
+		}
+		if (e.Kind == "func" || e.Kind == "var") && !nested {
+			e.Symbol = e.Package + "." + e.Name
+		}
+		entries[i] = e
+	}
+
+	data, err := json.MarshalIndent(entries, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, append(data, '\n'), 0666)
+}
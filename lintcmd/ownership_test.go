@@ -0,0 +1,66 @@
+package lintcmd
+
+import (
+	"encoding/json"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"honnef.co/go/tools/unused"
+)
+
+func TestOwnership(t *testing.T) {
+	pos := func(line, offset int) token.Position {
+		return token.Position{Filename: "/pkg/a.go", Line: line, Column: 1, Offset: offset}
+	}
+	obj := func(name, kind string, line, offset int) unused.SerializedObject {
+		return unused.SerializedObject{Name: name, PkgPath: "example.com/pkg", Kind: kind, Position: pos(line, offset)}
+	}
+	typ := obj("T", "type", 3, 20)
+	field := obj("x", "field", 4, 40)
+	method := obj("(*T).m", "func", 7, 80)
+	iface := obj("I", "type", 9, 100)
+	imethod := obj("I.n", "func", 10, 120)
+	decls := []unused.SerializedDeclaration{
+		{Object: method, Owner: typ, Position: pos(7, 75), End: pos(7, 95)},
+		{Object: typ, Position: pos(3, 15), End: pos(5, 50)},
+		{Object: field, Owner: typ, Position: pos(4, 40), End: pos(4, 45)},
+		{Object: iface, Position: pos(9, 95), End: pos(11, 130)},
+		{Object: imethod, Owner: iface, Position: pos(10, 120), End: pos(10, 125)},
+		// the same declaration, as seen by the package's test variant
+		{Object: typ, Position: pos(3, 15), End: pos(5, 50)},
+	}
+
+	path := filepath.Join(t.TempDir(), "ownership.json")
+	if err := writeOwnership(path, decls); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type entry struct {
+		ID     int
+		Owner  *int
+		Name   string
+		Symbol string
+		Size   int
+	}
+	var got []entry
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	ref := func(id int) *int { return &id }
+	want := []entry{
+		{ID: 0, Name: "T", Size: 35},
+		{ID: 1, Owner: ref(0), Name: "x", Size: 5},
+		{ID: 2, Owner: ref(0), Name: "(*T).m", Symbol: "example.com/pkg.(*T).m", Size: 20},
+		{ID: 3, Name: "I", Size: 35},
+		{ID: 4, Owner: ref(3), Name: "I.n", Size: 5},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
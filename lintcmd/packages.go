@@ -0,0 +1,157 @@
+package lintcmd
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/go/loader"
+	"honnef.co/go/tools/lintcmd/runner"
+)
+
+// An importedPackage is a node of the import graph of the checked
+// packages, without their tests, for finding the packages that no
+// non-test code imports.
+type importedPackage struct {
+	path string
+	// the directory of the package, and of its module, if any
+	dir, moduleDir string
+	// the first Go file of the package
+	file    string
+	imports []string
+	// whether the package is used regardless of whether the other
+	// packages import it, see unused.Result.Root
+	root bool
+	// whether to report the package if it is unused
+	report bool
+	cfg    config.Config
+}
+
+// addPackage adds spec to the import graph, unless it is a test
+// variant.
+func (st *unusedState) addPackage(spec *loader.PackageSpec, root, report bool, cfg config.Config) {
+	if spec.ID != spec.PkgPath || len(spec.GoFiles) == 0 {
+		// test variants and test binaries
+		return
+	}
+	p := importedPackage{
+		path:   spec.PkgPath,
+		dir:    filepath.Dir(spec.GoFiles[0]),
+		file:   spec.GoFiles[0],
+		root:   root,
+		report: report,
+		cfg:    cfg,
+	}
+	if spec.Module != nil {
+		p.moduleDir = spec.Module.Dir
+	}
+	for _, imp := range spec.Imports {
+		p.imports = append(p.imports, imp.PkgPath)
+	}
+	sort.Strings(p.imports)
+	st.packages = append(st.packages, p)
+}
+
+// unusedPackages returns the packages that the roots don't import,
+// directly or indirectly, sorted by path.
+func unusedPackages(pkgs []importedPackage) []importedPackage {
+	byPath := map[string]importedPackage{}
+	var queue []string
+	used := map[string]bool{}
+	for _, p := range pkgs {
+		byPath[p.path] = p
+		if p.root && !used[p.path] {
+			used[p.path] = true
+			queue = append(queue, p.path)
+		}
+	}
+	for len(queue) > 0 {
+		path := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		for _, imp := range byPath[path].imports {
+			if !used[imp] {
+				used[imp] = true
+				queue = append(queue, imp)
+			}
+		}
+	}
+
+	var out []importedPackage
+	for path, p := range byPath {
+		if !used[path] {
+			out = append(out, p)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].path < out[j].path
+	})
+	return out
+}
+
+// deletableDir returns the highest directory of the unused package p
+// that contains no other packages than unused ones, without leaving
+// p's module.
+func deletableDir(p importedPackage, pkgs []importedPackage, unused map[string]bool) string {
+	within := func(dir, parent string) bool {
+		return dir == parent || strings.HasPrefix(dir, parent+string(filepath.Separator))
+	}
+	dir := p.dir
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir || p.moduleDir == "" || !within(parent, p.moduleDir) || parent == p.moduleDir {
+			return dir
+		}
+		for _, other := range pkgs {
+			if within(other.dir, parent) && !unused[other.path] {
+				return dir
+			}
+		}
+		dir = parent
+	}
+}
+
+// packageDiagnostics reports the unused packages of the import graph,
+// suggesting the deletion of their directories.
+func packageDiagnostics(pkgs []importedPackage) []diagnostic {
+	dead := unusedPackages(pkgs)
+	unused := map[string]bool{}
+	for _, p := range dead {
+		unused[p.path] = true
+	}
+	var out []diagnostic
+	for _, p := range dead {
+		if !p.report {
+			continue
+		}
+		dir := deletableDir(p, pkgs, unused)
+		if p.moduleDir != "" {
+			if rel, err := filepath.Rel(p.moduleDir, dir); err == nil {
+				dir = rel
+			}
+		}
+		// Point at the package clause, after copyright headers and
+		// the like.
+		pos := token.Position{Filename: p.file, Line: 1, Column: 1}
+		fset := token.NewFileSet()
+		if f, err := parser.ParseFile(fset, p.file, nil, parser.PackageClauseOnly); err == nil {
+			pos = fset.PositionFor(f.Package, false)
+		}
+		diag := diagnostic{
+			Diagnostic: runner.Diagnostic{
+				Position: pos,
+				Message:  fmt.Sprintf("package %s isn't imported by any non-test code; consider deleting the directory %s", p.path, filepath.ToSlash(dir)),
+				Category: "U1000",
+				Package:  p.path,
+			},
+			mergeIf: lint.MergeIfAll,
+		}
+		configureSeverity(&diag, p.cfg, "U1000.package", "U1000")
+		out = append(out, diag)
+	}
+	return out
+}
@@ -0,0 +1,59 @@
+package lintcmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestUnusedPackages(t *testing.T) {
+	mod := t.TempDir()
+	pkg := func(rel string, root bool, imports ...string) importedPackage {
+		dir := filepath.Join(mod, filepath.FromSlash(rel))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		file := filepath.Join(dir, "x.go")
+		// The package clause follows a copyright header.
+		if err := os.WriteFile(file, []byte("// Copyright\n\npackage x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return importedPackage{
+			path:      "example.com/" + rel,
+			dir:       dir,
+			moduleDir: mod,
+			file:      file,
+			imports:   imports,
+			root:      root,
+			report:    true,
+		}
+	}
+
+	// cmd imports lib, which imports lib/util. old and old/sub are
+	// only imported by each other, and lib/legacy by nothing.
+	pkgs := []importedPackage{
+		pkg("cmd", true, "example.com/lib", "fmt"),
+		pkg("lib", false, "example.com/lib/util"),
+		pkg("lib/util", false),
+		pkg("lib/legacy", false),
+		pkg("old", false, "example.com/old/sub"),
+		pkg("old/sub", false, "example.com/old"),
+	}
+
+	var got []string
+	for _, diag := range packageDiagnostics(pkgs) {
+		got = append(got, diag.Message)
+		if diag.Position.Line != 3 {
+			t.Errorf("got line %d for %q, want the package clause on line 3", diag.Position.Line, diag.Message)
+		}
+	}
+	want := []string{
+		"package example.com/lib/legacy isn't imported by any non-test code; consider deleting the directory lib/legacy",
+		"package example.com/old isn't imported by any non-test code; consider deleting the directory old",
+		"package example.com/old/sub isn't imported by any non-test code; consider deleting the directory old",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,229 @@
+package lintcmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"honnef.co/go/tools/analysis/lint"
+)
+
+// FormatterABIVersion is the version of the interface between the
+// command and out-of-tree formatters, which they receive in
+// FormatterConfig.ABIVersion. Fields may be added to Finding and
+// FormatterConfig without changing the version. It changes when
+// fields get removed or change their meaning.
+const FormatterABIVersion = 1
+
+// execPrefix is the prefix of output formats that run programs, as
+// in -f exec:staticcheck-gitlab.
+const execPrefix = "exec:"
+
+// A Formatter implements an output format outside of this package.
+// Programs that embed the command add formatters with
+// Command.AddFormatter. Formatters that are separate programs are
+// selected with -f exec:program instead, and receive the
+// configuration and the findings as a FormatterRequest in JSON on
+// their standard input.
+type Formatter interface {
+	// Init is called once, before Format.
+	Init(cfg FormatterConfig) error
+	// Format returns the output for the findings that aren't
+	// ignored, which the command writes to standard output.
+	Format(findings []Finding) ([]byte, error)
+}
+
+// FormatterConfig describes the run that findings are formatted for.
+type FormatterConfig struct {
+	ABIVersion int `json:"abi_version"`
+	// Tool and Version are the name and version of the command, such
+	// as staticcheck and 2023.1.
+	Tool    string `json:"tool"`
+	Version string `json:"version"`
+	// Options are the key=value pairs of the -format-options flag.
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// A Finding is a problem as passed to formatters. It has the fields
+// of the json format.
+type Finding struct {
+	// Code is the check or category of the problem, such as SA4006
+	// or U1000.
+	Code string `json:"code"`
+	// Severity is one of "error", "warning" and "info".
+	Severity string           `json:"severity,omitempty"`
+	Location FindingLocation  `json:"location"`
+	End      FindingLocation  `json:"end"`
+	Message  string           `json:"message"`
+	Related  []RelatedFinding `json:"related,omitempty"`
+	// Package and Symbol are the import path of the package and the
+	// name of the declaration that contain the problem, if any.
+	Package string `json:"package,omitempty"`
+	Symbol  string `json:"symbol,omitempty"`
+	// Owners are the owners of the file, if the -codeowners flag is
+	// set.
+	Owners []string `json:"owners,omitempty"`
+	// FirstSeen is the RFC 3339 time at which the problem was first
+	// seen, if the age of problems is being tracked.
+	FirstSeen string `json:"first_seen,omitempty"`
+	// Metrics are the size and complexity of unused functions.
+	Metrics *FindingMetrics `json:"metrics,omitempty"`
+	// Fingerprint identifies the problem across changes that move it
+	// around.
+	Fingerprint string `json:"fingerprint"`
+}
+
+type FindingLocation struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+type RelatedFinding struct {
+	Location FindingLocation `json:"location"`
+	End      FindingLocation `json:"end"`
+	Message  string          `json:"message"`
+}
+
+type FindingMetrics struct {
+	Complexity int `json:"complexity"`
+	Lines      int `json:"lines"`
+	Params     int `json:"params"`
+}
+
+// A FormatterRequest is what programs selected with -f exec:program
+// read from their standard input. They write the formatted output to
+// their standard output, and exit with a non-zero status if they fail.
+type FormatterRequest struct {
+	Config   FormatterConfig `json:"config"`
+	Findings []Finding       `json:"findings"`
+}
+
+// AddFormatter makes the formatter available as the output format
+// with the name, as in -f name. Formatters can't replace the built-in
+// formats.
+func (cmd *Command) AddFormatter(name string, f Formatter) {
+	cmd.formatters[name] = f
+}
+
+// lookupFormatter returns the added formatter with the name or, for
+// names starting with exec:, the formatter running the program.
+func (cmd *Command) lookupFormatter(name string) (Formatter, bool) {
+	if f, ok := cmd.formatters[name]; ok {
+		return f, true
+	}
+	if strings.HasPrefix(name, execPrefix) && len(name) > len(execPrefix) {
+		return &execFormatter{program: name[len(execPrefix):]}, true
+	}
+	return nil, false
+}
+
+// parseFormatOptions parses the key=value pairs of the -format-options
+// flag.
+func parseFormatOptions(opts []string) (map[string]string, error) {
+	if len(opts) == 0 {
+		return nil, nil
+	}
+	out := map[string]string{}
+	for _, opt := range opts {
+		i := strings.IndexByte(opt, '=')
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid format option %q, want key=value", opt)
+		}
+		out[opt[:i]] = opt[i+1:]
+	}
+	return out, nil
+}
+
+func findingLocation(pos token.Position) FindingLocation {
+	return FindingLocation{File: pos.Filename, Line: pos.Line, Column: pos.Column}
+}
+
+// toFinding converts a diagnostic into the finding that formatters
+// receive.
+func toFinding(p diagnostic) Finding {
+	f := Finding{
+		Code:        p.Category,
+		Severity:    p.severity.String(),
+		Location:    findingLocation(p.Position),
+		End:         findingLocation(p.End),
+		Message:     p.Message,
+		Package:     p.Package,
+		Symbol:      p.Symbol,
+		Owners:      p.owners,
+		Fingerprint: p.fingerprint,
+	}
+	if !p.firstSeen.IsZero() {
+		f.FirstSeen = p.firstSeen.UTC().Format(time.RFC3339)
+	}
+	if m := p.metrics; m != nil {
+		f.Metrics = &FindingMetrics{Complexity: m.Complexity, Lines: m.Lines, Params: m.Params}
+	}
+	for _, r := range p.Related {
+		f.Related = append(f.Related, RelatedFinding{
+			Location: findingLocation(r.Position),
+			End:      findingLocation(r.End),
+			Message:  r.Message,
+		})
+	}
+	return f
+}
+
+// pluginFormatter adapts a Formatter to the formatters of the
+// command. Format records the formatter's error, if any, in err.
+type pluginFormatter struct {
+	f   Formatter
+	w   io.Writer
+	err error
+}
+
+func (o *pluginFormatter) Format(_ []*lint.Analyzer, ps []diagnostic) {
+	findings := make([]Finding, 0, len(ps))
+	for _, p := range ps {
+		findings = append(findings, toFinding(p))
+	}
+	out, err := o.f.Format(findings)
+	if err != nil {
+		o.err = err
+		return
+	}
+	_, o.err = o.w.Write(out)
+}
+
+// execFormatter is the formatter of -f exec:program, which runs the
+// program once per call of Format.
+type execFormatter struct {
+	program string
+	cfg     FormatterConfig
+}
+
+func (f *execFormatter) Init(cfg FormatterConfig) error {
+	path, err := exec.LookPath(f.program)
+	if err != nil {
+		return err
+	}
+	f.program = path
+	f.cfg = cfg
+	return nil
+}
+
+func (f *execFormatter) Format(findings []Finding) ([]byte, error) {
+	in, err := json.Marshal(FormatterRequest{Config: f.cfg, Findings: findings})
+	if err != nil {
+		return nil, err
+	}
+	c := exec.Command(f.program)
+	c.Stdin = bytes.NewReader(in)
+	c.Stderr = os.Stderr
+	out, err := c.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", f.program, err)
+	}
+	return out, nil
+}
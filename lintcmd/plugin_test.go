@@ -0,0 +1,160 @@
+package lintcmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"honnef.co/go/tools/lintcmd/runner"
+	"honnef.co/go/tools/unused"
+)
+
+type countingFormatter struct {
+	cfg      FormatterConfig
+	findings []Finding
+}
+
+func (f *countingFormatter) Init(cfg FormatterConfig) error {
+	f.cfg = cfg
+	return nil
+}
+
+func (f *countingFormatter) Format(findings []Finding) ([]byte, error) {
+	f.findings = findings
+	return []byte("formatted\n"), nil
+}
+
+func pluginDiagnostics() []diagnostic {
+	return []diagnostic{{
+		Diagnostic: runner.Diagnostic{
+			Position: token.Position{Filename: "/app/lib.go", Line: 3, Column: 6},
+			End:      token.Position{Filename: "/app/lib.go", Line: 3, Column: 10},
+			Message:  "func dead is unused",
+			Category: "U1000",
+			Package:  "example.com/app",
+			Symbol:   "example.com/app.dead",
+			Related: []runner.RelatedInformation{{
+				Position: token.Position{Filename: "/app/lib.go", Line: 7, Column: 2},
+				Message:  "dead calls this",
+			}},
+		},
+		severity:    severityWarning,
+		fingerprint: "abc123",
+		metrics:     &unused.FuncMetrics{Complexity: 2, Lines: 4, Params: 1},
+	}}
+}
+
+func TestPluginFormatter(t *testing.T) {
+	cmd := NewCommand("staticcheck")
+	fake := &countingFormatter{}
+	cmd.AddFormatter("fake", fake)
+	if f, ok := cmd.lookupFormatter("fake"); !ok || f != fake {
+		t.Fatalf("got %v, want the added formatter", f)
+	}
+	if _, ok := cmd.lookupFormatter("exec:"); ok {
+		t.Errorf("exec: without a program is a valid output format")
+	}
+	if _, ok := cmd.lookupFormatter("unknown"); ok {
+		t.Errorf("unknown is a valid output format")
+	}
+
+	var buf bytes.Buffer
+	pf := &pluginFormatter{f: fake, w: &buf}
+	pf.Format(nil, pluginDiagnostics())
+	if pf.err != nil {
+		t.Fatal(pf.err)
+	}
+	if buf.String() != "formatted\n" {
+		t.Errorf("got output %q, want %q", buf.String(), "formatted\n")
+	}
+	want := []Finding{{
+		Code:     "U1000",
+		Severity: "warning",
+		Location: FindingLocation{File: "/app/lib.go", Line: 3, Column: 6},
+		End:      FindingLocation{File: "/app/lib.go", Line: 3, Column: 10},
+		Message:  "func dead is unused",
+		Related: []RelatedFinding{{
+			Location: FindingLocation{File: "/app/lib.go", Line: 7, Column: 2},
+			Message:  "dead calls this",
+		}},
+		Package:     "example.com/app",
+		Symbol:      "example.com/app.dead",
+		Metrics:     &FindingMetrics{Complexity: 2, Lines: 4, Params: 1},
+		Fingerprint: "abc123",
+	}}
+	if !reflect.DeepEqual(fake.findings, want) {
+		t.Errorf("got findings %+v, want %+v", fake.findings, want)
+	}
+}
+
+func TestParseFormatOptions(t *testing.T) {
+	got, err := parseFormatOptions([]string{"project=app", "url=https://example.com/?a=b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"project": "app", "url": "https://example.com/?a=b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	for _, opt := range []string{"project", "=app"} {
+		if _, err := parseFormatOptions([]string{opt}); err == nil {
+			t.Errorf("%q: got no error", opt)
+		}
+	}
+}
+
+func TestExecFormatter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a shell")
+	}
+	program := filepath.Join(t.TempDir(), "echo-formatter")
+	if err := os.WriteFile(program, []byte("#!/bin/sh\ncat\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewCommand("staticcheck")
+	f, ok := cmd.lookupFormatter("exec:" + program)
+	if !ok {
+		t.Fatalf("exec:%s isn't a valid output format", program)
+	}
+	cfg := FormatterConfig{ABIVersion: FormatterABIVersion, Tool: "staticcheck", Version: "devel", Options: map[string]string{"k": "v"}}
+	if err := f.Init(cfg); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	pf := &pluginFormatter{f: f, w: &buf}
+	pf.Format(nil, nil)
+	if pf.err != nil {
+		t.Fatal(pf.err)
+	}
+	// The program echoes the request.
+	var req FormatterRequest
+	if err := json.Unmarshal(buf.Bytes(), &req); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(req.Config, cfg) {
+		t.Errorf("got config %+v, want %+v", req.Config, cfg)
+	}
+	if req.Findings == nil {
+		t.Errorf("got null findings, want an empty list")
+	}
+
+	failing := filepath.Join(t.TempDir(), "failing-formatter")
+	if err := os.WriteFile(failing, []byte("#!/bin/sh\nexit 3\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	f, _ = cmd.lookupFormatter("exec:" + failing)
+	if err := f.Init(cfg); err != nil {
+		t.Fatal(err)
+	}
+	pf = &pluginFormatter{f: f, w: &buf}
+	pf.Format(nil, pluginDiagnostics())
+	if pf.err == nil {
+		t.Errorf("got no error for a program exiting with status 3")
+	}
+}
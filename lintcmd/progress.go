@@ -0,0 +1,86 @@
+package lintcmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"honnef.co/go/tools/lintcmd/runner"
+)
+
+// progressPrinter prints the progress of runs. On terminals, it keeps
+// updating a single line. Elsewhere, such as in CI logs, it prints a
+// line whenever the state changes, and heartbeats in between.
+type progressPrinter struct {
+	mu       sync.Mutex
+	w        io.Writer
+	terminal bool
+	interval time.Duration
+	last     time.Time
+	state    int
+	printed  bool
+}
+
+func newProgressPrinter(f *os.File) *progressPrinter {
+	p := &progressPrinter{w: f, state: -1, interval: 10 * time.Second}
+	if fi, err := f.Stat(); err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+		p.terminal = true
+		p.interval = 100 * time.Millisecond
+	}
+	return p
+}
+
+func (p *progressPrinter) update(prog runner.Progress) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	if prog.State == p.state && now.Sub(p.last) < p.interval {
+		return
+	}
+	p.state = prog.State
+	p.last = now
+	p.printed = true
+	if p.terminal {
+		// Rewrite the current line and clear whatever is left of the
+		// previous one.
+		fmt.Fprintf(p.w, "\r%s\x1b[K", formatProgress(prog))
+	} else {
+		fmt.Fprintln(p.w, formatProgress(prog))
+	}
+}
+
+// done finishes the output of a run.
+func (p *progressPrinter) done() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.terminal && p.printed {
+		fmt.Fprint(p.w, "\r\x1b[K")
+	}
+	p.state = -1
+	p.printed = false
+}
+
+func formatProgress(prog runner.Progress) string {
+	var s string
+	switch prog.State {
+	case runner.StateInitializing:
+		s = "initializing"
+	case runner.StateLoadPackageGraph:
+		s = "loading package graph"
+	case runner.StateBuildActionGraph:
+		s = "building action graph"
+	case runner.StateProcessing:
+		s = fmt.Sprintf("analyzing packages: %d/%d (%d/%d initial)",
+			prog.ProcessedPackages, prog.TotalPackages,
+			prog.ProcessedInitialPackages, prog.InitialPackages)
+	case runner.StateFinalizing:
+		s = "finalizing"
+	}
+	s += fmt.Sprintf(", %s elapsed", prog.Elapsed.Round(time.Second))
+	if prog.State == runner.StateProcessing && prog.ETA >= 0 {
+		s += fmt.Sprintf(", about %s left", prog.ETA.Round(time.Second))
+	}
+	return s
+}
@@ -0,0 +1,39 @@
+package lintcmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"honnef.co/go/tools/lintcmd/runner"
+)
+
+func TestProgress(t *testing.T) {
+	buf := &bytes.Buffer{}
+	p := &progressPrinter{w: buf, state: -1, interval: time.Hour}
+
+	p.update(runner.Progress{State: runner.StateLoadPackageGraph, ETA: -1})
+	processing := runner.Progress{
+		State:                    runner.StateProcessing,
+		InitialPackages:          4,
+		ProcessedInitialPackages: 1,
+		TotalPackages:            10,
+		ProcessedPackages:        3,
+		Elapsed:                  2 * time.Second,
+		ETA:                      90 * time.Second,
+	}
+	p.update(processing)
+	// Within the interval and without a change of state, updates are
+	// dropped.
+	processing.ProcessedPackages = 4
+	p.update(processing)
+	p.update(runner.Progress{State: runner.StateFinalizing, Elapsed: 5 * time.Second, ETA: -1})
+	p.done()
+
+	want := "loading package graph, 0s elapsed\n" +
+		"analyzing packages: 3/10 (1/4 initial), 2s elapsed, about 1m30s left\n" +
+		"finalizing, 5s elapsed\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,68 @@
+package lintcmd
+
+import (
+	"fmt"
+
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/lintcmd/runner"
+	"honnef.co/go/tools/unused"
+)
+
+// A handlerRoute is the registration of a handler of an HTTP route, as
+// found by U1000.
+type handlerRoute struct {
+	route unused.SerializedRoute
+	// the key of the handler
+	key unusedKey
+	// the configuration of the handler's package
+	cfg config.Config
+}
+
+// routeDiagnostics flags the handlers of routes that are dead in all
+// variants of their packages. Test variants may see tests that the
+// other variants don't. Handlers that are unused altogether are left
+// to the regular reports of unused objects.
+func routeDiagnostics(routes []handlerRoute, used map[unusedKey]bool) []diagnostic {
+	type routeKey struct {
+		handler unusedKey
+		pattern string
+	}
+	live := map[routeKey]bool{}
+	for _, r := range routes {
+		k := routeKey{r.key, r.route.Pattern}
+		live[k] = live[k] || r.route.Live
+	}
+
+	var out []diagnostic
+	seen := map[routeKey]bool{}
+	for _, r := range routes {
+		k := routeKey{r.key, r.route.Pattern}
+		if seen[k] || live[k] {
+			continue
+		}
+		seen[k] = true
+		if ok, known := used[r.key]; known && !ok {
+			continue
+		}
+		h := r.route.Handler
+		diag := diagnostic{
+			Diagnostic: runner.Diagnostic{
+				Position: h.DisplayPosition,
+				Message:  fmt.Sprintf("%s %s is only used as the handler of route %q, which no test requests", h.Kind, h.Name, r.route.Pattern),
+				Category: "U1000",
+				Anchor:   runner.ObjectAnchor(r.key.pkgPath, h.ObjectPath, h.Name),
+				Package:  r.key.pkgPath,
+				Symbol:   unusedSymbol(r.key.pkgPath, h),
+				Related: []runner.RelatedInformation{{
+					Position: r.route.Registration,
+					Message:  "registered here",
+				}},
+			},
+			mergeIf: lint.MergeIfAll,
+		}
+		configureSeverity(&diag, r.cfg, "U1000.dead_route", "U1000")
+		out = append(out, diag)
+	}
+	return out
+}
@@ -0,0 +1,57 @@
+package lintcmd
+
+import (
+	"go/token"
+	"testing"
+
+	"honnef.co/go/tools/unused"
+)
+
+func TestRoutes(t *testing.T) {
+	route := func(name string, line int, pattern string, live bool) handlerRoute {
+		return handlerRoute{
+			route: unused.SerializedRoute{
+				Handler: unused.SerializedObject{
+					Name:            name,
+					Kind:            "func",
+					DisplayPosition: token.Position{Filename: "/app/routes.go", Line: line, Column: 6},
+				},
+				Pattern:      pattern,
+				Registration: token.Position{Filename: "/app/routes.go", Line: 20, Column: 2},
+				Live:         live,
+			},
+			key: unusedKey{pkgPath: "example.com/app", base: "routes.go", line: line, name: name},
+		}
+	}
+	routes := []handlerRoute{
+		route("handleLegacy", 3, "/legacy", false),
+		// the same registration, as seen by the package's test variant
+		route("handleLegacy", 3, "/legacy", false),
+		// only the test variant sees the test requesting the route
+		route("handleUsers", 5, "/users", false),
+		route("handleUsers", 5, "/users", true),
+		// unused handlers get reported as such
+		route("handleDead", 7, "/dead", false),
+	}
+	used := map[unusedKey]bool{
+		routes[0].key: true,
+		routes[2].key: true,
+		routes[4].key: false,
+	}
+	diags := routeDiagnostics(routes, used)
+
+	want := []string{
+		`func handleLegacy is only used as the handler of route "/legacy", which no test requests`,
+	}
+	if len(diags) != len(want) {
+		t.Fatalf("got %d diagnostics, want %d", len(diags), len(want))
+	}
+	for i, diag := range diags {
+		if diag.Message != want[i] {
+			t.Errorf("got message %q, want %q", diag.Message, want[i])
+		}
+		if len(diag.Related) != 1 || diag.Related[0].Position.Line != 20 {
+			t.Errorf("%s: got related information %v, want the registration", diag.Message, diag.Related)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package runner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"honnef.co/go/tools/go/loader"
+
+	"golang.org/x/tools/go/types/objectpath"
+)
+
+// Anchor returns a description of the location of the declaration of
+// obj that doesn't depend on line numbers: its package path and
+// object path, or its name if it has no object path, such as for init
+// functions.
+func Anchor(obj types.Object) string {
+	if obj.Pkg() == nil {
+		return obj.Name()
+	}
+	path, _ := objectpath.For(obj)
+	return ObjectAnchor(obj.Pkg().Path(), string(path), obj.Name())
+}
+
+// ObjectAnchor is like Anchor, for objects that are described by
+// their package paths, object paths and names.
+func ObjectAnchor(pkgPath, objPath, name string) string {
+	if objPath == "" {
+		return pkgPath + " " + name
+	}
+	return pkgPath + " " + objPath
+}
+
+// FileAnchor returns the anchor of problems that aren't part of any
+// declaration, such as problems with imports.
+func FileAnchor(filename string) string {
+	return "file " + filepath.Base(filename)
+}
+
+// Symbol returns the full name of obj, such as example.com/pkg.Func,
+// example.com/pkg.T or (*example.com/pkg.T).Method.
+func Symbol(obj types.Object) string {
+	if fn, ok := obj.(*types.Func); ok {
+		return fn.FullName()
+	}
+	if obj.Pkg() == nil {
+		return obj.Name()
+	}
+	return obj.Pkg().Path() + "." + obj.Name()
+}
+
+// ObjectSymbol is like Symbol, for objects that are described by their
+// package paths and names, where the names of methods are qualified by
+// their receivers, such as (*T).Method or T.Method.
+func ObjectSymbol(pkgPath, name string) string {
+	if strings.HasPrefix(name, "(*") {
+		return "(*" + pkgPath + "." + name[2:]
+	}
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		return "(" + pkgPath + "." + name[:i] + ")" + name[i:]
+	}
+	return pkgPath + "." + name
+}
+
+// anchor returns the anchor and the symbol of the package-level
+// declaration or method of pkg that contains pos.
+func anchor(pkg *loader.Package, pos token.Pos) (anchor, symbol string) {
+	for _, f := range pkg.Syntax {
+		if pos < f.Pos() || pos > f.End() {
+			continue
+		}
+		i := sort.Search(len(f.Decls), func(i int) bool {
+			return f.Decls[i].End() > pos
+		})
+		if i < len(f.Decls) && f.Decls[i].Pos() <= pos {
+			var name *ast.Ident
+			switch decl := f.Decls[i].(type) {
+			case *ast.FuncDecl:
+				name = decl.Name
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					if pos < spec.Pos() || pos >= spec.End() {
+						continue
+					}
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						name = spec.Name
+					case *ast.ValueSpec:
+						name = spec.Names[0]
+					}
+				}
+			}
+			if name != nil {
+				if obj := pkg.TypesInfo.Defs[name]; obj != nil {
+					return Anchor(obj), Symbol(obj)
+				}
+			}
+		}
+		return FileAnchor(pkg.Fset.PositionFor(f.Pos(), false).Filename), ""
+	}
+	return "", ""
+}
+
+// Fingerprint returns the fingerprint of a problem, which identifies
+// it across changes that move it around, such as reformatting and
+// moving declarations between files. Problems with the same category,
+// anchor and message are told apart by their ordinal, their index in
+// the order of their positions.
+func Fingerprint(category, anchor, message string, ordinal int) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%d", category, anchor, message, ordinal)
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
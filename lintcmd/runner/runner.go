@@ -56,6 +56,13 @@
 // the dependency graph. A lot of inter-connected packages will see
 // less parallelism than a lot of independent packages.
 //
+// Cancellation
+//
+// Runs can be cancelled with RunContext. Cancellation is checked
+// before each package and analyzer is executed, and analyzers that
+// depend on cancellation.Analyzer may check it as often as they
+// like. Packages that were abandoned aren't cached.
+//
 // Caching
 //
 // The runner caches facts, directives and diagnostics in a
@@ -113,7 +120,10 @@ package runner
 
 import (
 	"bytes"
+	"context"
+	"encoding/binary"
 	"encoding/gob"
+	"errors"
 	"fmt"
 	"go/token"
 	"go/types"
@@ -126,10 +136,12 @@ import (
 	"sync/atomic"
 	"time"
 
+	"honnef.co/go/tools/analysis/facts/cancellation"
 	"honnef.co/go/tools/analysis/lint"
 	"honnef.co/go/tools/analysis/report"
 	"honnef.co/go/tools/config"
 	"honnef.co/go/tools/go/loader"
+	"honnef.co/go/tools/internal/passes/buildir"
 	tsync "honnef.co/go/tools/internal/sync"
 	"honnef.co/go/tools/lintcmd/cache"
 	"honnef.co/go/tools/unused"
@@ -150,6 +162,17 @@ type Diagnostic struct {
 
 	SuggestedFixes []SuggestedFix
 	Related        []RelatedInformation
+
+	// Anchor describes the location of the diagnostic without
+	// relying on line numbers, for computing fingerprints. See
+	// Fingerprint.
+	Anchor string
+	// Package is the import path of the package that the diagnostic
+	// belongs to, if any.
+	Package string
+	// Symbol is the full name of the package-level declaration or
+	// method that contains the diagnostic, if any. See Symbol.
+	Symbol string
 }
 
 // RelatedInformation provides additional context for a diagnostic.
@@ -195,6 +218,10 @@ type SerializedDirective struct {
 	DirectivePosition token.Position
 	// The position of the node that the comment is attached to
 	NodePosition token.Position
+	// The positions of the node, ignoring and respecting line
+	// directives. Diagnostics may be reported at either of them.
+	RawNodePosition      token.Position
+	AdjustedNodePosition token.Position
 }
 
 func serializeDirective(dir lint.Directive, fset *token.FileSet) SerializedDirective {
@@ -203,6 +230,9 @@ func serializeDirective(dir lint.Directive, fset *token.FileSet) SerializedDirec
 		Arguments:         dir.Arguments,
 		DirectivePosition: report.DisplayPosition(fset, dir.Directive.Pos()),
 		NodePosition:      report.DisplayPosition(fset, dir.Node.Pos()),
+
+		RawNodePosition:      fset.PositionFor(dir.Node.Pos(), false),
+		AdjustedNodePosition: fset.PositionFor(dir.Node.Pos(), true),
 	}
 }
 
@@ -210,6 +240,16 @@ type ResultData struct {
 	Directives  []SerializedDirective
 	Diagnostics []Diagnostic
 	Unused      unused.SerializedResult
+	// BuildErrors lists the functions whose IR couldn't be built,
+	// because of which the analyzers needing the IR of all functions
+	// skipped the package.
+	BuildErrors []BuildError
+}
+
+// A BuildError records that building the IR of a function failed.
+type BuildError struct {
+	Position token.Position
+	Message  string
 }
 
 func (r Result) Load() (ResultData, error) {
@@ -312,6 +352,10 @@ type packageAction struct {
 	results  string
 	testData string
 	skipped  bool
+
+	// how long processing the package took in the previous run, if
+	// known
+	expected time.Duration
 }
 
 func (act *packageAction) String() string {
@@ -384,6 +428,10 @@ type Runner struct {
 	FallbackGoVersion string
 	// If set to true, Runner will populate results with data relevant to testing analyzers
 	TestMode bool
+	// If not nil, only the packages affected by these changed files
+	// and import paths, and their reverse dependencies, get analyzed.
+	// See loader.ReverseDependencies.
+	Changed []string
 
 	// GoVersion might be "module"; actualGoVersion contains the resolved version
 	actualGoVersion string
@@ -392,6 +440,9 @@ type Runner struct {
 	cfg       config.Config
 	cache     *cache.Cache
 	semaphore tsync.Semaphore
+
+	// the context of the current run, see RunContext
+	ctx context.Context
 }
 
 type subrunner struct {
@@ -516,11 +567,19 @@ func getCachedFiles(cache *cache.Cache, ids []cache.ActionID, out []*string) err
 
 func (r *subrunner) do(act action) error {
 	a := act.(*packageAction)
+	if err := r.ctx.Err(); err != nil {
+		// Don't start on new packages once the run has been
+		// cancelled.
+		return err
+	}
+	t := time.Now()
 	defer func() {
-		r.Stats.finishPackage()
+		r.recordTiming(a, time.Since(t))
+		r.Stats.finishPackage(a.expected)
 		if !a.factsOnly {
 			r.Stats.finishInitialPackage()
 		}
+		r.Stats.progress()
 	}()
 
 	// compute hash of action
@@ -620,6 +679,7 @@ func (r *subrunner) do(act action) error {
 
 		out.Diagnostics = result.diags
 		out.Unused = result.unused
+		out.BuildErrors = result.buildErrors
 		a.results, err = r.writeCacheGob(a, "results", out)
 		if err != nil {
 			return err
@@ -639,6 +699,59 @@ func (r *subrunner) do(act action) error {
 	return nil
 }
 
+// timingKey returns the cache key under which we store how long
+// processing the package of a took.
+func (r *Runner) timingKey(a *packageAction) cache.ActionID {
+	h := r.cache.NewHash("staticcheck timing")
+	fmt.Fprintf(h, "pkg %s\n", a.Package.ID)
+	fmt.Fprintf(h, "facts only %t\n", a.factsOnly)
+	return cache.ActionID(h.Sum())
+}
+
+func (r *Runner) recordTiming(a *packageAction, d time.Duration) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], uint64(d))
+	// Timings only feed estimates, we don't care if we fail to
+	// store them.
+	_ = r.cache.PutBytes(r.timingKey(a), b[:])
+}
+
+// estimate sets the expected durations of the actions, based on
+// earlier runs, and the estimate of the whole run. Packages that we
+// haven't seen before are assumed to take as long as the average
+// package. If we haven't seen any package before, there is no
+// estimate.
+func (r *Runner) estimate(all map[*loader.PackageSpec]*packageAction) {
+	var total time.Duration
+	var known, unknown int
+	for _, a := range all {
+		if a.Package == nil {
+			continue
+		}
+		b, _, err := r.cache.GetBytes(r.timingKey(a))
+		if err != nil || len(b) != 8 {
+			unknown++
+			continue
+		}
+		a.expected = time.Duration(binary.LittleEndian.Uint64(b))
+		total += a.expected
+		known++
+	}
+	if known == 0 {
+		return
+	}
+	if unknown > 0 {
+		avg := total / time.Duration(known)
+		for _, a := range all {
+			if a.Package != nil && a.expected == 0 {
+				a.expected = avg
+				total += avg
+			}
+		}
+	}
+	r.Stats.setEstimate(total, r.TotalWorkers())
+}
+
 // ActiveWorkers returns the number of currently running workers.
 func (r *Runner) ActiveWorkers() int {
 	return r.semaphore.Len()
@@ -675,12 +788,13 @@ func (r *Runner) writeCacheGob(a *packageAction, kind string, data interface{})
 }
 
 type packageActionResult struct {
-	facts   []gobFact
-	diags   []Diagnostic
-	unused  unused.SerializedResult
-	dirs    []lint.Directive
-	lpkg    *loader.Package
-	skipped bool
+	facts       []gobFact
+	diags       []Diagnostic
+	unused      unused.SerializedResult
+	buildErrors []BuildError
+	dirs        []lint.Directive
+	lpkg        *loader.Package
+	skipped     bool
 
 	// Only set when using test mode
 	testFacts []TestFact
@@ -720,12 +834,13 @@ func (r *subrunner) doUncached(a *packageAction) (packageActionResult, error) {
 	res, err := r.runAnalyzers(a, pkg)
 
 	return packageActionResult{
-		facts:     res.facts,
-		testFacts: res.testFacts,
-		diags:     res.diagnostics,
-		unused:    res.unused,
-		dirs:      dirs,
-		lpkg:      pkg,
+		facts:       res.facts,
+		testFacts:   res.testFacts,
+		diags:       res.diagnostics,
+		unused:      res.unused,
+		buildErrors: res.buildErrors,
+		dirs:        dirs,
+		lpkg:        pkg,
 	}, err
 }
 
@@ -839,12 +954,20 @@ type analyzerRunner struct {
 	// analyzers other than the current one
 	depPkgFacts map[packageFactKey]analysis.Fact
 	factsOnly   bool
+	// the package's configuration, including overrides from the
+	// command line
+	cfg config.Config
+	// the context of the run
+	ctx context.Context
 
 	stats *Stats
 }
 
 func (ar *analyzerRunner) do(act action) error {
 	a := act.(*analyzerAction)
+	if err := ar.ctx.Err(); err != nil {
+		return err
+	}
 	results := map[*analysis.Analyzer]interface{}{}
 	// TODO(dh): does this have to be recursive?
 	for _, dep := range a.deps {
@@ -865,13 +988,14 @@ func (ar *analyzerRunner) do(act action) error {
 		return ok
 	}
 	a.Pass = &analysis.Pass{
-		Analyzer:   a.Analyzer,
-		Fset:       ar.pkg.Fset,
-		Files:      ar.pkg.Syntax,
-		OtherFiles: ar.pkg.OtherFiles,
-		Pkg:        ar.pkg.Types,
-		TypesInfo:  ar.pkg.TypesInfo,
-		TypesSizes: ar.pkg.TypesSizes,
+		Analyzer:     a.Analyzer,
+		Fset:         ar.pkg.Fset,
+		Files:        ar.pkg.Syntax,
+		OtherFiles:   ar.pkg.OtherFiles,
+		IgnoredFiles: ar.pkg.IgnoredFiles,
+		Pkg:          ar.pkg.Types,
+		TypesInfo:    ar.pkg.TypesInfo,
+		TypesSizes:   ar.pkg.TypesSizes,
 		Report: func(diag analysis.Diagnostic) {
 			if !ar.factsOnly {
 				if diag.Category == "" {
@@ -882,7 +1006,9 @@ func (ar *analyzerRunner) do(act action) error {
 					End:      report.DisplayPosition(ar.pkg.Fset, diag.End),
 					Category: diag.Category,
 					Message:  diag.Message,
+					Package:  ar.pkg.PkgPath,
 				}
+				d.Anchor, d.Symbol = anchor(ar.pkg, diag.Pos)
 				for _, sugg := range diag.SuggestedFixes {
 					s := SuggestedFix{
 						Message: sugg.Message,
@@ -988,6 +1114,20 @@ func (ar *analyzerRunner) do(act action) error {
 		},
 	}
 
+	if a.Analyzer == config.Analyzer {
+		// Use the configuration that we've already loaded and merged
+		// with the runner's configuration, so that analyzers see
+		// options that were set on the command line.
+		cfg := ar.cfg
+		a.Result = &cfg
+		return nil
+	}
+	if a.Analyzer == cancellation.Analyzer {
+		// Let analyzers see the cancellation of the run.
+		a.Result = &cancellation.Context{Context: ar.ctx}
+		return nil
+	}
+
 	t := time.Now()
 	res, err := a.Analyzer.Run(a.Pass)
 	ar.stats.measureAnalyzer(a.Analyzer, ar.pkg.PackageSpec, time.Since(t))
@@ -1002,6 +1142,7 @@ type analysisResult struct {
 	facts       []gobFact
 	diagnostics []Diagnostic
 	unused      unused.SerializedResult
+	buildErrors []BuildError
 
 	// Only set when using test mode
 	testFacts []TestFact
@@ -1038,6 +1179,8 @@ func (r *subrunner) runAnalyzers(pkgAct *packageAction, pkg *loader.Package) (an
 	ar := &analyzerRunner{
 		pkg:         pkg,
 		factsOnly:   pkgAct.factsOnly,
+		cfg:         pkgAct.cfg,
+		ctx:         r.ctx,
 		depObjFacts: depObjFacts,
 		depPkgFacts: depPkgFacts,
 		stats:       &r.Stats,
@@ -1065,13 +1208,27 @@ func (r *subrunner) runAnalyzers(pkgAct *packageAction, pkg *loader.Package) (an
 			genericHandle(item, root, queue, nil, ar.do)
 		}
 	}
+	if err := r.ctx.Err(); err != nil {
+		// Analyzers that saw the cancellation return incomplete
+		// results, which we mustn't cache.
+		return analysisResult{}, err
+	}
 
 	var unusedResult unused.SerializedResult
 	for _, a := range all {
 		if a != root && a.Analyzer.Name == "U1000" && !a.failed {
 			// TODO(dh): figure out a clean abstraction, instead of
 			// special-casing U1000.
-			unusedResult = unused.Serialize(a.Pass, a.Result.(unused.Result), pkg.Fset)
+			res := a.Result.(unused.Result)
+			unusedResult = unused.Serialize(a.Pass, res, pkg.Fset)
+			if g := res.Graph; g != nil {
+				// All analyzers that could query the graph have
+				// finished. Nothing may use the graph once it's
+				// freed, so take it out of the result first.
+				res.Graph = nil
+				a.Result = res
+				g.Free()
+			}
 		}
 
 		for key, fact := range a.ObjectFacts {
@@ -1140,11 +1297,30 @@ func (r *subrunner) runAnalyzers(pkgAct *packageAction, pkg *loader.Package) (an
 		a := a.(*analyzerAction)
 		diags = append(diags, a.Diagnostics...)
 	}
+
+	// We don't report the errors of analyzers, but the analyzers
+	// needing the IR of all functions fail silently otherwise.
+	var buildErrs []BuildError
+	if a, ok := all[buildir.Analyzer]; ok {
+		for _, err := range a.errors {
+			var errs buildir.BuildErrors
+			if !errors.As(err, &errs) {
+				continue
+			}
+			for _, err := range errs {
+				buildErrs = append(buildErrs, BuildError{
+					Position: report.DisplayPosition(pkg.Fset, err.Pos),
+					Message:  err.Error(),
+				})
+			}
+		}
+	}
 	return analysisResult{
 		facts:       gobFacts,
 		testFacts:   testFacts,
 		diagnostics: diags,
 		unused:      unusedResult,
+		buildErrors: buildErrs,
 	}, nil
 }
 
@@ -1188,14 +1364,57 @@ func allAnalyzers(analyzers []*analysis.Analyzer) []*analysis.Analyzer {
 // If cfg is nil, a default config will be used. Otherwise, cfg will
 // be used, with the exception of the Mode field.
 func (r *Runner) Run(cfg *packages.Config, analyzers []*analysis.Analyzer, patterns []string) ([]Result, error) {
+	return r.RunContext(context.Background(), cfg, analyzers, patterns)
+}
+
+// RunContext is like Run, but stops once ctx is done. Packages that
+// are being analyzed when ctx is cancelled are abandoned at the next
+// file or function that analyzers supporting cancellation look at, no
+// further packages are analyzed, and RunContext returns ctx's error
+// instead of any results. Analyzers learn about ctx via
+// cancellation.Analyzer. Unless cfg specifies its own context, ctx
+// also cancels the loading of the package graph.
+// markTestSupport adds the packages that only tests import to the
+// test support packages of their configuration, if the configuration
+// enables config.RuleTestSupportImports.
+func (r *Runner) markTestSupport(lpkgs []*loader.PackageSpec) {
+	testSupport := loader.TestSupport(lpkgs)
+	for _, lpkg := range lpkgs {
+		if !testSupport[lpkg.PkgPath] || !lpkg.Config.Merge(r.cfg).Unused.Rules[config.RuleTestSupportImports] {
+			continue
+		}
+		patterns := lpkg.Config.Unused.TestSupportPackages
+		lpkg.Config.Unused.TestSupportPackages = append(patterns[:len(patterns):len(patterns)], lpkg.PkgPath)
+	}
+}
+
+func (r *Runner) RunContext(ctx context.Context, cfg *packages.Config, analyzers []*analysis.Analyzer, patterns []string) ([]Result, error) {
+	r.ctx = ctx
 	analyzers = allAnalyzers(analyzers)
 	registerGobTypes(analyzers)
 
+	if cfg == nil || cfg.Context == nil {
+		var dcfg packages.Config
+		if cfg != nil {
+			dcfg = *cfg
+		}
+		dcfg.Context = ctx
+		cfg = &dcfg
+	}
 	r.Stats.setState(StateLoadPackageGraph)
 	lpkgs, err := loader.Graph(r.cache, cfg, patterns...)
 	if err != nil {
+		if ctx.Err() != nil {
+			// Report the cancellation instead of the failure of go
+			// list that it caused.
+			return nil, ctx.Err()
+		}
 		return nil, err
 	}
+	r.markTestSupport(lpkgs)
+	if r.Changed != nil {
+		lpkgs = loader.ReverseDependencies(lpkgs, r.Changed)
+	}
 	r.Stats.setInitialPackages(len(lpkgs))
 
 	if len(lpkgs) == 0 {
@@ -1251,6 +1470,7 @@ func (r *Runner) Run(cfg *packages.Config, analyzers []*analysis.Analyzer, patte
 
 	queue := make(chan action)
 	r.Stats.setTotalPackages(len(all) - 1)
+	r.estimate(all)
 
 	r.Stats.setState(StateProcessing)
 	go func() {
@@ -1269,6 +1489,10 @@ func (r *Runner) Run(cfg *packages.Config, analyzers []*analysis.Analyzer, patte
 		})
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	r.Stats.setState(StateFinalizing)
 	out := make([]Result, 0, len(all))
 	for _, item := range all {
@@ -23,19 +23,89 @@ type Stats struct {
 	totalPackages            uint32
 	processedPackages        uint32
 	processedInitialPackages uint32
+	// the time the run started, in nanoseconds since the Unix epoch
+	start int64
+	// estimated duration of the packages that are yet to be
+	// processed, in nanoseconds, or -1 if there is no estimate
+	remaining int64
+	workers   uint32
 
 	// optional function to call every time an analyzer has finished analyzing a package.
 	PrintAnalyzerMeasurement func(*analysis.Analyzer, *loader.PackageSpec, time.Duration)
+	// optional function to call every time the state changes or a
+	// package has been processed. It may be called concurrently.
+	OnProgress func(Progress)
+}
+
+// Progress is a snapshot of the progress of a run.
+type Progress struct {
+	State                    int
+	InitialPackages          int
+	ProcessedInitialPackages int
+	TotalPackages            int
+	ProcessedPackages        int
+	// Elapsed is the time since the run started.
+	Elapsed time.Duration
+	// ETA estimates the remaining time of the processing state,
+	// based on how long processing the remaining packages took in
+	// earlier runs. It is negative if there is no estimate.
+	ETA time.Duration
+}
+
+// Progress returns a snapshot of the run's progress.
+func (s *Stats) Progress() Progress {
+	p := Progress{
+		State:                    s.State(),
+		InitialPackages:          s.InitialPackages(),
+		ProcessedInitialPackages: s.ProcessedInitialPackages(),
+		TotalPackages:            s.TotalPackages(),
+		ProcessedPackages:        s.ProcessedPackages(),
+		ETA:                      -1,
+	}
+	if start := atomic.LoadInt64(&s.start); start != 0 {
+		p.Elapsed = time.Since(time.Unix(0, start))
+	}
+	workers := int64(atomic.LoadUint32(&s.workers))
+	if remaining := atomic.LoadInt64(&s.remaining); remaining >= 0 && workers > 0 && p.State <= StateProcessing {
+		// This assumes that all workers are busy, which they
+		// aren't while the dependency graph is narrow.
+		p.ETA = time.Duration(remaining / workers)
+	}
+	return p
+}
+
+func (s *Stats) progress() {
+	if s.OnProgress != nil {
+		s.OnProgress(s.Progress())
+	}
+}
+
+func (s *Stats) setState(state uint32) {
+	if state == StateLoadPackageGraph {
+		atomic.StoreInt64(&s.start, time.Now().UnixNano())
+		atomic.StoreInt64(&s.remaining, -1)
+	}
+	atomic.StoreUint32(&s.state, state)
+	s.progress()
 }
 
-func (s *Stats) setState(state uint32)    { atomic.StoreUint32(&s.state, state) }
 func (s *Stats) State() int               { return int(atomic.LoadUint32(&s.state)) }
 func (s *Stats) setInitialPackages(n int) { atomic.StoreUint32(&s.initialPackages, uint32(n)) }
 func (s *Stats) InitialPackages() int     { return int(atomic.LoadUint32(&s.initialPackages)) }
 func (s *Stats) setTotalPackages(n int)   { atomic.StoreUint32(&s.totalPackages, uint32(n)) }
 func (s *Stats) TotalPackages() int       { return int(atomic.LoadUint32(&s.totalPackages)) }
 
-func (s *Stats) finishPackage()         { atomic.AddUint32(&s.processedPackages, 1) }
+func (s *Stats) setEstimate(remaining time.Duration, workers int) {
+	atomic.StoreInt64(&s.remaining, int64(remaining))
+	atomic.StoreUint32(&s.workers, uint32(workers))
+}
+
+func (s *Stats) finishPackage(expected time.Duration) {
+	if expected > 0 {
+		atomic.AddInt64(&s.remaining, -int64(expected))
+	}
+	atomic.AddUint32(&s.processedPackages, 1)
+}
 func (s *Stats) finishInitialPackage()  { atomic.AddUint32(&s.processedInitialPackages, 1) }
 func (s *Stats) ProcessedPackages() int { return int(atomic.LoadUint32(&s.processedPackages)) }
 func (s *Stats) ProcessedInitialPackages() int {
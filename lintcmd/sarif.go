@@ -283,6 +283,28 @@ func (o *sarifFormatter) Format(checks []*lint.Analyzer, diagnostics []diagnosti
 			Message: sarif.Message{
 				Text: p.Message,
 			},
+			PartialFingerprints: map[string]string{
+				"staticcheckFingerprint/v1": p.fingerprint,
+			},
+		}
+		if m := p.metrics; m != nil {
+			r.Properties = map[string]interface{}{
+				"complexity": m.Complexity,
+				"lines":      m.Lines,
+				"params":     m.Params,
+			}
+		}
+		if p.configured {
+			// Override the rule's default level with the configured
+			// severity.
+			switch p.severity {
+			case severityError:
+				r.Level = "error"
+			case severityWarning:
+				r.Level = "warning"
+			case severityInfo:
+				r.Level = "note"
+			}
 		}
 		r.Locations = []sarif.Location{{
 			PhysicalLocation: sarif.PhysicalLocation{
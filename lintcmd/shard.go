@@ -0,0 +1,300 @@
+package lintcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/unused"
+)
+
+// shardVersion is the version of the format of shard files. Merging
+// shards of different versions isn't supported.
+const shardVersion = 2
+
+// A shard is the state of the analysis of unused code for a subset of
+// a program's packages, as written by -unused.shard and read by
+// -unused.merge. Each package of the program has to be checked by at
+// least one shard. Of the configuration of packages, only the
+// severities are needed for reporting. Shards always record which
+// binaries use which objects and why objects are used, so that the
+// merge can take -unused.binaries and -unused.snapshot.
+type shard struct {
+	Version      int
+	Used         []shardKey
+	Unused       []shardKey
+	Linked       []string
+	Dependencies []shardDependency
+	Objects      []shardObject
+	Uncovered    []shardObject
+	References   []shardReference
+	Routes       []shardRoute
+	Packages     []shardPackage
+	// the exported constants, for finding duplicated constants
+	Constants []shardObject `json:",omitempty"`
+	// the objects that main packages use, by import path, and the
+	// objects that other packages use
+	Binaries map[string][]shardKey `json:",omitempty"`
+	Shared   []shardKey            `json:",omitempty"`
+	// the used objects and why they are used
+	Useds []shardUsed `json:",omitempty"`
+}
+
+type shardKey struct {
+	PkgPath string
+	Base    string
+	Line    int
+	Name    string
+}
+
+type shardDependency struct {
+	From shardKey
+	To   []shardKey
+}
+
+type shardUsed struct {
+	Key    shardKey
+	Object unused.SerializedObject
+	Own    bool `json:",omitempty"`
+}
+
+type shardObject struct {
+	Key             shardKey
+	Object          unused.SerializedObject
+	ReportGenerated bool              `json:",omitempty"`
+	Severity        map[string]string `json:",omitempty"`
+}
+
+type shardReference struct {
+	Reference unused.SerializedReference
+	From      shardKey
+}
+
+type shardRoute struct {
+	Route    unused.SerializedRoute
+	Key      shardKey
+	Severity map[string]string `json:",omitempty"`
+}
+
+type shardPackage struct {
+	Path      string
+	Dir       string
+	ModuleDir string `json:",omitempty"`
+	File      string
+	Imports   []string
+	Root      bool              `json:",omitempty"`
+	Report    bool              `json:",omitempty"`
+	Severity  map[string]string `json:",omitempty"`
+}
+
+func toShardKey(key unusedKey) shardKey {
+	return shardKey{PkgPath: key.pkgPath, Base: key.base, Line: key.line, Name: key.name}
+}
+
+func toShardKeys(keys []unusedKey) []shardKey {
+	out := make([]shardKey, len(keys))
+	for i, key := range keys {
+		out[i] = toShardKey(key)
+	}
+	return out
+}
+
+// sortedKeys returns the keys of m in a deterministic order, so that
+// shards of the same packages are identical.
+func sortedKeys[V any](m map[unusedKey]V) []unusedKey {
+	out := make([]unusedKey, 0, len(m))
+	for key := range m {
+		out = append(out, key)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.pkgPath != b.pkgPath {
+			return a.pkgPath < b.pkgPath
+		}
+		if a.base != b.base {
+			return a.base < b.base
+		}
+		if a.line != b.line {
+			return a.line < b.line
+		}
+		return a.name < b.name
+	})
+	return out
+}
+
+func fromShardKey(key shardKey) unusedKey {
+	return unusedKey{pkgPath: key.PkgPath, base: key.Base, line: key.Line, name: key.Name}
+}
+
+func toShardObjects(pairs []unusedPair) []shardObject {
+	out := make([]shardObject, len(pairs))
+	for i, p := range pairs {
+		out[i] = shardObject{
+			Key:             toShardKey(p.key),
+			Object:          p.obj,
+			ReportGenerated: p.reportGenerated,
+			Severity:        p.cfg.Severity,
+		}
+	}
+	return out
+}
+
+func fromShardObjects(objs []shardObject) []unusedPair {
+	out := make([]unusedPair, len(objs))
+	for i, o := range objs {
+		out[i] = unusedPair{
+			key:             fromShardKey(o.Key),
+			obj:             o.Object,
+			reportGenerated: o.ReportGenerated,
+			cfg:             config.Config{Severity: o.Severity},
+		}
+	}
+	return out
+}
+
+// writeShard writes the state of the analysis of unused code to the
+// named file as JSON.
+func writeShard(name string, st *unusedState) error {
+	sh := shard{
+		Version:   shardVersion,
+		Objects:   toShardObjects(st.unuseds),
+		Uncovered: toShardObjects(st.uncovered),
+	}
+	for _, key := range sortedKeys(st.used) {
+		if st.used[key] {
+			sh.Used = append(sh.Used, toShardKey(key))
+		} else {
+			sh.Unused = append(sh.Unused, toShardKey(key))
+		}
+	}
+	for sym := range st.linked {
+		sh.Linked = append(sh.Linked, sym)
+	}
+	sort.Strings(sh.Linked)
+	for _, from := range sortedKeys(st.dependencies) {
+		sh.Dependencies = append(sh.Dependencies, shardDependency{
+			From: toShardKey(from),
+			To:   toShardKeys(st.dependencies[from]),
+		})
+	}
+	if b := st.binaries; b != nil {
+		for name, keys := range b.byBinary {
+			if sh.Binaries == nil {
+				sh.Binaries = map[string][]shardKey{}
+			}
+			sh.Binaries[name] = toShardKeys(keys)
+		}
+		if len(b.shared) > 0 {
+			sh.Shared = toShardKeys(b.shared)
+		}
+	}
+	for _, key := range sortedKeys(st.useds) {
+		uo := st.useds[key]
+		sh.Useds = append(sh.Useds, shardUsed{Key: toShardKey(key), Object: uo.obj, Own: uo.own})
+	}
+	for _, ref := range st.refs {
+		sh.References = append(sh.References, shardReference{Reference: ref.ref, From: toShardKey(ref.from)})
+	}
+	for _, r := range st.routes {
+		sh.Routes = append(sh.Routes, shardRoute{Route: r.route, Key: toShardKey(r.key), Severity: r.cfg.Severity})
+	}
+	for _, c := range st.constants {
+		sh.Constants = append(sh.Constants, shardObject{Key: toShardKey(c.key), Object: c.obj})
+	}
+	for _, p := range st.packages {
+		sh.Packages = append(sh.Packages, shardPackage{
+			Path:      p.path,
+			Dir:       p.dir,
+			ModuleDir: p.moduleDir,
+			File:      p.file,
+			Imports:   p.imports,
+			Root:      p.root,
+			Report:    p.report,
+			Severity:  p.cfg.Severity,
+		})
+	}
+
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f).Encode(sh); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readShard reads a shard written by writeShard and merges it into
+// st. Binary uses only get merged if st records them.
+func readShard(name string, st *unusedState) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var sh shard
+	if err := json.NewDecoder(f).Decode(&sh); err != nil {
+		return fmt.Errorf("couldn't parse shard %s: %s", name, err)
+	}
+	if sh.Version != shardVersion {
+		return fmt.Errorf("shard %s has version %d, want %d", name, sh.Version, shardVersion)
+	}
+
+	for _, key := range sh.Used {
+		st.used[fromShardKey(key)] = true
+	}
+	for _, key := range sh.Unused {
+		if _, ok := st.used[fromShardKey(key)]; !ok {
+			st.used[fromShardKey(key)] = false
+		}
+	}
+	for _, sym := range sh.Linked {
+		st.linked[sym] = true
+	}
+	for _, dep := range sh.Dependencies {
+		from := fromShardKey(dep.From)
+		for _, to := range dep.To {
+			st.dependencies[from] = append(st.dependencies[from], fromShardKey(to))
+		}
+	}
+	if b := st.binaries; b != nil {
+		for name, keys := range sh.Binaries {
+			for _, key := range keys {
+				b.byBinary[name] = append(b.byBinary[name], fromShardKey(key))
+			}
+		}
+		for _, key := range sh.Shared {
+			b.shared = append(b.shared, fromShardKey(key))
+		}
+	}
+	for _, u := range sh.Useds {
+		st.addUsed(fromShardKey(u.Key), u.Object, u.Own)
+	}
+	st.unuseds = append(st.unuseds, fromShardObjects(sh.Objects)...)
+	st.uncovered = append(st.uncovered, fromShardObjects(sh.Uncovered)...)
+	for _, c := range sh.Constants {
+		st.constants = append(st.constants, constantCopy{fromShardKey(c.Key), c.Object})
+	}
+	for _, ref := range sh.References {
+		st.refs = append(st.refs, impactReference{ref: ref.Reference, from: fromShardKey(ref.From)})
+	}
+	for _, r := range sh.Routes {
+		st.routes = append(st.routes, handlerRoute{route: r.Route, key: fromShardKey(r.Key), cfg: config.Config{Severity: r.Severity}})
+	}
+	for _, p := range sh.Packages {
+		st.packages = append(st.packages, importedPackage{
+			path:      p.Path,
+			dir:       p.Dir,
+			moduleDir: p.ModuleDir,
+			file:      p.File,
+			imports:   p.Imports,
+			root:      p.Root,
+			report:    p.Report,
+			cfg:       config.Config{Severity: p.Severity},
+		})
+	}
+	return nil
+}
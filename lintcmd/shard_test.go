@@ -0,0 +1,140 @@
+package lintcmd
+
+import (
+	"bytes"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/go/loader"
+	"honnef.co/go/tools/unused"
+)
+
+func TestShards(t *testing.T) {
+	key := func(pkg string, line int, name string) unusedKey {
+		return unusedKey{pkgPath: pkg, base: "file.go", line: line, name: name}
+	}
+	object := func(k unusedKey) unusedPair {
+		return unusedPair{
+			key: k,
+			obj: unused.SerializedObject{
+				Name:            k.name,
+				Kind:            "func",
+				Position:        token.Position{Filename: "/src/" + k.pkgPath + "/file.go", Line: k.line},
+				DisplayPosition: token.Position{Filename: "/src/" + k.pkgPath + "/file.go", Line: k.line},
+			},
+			cfg: config.Config{Severity: map[string]string{"U1000": config.SeverityWarning}},
+		}
+	}
+	helper, inner, dead := key("a", 3, "helper"), key("a", 7, "inner"), key("b", 5, "dead")
+
+	// Package a doesn't use its helper, which uses inner, but package
+	// b, checked by the other shard, does.
+	a := newUnusedState()
+	a.used[helper] = false
+	a.used[inner] = false
+	a.dependencies[helper] = []unusedKey{inner}
+	a.unuseds = []unusedPair{object(helper), object(inner)}
+	b := newUnusedState()
+	b.used[helper] = true
+	b.used[dead] = false
+	b.unuseds = []unusedPair{object(dead)}
+
+	dir := t.TempDir()
+	st := newUnusedState()
+	for i, shard := range []*unusedState{a, b} {
+		name := filepath.Join(dir, string(rune('a'+i))+".json")
+		if err := writeShard(name, shard); err != nil {
+			t.Fatal(err)
+		}
+		if err := readShard(name, st); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	messages := func(diags []diagnostic) []string {
+		var out []string
+		for _, diag := range diags {
+			out = append(out, diag.Message)
+			if diag.severity != severityWarning {
+				t.Errorf("%s: got severity %v, want a warning", diag.Message, diag.severity)
+			}
+		}
+		sort.Strings(out)
+		return out
+	}
+	if got, want := messages(st.diagnostics(nil)), []string{"func dead is unused"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q for the merged shards, want %q", got, want)
+	}
+	if got, want := messages(a.diagnostics(nil)), []string{"func helper is unused", "func inner is unused"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q for a single shard, want %q", got, want)
+	}
+}
+
+func TestShardsRecordBinariesAndReasons(t *testing.T) {
+	key := func(pkg string, line int, name string) unusedKey {
+		return unusedKey{pkgPath: pkg, base: "file.go", line: line, name: name}
+	}
+	legacy, shared := key("lib", 3, "Legacy"), key("lib", 5, "Shared")
+	legacyPkg := &loader.PackageSpec{ID: "cmd/legacy", PkgPath: "cmd/legacy", Name: "main"}
+	libPkg := &loader.PackageSpec{ID: "lib", PkgPath: "lib", Name: "lib"}
+
+	sh := newUnusedState()
+	sh.binaries = newBinaryUses(nil)
+	for _, k := range []unusedKey{legacy, shared} {
+		sh.used[k] = false
+		sh.unuseds = append(sh.unuseds, unusedPair{key: k, obj: unused.SerializedObject{Name: k.name, Kind: "func"}})
+	}
+	sh.used[key("a", 1, "a")] = true
+	sh.used[key("b", 1, "b")] = true
+	sh.linked["x"] = true
+	sh.linked["y"] = true
+	sh.dependencies[key("a", 1, "a")] = []unusedKey{shared}
+	sh.dependencies[key("b", 1, "b")] = []unusedKey{shared}
+	sh.binaries.use(legacyPkg, legacy)
+	sh.binaries.use(libPkg, shared)
+	sh.addUsed(shared, unused.SerializedObject{Name: "Shared", Reason: "InstructionOperand"}, false)
+
+	// Maps get written in a deterministic order.
+	dir := t.TempDir()
+	var written [][]byte
+	for i := 0; i < 10; i++ {
+		name := filepath.Join(dir, "shard.json")
+		if err := writeShard(name, sh); err != nil {
+			t.Fatal(err)
+		}
+		b, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		written = append(written, b)
+		if !bytes.Equal(b, written[0]) {
+			t.Fatalf("shards of the same state differ:\n%s\n%s", written[0], b)
+		}
+	}
+
+	// The merge only records binary uses if it attributes objects
+	// to binaries.
+	st := newUnusedState()
+	st.binaries = newBinaryUses([]string{"cmd/legacy"})
+	if err := readShard(filepath.Join(dir, "shard.json"), st); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(st.binaries.byBinary, sh.binaries.byBinary) || !reflect.DeepEqual(st.binaries.shared, sh.binaries.shared) {
+		t.Errorf("got binary uses %v and %v, want %v and %v", st.binaries.byBinary, st.binaries.shared, sh.binaries.byBinary, sh.binaries.shared)
+	}
+	if !reflect.DeepEqual(st.useds, sh.useds) {
+		t.Errorf("got used objects %v, want %v", st.useds, sh.useds)
+	}
+	var got []string
+	for _, diag := range st.binaryDiagnostics() {
+		got = append(got, diag.Message)
+	}
+	if want := []string{"func Legacy is only used by cmd/legacy"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,230 @@
+package lintcmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"honnef.co/go/tools/unused"
+)
+
+// snapshotVersion is the version of the format of snapshot files.
+// Comparing snapshots of different versions isn't supported.
+const snapshotVersion = 1
+
+// A snapshot records which objects of a program are unused, and why
+// the used ones are used, as written by -unused.snapshot. Comparing
+// the snapshots of two commits with -unused.compare tells what a
+// change did to the program's unused code.
+type snapshot struct {
+	Version int
+	Objects []snapshotObject
+}
+
+type snapshotObject struct {
+	PkgPath string
+	// ObjectPath is the object's path in its package, which, unlike
+	// its position, doesn't change with unrelated edits. It is empty
+	// for objects that don't have paths.
+	ObjectPath string `json:",omitempty"`
+	Name       string
+	Kind       string
+	Position   token.Position
+	Unused     bool
+	Category   unused.Category `json:",omitempty"`
+	// Reason is why the object is used, see
+	// unused.SerializedObject.Reason.
+	Reason string `json:",omitempty"`
+}
+
+// id identifies the object across snapshots.
+func (o snapshotObject) id() string {
+	if o.ObjectPath != "" {
+		return o.PkgPath + " " + o.ObjectPath
+	}
+	return o.PkgPath + " " + o.Kind + " " + o.Name
+}
+
+func (o snapshotObject) String() string {
+	return fmt.Sprintf("%s: %s %s.%s", o.Position, o.Kind, o.PkgPath, o.Name)
+}
+
+// A usedObject is a used object along with why it is used.
+type usedObject struct {
+	obj unused.SerializedObject
+	// whether the object's own package recorded it, rather than a
+	// package using it in whole-program mode
+	own bool
+}
+
+// addUsed records the reason of a used object, preferring the reason
+// of the object's own package. Of other reasons, the least one wins,
+// so that the order of packages doesn't matter.
+func (st *unusedState) addUsed(key unusedKey, obj unused.SerializedObject, own bool) {
+	if st.useds == nil {
+		st.useds = map[unusedKey]usedObject{}
+	}
+	prev, ok := st.useds[key]
+	if !ok || own && !prev.own || own == prev.own && obj.Reason < prev.obj.Reason {
+		st.useds[key] = usedObject{obj, own}
+	}
+}
+
+// snapshot returns the snapshot of the state, sorted by position. It
+// has to run after propagate.
+func (st *unusedState) snapshot() snapshot {
+	snap := snapshot{Version: snapshotVersion}
+	for _, uo := range st.reported() {
+		snap.Objects = append(snap.Objects, snapshotObject{
+			PkgPath:    uo.key.pkgPath,
+			ObjectPath: uo.obj.ObjectPath,
+			Name:       uo.obj.Name,
+			Kind:       uo.obj.Kind,
+			Position:   uo.obj.DisplayPosition,
+			Unused:     true,
+			Category:   uo.obj.Category,
+		})
+	}
+	for key, uo := range st.useds {
+		if !st.used[key] {
+			continue
+		}
+		snap.Objects = append(snap.Objects, snapshotObject{
+			PkgPath:    key.pkgPath,
+			ObjectPath: uo.obj.ObjectPath,
+			Name:       uo.obj.Name,
+			Kind:       uo.obj.Kind,
+			Position:   uo.obj.DisplayPosition,
+			Reason:     uo.obj.Reason,
+		})
+	}
+	sort.Slice(snap.Objects, func(i, j int) bool {
+		a, b := snap.Objects[i], snap.Objects[j]
+		if a.Position.Filename != b.Position.Filename {
+			return a.Position.Filename < b.Position.Filename
+		}
+		if a.Position.Line != b.Position.Line {
+			return a.Position.Line < b.Position.Line
+		}
+		if a.Position.Column != b.Position.Column {
+			return a.Position.Column < b.Position.Column
+		}
+		return a.id() < b.id()
+	})
+	return snap
+}
+
+// writeSnapshot writes the snapshot of the state to the named file as
+// JSON.
+func writeSnapshot(name string, st *unusedState) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f).Encode(st.snapshot()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readSnapshot reads a snapshot written by writeSnapshot.
+func readSnapshot(name string) (snapshot, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return snapshot{}, err
+	}
+	defer f.Close()
+	var snap snapshot
+	if err := json.NewDecoder(f).Decode(&snap); err != nil {
+		return snapshot{}, fmt.Errorf("couldn't parse snapshot %s: %s", name, err)
+	}
+	if snap.Version != snapshotVersion {
+		return snapshot{}, fmt.Errorf("snapshot %s has version %d, want %d", name, snap.Version, snapshotVersion)
+	}
+	return snap, nil
+}
+
+// A snapshotDiff is the difference between two snapshots.
+type snapshotDiff struct {
+	// Unused are the objects that are unused in the new snapshot, but
+	// were used or didn't exist in the old one.
+	Unused []snapshotObject
+	// Fixed are the objects that were unused in the old snapshot, but
+	// are used or don't exist anymore in the new one. Their positions
+	// are the old ones.
+	Fixed []snapshotObject
+	// Changed are the objects that are used in both snapshots, but
+	// for different reasons.
+	Changed []reasonChange
+}
+
+type reasonChange struct {
+	Object    snapshotObject
+	OldReason string
+}
+
+// compareSnapshots computes the difference between the snapshots
+// before and after, keeping their order.
+func compareSnapshots(before, after snapshot) snapshotDiff {
+	olds := map[string]snapshotObject{}
+	for _, o := range before.Objects {
+		olds[o.id()] = o
+	}
+	news := map[string]snapshotObject{}
+	for _, o := range after.Objects {
+		news[o.id()] = o
+	}
+
+	var diff snapshotDiff
+	for _, o := range after.Objects {
+		prev, ok := olds[o.id()]
+		switch {
+		case o.Unused && (!ok || !prev.Unused):
+			diff.Unused = append(diff.Unused, o)
+		case !o.Unused && ok && !prev.Unused && o.Reason != prev.Reason && o.Reason != "" && prev.Reason != "":
+			diff.Changed = append(diff.Changed, reasonChange{o, prev.Reason})
+		}
+	}
+	for _, o := range before.Objects {
+		if cur, ok := news[o.id()]; o.Unused && (!ok || !cur.Unused) {
+			diff.Fixed = append(diff.Fixed, o)
+		}
+	}
+	return diff
+}
+
+// writeText writes the difference in a form suitable for humans, such
+// as for comments on pull requests.
+func (diff snapshotDiff) writeText(w io.Writer) {
+	plural := func(n int, s string) string {
+		if n == 1 {
+			return fmt.Sprintf("%d %s", n, s)
+		}
+		return fmt.Sprintf("%d %ss", n, s)
+	}
+	fmt.Fprintf(w, "%s, %s, %s used for different reasons\n",
+		plural(len(diff.Unused), "newly unused object"), plural(len(diff.Fixed), "fixed unused object"), plural(len(diff.Changed), "object"))
+	if len(diff.Unused) > 0 {
+		fmt.Fprintln(w, "\nnewly unused:")
+		for _, o := range diff.Unused {
+			fmt.Fprintf(w, "\t%s\n", o)
+		}
+	}
+	if len(diff.Fixed) > 0 {
+		fmt.Fprintln(w, "\nfixed:")
+		for _, o := range diff.Fixed {
+			fmt.Fprintf(w, "\t%s\n", o)
+		}
+	}
+	if len(diff.Changed) > 0 {
+		fmt.Fprintln(w, "\nused for different reasons:")
+		for _, c := range diff.Changed {
+			fmt.Fprintf(w, "\t%s: %s (was %s)\n", c.Object, strings.ReplaceAll(c.Object.Reason, ",", ", "), strings.ReplaceAll(c.OldReason, ",", ", "))
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package lintcmd
+
+import (
+	"bytes"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"honnef.co/go/tools/unused"
+)
+
+func TestSnapshots(t *testing.T) {
+	key := func(line int, name string) unusedKey {
+		return unusedKey{pkgPath: "a", base: "file.go", line: line, name: name}
+	}
+	object := func(k unusedKey, reason string) unused.SerializedObject {
+		return unused.SerializedObject{
+			Name:            k.name,
+			PkgPath:         k.pkgPath,
+			ObjectPath:      k.name,
+			Kind:            "func",
+			DisplayPosition: token.Position{Filename: "/src/a/file.go", Line: k.line},
+			Reason:          reason,
+		}
+	}
+
+	// Before, render is called and helper is unused. After, render is
+	// only used via an interface, helper got deleted and legacy and
+	// its caller became unused.
+	render, helper, legacy, caller := key(3, "render"), key(9, "helper"), key(12, "legacy"), key(15, "caller")
+	before := newUnusedState()
+	before.used[render] = true
+	before.used[legacy] = true
+	before.used[helper] = false
+	before.addUsed(render, object(render, "InstructionOperand"), true)
+	before.addUsed(legacy, object(legacy, "ExportedFunction"), true)
+	// A package using render in whole-program mode doesn't get a say.
+	before.addUsed(render, object(render, "FunctionArgument"), false)
+	before.unuseds = []unusedPair{{key: helper, obj: object(helper, "")}}
+
+	after := newUnusedState()
+	after.used[render] = true
+	after.used[legacy] = false
+	after.used[caller] = false
+	after.addUsed(render, object(render, "Implements"), true)
+	after.unuseds = []unusedPair{{key: legacy, obj: object(legacy, "")}, {key: caller, obj: object(caller, "")}}
+
+	dir := t.TempDir()
+	var snaps []snapshot
+	for i, st := range []*unusedState{before, after} {
+		name := filepath.Join(dir, string(rune('a'+i))+".json")
+		st.propagate()
+		if err := writeSnapshot(name, st); err != nil {
+			t.Fatal(err)
+		}
+		snap, err := readSnapshot(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		snaps = append(snaps, snap)
+	}
+
+	diff := compareSnapshots(snaps[0], snaps[1])
+	names := func(objs []snapshotObject) []string {
+		var out []string
+		for _, o := range objs {
+			out = append(out, o.Name)
+		}
+		return out
+	}
+	if got, want := names(diff.Unused), []string{"legacy", "caller"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got newly unused objects %v, want %v", got, want)
+	}
+	if got, want := names(diff.Fixed), []string{"helper"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got fixed objects %v, want %v", got, want)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].Object.Name != "render" || diff.Changed[0].OldReason != "InstructionOperand" || diff.Changed[0].Object.Reason != "Implements" {
+		t.Errorf("got changed reasons %v, want render changing from InstructionOperand to Implements", diff.Changed)
+	}
+
+	var buf bytes.Buffer
+	diff.writeText(&buf)
+	if got, want := strings.SplitN(buf.String(), "\n", 2)[0], "2 newly unused objects, 1 fixed unused object, 1 object used for different reasons"; got != want {
+		t.Errorf("got summary %q, want %q", got, want)
+	}
+}
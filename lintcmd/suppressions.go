@@ -0,0 +1,166 @@
+package lintcmd
+
+import (
+	"encoding/json"
+	"go/token"
+	"os"
+	"sort"
+	"strings"
+
+	"honnef.co/go/tools/lintcmd/runner"
+	"honnef.co/go/tools/unused"
+)
+
+// A suppression is an ignore directive that matched problems or, for
+// U1000, objects that it keeps alive.
+type suppression struct {
+	directive runner.SerializedDirective
+	problems  []diagnostic
+	objects   []suppressedObject
+}
+
+type suppressedObject struct {
+	obj unused.SerializedObject
+	// whether the object would be unused without the directive
+	keptAlive bool
+}
+
+// unusedSuppressions returns the ignore directives for U1000 that
+// apply to objects, along with these objects.
+func unusedSuppressions(res runner.ResultData) []suppression {
+	if len(res.Unused.Ignored) == 0 {
+		return nil
+	}
+	byPos := map[token.Position]int{}
+	var out []suppression
+	for _, ig := range res.Unused.Ignored {
+		idx, ok := byPos[ig.Directive]
+		if !ok {
+			var dir runner.SerializedDirective
+			for _, d := range res.Directives {
+				if d.DirectivePosition == ig.Directive {
+					dir = d
+					break
+				}
+			}
+			idx = len(out)
+			byPos[ig.Directive] = idx
+			out = append(out, suppression{directive: dir})
+		}
+		out[idx].objects = append(out[idx].objects, suppressedObject{ig.Object, ig.KeptAlive})
+	}
+	return out
+}
+
+// mergeSuppressions merges the suppressions of the same directives,
+// which are reported once per package variant and build
+// configuration. An object only counts as kept alive if it is in all
+// of them.
+func mergeSuppressions(sups []suppression) []suppression {
+	type objectKey struct {
+		name string
+		pos  token.Position
+	}
+	byPos := map[token.Position]*suppression{}
+	problems := map[token.Position]map[string]bool{}
+	objects := map[token.Position]map[objectKey]int{}
+	var out []*suppression
+	for _, sup := range sups {
+		pos := sup.directive.DirectivePosition
+		m, ok := byPos[pos]
+		if !ok {
+			m = &suppression{directive: sup.directive}
+			byPos[pos] = m
+			problems[pos] = map[string]bool{}
+			objects[pos] = map[objectKey]int{}
+			out = append(out, m)
+		}
+		for _, p := range sup.problems {
+			key := relativePositionString(p.Position) + "\x00" + p.Category + "\x00" + p.Message
+			if !problems[pos][key] {
+				problems[pos][key] = true
+				m.problems = append(m.problems, p)
+			}
+		}
+		for _, obj := range sup.objects {
+			key := objectKey{obj.obj.Name, obj.obj.DisplayPosition}
+			if idx, ok := objects[pos][key]; ok {
+				m.objects[idx].keptAlive = m.objects[idx].keptAlive && obj.keptAlive
+			} else {
+				objects[pos][key] = len(m.objects)
+				m.objects = append(m.objects, obj)
+			}
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		pi, pj := out[i].directive.DirectivePosition, out[j].directive.DirectivePosition
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		return pi.Offset < pj.Offset
+	})
+	res := make([]suppression, len(out))
+	for i, sup := range out {
+		res[i] = *sup
+	}
+	return res
+}
+
+// writeSuppressions writes a JSON report of the suppressions to the
+// named file.
+func writeSuppressions(name string, sups []suppression) error {
+	type location struct {
+		File   string `json:"file"`
+		Line   int    `json:"line"`
+		Column int    `json:"column"`
+	}
+	type problem struct {
+		Code     string   `json:"code"`
+		Location location `json:"location"`
+		Message  string   `json:"message"`
+	}
+	type object struct {
+		Name      string   `json:"name"`
+		Kind      string   `json:"kind"`
+		Location  location `json:"location"`
+		KeptAlive bool     `json:"kept_alive"`
+	}
+	type entry struct {
+		Directive location  `json:"directive"`
+		Command   string    `json:"command"`
+		Checks    []string  `json:"checks"`
+		Reason    string    `json:"reason"`
+		Problems  []problem `json:"problems,omitempty"`
+		Objects   []object  `json:"objects,omitempty"`
+	}
+	loc := func(pos token.Position) location {
+		return location{pos.Filename, pos.Line, pos.Column}
+	}
+
+	entries := make([]entry, 0, len(sups))
+	for _, sup := range sups {
+		dir := sup.directive
+		e := entry{
+			Directive: loc(dir.DirectivePosition),
+			Command:   dir.Command,
+		}
+		if len(dir.Arguments) > 0 {
+			e.Checks = strings.Split(dir.Arguments[0], ",")
+			e.Reason = directiveReason(dir)
+		}
+		for _, p := range sup.problems {
+			e.Problems = append(e.Problems, problem{p.Category, loc(p.Position), p.Message})
+		}
+		for _, obj := range sup.objects {
+			e.Objects = append(e.Objects, object{obj.obj.Name, obj.obj.Kind, loc(obj.obj.DisplayPosition), obj.keptAlive})
+		}
+		entries = append(entries, e)
+	}
+
+	data, err := json.MarshalIndent(entries, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, append(data, '\n'), 0666)
+}
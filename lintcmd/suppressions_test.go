@@ -0,0 +1,96 @@
+package lintcmd
+
+import (
+	"encoding/json"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"honnef.co/go/tools/lintcmd/runner"
+	"honnef.co/go/tools/unused"
+)
+
+func TestSuppressions(t *testing.T) {
+	dir := runner.SerializedDirective{
+		Command:           "ignore",
+		Arguments:         []string{"U1000,SA4006", "kept", "for", "later"},
+		DirectivePosition: token.Position{Filename: "/pkg/a.go", Offset: 10, Line: 2, Column: 1},
+	}
+	other := runner.SerializedDirective{
+		Command:           "file-ignore",
+		Arguments:         []string{"ST1000", "generated"},
+		DirectivePosition: token.Position{Filename: "/pkg/a.go", Offset: 0, Line: 1, Column: 1},
+	}
+	problem := diagnostic{
+		Diagnostic: runner.Diagnostic{
+			Position: token.Position{Filename: "/pkg/a.go", Line: 3, Column: 2},
+			Category: "SA4006",
+			Message:  "this value of x is never used",
+		},
+	}
+	obj := func(keptAlive bool) suppressedObject {
+		return suppressedObject{
+			obj: unused.SerializedObject{
+				Name:            "fn",
+				Kind:            "func",
+				DisplayPosition: token.Position{Filename: "/pkg/a.go", Line: 3, Column: 6},
+			},
+			keptAlive: keptAlive,
+		}
+	}
+
+	// The same directive, as seen by two package variants. Only one
+	// of them needs the directive to keep fn alive.
+	sups := mergeSuppressions([]suppression{
+		{directive: dir, problems: []diagnostic{problem}, objects: []suppressedObject{obj(true)}},
+		{directive: other, problems: []diagnostic{problem}},
+		{directive: dir, problems: []diagnostic{problem}, objects: []suppressedObject{obj(false)}},
+	})
+
+	path := filepath.Join(t.TempDir(), "suppressions.json")
+	if err := writeSuppressions(path, sups); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report []struct {
+		Directive struct{ Line int }
+		Command   string
+		Checks    []string
+		Reason    string
+		Problems  []struct{ Code string }
+		Objects   []struct {
+			Name      string
+			KeptAlive bool `json:"kept_alive"`
+		}
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report) != 2 {
+		t.Fatalf("got %d directives, want 2", len(report))
+	}
+	if report[0].Command != "file-ignore" || report[1].Command != "ignore" {
+		t.Errorf("directives aren't sorted by position: %s, %s", report[0].Command, report[1].Command)
+	}
+	e := report[1]
+	if e.Reason != "kept for later" {
+		t.Errorf("got reason %q, want %q", e.Reason, "kept for later")
+	}
+	if len(e.Checks) != 2 || e.Checks[0] != "U1000" || e.Checks[1] != "SA4006" {
+		t.Errorf("got checks %v, want [U1000 SA4006]", e.Checks)
+	}
+	if len(e.Problems) != 1 || e.Problems[0].Code != "SA4006" {
+		t.Errorf("got problems %v, want a single SA4006", e.Problems)
+	}
+	if len(e.Objects) != 1 || e.Objects[0].Name != "fn" {
+		t.Fatalf("got objects %v, want fn", e.Objects)
+	}
+	if e.Objects[0].KeptAlive {
+		t.Errorf("fn is used in one variant and shouldn't be kept alive")
+	}
+}
@@ -0,0 +1,90 @@
+package lintcmd
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"honnef.co/go/tools/config"
+)
+
+// A reportWindow restricts the reported problems to parts of the
+// code, for cleaning up one subsystem at a time. It is applied after
+// the analysis, so that facts and the uses of objects still flow
+// between all packages.
+//
+// Patterns are matched against the package path of a problem, the
+// path of its file and its symbol, the full name of the declaration
+// containing it, such as example.com/pkg.Func or
+// (*example.com/pkg.T).Method. Patterns use the syntax of path.Match.
+// A pattern ending in /... also matches the packages and files below
+// the path before it. File paths are matched relative to the current
+// directory and as absolute paths, with forward slashes.
+type reportWindow struct {
+	// If not empty, only problems matching one of these patterns are
+	// reported.
+	include []string
+	// Problems matching one of these patterns are never reported.
+	exclude []string
+	// the directory that relative file paths are relative to
+	cwd string
+}
+
+// contains reports whether the window lets diag be reported.
+func (w reportWindow) contains(diag diagnostic) bool {
+	if len(w.include) > 0 && !w.matchesAny(w.include, diag) {
+		return false
+	}
+	return !w.matchesAny(w.exclude, diag)
+}
+
+func (w reportWindow) matchesAny(patterns []string, diag diagnostic) bool {
+	var files []string
+	if name := diag.Position.Filename; name != "" {
+		abs := name
+		if !filepath.IsAbs(abs) && w.cwd != "" {
+			abs = filepath.Join(w.cwd, abs)
+		}
+		files = append(files, filepath.ToSlash(abs))
+		if w.cwd != "" {
+			if rel, err := filepath.Rel(w.cwd, abs); err == nil {
+				// Only files inside of the current directory have
+				// relative paths, but their names may start with
+				// two dots, like ..file.go.
+				if rel := filepath.ToSlash(rel); rel != ".." && !strings.HasPrefix(rel, "../") {
+					files = append(files, rel)
+				}
+			}
+		}
+	}
+	for _, pattern := range patterns {
+		if diag.Package != "" && config.MatchTree(pattern, diag.Package) {
+			return true
+		}
+		for _, file := range files {
+			if config.MatchTree(pattern, file) {
+				return true
+			}
+		}
+		if diag.Symbol != "" {
+			if ok, _ := path.Match(pattern, diag.Symbol); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// filter returns the diagnostics that the window lets be reported.
+func (w reportWindow) filter(diags []diagnostic) []diagnostic {
+	if len(w.include) == 0 && len(w.exclude) == 0 {
+		return diags
+	}
+	out := diags[:0]
+	for _, diag := range diags {
+		if w.contains(diag) {
+			out = append(out, diag)
+		}
+	}
+	return out
+}
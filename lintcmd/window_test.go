@@ -0,0 +1,77 @@
+package lintcmd
+
+import (
+	"go/token"
+	"reflect"
+	"testing"
+
+	"honnef.co/go/tools/lintcmd/runner"
+	"honnef.co/go/tools/unused"
+)
+
+func TestReportWindow(t *testing.T) {
+	diag := func(file, pkg, symbol string) diagnostic {
+		return diagnostic{Diagnostic: runner.Diagnostic{
+			Position: token.Position{Filename: file, Line: 1},
+			Message:  file,
+			Package:  pkg,
+			Symbol:   symbol,
+		}}
+	}
+	diags := []diagnostic{
+		diag("/repo/storage/disk.go", "example.com/storage", "example.com/storage.Open"),
+		diag("/repo/storage/cache/lru.go", "example.com/storage/cache", "(*example.com/storage/cache.LRU).Evict"),
+		diag("/repo/api/handler.go", "example.com/api", "example.com/api.Serve"),
+		diag("/repo/api/handler_gen.go", "example.com/api", ""),
+		diag("/repo/api/routes.go", "example.com/api", "(*example.com/api.Router).legacy"),
+		diag("/repo/..gen.go", "example.com", ""),
+		diag("/vendor/gen.go", "example.org", ""),
+	}
+	tests := []struct {
+		include, exclude []string
+		want             []string
+	}{
+		{nil, nil, []string{"/repo/storage/disk.go", "/repo/storage/cache/lru.go", "/repo/api/handler.go", "/repo/api/handler_gen.go", "/repo/api/routes.go", "/repo/..gen.go", "/vendor/gen.go"}},
+		// package paths, with and without their subtrees
+		{[]string{"example.com/storage"}, nil, []string{"/repo/storage/disk.go"}},
+		{[]string{"example.com/storage/..."}, nil, []string{"/repo/storage/disk.go", "/repo/storage/cache/lru.go"}},
+		// files, relative to the current directory and absolute
+		{[]string{"api/*_gen.go"}, nil, []string{"/repo/api/handler_gen.go"}},
+		{[]string{"/repo/storage/..."}, []string{"storage/cache/..."}, []string{"/repo/storage/disk.go"}},
+		// files outside of the current directory have no relative
+		// paths, unlike files whose names start with two dots
+		{[]string{"..*.go"}, nil, []string{"/repo/..gen.go"}},
+		{[]string{"../vendor/*.go"}, nil, nil},
+		// symbols
+		{[]string{"example.com/api.*"}, nil, []string{"/repo/api/handler.go"}},
+		{nil, []string{"example.com/storage/...", "(*example.com/api.Router).*"}, []string{"/repo/api/handler.go", "/repo/api/handler_gen.go", "/repo/..gen.go", "/vendor/gen.go"}},
+		// exclusions win over inclusions
+		{[]string{"example.com/api"}, []string{"api/handler*.go"}, []string{"/repo/api/routes.go"}},
+	}
+	for _, tt := range tests {
+		w := reportWindow{include: tt.include, exclude: tt.exclude, cwd: "/repo"}
+		var got []string
+		for _, diag := range w.filter(append([]diagnostic(nil), diags...)) {
+			got = append(got, diag.Message)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("-report %v -no-report %v: got %v, want %v", tt.include, tt.exclude, got, tt.want)
+		}
+	}
+}
+
+func TestUnusedSymbol(t *testing.T) {
+	for _, tt := range []struct {
+		obj  unused.SerializedObject
+		want string
+	}{
+		{unused.SerializedObject{Name: "fn", Kind: "func"}, "example.com/pkg.fn"},
+		{unused.SerializedObject{Name: "(*T).m", Kind: "func"}, "(*example.com/pkg.T).m"},
+		{unused.SerializedObject{Name: "T.m", Kind: "func"}, "(example.com/pkg.T).m"},
+		{unused.SerializedObject{Name: "f", Kind: "field"}, ""},
+	} {
+		if got := unusedSymbol("example.com/pkg", tt.obj); got != tt.want {
+			t.Errorf("got symbol %q for %s %s, want %q", got, tt.obj.Kind, tt.obj.Name, tt.want)
+		}
+	}
+}
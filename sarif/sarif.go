@@ -82,6 +82,12 @@ type Result struct {
 	RelatedLocations []Location    `json:"relatedLocations,omitempty"`
 	Fixes            []Fix         `json:"fixes,omitempty"`
 	Suppressions     []Suppression `json:"suppressions"`
+	// PartialFingerprints identify results across runs, independently
+	// of their locations.
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	// Properties holds additional data about the result, such as
+	// the size and complexity of unused functions.
+	Properties map[string]interface{} `json:"properties,omitempty"`
 }
 
 type Suppression struct {
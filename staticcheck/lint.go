@@ -2251,6 +2251,10 @@ func CheckIneffectiveLoop(pass *analysis.Pass) (interface{}, error) {
 						// looping once over a map is a valid pattern for
 						// getting an arbitrary element.
 						return false
+					case *types.Signature:
+						// so is looping once over an iterator
+						// function for getting its first element.
+						return false
 					default:
 						lint.ExhaustiveTypeSwitch(term.Type().Underlying())
 						return false
@@ -0,0 +1,46 @@
+package unused
+
+import (
+	"go/types"
+	"sort"
+
+	"honnef.co/go/tools/go/types/typeutil"
+)
+
+// groupAliases marks the unused package-level aliases of unused named
+// types of the package as quiet and returns them, keyed by the types
+// they're aliases of and sorted by position. Deleting a type means
+// deleting its aliases, too, so we report them as part of the type
+// instead of as findings of their own. Aliases of instances of generic
+// types belong to the generic types.
+func (g *graph) groupAliases() map[*types.TypeName][]*types.TypeName {
+	var out map[*types.TypeName][]*types.TypeName
+	for _, n := range g.Nodes {
+		alias, ok := n.Obj.(*types.TypeName)
+		if !ok || n.Seen || !alias.IsAlias() || alias.Pkg() != g.pkg.Pkg || alias.Parent() != g.pkg.Pkg.Scope() {
+			continue
+		}
+		named, ok := typeutil.Unalias(alias.Type()).(*types.Named)
+		if !ok {
+			continue
+		}
+		tname := named.Origin().Obj()
+		if tname.Pkg() != g.pkg.Pkg || tname.Parent() != g.pkg.Pkg.Scope() {
+			continue
+		}
+		if tn, ok := g.Lookup(tname); !ok || tn.Seen {
+			continue
+		}
+		if out == nil {
+			out = map[*types.TypeName][]*types.TypeName{}
+		}
+		out[tname] = append(out[tname], alias)
+		g.quiet[n] = true
+	}
+	for _, aliases := range out {
+		sort.Slice(aliases, func(i, j int) bool {
+			return aliases[i].Pos() < aliases[j].Pos()
+		})
+	}
+	return out
+}
@@ -0,0 +1,65 @@
+package unused
+
+import (
+	"go/types"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestGroupAliases(t *testing.T) {
+	const src = `package pkg
+
+type t1 struct{}
+
+type a1 = t1
+type a2 = a1
+
+type t2 struct{}
+
+type a3 = t2
+
+type t3[T any] struct{}
+
+type a4 = t3[int]
+
+type a5 = *t1
+
+func Fn(t2) {}
+`
+	p := buildPackage(t, src)
+	g := newGraph()
+	g.entry(p)
+	_, unused, quiet := results(g)
+
+	names := func(objs []types.Object) string {
+		var out []string
+		for _, obj := range objs {
+			if _, ok := obj.Type().(*types.TypeParam); ok {
+				continue
+			}
+			out = append(out, obj.Name())
+		}
+		sort.Strings(out)
+		return strings.Join(out, " ")
+	}
+	// The alias of a pointer to t1 doesn't have to go when t1 does.
+	if got, want := names(unused), "a5 t1 t3"; got != want {
+		t.Errorf("got unused objects %s, want %s", got, want)
+	}
+	if got, want := names(quiet), "a1 a2 a4"; got != want {
+		t.Errorf("got quiet objects %s, want %s", got, want)
+	}
+
+	grouped := map[string]string{}
+	for tname, aliases := range g.aliases {
+		var objs []types.Object
+		for _, alias := range aliases {
+			objs = append(objs, alias)
+		}
+		grouped[tname.Name()] = names(objs)
+	}
+	if len(grouped) != 2 || grouped["t1"] != "a1 a2" || grouped["t3"] != "a4" {
+		t.Errorf("got aliases %v, want a1 and a2 for t1 and a4 for t3", grouped)
+	}
+}
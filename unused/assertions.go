@@ -0,0 +1,176 @@
+package unused
+
+import (
+	"go/ast"
+	"go/types"
+	"sort"
+
+	"honnef.co/go/tools/analysis/facts/ownership"
+	"honnef.co/go/tools/go/ast/astutil"
+	"honnef.co/go/tools/go/ir"
+	"honnef.co/go/tools/go/types/typeutil"
+	"honnef.co/go/tools/unused/refgraph"
+)
+
+// An assertion is a package-level declaration of a blank variable
+// that asserts at compile time that a type of the package implements
+// an interface, such as
+//
+//	var _ io.Reader = (*T)(nil)
+type assertion struct {
+	decl ownership.Decl
+}
+
+// isAssertion reports whether spec is an interface assertion: a
+// single blank variable of an interface type, whose value is a zero
+// value of a named type of the package, written as (*T)(nil), T{},
+// &T{} or new(T).
+func (g *graph) isAssertion(spec *ast.ValueSpec) bool {
+	if spec.Type == nil || len(spec.Names) != 1 || spec.Names[0].Name != "_" || len(spec.Values) != 1 {
+		return false
+	}
+	if !types.IsInterface(g.pkg.TypesInfo.TypeOf(spec.Type)) {
+		return false
+	}
+	named, ok := typeutil.Dereference(g.pkg.TypesInfo.TypeOf(spec.Values[0])).(*types.Named)
+	if !ok || named.Obj().Pkg() != g.pkg.Pkg {
+		return false
+	}
+
+	isZero := func(expr ast.Expr) bool {
+		lit, ok := expr.(*ast.CompositeLit)
+		return ok && len(lit.Elts) == 0
+	}
+	switch expr := astutil.Unparen(spec.Values[0]).(type) {
+	case *ast.CompositeLit:
+		return isZero(expr)
+	case *ast.UnaryExpr:
+		return isZero(expr.X)
+	case *ast.CallExpr:
+		if len(expr.Args) != 1 {
+			return false
+		}
+		if g.pkg.TypesInfo.Types[expr.Fun].IsType() {
+			// (*T)(nil)
+			tv := g.pkg.TypesInfo.Types[expr.Args[0]]
+			return tv.IsNil()
+		}
+		if ident, ok := astutil.Unparen(expr.Fun).(*ast.Ident); ok {
+			_, ok := g.pkg.TypesInfo.Uses[ident].(*types.Builtin)
+			return ok && ident.Name == "new"
+		}
+	}
+	return false
+}
+
+// assertion adds spec, which declares an interface assertion, to the
+// graph. The assertion uses the interface and the asserted type.
+func (g *graph) assertion(f *ast.File, gen *ast.GenDecl, spec *ast.ValueSpec) {
+	a := &assertion{decl: ownership.Decl{File: f, Decl: gen, Spec: spec}}
+	g.see(a)
+	// (1.15) packages use interface assertions
+	g.use(a, nil, refgraph.EdgeInterfaceAssertion)
+	for _, T := range []types.Type{g.pkg.TypesInfo.TypeOf(spec.Type), g.pkg.TypesInfo.TypeOf(spec.Values[0])} {
+		g.seeAndUse(T, a, refgraph.EdgeInterfaceAssertion)
+		g.typ(T, nil)
+	}
+	g.assertions = append(g.assertions, a)
+}
+
+// assertionInstrs returns the instructions of the package initializer
+// fn that only compute the values of interface assertions. Their uses
+// are the assertions' uses, which assertion already added.
+func (g *graph) assertionInstrs(fn *ir.Function) map[ir.Instruction]bool {
+	if len(g.assertions) == 0 {
+		return nil
+	}
+	values := map[ir.Value]bool{}
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			ref, ok := instr.(*ir.DebugRef)
+			if !ok {
+				continue
+			}
+			for _, a := range g.assertions {
+				v := a.decl.Spec.(*ast.ValueSpec).Values[0]
+				if ref.Expr.Pos() >= v.Pos() && ref.Expr.End() <= v.End() {
+					values[ref.X] = true
+				}
+			}
+		}
+	}
+
+	skip := map[ir.Instruction]bool{}
+	var queue []ir.Instruction
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			if store, ok := instr.(*ir.BlankStore); ok && values[store.Val] {
+				skip[instr] = true
+				queue = append(queue, instr)
+			}
+		}
+	}
+	// Skip the instructions whose values only flow into skipped
+	// instructions, such as the allocations of &T{}.
+	for len(queue) > 0 {
+		instr := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		for _, op := range instr.Operands(nil) {
+			v, ok := (*op).(ir.Instruction)
+			if !ok || skip[v] {
+				continue
+			}
+			only := true
+			for _, ref := range *(*op).Referrers() {
+				if _, ok := ref.(*ir.DebugRef); !ok && !skip[ref] {
+					only = false
+					break
+				}
+			}
+			if only {
+				skip[v] = true
+				queue = append(queue, v)
+			}
+		}
+	}
+	return skip
+}
+
+// assertedOnly finds the unused objects that interface assertions use,
+// directly or indirectly, and returns these assertions for each such
+// object. It has to run after results, with the assertions themselves
+// colored, so that the objects that only they use are left unseen.
+func (g *graph) assertedOnly(unused []types.Object) map[types.Object][]*assertion {
+	isUnused := map[types.Object]bool{}
+	for _, obj := range unused {
+		isUnused[obj] = true
+	}
+	out := map[types.Object][]*assertion{}
+	for _, a := range g.assertions {
+		n, ok := g.Lookup(a)
+		if !ok {
+			continue
+		}
+		seen := map[*refgraph.Node]bool{}
+		var walk func(n *refgraph.Node)
+		walk = func(n *refgraph.Node) {
+			for _, e := range n.Uses {
+				if e.Node.Seen || seen[e.Node] {
+					continue
+				}
+				seen[e.Node] = true
+				if obj, ok := e.Node.Obj.(types.Object); ok && isUnused[obj] {
+					out[obj] = append(out[obj], a)
+				}
+				walk(e.Node)
+			}
+		}
+		walk(n)
+	}
+	for _, as := range out {
+		sort.Slice(as, func(i, j int) bool {
+			return as[i].decl.Spec.Pos() < as[j].decl.Spec.Pos()
+		})
+	}
+	return out
+}
@@ -0,0 +1,53 @@
+package unused
+
+import (
+	"go/token"
+	"go/types"
+
+	"honnef.co/go/tools/analysis/facts/generated"
+)
+
+// Packages that use cgo consist of files that cmd/cgo rewrote and of
+// files that it synthesized, such as _cgo_gotypes.go. All of them are
+// marked as generated by cgo. The rewritten files use line directives
+// to map back to the user's code, however, and objects declared in
+// them are the user's objects, not generated ones. The synthesized
+// files declare objects such as _Cfunc_free and _Ctype_int, which
+// must never be reported.
+
+// generator returns the generator of the file containing pos. Unlike
+// code.Generator, it doesn't consider code that cgo copied from the
+// user's files to be generated.
+func generator(fset *token.FileSet, m map[string]generated.Generator, pos token.Pos) (generated.Generator, bool) {
+	raw := fset.PositionFor(pos, false)
+	gen, ok := m[raw.Filename]
+	if ok && gen == generated.Cgo && fset.PositionFor(pos, true).Filename != raw.Filename {
+		return 0, false
+	}
+	return gen, ok
+}
+
+// isGenerated reports whether obj is declared in generated code.
+func (g *graph) isGenerated(obj types.Object) bool {
+	_, ok := generator(g.pkg.Fset, g.pkg.Generated, obj.Pos())
+	return ok
+}
+
+// isCgoSynthesized reports whether obj was synthesized by cgo.
+func (g *graph) isCgoSynthesized(obj types.Object) bool {
+	gen, ok := generator(g.pkg.Fset, g.pkg.Generated, obj.Pos())
+	return ok && gen == generated.Cgo
+}
+
+// filterCgo removes objects synthesized by cgo from objs.
+func (g *graph) filterCgo(objs []types.Object) []types.Object {
+	out := objs[:0]
+	for _, obj := range objs {
+		if g.isCgoSynthesized(obj) {
+			g.skip(obj, "object was synthesized by cgo")
+		} else {
+			out = append(out, obj)
+		}
+	}
+	return out
+}
@@ -0,0 +1,126 @@
+package unused
+
+import (
+	"go/ast"
+	"go/types"
+	"sort"
+
+	"honnef.co/go/tools/unused/refgraph"
+)
+
+// A Cluster is a maximal set of unused objects that refer to each
+// other, directly or via other unused objects. Nothing that is used
+// refers to a cluster, so each cluster can be deleted as a whole,
+// which makes clusters the natural chunks of cleanup work.
+type Cluster struct {
+	// Objects are the package-level objects and methods of the
+	// cluster, sorted by position. Fields, parameters and the like
+	// belong to the declarations of these objects.
+	Objects []types.Object
+	// Decls is the number of declarations of the objects. Objects
+	// declared together, such as in var a, b = 1, 2, share one.
+	Decls int
+	// Lines is the number of lines of the declarations, including
+	// their doc comments.
+	Lines int
+}
+
+// clusters groups the unused objects into clusters, sorted by size,
+// largest first. It has to run after results.
+func (g *graph) clusters(unused []types.Object) []Cluster {
+	if len(unused) == 0 {
+		return nil
+	}
+	// Edges between unseen nodes, in both directions, as it doesn't
+	// matter which object refers to which.
+	adj := map[*refgraph.Node][]*refgraph.Node{}
+	link := func(n *refgraph.Node) {
+		if n.Seen {
+			return
+		}
+		for _, e := range n.Uses {
+			if !e.Node.Seen {
+				adj[n] = append(adj[n], e.Node)
+				adj[e.Node] = append(adj[e.Node], n)
+			}
+		}
+	}
+	for _, n := range g.Nodes {
+		link(n)
+	}
+	for _, n := range g.TypeNodes {
+		link(n)
+	}
+
+	visited := map[*refgraph.Node]bool{}
+	var out []Cluster
+	for _, obj := range unused {
+		n, ok := g.Lookup(obj)
+		if !ok || visited[n] {
+			continue
+		}
+		var objs []types.Object
+		visited[n] = true
+		queue := []*refgraph.Node{n}
+		for len(queue) > 0 {
+			n := queue[0]
+			queue = queue[1:]
+			if obj, ok := n.Obj.(types.Object); ok && obj.Pkg() == g.pkg.Pkg {
+				objs = append(objs, obj)
+			}
+			for _, m := range adj[n] {
+				if !visited[m] {
+					visited[m] = true
+					queue = append(queue, m)
+				}
+			}
+		}
+		if c, ok := g.cluster(objs); ok {
+			out = append(out, c)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Lines != b.Lines {
+			return a.Lines > b.Lines
+		}
+		if a.Decls != b.Decls {
+			return a.Decls > b.Decls
+		}
+		return a.Objects[0].Pos() < b.Objects[0].Pos()
+	})
+	return out
+}
+
+// cluster computes the cluster of the objects of a connected part of
+// the graph. It reports false if none of the objects have
+// declarations of their own.
+func (g *graph) cluster(objs []types.Object) (Cluster, bool) {
+	var c Cluster
+	decls := map[ast.Node]bool{}
+	for _, obj := range objs {
+		d, ok := g.pkg.Ownership.Decl(obj)
+		if !ok || !declares(d, obj) {
+			continue
+		}
+		c.Objects = append(c.Objects, obj)
+		node := d.Node()
+		if decls[node] {
+			continue
+		}
+		decls[node] = true
+		c.Decls++
+		start := node.Pos()
+		if doc := declDoc(d); doc != nil {
+			start = doc.Pos()
+		}
+		c.Lines += g.pkg.Fset.PositionFor(node.End(), false).Line - g.pkg.Fset.PositionFor(start, false).Line + 1
+	}
+	if len(c.Objects) == 0 {
+		return Cluster{}, false
+	}
+	sort.Slice(c.Objects, func(i, j int) bool {
+		return c.Objects[i].Pos() < c.Objects[j].Pos()
+	})
+	return c, true
+}
@@ -0,0 +1,48 @@
+package unused
+
+import (
+	"go/types"
+)
+
+// deadConstraints returns the underlying interfaces of the
+// package-level interface types that can only be used as constraints,
+// because their type sets aren't method sets, and that nothing refers
+// to, not even other constraints. No type argument can ever get
+// checked against such interfaces, so types don't need the methods
+// that would implement them (8.6).
+func (g *graph) deadConstraints() map[*types.Interface]bool {
+	if g.constraints != nil {
+		return g.constraints
+	}
+	g.constraints = map[*types.Interface]bool{}
+	var candidates []*types.TypeName
+	scope := g.pkg.Pkg.Scope()
+	for _, name := range scope.Names() {
+		obj, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || obj.IsAlias() {
+			continue
+		}
+		iface, ok := obj.Type().Underlying().(*types.Interface)
+		if !ok || iface.IsMethodSet() {
+			continue
+		}
+		if obj.Exported() && g.exportedIsUsed(obj) || g.isKept(obj) {
+			// Other packages may use the constraint.
+			continue
+		}
+		candidates = append(candidates, obj)
+	}
+	if len(candidates) == 0 {
+		return g.constraints
+	}
+	referenced := map[types.Object]bool{}
+	for _, obj := range g.pkg.TypesInfo.Uses {
+		referenced[obj] = true
+	}
+	for _, obj := range candidates {
+		if !referenced[obj] {
+			g.constraints[obj.Type().Underlying().(*types.Interface)] = true
+		}
+	}
+	return g.constraints
+}
@@ -0,0 +1,113 @@
+package unused
+
+import (
+	"go/token"
+	"go/types"
+	"sort"
+
+	"honnef.co/go/tools/go/types/typeutil"
+	"honnef.co/go/tools/unused/refgraph"
+)
+
+// A Conversion is a conversion between two struct types, whose fields
+// use each other by (5.1), that involves a named struct type matching
+// the patterns of the unused.Conversions option. It explains why
+// fields that nothing else uses are still used.
+type Conversion struct {
+	// From and To are the types of the converted value and of the
+	// result, without pointers.
+	From, To types.Type
+	Pos      token.Pos
+	// Fields are the coupled fields, in the order of the structs.
+	Fields []CoupledFields
+}
+
+// CoupledFields are the fields of the two structs of a conversion at
+// the same index.
+type CoupledFields struct {
+	From, To *types.Var
+	// Used is set if the fields are used. As the fields use each
+	// other, either both of them are used or neither is.
+	Used bool
+	// FromKeptAlive and ToKeptAlive are set for the fields of the
+	// package that are only used because of struct conversions.
+	FromKeptAlive, ToKeptAlive bool
+}
+
+// structName returns the type name of the named struct type T, or nil
+// if T isn't named.
+func structName(T types.Type) *types.TypeName {
+	if named, ok := typeutil.Unalias(T).(*types.Named); ok {
+		return named.Origin().Obj()
+	}
+	return nil
+}
+
+// recordConversion records a conversion from one struct type to
+// another if either of them matches the unused.Conversions option.
+func (g *graph) recordConversion(from, to types.Type, pos token.Pos) {
+	if len(g.conversionPatterns) == 0 || !pos.IsValid() {
+		return
+	}
+	from, to = typeutil.Dereference(from), typeutil.Dereference(to)
+	matches := false
+	for _, T := range []types.Type{from, to} {
+		if tname := structName(T); tname != nil && tname.Pkg() != nil && matchesAny(g.conversionPatterns, tname) {
+			matches = true
+		}
+	}
+	if matches {
+		g.conversions = append(g.conversions, Conversion{From: from, To: to, Pos: pos})
+	}
+}
+
+// couplings returns the recorded conversions, sorted by position,
+// along with which of their fields are used and which of them only
+// the conversions keep alive. It has to run after results.
+func (g *graph) couplings() []Conversion {
+	if len(g.conversions) == 0 {
+		return nil
+	}
+	reachable := g.ReachableWithout(refgraph.EdgeStructConversion)
+	seen := func(field *types.Var) bool {
+		n, ok := g.Lookup(field)
+		return ok && n.Seen
+	}
+	keptAlive := func(field *types.Var) bool {
+		if field.Pkg() != g.pkg.Pkg {
+			// We don't know how other packages use their fields.
+			return false
+		}
+		n, ok := g.Lookup(field)
+		return ok && n.Seen && !reachable[n]
+	}
+
+	sort.SliceStable(g.conversions, func(i, j int) bool {
+		return g.conversions[i].Pos < g.conversions[j].Pos
+	})
+	var out []Conversion
+	for _, c := range g.conversions {
+		if len(out) > 0 {
+			last := out[len(out)-1]
+			if last.Pos == c.Pos && types.Identical(last.From, c.From) && types.Identical(last.To, c.To) {
+				// Instantiations of generic functions convert
+				// between the same types more than once.
+				continue
+			}
+		}
+		s1 := typeutil.CoreType(c.From).(*types.Struct)
+		s2 := typeutil.CoreType(c.To).(*types.Struct)
+		for i := 0; i < s1.NumFields() && i < s2.NumFields(); i++ {
+			f1, f2 := s1.Field(i), s2.Field(i)
+			c.Fields = append(c.Fields, CoupledFields{
+				From:          f1,
+				To:            f2,
+				Used:          seen(f1) || seen(f2),
+				FromKeptAlive: keptAlive(f1),
+				ToKeptAlive:   keptAlive(f2),
+			})
+		}
+		out = append(out, c)
+	}
+	return out
+}
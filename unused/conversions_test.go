@@ -0,0 +1,60 @@
+package unused
+
+import "testing"
+
+func TestCouplings(t *testing.T) {
+	const src = `package pkg
+
+type wire struct {
+	a int
+	b int
+	c int
+}
+
+type model struct {
+	a int
+	b int
+	c int
+}
+
+type other struct{ x int }
+
+type other2 struct{ x int }
+
+func Decode() int {
+	var w wire
+	m := model(w)
+	_ = other2(other{})
+	return m.a + w.b
+}
+`
+	p := buildPackage(t, src)
+	g := newGraph()
+	g.conversionPatterns = []string{"pkg.wire"}
+	g.entry(p)
+	results(g)
+
+	convs := g.couplings()
+	if len(convs) != 1 {
+		t.Fatalf("got %d conversions, want only the one of wire", len(convs))
+	}
+	c := convs[0]
+	if got := p.Fset.Position(c.Pos).Line; got != 21 {
+		t.Errorf("got conversion on line %d, want 21", got)
+	}
+	if len(c.Fields) != 3 {
+		t.Fatalf("got %d coupled fields, want 3", len(c.Fields))
+	}
+	want := []CoupledFields{
+		// m.a is accessed, w.a is only used because of it
+		{Used: true, FromKeptAlive: true},
+		{Used: true, ToKeptAlive: true},
+		{},
+	}
+	for i, f := range c.Fields {
+		f.From, f.To = nil, nil
+		if f != want[i] {
+			t.Errorf("got %+v for field %d, want %+v", f, i, want[i])
+		}
+	}
+}
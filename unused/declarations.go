@@ -0,0 +1,151 @@
+package unused
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"honnef.co/go/tools/analysis/edit"
+	"honnef.co/go/tools/go/types/typeutil"
+)
+
+// A Declaration is a node of a package's declaration tree, which
+// consists of package-level objects, methods, fields and interface
+// methods. Tools attributing code size or coverage to declarations can
+// join the tree against symbol tables.
+type Declaration struct {
+	Object types.Object
+	// Owner is the object whose declaration contains this one, such
+	// as the type of a method or field, or nil for package-level
+	// objects.
+	Owner types.Object
+	// Pos and End delimit the declaration, including its doc
+	// comment. Objects declared by the same spec, such as in
+	// var a, b int, share its range.
+	Pos, End token.Pos
+}
+
+// declarations returns the declaration tree of pkg, in the order of
+// the declarations.
+func declarations(pkg *pkg) []Declaration {
+	var out []Declaration
+	add := func(ident *ast.Ident, owner types.Object, r edit.Range) types.Object {
+		obj := pkg.TypesInfo.Defs[ident]
+		if obj == nil || obj.Name() == "_" {
+			return nil
+		}
+		out = append(out, Declaration{Object: obj, Owner: owner, Pos: r[0], End: r[1]})
+		return obj
+	}
+	// members adds the fields and interface methods declared in
+	// expr, which belong to owner.
+	var members func(expr ast.Expr, owner types.Object)
+	members = func(expr ast.Expr, owner types.Object) {
+		ast.Inspect(expr, func(n ast.Node) bool {
+			var fields *ast.FieldList
+			switch n := n.(type) {
+			case *ast.StructType:
+				fields = n.Fields
+			case *ast.InterfaceType:
+				fields = n.Methods
+			case *ast.FuncType:
+				// Parameters aren't part of the tree.
+				return false
+			default:
+				return true
+			}
+			for _, field := range fields.List {
+				r := commentedRange(field.Doc, field, field.Comment)
+				names := field.Names
+				if len(names) == 0 {
+					if ident := embeddedName(field.Type); ident != nil {
+						names = []*ast.Ident{ident}
+					}
+				}
+				var obj types.Object
+				for _, name := range names {
+					if o := add(name, owner, r); o != nil && obj == nil {
+						obj = o
+					}
+				}
+				if obj != nil {
+					members(field.Type, obj)
+				}
+			}
+			return false
+		})
+	}
+
+	for _, f := range pkg.Files {
+		for _, decl := range f.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				var owner types.Object
+				if decl.Recv != nil {
+					if obj, ok := pkg.TypesInfo.Defs[decl.Name].(*types.Func); ok {
+						if named, ok := typeutil.Dereference(obj.Type().(*types.Signature).Recv().Type()).(*types.Named); ok {
+							owner = named.Origin().Obj()
+						}
+					}
+				}
+				add(decl.Name, owner, commentedRange(decl.Doc, decl, nil))
+			case *ast.GenDecl:
+				if decl.Tok == token.IMPORT {
+					continue
+				}
+				for _, spec := range decl.Specs {
+					var r edit.Range
+					if len(decl.Specs) == 1 && !decl.Lparen.IsValid() {
+						r = commentedRange(decl.Doc, decl, nil)
+					}
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						if r[0] == 0 {
+							r = commentedRange(spec.Doc, spec, spec.Comment)
+						}
+						if obj := add(spec.Name, nil, r); obj != nil {
+							members(spec.Type, obj)
+						}
+					case *ast.ValueSpec:
+						if r[0] == 0 {
+							r = commentedRange(spec.Doc, spec, spec.Comment)
+						}
+						var first types.Object
+						for _, name := range spec.Names {
+							if obj := add(name, nil, r); obj != nil && first == nil {
+								first = obj
+							}
+						}
+						if first != nil && spec.Type != nil {
+							members(spec.Type, first)
+						}
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+// embeddedName returns the identifier naming the embedded field of
+// type expr.
+func embeddedName(expr ast.Expr) *ast.Ident {
+	for {
+		switch e := expr.(type) {
+		case *ast.Ident:
+			return e
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.SelectorExpr:
+			return e.Sel
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		default:
+			return nil
+		}
+	}
+}
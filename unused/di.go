@@ -0,0 +1,220 @@
+package unused
+
+import (
+	"go/ast"
+	"go/types"
+
+	"honnef.co/go/tools/analysis/code"
+	"honnef.co/go/tools/go/ast/astutil"
+
+	"golang.org/x/exp/typeparams"
+	"golang.org/x/tools/go/analysis"
+)
+
+// Functions of dependency injection frameworks whose arguments name
+// constructors, or types via new(T). Wire generates the code that
+// calls the constructors, replacing the injectors that call
+// wire.Build, while fx and dig call them via reflection.
+var (
+	wireFunctions = map[string]bool{
+		"github.com/google/wire.Build":          true,
+		"github.com/google/wire.NewSet":         true,
+		"github.com/google/wire.Struct":         true,
+		"github.com/google/wire.FieldsOf":       true,
+		"github.com/google/wire.Bind":           true,
+		"github.com/google/wire.InterfaceValue": true,
+	}
+	reflectionFunctions = map[string]bool{
+		"go.uber.org/fx.Provide":                true,
+		"go.uber.org/fx.Invoke":                 true,
+		"go.uber.org/fx.Decorate":               true,
+		"go.uber.org/fx.Annotate":               true,
+		"(*go.uber.org/dig.Container).Provide":  true,
+		"(*go.uber.org/dig.Container).Invoke":   true,
+		"(*go.uber.org/dig.Container).Decorate": true,
+		"(*go.uber.org/dig.Scope).Provide":      true,
+		"(*go.uber.org/dig.Scope).Invoke":       true,
+		"(*go.uber.org/dig.Scope).Decorate":     true,
+	}
+	// structs embedding these types get their fields filled in by
+	// the framework
+	parameterObjects = map[string]bool{
+		"go.uber.org/fx.In":  true,
+		"go.uber.org/dig.In": true,
+	}
+)
+
+// diProvider provides the uses that dependency injection frameworks
+// make via code generation or reflection. Constructors that wire
+// sets refer to are used unconditionally, because their injectors
+// get replaced by generated code. Constructors that fx and dig
+// calls refer to are already used by the calls, but the frameworks
+// also use the methods and fields of the types the constructors
+// return, and the fields of their fx.In and dig.In parameters.
+type diProvider struct{}
+
+func (diProvider) Edges(pass *analysis.Pass) []Edge {
+	var out []Edge
+	for _, f := range pass.Files {
+		for _, decl := range f.Decls {
+			by := declObject(pass, decl)
+			ast.Inspect(decl, func(node ast.Node) bool {
+				call, ok := node.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				name := code.CallName(pass, call)
+				if !wireFunctions[name] && !reflectionFunctions[name] {
+					return true
+				}
+				for _, arg := range call.Args {
+					if fn := localFunc(pass, arg); fn != nil {
+						if wireFunctions[name] {
+							out = append(out, Edge{Used: fn})
+						} else {
+							out = append(out, Edge{Used: fn, By: by})
+						}
+						out = append(out, constructorEdges(fn)...)
+					} else if T := newArgument(pass, arg); T != nil {
+						out = append(out, Edge{Used: T.Obj()})
+						out = append(out, memberEdges(T, T.Obj(), true)...)
+					}
+				}
+				return true
+			})
+		}
+	}
+	return out
+}
+
+// declObject returns the object that a top-level declaration
+// declares, or nil.
+func declObject(pass *analysis.Pass, decl ast.Decl) types.Object {
+	switch decl := decl.(type) {
+	case *ast.FuncDecl:
+		return pass.TypesInfo.Defs[decl.Name]
+	case *ast.GenDecl:
+		for _, spec := range decl.Specs {
+			for _, name := range specNames(spec) {
+				if name.Name != "_" {
+					return pass.TypesInfo.Defs[name]
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// localFunc returns the function of the package that expr refers to,
+// or nil.
+func localFunc(pass *analysis.Pass, expr ast.Expr) *types.Func {
+	expr = astutil.Unparen(expr)
+	switch idx := expr.(type) {
+	case *ast.IndexExpr:
+		expr = idx.X
+	case *ast.IndexListExpr:
+		expr = idx.X
+	}
+	var id *ast.Ident
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		id = expr
+	case *ast.SelectorExpr:
+		id = expr.Sel
+	default:
+		return nil
+	}
+	fn, ok := pass.TypesInfo.ObjectOf(id).(*types.Func)
+	if !ok || fn.Pkg() != pass.Pkg {
+		return nil
+	}
+	return typeparams.OriginMethod(fn)
+}
+
+// newArgument returns the named type T of the package if expr is
+// new(T) or new(*T), or nil.
+func newArgument(pass *analysis.Pass, expr ast.Expr) *types.Named {
+	call, ok := astutil.Unparen(expr).(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 || code.CallName(pass, call) != "new" {
+		return nil
+	}
+	T := pass.TypesInfo.TypeOf(call.Args[0])
+	if ptr, ok := T.(*types.Pointer); ok {
+		// wire.Bind(new(Interface), new(*Implementation))
+		T = ptr.Elem()
+	}
+	named, ok := T.(*types.Named)
+	if !ok || named.Obj().Pkg() != pass.Pkg {
+		return nil
+	}
+	return typeparams.NamedTypeOrigin(named).(*types.Named)
+}
+
+// constructorEdges returns the uses of the types that fn returns,
+// and of the fields of its parameter objects, by fn.
+func constructorEdges(fn *types.Func) []Edge {
+	var out []Edge
+	sig := fn.Type().(*types.Signature)
+	for i := 0; i < sig.Results().Len(); i++ {
+		if T := localNamed(fn, sig.Results().At(i).Type()); T != nil {
+			out = append(out, Edge{Used: T.Obj(), By: fn})
+			out = append(out, memberEdges(T, fn, true)...)
+		}
+	}
+	for i := 0; i < sig.Params().Len(); i++ {
+		if T := localNamed(fn, sig.Params().At(i).Type()); T != nil && isParameterObject(T) {
+			out = append(out, memberEdges(T, fn, false)...)
+		}
+	}
+	return out
+}
+
+// localNamed returns the named type of fn's package that T is or
+// points to, or nil.
+func localNamed(fn *types.Func, T types.Type) *types.Named {
+	if ptr, ok := T.(*types.Pointer); ok {
+		T = ptr.Elem()
+	}
+	named, ok := T.(*types.Named)
+	if !ok || named.Obj().Pkg() != fn.Pkg() {
+		return nil
+	}
+	return typeparams.NamedTypeOrigin(named).(*types.Named)
+}
+
+// memberEdges returns the uses of the fields and, if methods is
+// true, the methods of T by by.
+func memberEdges(T *types.Named, by types.Object, methods bool) []Edge {
+	var out []Edge
+	if methods {
+		for i := 0; i < T.NumMethods(); i++ {
+			out = append(out, Edge{Used: T.Method(i), By: by})
+		}
+	}
+	if s, ok := T.Underlying().(*types.Struct); ok {
+		for i := 0; i < s.NumFields(); i++ {
+			out = append(out, Edge{Used: s.Field(i), By: by})
+		}
+	}
+	return out
+}
+
+// isParameterObject reports whether T is a struct that embeds fx.In
+// or dig.In.
+func isParameterObject(T *types.Named) bool {
+	s, ok := T.Underlying().(*types.Struct)
+	if !ok {
+		return false
+	}
+	for i := 0; i < s.NumFields(); i++ {
+		field := s.Field(i)
+		if !field.Embedded() {
+			continue
+		}
+		if named, ok := field.Type().(*types.Named); ok && named.Obj().Pkg() != nil &&
+			parameterObjects[named.Obj().Pkg().Path()+"."+named.Obj().Name()] {
+			return true
+		}
+	}
+	return false
+}
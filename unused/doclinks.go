@@ -0,0 +1,135 @@
+package unused
+
+import (
+	"go/ast"
+	"go/types"
+	"regexp"
+	"strings"
+
+	"honnef.co/go/tools/unused/refgraph"
+)
+
+// docLinkRe matches doc links such as [Name], [Name.Method],
+// [*Name] and [pkg.Name.Method]. Whether a match is a doc link
+// depends on the characters around it, see docLinks.
+var docLinkRe = regexp.MustCompile(`\[\*?([\pL_][\pL\pN_]*(?:\.[\pL_][\pL\pN_]*){0,2})\]`)
+
+// docLinks returns the local objects that the doc links in cg refer
+// to.
+func (g *graph) docLinks(cg *ast.CommentGroup) []types.Object {
+	if cg == nil {
+		return nil
+	}
+	var out []types.Object
+	text := cg.Text()
+	for _, m := range docLinkRe.FindAllStringSubmatchIndex(text, -1) {
+		// Like go/doc/comment, require punctuation or spaces around
+		// the link, and skip link definitions such as "[Name]: URL".
+		if m[0] > 0 && isWordByte(text[m[0]-1]) {
+			continue
+		}
+		if m[1] < len(text) && (isWordByte(text[m[1]]) || text[m[1]] == ':') {
+			continue
+		}
+		out = append(out, g.resolveDocLink(strings.Split(text[m[2]:m[3]], "."))...)
+	}
+	return out
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= 0x80
+}
+
+// resolveDocLink resolves the dot-separated names of a doc link to
+// objects of the current package: the object it links to and, for
+// links to fields and methods, their type. It returns nil if the link
+// doesn't refer to an object of the current package.
+func (g *graph) resolveDocLink(names []string) []types.Object {
+	pkg := g.pkg.Pkg
+	if len(names) > 1 && names[0] == pkg.Name() && pkg.Scope().Lookup(names[0]) == nil {
+		// [pkg.Name] and [pkg.Name.Method]
+		names = names[1:]
+	}
+	obj := pkg.Scope().Lookup(names[0])
+	if obj == nil {
+		return nil
+	}
+	switch len(names) {
+	case 1:
+		return []types.Object{obj}
+	case 2:
+		tname, ok := obj.(*types.TypeName)
+		if !ok {
+			return nil
+		}
+		member, _, _ := types.LookupFieldOrMethod(tname.Type(), true, pkg, names[1])
+		if member == nil || member.Pkg() != pkg {
+			return nil
+		}
+		return []types.Object{obj, member}
+	default:
+		return nil
+	}
+}
+
+// useDocLinks marks the objects that doc comments link to as used by
+// the objects the comments document. Links in package comments are
+// used by the package.
+func (g *graph) useDocLinks() {
+	use := func(cg *ast.CommentGroup, users ...types.Object) {
+		for _, obj := range g.docLinks(cg) {
+			if len(users) == 0 {
+				g.seeAndUse(obj, nil, refgraph.EdgeDocLink)
+			}
+			for _, user := range users {
+				g.see(user)
+				g.seeAndUse(obj, user, refgraph.EdgeDocLink)
+			}
+		}
+	}
+	defs := func(names []*ast.Ident) []types.Object {
+		var out []types.Object
+		for _, name := range names {
+			if obj := g.pkg.TypesInfo.Defs[name]; obj != nil {
+				out = append(out, obj)
+			}
+		}
+		return out
+	}
+	fields := func(expr ast.Expr) {
+		ast.Inspect(expr, func(node ast.Node) bool {
+			if field, ok := node.(*ast.Field); ok {
+				use(field.Doc, defs(field.Names)...)
+			}
+			return true
+		})
+	}
+
+	for _, f := range g.pkg.Files {
+		use(f.Doc)
+		for _, decl := range f.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				use(decl.Doc, defs([]*ast.Ident{decl.Name})...)
+			case *ast.GenDecl:
+				var all []types.Object
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						objs := defs([]*ast.Ident{spec.Name})
+						use(spec.Doc, objs...)
+						fields(spec.Type)
+						all = append(all, objs...)
+					case *ast.ValueSpec:
+						objs := defs(spec.Names)
+						use(spec.Doc, objs...)
+						all = append(all, objs...)
+					}
+				}
+				// The comment of a group documents all of its
+				// members.
+				use(decl.Doc, all...)
+			}
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package unused
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"io"
+	"reflect"
+)
+
+// minDuplicateNodes is the number of AST nodes that a function body
+// needs for being considered a duplicate of another. Tiny bodies,
+// such as return nil, are identical too often to be worth pointing
+// out.
+const minDuplicateNodes = 20
+
+// duplicates maps unused functions and methods to used ones with
+// identical signatures and bodies, ignoring comments, formatting and
+// the names of parameters and local variables. Such functions may
+// have been copied instead of moved, and can be consolidated rather
+// than deleted. Exported functions are left alone, as other packages
+// may use them in whole-program mode.
+func (g *graph) duplicates(unused, used []types.Object) map[types.Object]types.Object {
+	var candidates []types.Object
+	for _, obj := range unused {
+		if _, ok := obj.(*types.Func); ok && !obj.Exported() {
+			candidates = append(candidates, obj)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	decls := map[types.Object]*ast.FuncDecl{}
+	for _, f := range g.pkg.Files {
+		for _, decl := range f.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Body != nil && fn.Type.TypeParams == nil {
+				decls[g.pkg.TypesInfo.Defs[fn.Name]] = fn
+			}
+		}
+	}
+
+	type digest [sha256.Size]byte
+	bodies := map[digest]types.Object{}
+	for _, obj := range used {
+		decl, ok := decls[obj]
+		if !ok {
+			continue
+		}
+		if d, ok := g.hashBody(decl); ok {
+			if _, ok := bodies[d]; !ok {
+				bodies[d] = obj
+			}
+		}
+	}
+	var out map[types.Object]types.Object
+	for _, obj := range candidates {
+		decl, ok := decls[obj]
+		if !ok {
+			continue
+		}
+		d, ok := g.hashBody(decl)
+		if !ok {
+			continue
+		}
+		if orig, ok := bodies[d]; ok {
+			if out == nil {
+				out = map[types.Object]types.Object{}
+			}
+			out[obj] = orig
+		}
+	}
+	return out
+}
+
+// hashBody hashes the normalized signature and body of decl. It
+// reports false if the body is too small to be compared.
+func (g *graph) hashBody(decl *ast.FuncDecl) ([sha256.Size]byte, bool) {
+	var d [sha256.Size]byte
+	h := sha256.New()
+	sig := g.pkg.TypesInfo.Defs[decl.Name].Type().(*types.Signature)
+	if recv := sig.Recv(); recv != nil {
+		fmt.Fprintf(h, "%s;", recv.Type())
+	}
+	for _, tuple := range []*types.Tuple{sig.Params(), sig.Results()} {
+		for i := 0; i < tuple.Len(); i++ {
+			fmt.Fprintf(h, "%s,", tuple.At(i).Type())
+		}
+		io.WriteString(h, ";")
+	}
+	fmt.Fprintf(h, "%t;", sig.Variadic())
+
+	// Objects declared by the function itself get numbered in the
+	// order of their first uses, so that renaming them doesn't
+	// matter.
+	locals := map[types.Object]int{}
+	local := func(obj types.Object) bool {
+		return obj.Pkg() == g.pkg.Pkg && obj.Pos() >= decl.Pos() && obj.Pos() < decl.End()
+	}
+	nodes := 0
+	writeNames := func(fields *ast.FieldList) {
+		if fields == nil {
+			return
+		}
+		for _, field := range fields.List {
+			for _, name := range field.Names {
+				if obj := g.pkg.TypesInfo.Defs[name]; obj != nil {
+					locals[obj] = len(locals)
+				}
+			}
+			io.WriteString(h, ",")
+		}
+	}
+	writeNames(decl.Recv)
+	writeNames(decl.Type.Params)
+	writeNames(decl.Type.Results)
+	ast.Inspect(decl.Body, func(node ast.Node) bool {
+		if node == nil {
+			io.WriteString(h, ")")
+			return true
+		}
+		nodes++
+		fmt.Fprintf(h, "(%s", reflect.TypeOf(node).Elem().Name())
+		writeNode(h, node)
+		if id, ok := node.(*ast.Ident); ok {
+			obj := g.pkg.TypesInfo.ObjectOf(id)
+			_, symbolic := g.pkg.TypesInfo.Defs[id]
+			switch {
+			case obj == nil && symbolic:
+				// the x of switch x := v.(type), whose uses refer
+				// to the implicit objects of the clauses
+				io.WriteString(h, " $")
+			case obj == nil:
+				fmt.Fprintf(h, " %s", id.Name)
+			case local(obj):
+				n, ok := locals[obj]
+				if !ok {
+					n = len(locals)
+					locals[obj] = n
+				}
+				fmt.Fprintf(h, " $%d", n)
+			case obj.Pkg() != nil:
+				fmt.Fprintf(h, " %s.%s", obj.Pkg().Path(), obj.Name())
+			default:
+				fmt.Fprintf(h, " %s", obj.Name())
+			}
+		}
+		return true
+	})
+	if nodes < minDuplicateNodes {
+		return d, false
+	}
+	copy(d[:], h.Sum(nil))
+	return d, true
+}
+
+// writeNode writes the parts of node that its children don't cover,
+// such as operators and the values of literals.
+func writeNode(h io.Writer, node ast.Node) {
+	switch node := node.(type) {
+	case *ast.BasicLit:
+		fmt.Fprintf(h, " %s", node.Value)
+	case *ast.BinaryExpr:
+		fmt.Fprintf(h, " %s", node.Op)
+	case *ast.UnaryExpr:
+		fmt.Fprintf(h, " %s", node.Op)
+	case *ast.AssignStmt:
+		fmt.Fprintf(h, " %s", node.Tok)
+	case *ast.IncDecStmt:
+		fmt.Fprintf(h, " %s", node.Tok)
+	case *ast.BranchStmt:
+		fmt.Fprintf(h, " %s", node.Tok)
+	case *ast.RangeStmt:
+		fmt.Fprintf(h, " %s", node.Tok)
+	case *ast.GenDecl:
+		fmt.Fprintf(h, " %s", node.Tok)
+	case *ast.ChanType:
+		fmt.Fprintf(h, " %d", node.Dir)
+	case *ast.SliceExpr:
+		fmt.Fprintf(h, " %t", node.Slice3)
+	case *ast.CallExpr:
+		fmt.Fprintf(h, " %t", node.Ellipsis.IsValid())
+	}
+}
@@ -0,0 +1,101 @@
+package unused
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+)
+
+var iotaObj = types.Universe.Lookup("iota")
+
+// usesIota reports whether expr refers to iota.
+func (g *graph) usesIota(expr ast.Expr) bool {
+	found := false
+	ast.Inspect(expr, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Ident); ok && g.pkg.TypesInfo.Uses[ident] == iotaObj {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// iotaSpecs returns, for each spec of a const declaration, whether the
+// value of its constants depends on iota, either directly or by
+// implicitly repeating an expression that does. It returns nil if none
+// of them do.
+func (g *graph) iotaSpecs(gen *ast.GenDecl) []bool {
+	out := make([]bool, len(gen.Specs))
+	depends := false
+	var last []ast.Expr
+	for i, spec := range gen.Specs {
+		if values := spec.(*ast.ValueSpec).Values; len(values) != 0 {
+			last = values
+		}
+		for _, v := range last {
+			if g.usesIota(v) {
+				out[i] = true
+				depends = true
+				break
+			}
+		}
+	}
+	if !depends {
+		return nil
+	}
+	return out
+}
+
+// enumGaps returns the unused constants whose removal would change the
+// values of used constants that follow them in their declarations,
+// because the latter depend on iota. Such constants are the gaps in
+// enumerations; unused constants at the ends of enumerations can be
+// removed safely.
+func (g *graph) enumGaps(unused []types.Object) map[types.Object]bool {
+	isUnused := map[types.Object]bool{}
+	for _, obj := range unused {
+		if _, ok := obj.(*types.Const); ok {
+			isUnused[obj] = true
+		}
+	}
+	if len(isUnused) == 0 {
+		return nil
+	}
+
+	out := map[types.Object]bool{}
+	for _, f := range g.pkg.Files {
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+			specs := g.iotaSpecs(gen)
+			if specs == nil {
+				continue
+			}
+			// Walk the declaration backwards, remembering whether a
+			// used constant that depends on iota follows.
+			shifts := false
+			for i := len(gen.Specs) - 1; i >= 0; i-- {
+				var used bool
+				for _, name := range gen.Specs[i].(*ast.ValueSpec).Names {
+					obj := g.pkg.TypesInfo.Defs[name]
+					if obj == nil || name.Name == "_" {
+						continue
+					}
+					if isUnused[obj] {
+						if shifts {
+							out[obj] = true
+						}
+					} else {
+						used = true
+					}
+				}
+				if used && specs[i] {
+					shifts = true
+				}
+			}
+		}
+	}
+	return out
+}
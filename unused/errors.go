@@ -0,0 +1,112 @@
+package unused
+
+import (
+	"fmt"
+	"go/token"
+	"runtime/debug"
+
+	"honnef.co/go/tools/unused/refgraph"
+)
+
+// An ErrorKind classifies internal failures of the analysis.
+type ErrorKind string
+
+const (
+	// ErrorAssertion is used for violated invariants of the analysis.
+	ErrorAssertion ErrorKind = "assertion"
+	// ErrorUnexpectedConstruct is used for constructs that the
+	// analysis doesn't know how to handle, such as new kinds of types
+	// or package members.
+	ErrorUnexpectedConstruct ErrorKind = "unexpected_construct"
+	// ErrorUnexpectedCount is used for mismatched numbers of names or
+	// fields, such as between the structs of a conversion.
+	ErrorUnexpectedCount ErrorKind = "unexpected_count"
+	// ErrorPanic is used for any other panic during the analysis,
+	// such as a nil pointer dereference.
+	ErrorPanic ErrorKind = "panic"
+)
+
+// An AnalysisError describes an internal failure of the analysis of
+// a package. Such failures are bugs in the analysis, not in the
+// analyzed code. When the analysis of a package fails, all of its
+// objects are considered used.
+type AnalysisError struct {
+	Kind ErrorKind
+	// Construct describes what the analysis failed on, such as the
+	// Go type of an unexpected types.Type.
+	Construct string
+	// Position is the position of the construct, if known.
+	Position token.Position
+	Message  string
+	// Stack is the stack trace of the failure, for bug reports.
+	Stack string
+}
+
+func (err AnalysisError) Error() string {
+	msg := fmt.Sprintf("internal error (%s)", err.Kind)
+	if err.Construct != "" {
+		msg += " in " + err.Construct
+	}
+	if err.Position.IsValid() {
+		msg += " at " + err.Position.String()
+	}
+	return msg + ": " + err.Message
+}
+
+// fail aborts the analysis of the package with an AnalysisError,
+// which run recovers from.
+func (g *graph) fail(kind ErrorKind, construct string, pos token.Pos, format string, args ...interface{}) {
+	err := AnalysisError{
+		Kind:      kind,
+		Construct: construct,
+		Message:   fmt.Sprintf(format, args...),
+		Stack:     string(debug.Stack()),
+	}
+	if pos.IsValid() && g.pkg != nil && g.pkg.Fset.File(pos) != nil {
+		err.Position = g.pkg.Fset.Position(pos)
+	}
+	panic(err)
+}
+
+// assert fails the analysis with an ErrorAssertion if ok is false.
+func (g *graph) assert(ok bool, construct string, pos token.Pos) {
+	if !ok {
+		g.fail(ErrorAssertion, construct, pos, "failed assertion")
+	}
+}
+
+// cancelled is the value of the panic with which checkCancelled
+// aborts the analysis.
+type cancelled struct {
+	err error
+}
+
+// checkCancelled aborts the analysis of the package once the context
+// of the run is done. It is called between files and functions, so
+// that even the analysis of huge packages stops promptly.
+func (g *graph) checkCancelled() {
+	if err := g.ctx.Err(); err != nil {
+		panic(cancelled{err})
+	}
+}
+
+// recoverError turns the value of a panic during the analysis into
+// an error. Exceeding the graph's limits isn't a failure of the
+// analysis and results in a refgraph.BudgetError, and cancellation
+// results in the error of the context.
+func recoverError(r interface{}) error {
+	switch r := r.(type) {
+	case cancelled:
+		return r.err
+	case refgraph.BudgetError:
+		return r
+	case AnalysisError:
+		return r
+	default:
+		return AnalysisError{
+			Kind:    ErrorPanic,
+			Message: fmt.Sprint(r),
+			Stack:   string(debug.Stack()),
+		}
+	}
+}
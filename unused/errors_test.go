@@ -0,0 +1,93 @@
+package unused
+
+import (
+	"context"
+	"fmt"
+	"go/token"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+	"honnef.co/go/tools/unused/refgraph"
+)
+
+func TestRecoverError(t *testing.T) {
+	err := recoverError(refgraph.BudgetError{What: "nodes", Limit: 10})
+	if _, ok := err.(refgraph.BudgetError); !ok {
+		t.Errorf("got %T for exceeding the budget, want refgraph.BudgetError", err)
+	}
+
+	aerr := AnalysisError{
+		Kind:      ErrorUnexpectedCount,
+		Construct: "struct conversion",
+		Position:  token.Position{Filename: "a.go", Line: 3, Column: 7},
+		Message:   "converting between structs with 2 and 3 fields",
+	}
+	if got := recoverError(aerr); got != error(aerr) {
+		t.Errorf("got %v, want %v", got, aerr)
+	}
+	if got, want := aerr.Error(), "internal error (unexpected_count) in struct conversion at a.go:3:7: converting between structs with 2 and 3 fields"; got != want {
+		t.Errorf("got message %q, want %q", got, want)
+	}
+
+	err = recoverError("runtime error: invalid memory address or nil pointer dereference")
+	perr, ok := err.(AnalysisError)
+	if !ok || perr.Kind != ErrorPanic || perr.Stack == "" {
+		t.Errorf("got %#v for a panic, want an AnalysisError of kind %s with a stack trace", err, ErrorPanic)
+	}
+}
+
+// stopContext is a context that is done from the stop-th time its
+// Err method gets called.
+type stopContext struct {
+	context.Context
+	stop  int
+	calls int
+}
+
+func (ctx *stopContext) Err() error {
+	ctx.calls++
+	if ctx.calls >= ctx.stop {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestCancellation(t *testing.T) {
+	var srcs []string
+	for i := 0; i < 3; i++ {
+		srcs = append(srcs, fmt.Sprintf("package pkg\n\nfunc fn%d() {}\n", i))
+	}
+	p := buildPackage(t, srcs...)
+
+	for stop := 1; stop <= 3; stop++ {
+		ctx := &stopContext{Context: context.Background(), stop: stop}
+		g := newGraph()
+		g.ctx = ctx
+		_, err := g.run(p)
+		if err != context.Canceled {
+			t.Fatalf("got error %v after cancelling at check %d, want %v", err, stop, context.Canceled)
+		}
+		// The analysis has to stop at the check that saw the
+		// cancellation, before moving on to the next file.
+		if ctx.calls != stop {
+			t.Errorf("the analysis checked for cancellation %d times after cancelling at check %d", ctx.calls, stop)
+		}
+		if len(g.seenFns) != 0 {
+			t.Errorf("functions were analyzed after cancelling at check %d", stop)
+		}
+	}
+}
+
+func TestCancellationAnalyzer(t *testing.T) {
+	// Drivers other than the runner of lintcmd run the cancellation
+	// analyzer itself, and require its result to be of its
+	// ResultType.
+	for _, res := range analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "functions") {
+		if res.Err != nil {
+			t.Fatal(res.Err)
+		}
+		if _, ok := res.Result.(Result); !ok {
+			t.Errorf("got result of type %T, want Result", res.Result)
+		}
+	}
+}
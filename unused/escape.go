@@ -0,0 +1,154 @@
+package unused
+
+import (
+	"go/types"
+
+	"honnef.co/go/tools/go/ir"
+	"honnef.co/go/tools/go/types/typeutil"
+)
+
+// escapingTypes returns the unexported named types of the package
+// whose values other packages can get hold of, which lets them call
+// the types' exported methods. Values escape via the package's API,
+// via conversions to interfaces, which includes reflection, and via
+// type arguments, as generic code may call methods through its
+// constraints.
+func (g *graph) escapingTypes() map[*types.TypeName]bool {
+	out := map[*types.TypeName]bool{}
+	seen := map[types.Type]struct{}{}
+	var escape func(T types.Type)
+	escape = func(T types.Type) {
+		T = typeutil.Unalias(T)
+		if _, ok := seen[T]; ok {
+			return
+		}
+		seen[T] = struct{}{}
+
+		switch T := T.(type) {
+		case *types.Named:
+			obj := T.Obj()
+			for i := 0; i < T.TypeArgs().Len(); i++ {
+				escape(T.TypeArgs().At(i))
+			}
+			if obj.Pkg() != g.pkg.Pkg {
+				// Types of other packages can't contain our types,
+				// other than as type arguments.
+				return
+			}
+			if !isPackageLevelExported(obj) {
+				out[obj] = true
+			}
+			// Values of the type expose its exported fields and
+			// methods.
+			escape(T.Underlying())
+			for i := 0; i < T.NumMethods(); i++ {
+				if m := T.Method(i); m.Exported() {
+					escape(m.Type())
+				}
+			}
+		case *types.Pointer:
+			escape(T.Elem())
+		case *types.Slice:
+			escape(T.Elem())
+		case *types.Array:
+			escape(T.Elem())
+		case *types.Chan:
+			escape(T.Elem())
+		case *types.Map:
+			escape(T.Key())
+			escape(T.Elem())
+		case *types.Struct:
+			for i := 0; i < T.NumFields(); i++ {
+				// Embedded fields promote their methods, even if the
+				// fields themselves are unexported.
+				if f := T.Field(i); f.Exported() || f.Embedded() {
+					escape(f.Type())
+				}
+			}
+		case *types.Signature:
+			escape(T.Params())
+			escape(T.Results())
+		case *types.Tuple:
+			for i := 0; i < T.Len(); i++ {
+				escape(T.At(i).Type())
+			}
+		case *types.Interface:
+			for i := 0; i < T.NumMethods(); i++ {
+				escape(T.Method(i).Type())
+			}
+		}
+	}
+
+	scope := g.pkg.Pkg.Scope()
+	for _, name := range scope.Names() {
+		if obj := scope.Lookup(name); obj.Exported() {
+			escape(obj.Type())
+		}
+	}
+	for _, inst := range g.pkg.TypesInfo.Instances {
+		for i := 0; i < inst.TypeArgs.Len(); i++ {
+			escape(inst.TypeArgs.At(i))
+		}
+	}
+	for _, fn := range g.pkg.SrcFuncs {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				if instr, ok := instr.(*ir.MakeInterface); ok {
+					escape(instr.X.Type())
+				}
+			}
+		}
+	}
+	return out
+}
+
+// methodsEscape reports whether other packages may call the exported
+// methods of the named type obj.
+func (g *graph) methodsEscape(obj *types.TypeName) bool {
+	if g.escaping == nil || obj.Pkg() != g.pkg.Pkg || isPackageLevelExported(obj) {
+		return true
+	}
+	return g.escaping[obj]
+}
+
+// isPackageLevelExported reports whether other packages can refer to
+// obj by name.
+func isPackageLevelExported(obj types.Object) bool {
+	return obj.Exported() && obj.Parent() == obj.Pkg().Scope()
+}
+
+// unformattedMethod reports whether m is the String or Error method
+// of an unexported type of the package whose values are never
+// formatted, because they never escape the package, and records such
+// methods for classifying them. Promoted methods are judged by the
+// embedded types that declare them, which escape along with the
+// types embedding them.
+func (g *graph) unformattedMethod(m *types.Func) bool {
+	if g.formatting == nil || !isFormattingMethod(m) {
+		return false
+	}
+	named, ok := typeutil.Dereference(m.Type().(*types.Signature).Recv().Type()).(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	if obj.Pkg() != g.pkg.Pkg || isPackageLevelExported(obj) || g.formatting[obj] {
+		return false
+	}
+	g.unformatted[m] = true
+	return true
+}
+
+// isFormattingMethod reports whether m implements fmt.Stringer or
+// error.
+func isFormattingMethod(m *types.Func) bool {
+	if m.Name() != "String" && m.Name() != "Error" {
+		return false
+	}
+	sig := m.Type().(*types.Signature)
+	if sig.Params().Len() != 0 || sig.Results().Len() != 1 {
+		return false
+	}
+	basic, ok := sig.Results().At(0).Type().(*types.Basic)
+	return ok && basic.Kind() == types.String
+}
@@ -0,0 +1,84 @@
+package unused
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"honnef.co/go/tools/unused/refgraph"
+)
+
+// An Export records that a //lint:export-to directive marks an object
+// as used by a consumer outside of the analyzed code, such as a
+// service that calls it via reflection.
+type Export struct {
+	Object types.Object
+	// Consumer is the directive's first argument, naming the consumer.
+	Consumer string
+	// Reason is the free-form rest of the directive, if any.
+	Reason string
+	// Directive is the position of the directive's comment.
+	Directive token.Pos
+}
+
+// exportTo uses the objects declared by the declarations, specs and
+// fields that //lint:export-to directives are attached to, and records
+// them in g.exports. Directives without a consumer are ignored.
+func (g *graph) exportTo(pkg *pkg) {
+	for _, dir := range pkg.Directives {
+		if dir.Command != "export-to" || len(dir.Arguments) == 0 {
+			continue
+		}
+		var names []*ast.Ident
+		switch node := dir.Node.(type) {
+		case *ast.FuncDecl:
+			names = []*ast.Ident{node.Name}
+		case *ast.GenDecl:
+			for _, spec := range node.Specs {
+				names = append(names, specNames(spec)...)
+			}
+		case ast.Spec:
+			names = specNames(node)
+		case *ast.Field:
+			names = node.Names
+		}
+		for _, name := range names {
+			obj := pkg.TypesInfo.Defs[name]
+			if obj == nil {
+				continue
+			}
+			if _, ok := g.Lookup(obj); !ok {
+				// Irrelevant objects, such as blank identifiers,
+				// never get reported anyway.
+				continue
+			}
+			g.use(obj, nil, refgraph.EdgeExportTo)
+			if obj, ok := obj.(*types.TypeName); ok {
+				g.useMembers(obj, refgraph.EdgeExportTo)
+			}
+			g.exports = append(g.exports, Export{
+				Object:    obj,
+				Consumer:  dir.Arguments[0],
+				Reason:    strings.Join(dir.Arguments[1:], " "),
+				Directive: dir.Directive.Pos(),
+			})
+		}
+	}
+	sort.SliceStable(g.exports, func(i, j int) bool {
+		return g.exports[i].Object.Pos() < g.exports[j].Object.Pos()
+	})
+}
+
+// specNames returns the names that spec declares.
+func specNames(spec ast.Spec) []*ast.Ident {
+	switch spec := spec.(type) {
+	case *ast.TypeSpec:
+		return []*ast.Ident{spec.Name}
+	case *ast.ValueSpec:
+		return spec.Names
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,186 @@
+package unused
+
+import (
+	"go/types"
+
+	"honnef.co/go/tools/go/ir"
+	"honnef.co/go/tools/unused/refgraph"
+)
+
+// A fatalPath stands for the blocks of a function that always end in
+// a panic or in exiting the program. It uses what the instructions of
+// these blocks refer to on behalf of the function.
+type fatalPath struct {
+	fn types.Object
+}
+
+func (p *fatalPath) String() string { return "fatal paths of " + p.fn.Name() }
+
+// fatalBlocks returns the blocks of fn that always end in a panic or
+// in exiting the program: blocks ending in a panic, blocks calling
+// functions that never return, such as log.Fatal and os.Exit, and
+// blocks all of whose successors are fatal. It returns nil if the
+// whole function is fatal: such functions, like usage functions that
+// exit, are what makes the blocks of their callers fatal.
+func fatalBlocks(fn *ir.Function) map[*ir.BasicBlock]bool {
+	fatal := map[*ir.BasicBlock]bool{}
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			switch instr := instr.(type) {
+			case *ir.Panic:
+				fatal[b] = true
+			case *ir.Call:
+				if noReturn(instr) {
+					fatal[b] = true
+				}
+			}
+		}
+	}
+	if len(fatal) == 0 {
+		return nil
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, b := range fn.Blocks {
+			if fatal[b] || b == fn.Exit || len(b.Succs) == 0 {
+				continue
+			}
+			all := true
+			for _, succ := range b.Succs {
+				if !fatal[succ] {
+					all = false
+					break
+				}
+			}
+			if all {
+				fatal[b] = true
+				changed = true
+			}
+		}
+	}
+	if fatal[fn.Blocks[0]] {
+		return nil
+	}
+	return fatal
+}
+
+// noReturn reports whether instr is a call of a function that never
+// returns. Functions such as log.Fatal may exit or unwind, depending
+// on the program's state.
+func noReturn(instr ir.Instruction) bool {
+	call, ok := instr.(*ir.Call)
+	if !ok {
+		return false
+	}
+	callee := call.Call.StaticCallee()
+	return callee != nil && callee.NoReturn != ir.Returns
+}
+
+// isFatal reports whether instr is part of the fatal blocks: whether
+// it is in one of them, or is a constant that is only used by them.
+// Constants live in the entry block, regardless of their uses. Calls
+// of functions that never return aren't part of them, so that helpers
+// such as a usage function that exits count as used.
+func isFatal(instr ir.Instruction, fatal map[*ir.BasicBlock]bool) bool {
+	if fatal == nil {
+		return false
+	}
+	if fatal[instr.Block()] {
+		return !noReturn(instr)
+	}
+	c, ok := instr.(ir.Constant)
+	if !ok {
+		return false
+	}
+	used := false
+	for _, ref := range *c.Referrers() {
+		if _, ok := ref.(*ir.DebugRef); ok {
+			continue
+		}
+		if !fatal[ref.Block()] {
+			return false
+		}
+		used = true
+	}
+	return used
+}
+
+// fatalPathOf returns the fatal paths of fnObj, adding them to the
+// graph as a use of fnObj.
+func (g *graph) fatalPathOf(fnObj types.Object) *fatalPath {
+	if p, ok := g.fatalPaths[fnObj]; ok {
+		return p
+	}
+	p := &fatalPath{fn: fnObj}
+	if g.fatalPaths == nil {
+		g.fatalPaths = map[types.Object]*fatalPath{}
+	}
+	g.fatalPaths[fnObj] = p
+	g.see(p)
+	g.use(p, fnObj, refgraph.EdgeFatalPath)
+	return p
+}
+
+// fatalOnly finds the functions, methods and types of the package
+// that only the fatal paths of used functions use, directly or
+// indirectly. It colors the graph without the fatal paths, leaving
+// these objects unseen, and then colors everything else that the
+// fatal paths use. It has to run before results, with the fatal paths
+// themselves marked as seen.
+func (g *graph) fatalOnly() map[types.Object]bool {
+	if len(g.fatalPaths) == 0 {
+		return nil
+	}
+	g.Color(g.Root)
+
+	out := map[types.Object]bool{}
+	var rest []*refgraph.Node
+	visited := map[*refgraph.Node]bool{}
+	var walk func(n *refgraph.Node)
+	walk = func(n *refgraph.Node) {
+		for _, e := range n.Uses {
+			if visited[e.Node] {
+				continue
+			}
+			if _, ok := e.Node.Obj.(*fatalPath); !ok && e.Node.Seen {
+				continue
+			}
+			visited[e.Node] = true
+			if g.isFatalCandidate(e.Node.Obj) {
+				out[e.Node.Obj.(types.Object)] = true
+			} else if _, ok := e.Node.Obj.(types.Object); ok {
+				rest = append(rest, e.Node)
+			}
+			walk(e.Node)
+		}
+	}
+	for fnObj, p := range g.fatalPaths {
+		fn, ok := g.Lookup(fnObj)
+		if !ok || !fn.Seen {
+			// Unused functions are reported as such, along with
+			// everything that only they use.
+			continue
+		}
+		n, _ := g.Lookup(p)
+		walk(n)
+	}
+	// Variables, constants and fields that fatal paths use, such as
+	// the errors they panic with, are expected.
+	for _, n := range rest {
+		g.Color(n)
+	}
+	return out
+}
+
+// isFatalCandidate reports whether obj is a function, method or type
+// of the package, which fatalOnly may flag.
+func (g *graph) isFatalCandidate(obj interface{}) bool {
+	switch obj := obj.(type) {
+	case *types.Func:
+		return obj.Pkg() == g.pkg.Pkg
+	case *types.TypeName:
+		return obj.Pkg() == g.pkg.Pkg && obj.Parent() == obj.Pkg().Scope()
+	default:
+		return false
+	}
+}
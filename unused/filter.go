@@ -0,0 +1,169 @@
+package unused
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Filter returns a copy of res that only reports the unused and quiet
+// objects for which keep returns true, along with everything that the
+// result records about them: their fixes, aliases, clusters, metrics
+// and so on. Forbidden references are kept if keep returns true for
+// the objects making them, and dead routes if it does for their
+// handlers. Clusters are dropped as a whole if keep returns false for
+// any of their objects, as these can only be deleted together. Used
+// objects and the rest of the result are kept as they are, because
+// they overrule the unused objects of other packages, or describe the
+// package as a whole.
+func (res Result) Filter(keep func(obj types.Object) bool) Result {
+	filter := func(objs []types.Object) []types.Object {
+		var out []types.Object
+		for _, obj := range objs {
+			if keep(obj) {
+				out = append(out, obj)
+			}
+		}
+		return out
+	}
+
+	out := res
+	out.Unused = filter(res.Unused)
+	out.Quiet = filter(res.Quiet)
+	kept := map[types.Object]bool{}
+	for _, obj := range out.Unused {
+		kept[obj] = true
+	}
+	for _, obj := range out.Quiet {
+		kept[obj] = true
+	}
+
+	out.Fixes = filterObjects(res.Fixes, kept)
+	out.Unexports = filterObjects(res.Unexports, kept)
+	out.FixErrors = filterObjects(res.FixErrors, kept)
+	out.LowConfidence = filterObjects(res.LowConfidence, kept)
+	out.Categories = filterObjects(res.Categories, kept)
+	out.Tested = filterObjects(res.Tested, kept)
+	out.Duplicates = filterObjects(res.Duplicates, kept)
+	out.Metrics = filterObjects(res.Metrics, kept)
+
+	out.Aliases = nil
+	for tname, aliases := range res.Aliases {
+		if !kept[tname] {
+			continue
+		}
+		if out.Aliases == nil {
+			out.Aliases = map[*types.TypeName][]*types.TypeName{}
+		}
+		var keptAliases []*types.TypeName
+		for _, alias := range aliases {
+			if keep(alias) {
+				keptAliases = append(keptAliases, alias)
+			}
+		}
+		out.Aliases[tname] = keptAliases
+	}
+
+	out.Clusters = nil
+clusters:
+	for _, c := range res.Clusters {
+		for _, obj := range c.Objects {
+			if !keep(obj) {
+				continue clusters
+			}
+		}
+		out.Clusters = append(out.Clusters, c)
+	}
+
+	out.Forbidden = nil
+	for _, ref := range res.Forbidden {
+		if keep(ref.Site) {
+			out.Forbidden = append(out.Forbidden, ref)
+		}
+	}
+
+	out.Routes = nil
+	for _, r := range res.Routes {
+		if r.Live || keep(r.Handler) {
+			out.Routes = append(out.Routes, r)
+		}
+	}
+	return out
+}
+
+// filterObjects returns the entries of m for the kept objects.
+func filterObjects[V any](m map[types.Object]V, kept map[types.Object]bool) map[types.Object]V {
+	out := map[types.Object]V{}
+	for obj, v := range m {
+		if kept[obj] {
+			out[obj] = v
+		}
+	}
+	return out
+}
+
+// FilterKind returns a copy of res that only reports unused and quiet
+// objects of the given kinds, which are the kinds used in reports:
+// "func", "var", "field", "const", "type" and "type param".
+func (res Result) FilterKind(kinds ...string) Result {
+	return res.Filter(func(obj types.Object) bool {
+		kind := typString(obj)
+		for _, k := range kinds {
+			if k == kind {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// FilterPath returns a copy of res that only reports unused and quiet
+// objects declared in files for which keep returns true. Files are
+// named as they were compiled, ignoring line directives.
+func (res Result) FilterPath(fset *token.FileSet, keep func(path string) bool) Result {
+	return res.Filter(func(obj types.Object) bool {
+		return keep(fset.PositionFor(obj.Pos(), false).Filename)
+	})
+}
+
+// Exclude returns a copy of res that doesn't report unused and quiet
+// objects declared in files matching any of the globs, which use the
+// syntax of path.Match. Globs without slashes match the base names of
+// files, such as zz_generated*.go, while other globs match the
+// trailing elements of their paths, such as api/proto/*.go. Globs
+// starting with a slash match entire paths.
+func (res Result) Exclude(fset *token.FileSet, globs ...string) (Result, error) {
+	for _, glob := range globs {
+		if _, err := path.Match(glob, ""); err != nil {
+			return Result{}, fmt.Errorf("invalid glob %q: %s", glob, err)
+		}
+	}
+	return res.FilterPath(fset, func(name string) bool {
+		for _, glob := range globs {
+			if matchGlob(glob, filepath.ToSlash(name)) {
+				return false
+			}
+		}
+		return true
+	}), nil
+}
+
+// matchGlob matches glob against the same number of trailing elements
+// of the slash-separated name. Globs starting with a slash have to
+// match the entire name.
+func matchGlob(glob, name string) bool {
+	if strings.HasPrefix(glob, "/") {
+		ok, _ := path.Match(glob, name)
+		return ok
+	}
+	elems := strings.Split(name, "/")
+	n := strings.Count(glob, "/") + 1
+	if n > len(elems) {
+		return false
+	}
+	ok, _ := path.Match(glob, strings.Join(elems[len(elems)-n:], "/"))
+	return ok
+}
@@ -0,0 +1,321 @@
+package unused
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+
+	"honnef.co/go/tools/analysis/edit"
+	"honnef.co/go/tools/analysis/facts/ownership"
+	"honnef.co/go/tools/go/types/typeutil"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// methods returns the methods declared on each named type.
+func (g *graph) methods() map[*types.TypeName][]types.Object {
+	methods := map[*types.TypeName][]types.Object{}
+	for _, f := range g.pkg.Files {
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil {
+				continue
+			}
+			obj := g.pkg.TypesInfo.Defs[fn.Name]
+			if obj == nil {
+				continue
+			}
+			if named, ok := typeutil.Dereference(obj.Type().(*types.Signature).Recv().Type()).(*types.Named); ok {
+				tname := named.Origin().Obj()
+				methods[tname] = append(methods[tname], obj)
+			}
+		}
+	}
+	return methods
+}
+
+// declares reports whether obj is one of the names declared by d, as
+// opposed to an object nested inside it, such as a field or a
+// parameter.
+func declares(d ownership.Decl, obj types.Object) bool {
+	switch spec := d.Spec.(type) {
+	case nil:
+		return d.Decl.(*ast.FuncDecl).Name.Pos() == obj.Pos()
+	case *ast.TypeSpec:
+		return spec.Name.Pos() == obj.Pos()
+	case *ast.ValueSpec:
+		for _, name := range spec.Names {
+			if name.Pos() == obj.Pos() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// commentedRange returns the range of node, extended to include its
+// doc comment and trailing line comment, if any.
+func commentedRange(doc *ast.CommentGroup, node ast.Node, comment *ast.CommentGroup) edit.Range {
+	r := edit.Range{node.Pos(), node.End()}
+	if doc != nil {
+		r[0] = doc.Pos()
+	}
+	if comment != nil && comment.End() > r[1] {
+		r[1] = comment.End()
+	}
+	return r
+}
+
+// specRange returns the range of a spec in a parenthesized
+// declaration, including its comments. If the spec is on lines of its
+// own, the range covers these lines in their entirety, so that
+// deleting the spec doesn't leave an empty line behind.
+func specRange(fset *token.FileSet, gen *ast.GenDecl, spec ast.Spec, doc, comment *ast.CommentGroup) edit.Range {
+	r := commentedRange(doc, spec, comment)
+	prev, next := gen.Lparen, gen.Rparen
+	for i, ospec := range gen.Specs {
+		if ospec != spec {
+			continue
+		}
+		if i > 0 {
+			prev = gen.Specs[i-1].End()
+		}
+		if i+1 < len(gen.Specs) {
+			next = gen.Specs[i+1].Pos()
+		}
+	}
+
+	tf := fset.File(r.Pos())
+	start, end := tf.Line(r.Pos()), tf.Line(r.End())
+	if tf.Line(prev) < start && tf.Line(next) > end {
+		r[0] = tf.LineStart(start)
+		r[1] = tf.LineStart(end + 1)
+	}
+	return r
+}
+
+// deletion returns the range of code that has to be deleted to remove
+// the declaration. It returns false if the declaration cannot be
+// removed on its own, for example because it declares multiple
+// objects.
+func deletion(fset *token.FileSet, d ownership.Decl) (edit.Range, bool) {
+	if d.Spec == nil {
+		fn := d.Decl.(*ast.FuncDecl)
+		return commentedRange(fn.Doc, fn, nil), true
+	}
+
+	gen := d.Decl.(*ast.GenDecl)
+	var doc, comment *ast.CommentGroup
+	switch spec := d.Spec.(type) {
+	case *ast.TypeSpec:
+		doc, comment = spec.Doc, spec.Comment
+	case *ast.ValueSpec:
+		if len(spec.Names) != 1 {
+			return edit.Range{}, false
+		}
+		if gen.Tok == token.CONST && len(spec.Values) != 0 {
+			// Subsequent constants may implicitly repeat our
+			// expression, which they can't do anymore once we're
+			// gone.
+			for i, ospec := range gen.Specs {
+				if ospec == spec && i+1 < len(gen.Specs) && len(gen.Specs[i+1].(*ast.ValueSpec).Values) == 0 {
+					return edit.Range{}, false
+				}
+			}
+		}
+		doc, comment = spec.Doc, spec.Comment
+	}
+
+	if len(gen.Specs) == 1 {
+		if gen.Lparen.IsValid() {
+			comment = nil
+		}
+		return commentedRange(gen.Doc, gen, comment), true
+	}
+	return specRange(fset, gen, d.Spec, doc, comment), true
+}
+
+// importedName returns the package name declared by an import spec.
+func (g *graph) importedName(spec *ast.ImportSpec) *types.PkgName {
+	var obj types.Object
+	if spec.Name != nil {
+		obj = g.pkg.TypesInfo.Defs[spec.Name]
+	} else {
+		obj = g.pkg.TypesInfo.Implicits[spec]
+	}
+	pn, _ := obj.(*types.PkgName)
+	return pn
+}
+
+// importDeletions returns edits that remove the imports of file whose
+// uses are all contained in the deleted ranges.
+func (g *graph) importDeletions(f *ast.File, deleted []edit.Range) []analysis.TextEdit {
+	contained := func(pos token.Pos) bool {
+		for _, r := range deleted {
+			if pos >= r.Pos() && pos < r.End() {
+				return true
+			}
+		}
+		return false
+	}
+
+	var edits []analysis.TextEdit
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			spec := spec.(*ast.ImportSpec)
+			pn := g.importedName(spec)
+			if pn == nil {
+				continue
+			}
+			uses, ok := g.importUses[f][pn]
+			if !ok {
+				// blank and dot imports, or imports we've never seen being used
+				continue
+			}
+			unneeded := true
+			for _, use := range uses {
+				if !contained(use) {
+					unneeded = false
+					break
+				}
+			}
+			if !unneeded {
+				continue
+			}
+			if gen.Lparen.IsValid() {
+				edits = append(edits, edit.Delete(specRange(g.pkg.Fset, gen, spec, spec.Doc, spec.Comment)))
+			} else {
+				edits = append(edits, edit.Delete(commentedRange(gen.Doc, gen, spec.Comment)))
+			}
+		}
+	}
+	return edits
+}
+
+// inGeneratedFile reports whether f is a generated file.
+func (g *graph) inGeneratedFile(f *ast.File) bool {
+	_, ok := g.pkg.Generated[g.pkg.Fset.PositionFor(f.Pos(), false).Filename]
+	return ok
+}
+
+// blankFix returns a fix that replaces the name of obj with _, which
+// keeps the values of subsequent constants in iota-based
+// declarations intact.
+func (g *graph) blankFix(d ownership.Decl, obj types.Object) analysis.SuggestedFix {
+	var name *ast.Ident
+	for _, ident := range d.Spec.(*ast.ValueSpec).Names {
+		if ident.Pos() == obj.Pos() {
+			name = ident
+		}
+	}
+	return edit.Fix(fmt.Sprintf("Replace %s with _", obj.Name()), edit.ReplaceWithString(name, "_"))
+}
+
+// fixes computes suggested fixes that delete the declarations of
+// unused package-level objects. Deleting a type also deletes its
+// methods and its unused aliases. Imports that are only used by the
+// deleted code get removed, too. Constants that are gaps in
+// enumerations get renamed to _ instead.
+func (g *graph) fixes(unused []types.Object) map[types.Object][]analysis.SuggestedFix {
+	methods := g.methods()
+	out := map[types.Object][]analysis.SuggestedFix{}
+	for _, obj := range unused {
+		d, ok := g.pkg.Ownership.Decl(obj)
+		if !ok || !declares(d, obj) {
+			continue
+		}
+		if g.gaps[obj] {
+			// Deleting the constant would change the values of
+			// the constants that follow it.
+			if !g.inGeneratedFile(d.File) {
+				out[obj] = []analysis.SuggestedFix{g.blankFix(d, obj)}
+			}
+			continue
+		}
+		r, ok := deletion(g.pkg.Fset, d)
+		if !ok {
+			continue
+		}
+
+		deleted := map[*ast.File][]edit.Range{d.File: {r}}
+		// Deleting obj without the code that refers to it, such as
+		// its methods, aliases and tests, would break the build, so
+		// the fix has to delete all of it or nothing.
+		complete := true
+		deleteAlso := func(obj types.Object) {
+			d, ok := g.pkg.Ownership.Decl(obj)
+			if !ok {
+				complete = false
+				return
+			}
+			r, ok := deletion(g.pkg.Fset, d)
+			if !ok {
+				complete = false
+				return
+			}
+			deleted[d.File] = append(deleted[d.File], r)
+		}
+		msg := fmt.Sprintf("Remove %s %s", typString(obj), obj.Name())
+		if tname, ok := obj.(*types.TypeName); ok && !tname.IsAlias() {
+			for _, m := range methods[tname] {
+				deleteAlso(m)
+			}
+			if aliases := g.aliases[tname]; len(aliases) > 0 {
+				for _, alias := range aliases {
+					deleteAlso(alias)
+				}
+				msg += " and its aliases"
+			}
+		}
+		if tests := g.tested[obj]; len(tests) > 0 {
+			for _, test := range tests {
+				deleteAlso(test)
+			}
+			msg += " and its tests"
+		}
+		if as := g.asserted[obj]; len(as) > 0 {
+			for _, a := range as {
+				ar, ok := deletion(g.pkg.Fset, a.decl)
+				if ok {
+					deleted[a.decl.File] = append(deleted[a.decl.File], ar)
+				}
+			}
+			msg += " and its interface assertions"
+		}
+		if !complete {
+			continue
+		}
+
+		inGenerated := false
+		for f := range deleted {
+			if g.inGeneratedFile(f) {
+				inGenerated = true
+			}
+		}
+		if inGenerated {
+			// Don't edit generated code, which includes files
+			// rewritten by cgo, whose positions don't match the
+			// user's files.
+			continue
+		}
+
+		var edits []analysis.TextEdit
+		for f, rs := range deleted {
+			for _, r := range rs {
+				edits = append(edits, edit.Delete(r))
+			}
+			edits = append(edits, g.importDeletions(f, rs)...)
+		}
+		sort.Slice(edits, func(i, j int) bool {
+			return edits[i].Pos < edits[j].Pos
+		})
+		out[obj] = []analysis.SuggestedFix{edit.Fix(msg, edits...)}
+	}
+	return out
+}
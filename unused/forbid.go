@@ -0,0 +1,307 @@
+package unused
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path"
+	"sort"
+	"strings"
+
+	"honnef.co/go/tools/analysis/lint"
+	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/unused/refgraph"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// forbiddenRules returns the unused.forbid rules that apply to the
+// package at pkgPath.
+func forbiddenRules(rules []config.Forbidden, pkgPath string) []config.Forbidden {
+	var out []config.Forbidden
+	for _, rule := range rules {
+		if ok, _ := path.Match(rule.From, pkgPath); ok {
+			out = append(out, rule)
+		}
+	}
+	return out
+}
+
+// ForbiddenAnalyzer reports the references that the unused.forbid
+// rules forbid, which U1000 finds. They are a check of their own, so
+// that ignoring or disabling U1000 doesn't hide them.
+var ForbiddenAnalyzer = &lint.Analyzer{
+	Doc: &lint.Documentation{
+		Title: "Forbidden reference",
+		Text: `The unused.forbid option forbids packages from referencing
+certain objects. The problem is reported at the package-level object or
+method that makes the reference, together with the path through the
+program along which the reference is reachable.`,
+		Since:   "Unreleased",
+		Options: []string{"unused.forbid"},
+	},
+	Analyzer: &analysis.Analyzer{
+		Name:     "U1001",
+		Doc:      "Forbidden reference",
+		Run:      runForbidden,
+		Requires: []*analysis.Analyzer{Analyzer.Analyzer},
+	},
+}
+
+func runForbidden(pass *analysis.Pass) (interface{}, error) {
+	res := pass.ResultOf[Analyzer.Analyzer].(Result)
+	for _, ref := range res.Forbidden {
+		msg := fmt.Sprintf("forbidden reference to %s", ref.Path[len(ref.Path)-1])
+		if ref.Rule.Reason != "" {
+			msg += " (" + ref.Rule.Reason + ")"
+		}
+		msg += ": " + strings.Join(ref.Path, " → ")
+		report.Report(pass, ref.Site, msg)
+	}
+	return nil, nil
+}
+
+// A ForbiddenReference is a reference that an unused.forbid rule
+// forbids.
+type ForbiddenReference struct {
+	// Site is the package-level object or method that makes the
+	// reference to Target.
+	Site   types.Object
+	Target types.Object
+	Rule   config.Forbidden
+	// Path names the objects along the path in the graph along which
+	// the reference is reachable, ending in Target.
+	Path []string
+}
+
+// forbiddenReferences returns the references that violate the rules.
+// References are attributed to the package-level object or method
+// that makes them, together with the path in the graph along which
+// they are reachable.
+func (g *graph) forbiddenReferences(active []config.Forbidden) []ForbiddenReference {
+	// Shortest paths from the root, for explaining how references
+	// are reachable.
+	parents := map[*refgraph.Node]*refgraph.Node{g.Root: nil}
+	queue := []*refgraph.Node{g.Root}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for _, e := range n.Uses {
+			if _, ok := parents[e.Node]; !ok {
+				parents[e.Node] = n
+				queue = append(queue, e.Node)
+			}
+		}
+	}
+	var users map[*refgraph.Node][]*refgraph.Node
+
+	// site returns the top-level object that n belongs to, looking at
+	// the path from the root if n is reachable, and at its users
+	// otherwise.
+	site := func(n *refgraph.Node) types.Object {
+		if _, ok := parents[n]; ok {
+			for m := n; m != nil && m != g.Root; m = parents[m] {
+				if obj := g.declaringObject(m); obj != nil {
+					return obj
+				}
+			}
+			return nil
+		}
+		if users == nil {
+			users = map[*refgraph.Node][]*refgraph.Node{}
+			for _, m := range g.Nodes {
+				for _, e := range m.Uses {
+					users[e.Node] = append(users[e.Node], m)
+				}
+			}
+			for _, m := range g.TypeNodes {
+				for _, e := range m.Uses {
+					users[e.Node] = append(users[e.Node], m)
+				}
+			}
+		}
+		seen := map[*refgraph.Node]bool{n: true}
+		queue := []*refgraph.Node{n}
+		for len(queue) > 0 {
+			m := queue[0]
+			queue = queue[1:]
+			if obj := g.declaringObject(m); obj != nil {
+				return obj
+			}
+			for _, u := range users[m] {
+				if !seen[u] {
+					seen[u] = true
+					queue = append(queue, u)
+				}
+			}
+		}
+		return nil
+	}
+
+	type key struct {
+		site   types.Object
+		target types.Object
+	}
+	seen := map[key]bool{}
+	var out []ForbiddenReference
+	add := func(site, target types.Object) {
+		if site == nil || target == nil || target.Pkg() == nil || seen[key{site, target}] {
+			return
+		}
+		for _, rule := range active {
+			if matchesAny([]string{rule.To}, target) {
+				seen[key{site, target}] = true
+				out = append(out, ForbiddenReference{Site: site, Target: target, Rule: rule})
+				return
+			}
+		}
+	}
+	for _, n := range g.Nodes {
+		for _, e := range n.Uses {
+			if target := nodeObject(e.Node); target != nil && target.Pkg() != nil {
+				add(site(n), target)
+			}
+		}
+	}
+	for _, n := range g.TypeNodes {
+		for _, e := range n.Uses {
+			if target := nodeObject(e.Node); target != nil && target.Pkg() != nil {
+				add(site(n), target)
+			}
+		}
+	}
+	// The IR inlines constants, and the graph attributes constants
+	// used outside of functions to the root, not to their users.
+	for id, obj := range g.pkg.TypesInfo.Uses {
+		if c, ok := obj.(*types.Const); ok && c.Pkg() != nil && c.Pkg() != g.pkg.Pkg {
+			add(g.enclosingObject(id.Pos()), c)
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		ri, rj := out[i], out[j]
+		if ri.Site != rj.Site {
+			return ri.Site.Pos() < rj.Site.Pos()
+		}
+		return ri.Target.Pkg().Path()+"."+ri.Target.Name() < rj.Target.Pkg().Path()+"."+rj.Target.Name()
+	})
+	for i, ref := range out {
+		var names []string
+		if n, ok := g.Lookup(ref.Site); ok && n.Seen {
+			for m := n; m != g.Root; m = parents[m] {
+				if obj := g.declaringObject(m); obj != nil {
+					name := g.objectString(obj)
+					if len(names) == 0 || names[len(names)-1] != name {
+						names = append(names, name)
+					}
+				}
+			}
+			for i, j := 0, len(names)-1; i < j; i, j = i+1, j-1 {
+				names[i], names[j] = names[j], names[i]
+			}
+		} else {
+			names = append(names, g.objectString(ref.Site))
+		}
+		out[i].Path = append(names, g.objectString(ref.Target))
+	}
+	return out
+}
+
+// enclosingObject returns the package-level object or method whose
+// declaration contains pos.
+func (g *graph) enclosingObject(pos token.Pos) types.Object {
+	for _, f := range g.pkg.Files {
+		if pos < f.Pos() || pos >= f.End() {
+			continue
+		}
+		for _, decl := range f.Decls {
+			if pos < decl.Pos() || pos >= decl.End() {
+				continue
+			}
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				return g.pkg.TypesInfo.Defs[decl.Name]
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					if pos < spec.Pos() || pos >= spec.End() {
+						continue
+					}
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						return g.pkg.TypesInfo.Defs[spec.Name]
+					case *ast.ValueSpec:
+						return g.pkg.TypesInfo.Defs[spec.Names[0]]
+					}
+				}
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// declaringObject returns the package-level object or method of the
+// package that n is, or is declared in, such as the struct type of a
+// field or the function of a local variable.
+func (g *graph) declaringObject(n *refgraph.Node) types.Object {
+	obj := nodeObject(n)
+	if obj == nil || obj.Pkg() != g.pkg.Pkg {
+		return nil
+	}
+	d, ok := g.pkg.Ownership.Decl(obj)
+	if !ok {
+		return nil
+	}
+	switch node := d.Node().(type) {
+	case *ast.FuncDecl:
+		return g.pkg.TypesInfo.Defs[node.Name]
+	case *ast.TypeSpec:
+		return g.pkg.TypesInfo.Defs[node.Name]
+	case *ast.ValueSpec:
+		if obj.Parent() == obj.Pkg().Scope() {
+			return obj
+		}
+		// Objects of function literals in initializers belong to
+		// the first variable.
+		return g.pkg.TypesInfo.Defs[node.Names[0]]
+	default:
+		return nil
+	}
+}
+
+// nodeObject returns the object of n, which for named types is their
+// type name.
+func nodeObject(n *refgraph.Node) types.Object {
+	switch obj := n.Obj.(type) {
+	case types.Object:
+		return obj
+	case *types.Named:
+		return obj.Obj()
+	default:
+		return nil
+	}
+}
+
+// objectString returns a short name of obj, such as T.Method for
+// methods, qualified by its package's name if it belongs to another
+// package.
+func (g *graph) objectString(obj types.Object) string {
+	name := obj.Name()
+	if fn, ok := obj.(*types.Func); ok {
+		if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+			T := recv.Type()
+			if ptr, ok := T.(*types.Pointer); ok {
+				T = ptr.Elem()
+			}
+			if named, ok := T.(*types.Named); ok {
+				name = named.Obj().Name() + "." + name
+			}
+		}
+	}
+	if obj.Pkg() != g.pkg.Pkg {
+		name = obj.Pkg().Name() + "." + name
+	}
+	return name
+}
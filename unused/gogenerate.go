@@ -0,0 +1,94 @@
+package unused
+
+import (
+	"go/types"
+	"regexp"
+	"strings"
+
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/unused/refgraph"
+)
+
+// goGenerateNameRe matches the arguments of go:generate command lines
+// that may name objects of the package, such as Foo, *Foo, Foo.Method
+// and pkg.Foo.
+var goGenerateNameRe = regexp.MustCompile(`^\*?([\pL_][\pL\pN_]*(?:\.[\pL_][\pL\pN_]*){0,2})$`)
+
+// goGenerateNames returns the arguments of the command line of a
+// //go:generate directive that may name objects. It skips the command
+// itself, paths and environment variables, and flags without values.
+// The values of flags, such as Foo in -type=Foo, and comma-separated
+// lists, such as -type Foo,Bar, are split up.
+func goGenerateNames(line string) []string {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+	args := fields[1:]
+	if fields[0] == "go" && len(args) > 0 {
+		// go run ./cmd/gen, go tool stringer
+		if args[0] == "tool" && len(args) > 1 {
+			args = args[1:]
+		}
+		args = args[1:]
+	}
+	var out []string
+	for _, arg := range args {
+		arg = strings.Trim(arg, `"'`)
+		if strings.HasPrefix(arg, "-") {
+			i := strings.IndexByte(arg, '=')
+			if i == -1 {
+				continue
+			}
+			arg = strings.Trim(arg[i+1:], `"'`)
+		}
+		if strings.ContainsAny(arg, "/$") || strings.HasSuffix(arg, ".go") {
+			continue
+		}
+		for _, name := range strings.Split(arg, ",") {
+			if m := goGenerateNameRe.FindStringSubmatch(name); m != nil {
+				out = append(out, m[1])
+			}
+		}
+	}
+	return out
+}
+
+// goGenerateMentions returns the objects of the package that the
+// command lines of its //go:generate directives mention, resolved like
+// doc links.
+func (g *graph) goGenerateMentions() []types.Object {
+	var out []types.Object
+	for _, f := range g.pkg.Files {
+		for _, cg := range f.Comments {
+			for _, c := range cg.List {
+				if !strings.HasPrefix(c.Text, "//go:generate ") && !strings.HasPrefix(c.Text, "//go:generate\t") {
+					continue
+				}
+				line := c.Text[len("//go:generate"):]
+				for _, name := range goGenerateNames(line) {
+					out = append(out, g.resolveDocLink(strings.Split(name, "."))...)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// useGoGenerate handles the objects that go:generate directives
+// mention, according to the unused.go_generate option. Kept objects
+// are used by the package, while mentioned ones are only recorded, so
+// that they can be reported as such if they are unused.
+func (g *graph) useGoGenerate() {
+	switch g.goGenerate {
+	case config.GoGenerateKeep:
+		for _, obj := range g.goGenerateMentions() {
+			g.seeAndUse(obj, nil, refgraph.EdgeGoGenerate)
+		}
+	case config.GoGenerateMention:
+		g.generateMentioned = map[types.Object]bool{}
+		for _, obj := range g.goGenerateMentions() {
+			g.generateMentioned[obj] = true
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package unused
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGoGenerateNames(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{" go run ./cmd/gen -impl Foo", []string{"Foo"}},
+		{" stringer -type=Kind,Color", []string{"Kind", "Color"}},
+		{` stringer -type "Kind" -output kind_string.go`, []string{"Kind"}},
+		{" mockgen -destination=mock.go . Store", []string{"Store"}},
+		{" go tool gen -recv *T.Method pkg.Other", []string{"T.Method", "pkg.Other"}},
+		{" gen $GOFILE -flag", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := goGenerateNames(tt.line); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
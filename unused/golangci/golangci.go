@@ -0,0 +1,163 @@
+// Package golangci makes the unused code analyzer available as a
+// golangci-lint module plugin, including all of the options of the
+// unused analyzer.
+//
+// Module plugins register themselves with
+// github.com/golangci/plugin-module-register, which this module doesn't
+// depend on. A plugin module only has to forward New:
+//
+//	func init() {
+//		register.Plugin("unused", func(settings any) (register.LinterPlugin, error) {
+//			return golangci.New(settings)
+//		})
+//	}
+//
+// The settings are the options of the unused analyzer, using the same
+// names as the [unused] table of staticcheck.conf files, for example:
+//
+//	linters-settings:
+//	  custom:
+//	    unused:
+//	      type: module
+//	      settings:
+//	        keep: ["example.com/pkg.Hook*"]
+//	        rules:
+//	          receiver_names: true
+//
+// The settings replace the default configuration; staticcheck.conf
+// files still take precedence over them.
+package golangci
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"honnef.co/go/tools/analysis/facts/generated"
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/unused"
+
+	"github.com/BurntSushi/toml"
+	"golang.org/x/tools/go/analysis"
+)
+
+// LoadModeTypesInfo is the load mode that the analyzer requires, as
+// named by golangci-lint.
+const LoadModeTypesInfo = "typesinfo"
+
+// Analyzer reports unused objects, using the default configuration.
+// Unlike unused.Analyzer, which leaves reporting to the driver, it
+// reports the objects of each package on its own.
+var Analyzer = newAnalyzer(config.Analyzer, unused.Analyzer.Analyzer)
+
+func newAnalyzer(cfg, unusedAnalyzer *analysis.Analyzer) *analysis.Analyzer {
+	return &analysis.Analyzer{
+		Name: "unused",
+		Doc:  "Unused code",
+		Run: func(pass *analysis.Pass) (interface{}, error) {
+			return run(pass, unusedAnalyzer)
+		},
+		Requires: []*analysis.Analyzer{unusedAnalyzer, generated.Analyzer, cfg},
+	}
+}
+
+// Plugin implements golangci-lint's LinterPlugin interface.
+type Plugin struct {
+	settings config.Unused
+}
+
+// New decodes the plugin's settings, which are the options of the
+// [unused] table of staticcheck.conf files, as decoded from
+// golangci-lint's configuration.
+func New(settings interface{}) (*Plugin, error) {
+	cfg, err := decodeSettings(settings)
+	if err != nil {
+		return nil, err
+	}
+	return &Plugin{settings: cfg}, nil
+}
+
+// BuildAnalyzers returns an analyzer that uses the plugin's settings
+// as the default configuration of the unused analyzer.
+func (p *Plugin) BuildAnalyzers() ([]*analysis.Analyzer, error) {
+	defaults := config.DefaultConfig
+	defaults.Unused = defaults.Unused.Merge(p.settings)
+	cfg := config.NewAnalyzer(defaults)
+	return []*analysis.Analyzer{newAnalyzer(cfg, unused.NewAnalyzer(cfg))}, nil
+}
+
+func (*Plugin) GetLoadMode() string {
+	return LoadModeTypesInfo
+}
+
+func decodeSettings(settings interface{}) (config.Unused, error) {
+	if settings == nil {
+		return config.Unused{}, nil
+	}
+	m, ok := settings.(map[string]interface{})
+	if !ok {
+		return config.Unused{}, fmt.Errorf("invalid settings of type %T", settings)
+	}
+
+	// Round-tripping the settings through TOML gives them the exact
+	// names and semantics of the options in staticcheck.conf files.
+	buf := &bytes.Buffer{}
+	if err := toml.NewEncoder(buf).Encode(m); err != nil {
+		return config.Unused{}, fmt.Errorf("invalid settings: %s", err)
+	}
+	var cfg config.Unused
+	md, err := toml.Decode(buf.String(), &cfg)
+	if err != nil {
+		return config.Unused{}, fmt.Errorf("invalid settings: %s", err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		keys := make([]string, len(undecoded))
+		for i, key := range undecoded {
+			keys[i] = key.String()
+		}
+		sort.Strings(keys)
+		return config.Unused{}, fmt.Errorf("unknown settings: %v", keys)
+	}
+
+	if cfg.WholeProgram {
+		// golangci-lint runs analyzers on one package at a time,
+		// without a driver that could combine their results.
+		return config.Unused{}, errors.New("whole_program isn't supported by golangci-lint")
+	}
+	if err := config.DefaultConfig.Unused.Merge(cfg).Validate(); err != nil {
+		return config.Unused{}, err
+	}
+	return cfg, nil
+}
+
+func run(pass *analysis.Pass, unusedAnalyzer *analysis.Analyzer) (interface{}, error) {
+	res := pass.ResultOf[unusedAnalyzer].(unused.Result)
+	cfg := config.For(pass).Unused
+	if cfg.WholeProgram {
+		return nil, errors.New("whole_program isn't supported by golangci-lint")
+	}
+	sres := unused.Serialize(pass, res, pass.Fset)
+	for i, obj := range res.Unused {
+		sobj := sres.Unused[i]
+		if sobj.Kind == "type param" {
+			continue
+		}
+		if sobj.InGenerated && cfg.Generated != config.GeneratedReport {
+			continue
+		}
+		diag := analysis.Diagnostic{
+			Pos:            obj.Pos(),
+			Message:        sobj.Message(),
+			SuggestedFixes: res.Fixes[obj],
+		}
+		if msg := sobj.FixErrorMessage(); msg != "" {
+			diag.Related = append(diag.Related, analysis.RelatedInformation{
+				Pos:     obj.Pos(),
+				Message: msg,
+			})
+		}
+		pass.Report(diag)
+	}
+	return nil, nil
+}
@@ -0,0 +1,57 @@
+package golangci
+
+import (
+	"reflect"
+	"testing"
+
+	"honnef.co/go/tools/config"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	defaults := config.DefaultConfig.Unused
+	// Plugins only use their own settings, however many get created.
+	for i := 0; i < 2; i++ {
+		p, err := New(map[string]interface{}{
+			"keep": []interface{}{"example.hook"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		analyzers, err := p.BuildAnalyzers()
+		if err != nil {
+			t.Fatal(err)
+		}
+		analysistest.Run(t, analysistest.TestData(), analyzers[0], "example")
+	}
+	if !reflect.DeepEqual(config.DefaultConfig.Unused, defaults) {
+		t.Errorf("plugins changed the default configuration")
+	}
+}
+
+func TestSettings(t *testing.T) {
+	cfg, err := decodeSettings(map[string]interface{}{
+		"max_nodes": 100,
+		"keep":      []interface{}{"inherit", "example.com/pkg.Hook*"},
+		"rules":     map[string]interface{}{"receiver_names": true},
+		"positions": "raw",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.MaxNodes != 100 || len(cfg.Keep) != 2 || !cfg.Rules[config.RuleReceiverNames] || cfg.Positions != config.PositionsRaw {
+		t.Errorf("settings decoded incorrectly: %#v", cfg)
+	}
+
+	invalid := []map[string]interface{}{
+		{"max_node": 100},
+		{"positions": "bogus"},
+		{"whole_program": true},
+	}
+	for _, settings := range invalid {
+		if _, err := decodeSettings(settings); err == nil {
+			t.Errorf("expected an error for %v", settings)
+		}
+	}
+}
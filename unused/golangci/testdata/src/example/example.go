@@ -0,0 +1,15 @@
+package pkg
+
+func fn1() {} // want `func fn1 is unused`
+
+func Fn2() {}
+
+var x = 1 // want `var x is assigned but never read`
+
+func fn2() { x = 2 }
+
+func init() { fn2() }
+
+type t1 struct{} // want `type t1 is unused`
+
+func hook() {}
@@ -0,0 +1,149 @@
+package unused
+
+import (
+	"go/ast"
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"honnef.co/go/tools/unused/refgraph"
+)
+
+// ignoredFileNames returns the names that the package's files
+// excluded with //go:build ignore refer to. These files, typically
+// scripts run with go run, don't get type-checked, so names are all
+// we have: files of the same package refer to the names that they
+// don't declare themselves, and files importing the package refer to
+// the exported names that they select, either from the package or
+// from values of its types.
+func (g *graph) ignoredFileNames() map[string]bool {
+	names := map[string]bool{}
+	for _, path := range g.pkg.IgnoredFiles {
+		if !strings.HasSuffix(path, ".go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ParseComments)
+		if err != nil || !ignoreConstraint(f) {
+			continue
+		}
+		if f.Name.Name == g.pkg.Pkg.Name() {
+			ast.Inspect(f, func(node ast.Node) bool {
+				// The parser resolves the identifiers that refer to
+				// the file's own declarations.
+				if id, ok := node.(*ast.Ident); ok && id.Obj == nil {
+					names[id.Name] = true
+				}
+				return true
+			})
+			continue
+		}
+		imported := false
+		for _, imp := range f.Imports {
+			if path, err := strconv.Unquote(imp.Path.Value); err == nil && path == g.pkg.Pkg.Path() {
+				imported = true
+				break
+			}
+		}
+		if !imported {
+			continue
+		}
+		ast.Inspect(f, func(node ast.Node) bool {
+			if sel, ok := node.(*ast.SelectorExpr); ok && sel.Sel.IsExported() {
+				names[sel.Sel.Name] = true
+			}
+			return true
+		})
+	}
+	return names
+}
+
+// ignoreConstraint reports whether f's build constraints mention the
+// ignore tag, which is how files get excluded from builds for good.
+func ignoreConstraint(f *ast.File) bool {
+	for _, cg := range f.Comments {
+		if cg.Pos() >= f.Package {
+			break
+		}
+		for _, c := range cg.List {
+			if !constraint.IsGoBuild(c.Text) && !constraint.IsPlusBuild(c.Text) {
+				continue
+			}
+			expr, err := constraint.Parse(c.Text)
+			if err != nil {
+				continue
+			}
+			ignore := false
+			expr.Eval(func(tag string) bool {
+				if tag == "ignore" {
+					ignore = true
+				}
+				return false
+			})
+			if ignore {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ignoredOnly finds the unused objects that files excluded with
+// //go:build ignore refer to by name, and the unused objects that
+// only these objects use, directly or indirectly. It has to run after
+// results.
+func (g *graph) ignoredOnly(unused []types.Object) map[types.Object]bool {
+	if len(g.pkg.IgnoredFiles) == 0 || len(unused) == 0 {
+		return nil
+	}
+	names := g.ignoredFileNames()
+	if len(names) == 0 {
+		return nil
+	}
+	isUnused := map[types.Object]bool{}
+	for _, obj := range unused {
+		isUnused[obj] = true
+	}
+	out := map[types.Object]bool{}
+	seen := map[*refgraph.Node]bool{}
+	var walk func(n *refgraph.Node)
+	walk = func(n *refgraph.Node) {
+		for _, e := range n.Uses {
+			if e.Node.Seen || seen[e.Node] {
+				continue
+			}
+			seen[e.Node] = true
+			if obj, ok := e.Node.Obj.(types.Object); ok && isUnused[obj] {
+				out[obj] = true
+			}
+			walk(e.Node)
+		}
+	}
+	for _, obj := range unused {
+		if !names[obj.Name()] || !g.ignoredFileCandidate(obj) {
+			continue
+		}
+		out[obj] = true
+		if n, ok := g.Lookup(obj); ok && !seen[n] {
+			seen[n] = true
+			walk(n)
+		}
+	}
+	return out
+}
+
+// ignoredFileCandidate reports whether files other than the
+// package's own can refer to obj by name: whether it is a
+// package-level object, a field or a method.
+func (g *graph) ignoredFileCandidate(obj types.Object) bool {
+	switch obj := obj.(type) {
+	case *types.Var:
+		return obj.IsField() || obj.Parent() == g.pkg.Pkg.Scope()
+	case *types.Func:
+		return true
+	default:
+		return obj.Parent() == g.pkg.Pkg.Scope()
+	}
+}
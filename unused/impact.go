@@ -0,0 +1,104 @@
+package unused
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/typeparams"
+)
+
+// A Reference is a reference to an object of another package that
+// matches the configured impact patterns, which tells the maintainers
+// of that package what removing the object would break.
+type Reference struct {
+	// Target is the referenced object.
+	Target types.Object
+	// From is the package-level object or method whose declaration
+	// contains the reference, or nil if there is none, such as for
+	// references in blank variable declarations.
+	From types.Object
+	// Pos is the position of the reference.
+	Pos token.Pos
+}
+
+// references returns the references of the package to the objects of
+// other packages that match patterns, sorted by position.
+func references(pkg *pkg, patterns []string) []Reference {
+	if pkg.Pkg.Name() == "main" && strings.HasSuffix(pkg.Pkg.Path(), ".test") {
+		// The main packages that go test synthesizes refer to all
+		// tests, which doesn't tell anyone anything.
+		return nil
+	}
+	var out []Reference
+	for id, obj := range pkg.TypesInfo.Uses {
+		if obj.Pkg() == nil || obj.Pkg() == pkg.Pkg {
+			continue
+		}
+		if fn, ok := obj.(*types.Func); ok {
+			obj = typeparams.OriginMethod(fn)
+		}
+		if !isImpactTarget(obj) || !matchesAny(patterns, obj) {
+			continue
+		}
+		out = append(out, Reference{
+			Target: obj,
+			From:   enclosingObject(pkg, id.Pos()),
+			Pos:    id.Pos(),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Pos < out[j].Pos
+	})
+	return out
+}
+
+// isImpactTarget reports whether obj is an object that a package can
+// remove from its API: a package-level object, a method or a field.
+func isImpactTarget(obj types.Object) bool {
+	switch obj := obj.(type) {
+	case *types.PkgName:
+		return false
+	case *types.Var:
+		return obj.IsField() || obj.Parent() == obj.Pkg().Scope()
+	case *types.Func:
+		return true
+	default:
+		return obj.Parent() == obj.Pkg().Scope()
+	}
+}
+
+// enclosingObject returns the package-level object or method whose
+// declaration contains pos.
+func enclosingObject(pkg *pkg, pos token.Pos) types.Object {
+	for _, f := range pkg.Files {
+		if pos < f.Pos() || pos >= f.End() {
+			continue
+		}
+		i := sort.Search(len(f.Decls), func(i int) bool {
+			return f.Decls[i].End() > pos
+		})
+		if i == len(f.Decls) || f.Decls[i].Pos() > pos {
+			return nil
+		}
+		switch decl := f.Decls[i].(type) {
+		case *ast.FuncDecl:
+			return pkg.TypesInfo.Defs[decl.Name]
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				if pos < spec.Pos() || pos >= spec.End() {
+					continue
+				}
+				for _, name := range specNames(spec) {
+					if name.Name != "_" {
+						return pkg.TypesInfo.Defs[name]
+					}
+				}
+			}
+		}
+		return nil
+	}
+	return nil
+}
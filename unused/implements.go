@@ -1,6 +1,10 @@
 package unused
 
-import "go/types"
+import (
+	"go/types"
+
+	"honnef.co/go/tools/go/types/typeutil"
+)
 
 // lookupMethod returns the index of and method with matching package and name, or (-1, nil).
 func lookupMethod(T *types.Interface, pkg *types.Package, name string) (int, *types.Func) {
@@ -37,6 +41,85 @@ func sameId(obj types.Object, pkg *types.Package, name string) bool {
 	return pkg.Path() == obj.Pkg().Path()
 }
 
+// An implResult is the memoized result of graph.implements.
+type implResult struct {
+	sels []*types.Selection
+	ok   bool
+}
+
+// implementsCached is like implements, but memoizes the results per
+// pair of identical types. It also reuses results between a type T
+// and *T, as T implementing an interface means that *T does, too, via
+// the same methods, and *T not implementing it means that T doesn't,
+// either.
+//
+// The cache is per package; the runner type-checks every package
+// separately, so types aren't identical across packages.
+//
+// Only named types and pointers to them are memoized. They are the
+// only types with methods, and the hasher can't hash the types that
+// the IR builder makes up, such as iterators and pointers to them.
+func (g *graph) implementsCached(V types.Type, T *types.Interface, msV *types.MethodSet) ([]*types.Selection, bool) {
+	g.stats.ImplementsChecks++
+	if !isMemoizable(V) {
+		return g.implements(V, T, msV)
+	}
+	cache, ok := g.implCache.At(T)
+	if !ok {
+		cache = &typeutil.Map[implResult]{}
+		cache.SetHasher(g.hasher)
+		g.implCache.Set(T, cache)
+	}
+	if res, ok := cache.At(V); ok {
+		g.stats.ImplementsCacheHits++
+		return res.sels, res.ok
+	}
+
+	if ptr, ok := V.(*types.Pointer); ok && hasPointerMethodSet(ptr.Elem()) {
+		if res, ok := cache.At(ptr.Elem()); ok && res.ok {
+			g.stats.ImplementsCacheHits++
+			cache.Set(V, res)
+			return res.sels, res.ok
+		}
+	} else if hasPointerMethodSet(V) {
+		if res, ok := cache.At(types.NewPointer(V)); ok && !res.ok {
+			g.stats.ImplementsCacheHits++
+			cache.Set(V, res)
+			return res.sels, res.ok
+		}
+	}
+
+	sels, ok := g.implements(V, T, msV)
+	cache.Set(V, implResult{sels, ok})
+	return sels, ok
+}
+
+// isMemoizable reports whether implementsCached memoizes the results
+// for T.
+func isMemoizable(T types.Type) bool {
+	switch T := T.(type) {
+	case *types.Named:
+		return true
+	case *types.Pointer:
+		_, ok := T.Elem().(*types.Named)
+		return ok
+	default:
+		return false
+	}
+}
+
+// hasPointerMethodSet reports whether the method set of *T is a
+// superset of the method set of T, which is the case unless T is a
+// pointer or an interface.
+func hasPointerMethodSet(T types.Type) bool {
+	switch T.Underlying().(type) {
+	case *types.Pointer, *types.Interface:
+		return false
+	default:
+		return true
+	}
+}
+
 func (g *graph) implements(V types.Type, T *types.Interface, msV *types.MethodSet) ([]*types.Selection, bool) {
 	// fast path for common case
 	if T.Empty() {
@@ -0,0 +1,111 @@
+package unused
+
+import (
+	"go/ast"
+	"go/types"
+	"sort"
+
+	"honnef.co/go/tools/go/ast/astutil"
+	"honnef.co/go/tools/go/types/typeutil"
+	"honnef.co/go/tools/unused/refgraph"
+)
+
+// unsafeLayout adds the uses of call if it is a call of
+// unsafe.Offsetof, unsafe.Sizeof or unsafe.Alignof, whose results
+// depend on the layouts of structs.
+func (g *graph) unsafeLayout(call *ast.CallExpr, by interface{}) {
+	if len(call.Args) != 1 {
+		return
+	}
+	var id *ast.Ident
+	switch fun := astutil.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		// dot-imported unsafe
+		id = fun
+	case *ast.SelectorExpr:
+		id = fun.Sel
+	default:
+		return
+	}
+	builtin, ok := g.pkg.TypesInfo.Uses[id].(*types.Builtin)
+	if !ok {
+		return
+	}
+	seen := map[types.Type]struct{}{}
+	switch builtin.Name() {
+	case "Offsetof":
+		arg, ok := astutil.Unparen(call.Args[0]).(*ast.SelectorExpr)
+		if !ok {
+			return
+		}
+		s, ok := g.pkg.TypesInfo.Selections[arg]
+		if !ok {
+			return
+		}
+		// The offset of a promoted field depends on the layouts of
+		// all the structs on its path.
+		T := typeutil.Dereference(s.Recv())
+		g.seeAndUse(T, by, refgraph.EdgeUnsafeLayout)
+		g.typ(T, nil)
+		for _, idx := range s.Index() {
+			T = typeutil.Dereference(T)
+			g.layout(T, by, seen)
+			st, ok := typeutil.CoreType(T).(*types.Struct)
+			if !ok {
+				return
+			}
+			T = st.Field(idx).Type()
+		}
+	case "Sizeof", "Alignof":
+		T := g.pkg.TypesInfo.TypeOf(call.Args[0])
+		if T == nil {
+			return
+		}
+		g.seeAndUse(T, by, refgraph.EdgeUnsafeLayout)
+		g.typ(T, nil)
+		g.layout(T, by, seen)
+	}
+}
+
+// layout marks the fields of the structs in T as used by by, because
+// the layout of T matters. This includes the fields of nested structs
+// and of the elements of arrays, but not what pointers, slices and
+// maps refer to.
+func (g *graph) layout(T types.Type, by interface{}, seen map[types.Type]struct{}) {
+	T = typeutil.Unalias(T)
+	if _, ok := seen[T]; ok {
+		return
+	}
+	seen[T] = struct{}{}
+	if named, ok := T.(*types.Named); ok && named.Obj().Pkg() == g.pkg.Pkg {
+		if g.layoutSensitive == nil {
+			g.layoutSensitive = map[*types.TypeName]bool{}
+		}
+		g.layoutSensitive[named.Origin().Obj()] = true
+	}
+	switch U := T.Underlying().(type) {
+	case *types.Struct:
+		for i := 0; i < U.NumFields(); i++ {
+			g.seeAndUse(originField(T, i), by, refgraph.EdgeUnsafeLayout)
+			g.layout(U.Field(i).Type(), by, seen)
+		}
+	case *types.Array:
+		g.layout(U.Elem(), by, seen)
+	}
+}
+
+// layoutSensitiveTypes returns the named types whose layouts matter,
+// sorted by position.
+func (g *graph) layoutSensitiveTypes() []*types.TypeName {
+	if len(g.layoutSensitive) == 0 {
+		return nil
+	}
+	out := make([]*types.TypeName, 0, len(g.layoutSensitive))
+	for obj := range g.layoutSensitive {
+		out = append(out, obj)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Pos() < out[j].Pos()
+	})
+	return out
+}
@@ -0,0 +1,102 @@
+package unused
+
+import (
+	"go/ast"
+	"go/types"
+
+	"honnef.co/go/tools/go/types/typeutil"
+)
+
+// markers returns the unused marker types of the package whose only
+// references, besides the receivers of their methods, are embedded
+// fields, such as the field noCompare of struct { noCompare; x int },
+// as well as the methods of these types, such as the Lock method of a
+// noCopy marker. Marker types are named struct types
+// without fields, which are embedded to document or enforce
+// properties of the embedding structs. If a marker type is unused,
+// so are all the fields embedding it, and thus usually the types
+// declaring these fields, which makes the marker a leftover of dead
+// code rather than dead code of its own.
+func (g *graph) markers(unused []types.Object) map[types.Object]bool {
+	candidates := map[types.Object]bool{}
+	for _, obj := range unused {
+		if isMarker(obj) {
+			candidates[obj] = true
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	// typeIdent returns the identifier of the named type that expr,
+	// an embedded field or a receiver, refers to, if any.
+	typeIdent := func(expr ast.Expr) *ast.Ident {
+		if star, ok := expr.(*ast.StarExpr); ok {
+			expr = star.X
+		}
+		ident, _ := expr.(*ast.Ident)
+		return ident
+	}
+	embeddings := map[types.Object]int{}
+	receivers := map[*ast.Ident]bool{}
+	for _, f := range g.pkg.Files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncDecl:
+				if n.Recv != nil && len(n.Recv.List) == 1 {
+					if ident := typeIdent(n.Recv.List[0].Type); ident != nil {
+						receivers[ident] = true
+					}
+				}
+			case *ast.StructType:
+				for _, field := range n.Fields.List {
+					if len(field.Names) != 0 {
+						continue
+					}
+					if ident := typeIdent(field.Type); ident != nil {
+						if obj := g.pkg.TypesInfo.Uses[ident]; candidates[obj] {
+							embeddings[obj]++
+						}
+					}
+				}
+			}
+			return true
+		})
+	}
+	refs := map[types.Object]int{}
+	for ident, obj := range g.pkg.TypesInfo.Uses {
+		if candidates[obj] && !receivers[ident] {
+			refs[obj]++
+		}
+	}
+
+	out := map[types.Object]bool{}
+	for obj := range candidates {
+		if n := embeddings[obj]; n > 0 && n == refs[obj] {
+			out[obj] = true
+		}
+	}
+	for _, obj := range unused {
+		if fn, ok := obj.(*types.Func); ok {
+			if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+				if named, ok := typeutil.Dereference(recv.Type()).(*types.Named); ok && out[named.Obj()] {
+					out[obj] = true
+				}
+			}
+		}
+	}
+	return out
+}
+
+// isMarker reports whether obj is a named struct type without fields.
+func isMarker(obj types.Object) bool {
+	tname, ok := obj.(*types.TypeName)
+	if !ok || tname.IsAlias() {
+		return false
+	}
+	if _, ok := tname.Type().(*types.Named); !ok {
+		return false
+	}
+	st, ok := tname.Type().Underlying().(*types.Struct)
+	return ok && st.NumFields() == 0
+}
@@ -0,0 +1,60 @@
+package unused
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+)
+
+// A MessageContext is the data that the templates of the
+// unused.message_templates option get executed with. Besides the
+// fields of the reported object, such as Name, Kind, Category,
+// PkgPath, Position, Tests and DuplicateOf, it provides the default
+// message and some derived values.
+type MessageContext struct {
+	SerializedObject
+	// Message is the default message, as returned by
+	// SerializedObject.Message.
+	Message string
+	// Receiver is the receiver type of methods, such as *T, and empty
+	// for other objects.
+	Receiver string
+	// Action describes the suggested fix, such as "Remove func f",
+	// and is empty if no fix is suggested.
+	Action string
+}
+
+// MessageContext returns the data for executing message templates
+// for the unused object.
+func (obj SerializedObject) MessageContext() MessageContext {
+	ctx := MessageContext{
+		SerializedObject: obj,
+		Message:          obj.Message(),
+	}
+	if obj.Kind == "func" {
+		if i := strings.LastIndex(obj.Name, "."); i != -1 {
+			recv := obj.Name[:i]
+			recv = strings.TrimPrefix(recv, "(")
+			recv = strings.TrimSuffix(recv, ")")
+			ctx.Receiver = recv
+		}
+	}
+	if len(obj.Fixes) > 0 {
+		ctx.Action = obj.Fixes[0].Message
+	}
+	return ctx
+}
+
+// FormatMessage executes the message template text for the unused
+// object.
+func (obj SerializedObject) FormatMessage(text string) (string, error) {
+	tmpl, err := template.New("message").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, obj.MessageContext()); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,74 @@
+package unused
+
+import (
+	"go/types"
+
+	"honnef.co/go/tools/go/ir"
+)
+
+// FuncMetrics describe the size and complexity of an unused function,
+// which helps with prioritizing what to delete first.
+type FuncMetrics struct {
+	// Complexity is the cyclomatic complexity of the function's
+	// body, including the bodies of its closures: one plus the number
+	// of decisions, such as the conditions of if statements, loops
+	// and cases, and the operands of && and ||.
+	Complexity int
+	// Lines is the number of lines of the function's declaration,
+	// ignoring line directives.
+	Lines int
+	// Params is the number of parameters, not counting the receiver.
+	Params int
+}
+
+// metrics computes the metrics of the unused functions and methods
+// from their IR, whose control flow graphs already tell the number of
+// decisions. Functions without bodies or without IR, such as those
+// whose IR couldn't be built, get no metrics.
+func (g *graph) metrics(unused []types.Object) map[types.Object]FuncMetrics {
+	wanted := map[types.Object]bool{}
+	for _, obj := range unused {
+		if _, ok := obj.(*types.Func); ok {
+			wanted[obj] = true
+		}
+	}
+	if len(wanted) == 0 {
+		return nil
+	}
+
+	out := map[types.Object]FuncMetrics{}
+	for _, fn := range g.pkg.SrcFuncs {
+		obj := fn.Object()
+		if !wanted[obj] || len(fn.Blocks) == 0 || g.failed[fn] {
+			continue
+		}
+		src := fn.Source()
+		if src == nil {
+			continue
+		}
+		start := g.pkg.Fset.PositionFor(src.Pos(), false)
+		end := g.pkg.Fset.PositionFor(src.End(), false)
+		out[obj] = FuncMetrics{
+			Complexity: 1 + decisions(fn),
+			Lines:      end.Line - start.Line + 1,
+			Params:     obj.Type().(*types.Signature).Params().Len(),
+		}
+	}
+	return out
+}
+
+// decisions returns the number of decisions in the control flow
+// graphs of fn and its closures, which is the number of edges that
+// blocks have in addition to their first one.
+func decisions(fn *ir.Function) int {
+	n := 0
+	for _, b := range fn.Blocks {
+		if len(b.Succs) > 1 {
+			n += len(b.Succs) - 1
+		}
+	}
+	for _, anon := range fn.AnonFuncs {
+		n += decisions(anon)
+	}
+	return n
+}
@@ -0,0 +1,61 @@
+package unused
+
+import "testing"
+
+func TestMetrics(t *testing.T) {
+	const src = `package pkg
+
+func simple() {}
+
+func branches(a, b int, rest ...int) int {
+	if a > 0 && b > 0 {
+		return 1
+	}
+	for _, x := range rest {
+		switch x {
+		case 1:
+			a++
+		case 2:
+			b++
+		}
+	}
+	return a + b
+}
+
+type t struct{}
+
+func (t) closure(n int) func() bool {
+	return func() bool {
+		return n > 0 || n < -10
+	}
+}
+
+func Used() {}
+`
+	p := buildPackage(t, src)
+	g := newGraph()
+	g.entry(p)
+	_, unused, _ := results(g)
+
+	got := map[string]FuncMetrics{}
+	for obj, m := range g.metrics(unused) {
+		got[obj.Name()] = m
+	}
+	want := map[string]FuncMetrics{
+		"simple": {Complexity: 1, Lines: 1, Params: 0},
+		// if, &&, range, and two cases
+		"branches": {Complexity: 6, Lines: 14, Params: 3},
+		// || in the closure
+		"closure": {Complexity: 2, Lines: 5, Params: 1},
+	}
+	for name, w := range want {
+		if m, ok := got[name]; !ok {
+			t.Errorf("no metrics for %s", name)
+		} else if m != w {
+			t.Errorf("%s: got %+v, want %+v", name, m, w)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("got metrics for %d functions, want %d", len(got), len(want))
+	}
+}
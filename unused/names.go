@@ -0,0 +1,156 @@
+package unused
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"honnef.co/go/tools/analysis/edit"
+	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/config"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// checkNames flags named receivers and named results that are never
+// referenced in their functions' bodies, depending on which of
+// RuleReceiverNames and RuleNamedResults are enabled.
+func checkNames(pass *analysis.Pass, cfg config.Unused) {
+	used := map[types.Object]struct{}{}
+	for _, obj := range pass.TypesInfo.Uses {
+		if v, ok := obj.(*types.Var); ok {
+			used[v] = struct{}{}
+		}
+	}
+	isUsed := func(name *ast.Ident) bool {
+		obj := pass.TypesInfo.Defs[name]
+		if obj == nil {
+			return true
+		}
+		_, ok := used[obj]
+		return ok
+	}
+
+	var opts []report.Option
+	if cfg.Generated != config.GeneratedReport {
+		opts = append(opts, report.FilterGenerated())
+	}
+
+	for _, f := range pass.Files {
+		ast.Inspect(f, func(node ast.Node) bool {
+			var typ *ast.FuncType
+			var body *ast.BlockStmt
+			switch fn := node.(type) {
+			case *ast.FuncDecl:
+				if fn.Body == nil {
+					return false
+				}
+				if cfg.Rules[config.RuleReceiverNames] {
+					checkReceiver(pass, fn, isUsed, opts)
+				}
+				typ, body = fn.Type, fn.Body
+			case *ast.FuncLit:
+				typ, body = fn.Type, fn.Body
+			default:
+				return true
+			}
+			if cfg.Rules[config.RuleNamedResults] {
+				checkResults(pass, typ, body, isUsed, opts)
+			}
+			return true
+		})
+	}
+}
+
+// checkReceiver flags the receiver of fn if it is named but never
+// referenced, and suggests renaming it to _ or removing its name
+// altogether.
+func checkReceiver(pass *analysis.Pass, fn *ast.FuncDecl, isUsed func(*ast.Ident) bool, opts []report.Option) {
+	if fn.Recv == nil || len(fn.Recv.List) != 1 {
+		return
+	}
+	field := fn.Recv.List[0]
+	if len(field.Names) != 1 || field.Names[0].Name == "_" {
+		return
+	}
+	name := field.Names[0]
+	if isUsed(name) {
+		return
+	}
+
+	fixes := []analysis.SuggestedFix{
+		edit.Fix("Rename receiver to _", edit.ReplaceWithString(name, "_")),
+		edit.Fix("Remove receiver name", edit.Delete(edit.Range{name.Pos(), field.Type.Pos()})),
+	}
+	report.Report(pass, name, fmt.Sprintf("receiver %s is unused", name.Name), append(opts, report.Fixes(fixes...))...)
+}
+
+// checkResults flags named results that are never referenced, neither
+// in the function itself nor in the closures it defers, and suggests
+// renaming them to _. If none of the results are referenced and the
+// function has no bare returns, which would return the zero values of
+// the results, it also suggests removing the names altogether.
+func checkResults(pass *analysis.Pass, typ *ast.FuncType, body *ast.BlockStmt, isUsed func(*ast.Ident) bool, opts []report.Option) {
+	if typ.Results == nil {
+		return
+	}
+	var unused []*ast.Ident
+	all := true
+	for _, field := range typ.Results.List {
+		for _, name := range field.Names {
+			if name.Name == "_" {
+				continue
+			}
+			if isUsed(name) {
+				all = false
+			} else {
+				unused = append(unused, name)
+			}
+		}
+	}
+	if len(unused) == 0 {
+		return
+	}
+
+	var unname *analysis.SuggestedFix
+	if all && !hasBareReturn(body) {
+		var typs []string
+		for _, field := range typ.Results.List {
+			for range field.Names {
+				typs = append(typs, report.Render(pass, field.Type))
+			}
+		}
+		s := strings.Join(typs, ", ")
+		if len(typs) > 1 {
+			s = "(" + s + ")"
+		}
+		fix := edit.Fix("Remove result names", edit.ReplaceWithString(typ.Results, s))
+		unname = &fix
+	}
+	for _, name := range unused {
+		fixes := []analysis.SuggestedFix{edit.Fix("Rename result to _", edit.ReplaceWithString(name, "_"))}
+		if unname != nil {
+			fixes = append(fixes, *unname)
+		}
+		report.Report(pass, name, fmt.Sprintf("named result %s is unused", name.Name), append(opts, report.Fixes(fixes...))...)
+	}
+}
+
+// hasBareReturn reports whether body contains a return statement
+// without values, not counting function literals.
+func hasBareReturn(body *ast.BlockStmt) bool {
+	found := false
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			if len(node.Results) == 0 {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
@@ -0,0 +1,87 @@
+package unused
+
+import (
+	"go/types"
+	"sync"
+
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/unused/refgraph"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// An EdgeProvider injects uses into the graph that the analysis can't
+// see by itself, such as the wiring of a dependency injection
+// framework or the callers in code that is generated at build time.
+type EdgeProvider interface {
+	// Edges returns the extra uses of the package being analyzed. It
+	// is called once per package and may be called concurrently for
+	// different packages.
+	Edges(pass *analysis.Pass) []Edge
+}
+
+// An Edge is a use of an object.
+type Edge struct {
+	// Used is the used object. Edges to objects of other packages are
+	// ignored.
+	Used types.Object
+	// By is the object that uses Used, or nil if Used is always used,
+	// like exported objects are. Used is only as reachable as By.
+	By types.Object
+}
+
+var edgeProviders struct {
+	mu        sync.Mutex
+	providers []EdgeProvider
+}
+
+// RegisterEdgeProvider registers p to be consulted for every analyzed
+// package. Programs that embed the analyzer should call it before
+// running any analyses, typically from an init function.
+func RegisterEdgeProvider(p EdgeProvider) {
+	edgeProviders.mu.Lock()
+	defer edgeProviders.mu.Unlock()
+	edgeProviders.providers = append(edgeProviders.providers, p)
+}
+
+// builtinProviders are the edge providers that come with the
+// analysis, keyed by the rules that enable them.
+var builtinProviders = map[string]EdgeProvider{
+	config.RuleDependencyInjection: diProvider{},
+}
+
+// providedEdges returns the edges that the providers of enabled rules
+// and the registered providers return for the package.
+func providedEdges(pass *analysis.Pass, rules map[string]bool) []Edge {
+	edgeProviders.mu.Lock()
+	providers := edgeProviders.providers
+	edgeProviders.mu.Unlock()
+	var out []Edge
+	for rule, p := range builtinProviders {
+		if rules[rule] {
+			out = append(out, p.Edges(pass)...)
+		}
+	}
+	for _, p := range providers {
+		out = append(out, p.Edges(pass)...)
+	}
+	return out
+}
+
+// useProvided adds the edges of edge providers to the graph. Edges
+// from or to objects of other packages are dropped.
+func (g *graph) useProvided() {
+	for _, e := range g.provided {
+		if e.Used == nil || e.Used.Pkg() != g.pkg.Pkg {
+			continue
+		}
+		if e.By == nil {
+			g.seeAndUse(e.Used, nil, refgraph.EdgeProvided)
+			continue
+		}
+		if e.By.Pkg() != g.pkg.Pkg || g.see(e.By) == nil {
+			continue
+		}
+		g.seeAndUse(e.Used, e.By, refgraph.EdgeProvided)
+	}
+}
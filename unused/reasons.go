@@ -0,0 +1,25 @@
+package unused
+
+import (
+	"go/types"
+
+	"honnef.co/go/tools/unused/refgraph"
+)
+
+// reasons maps the used objects to the kinds of all edges that lead to
+// them from the root and other used objects. It has to run after
+// results.
+func (g *graph) reasons(used []types.Object) map[types.Object]refgraph.EdgeKind {
+	kinds := g.Reasons()
+	out := map[types.Object]refgraph.EdgeKind{}
+	for _, obj := range used {
+		n, ok := g.Lookup(obj)
+		if !ok {
+			continue
+		}
+		if kind, ok := kinds[n]; ok {
+			out[obj] = kind
+		}
+	}
+	return out
+}
@@ -0,0 +1,57 @@
+package refgraph
+
+import "unsafe"
+
+// Nodes are allocated in chunks instead of one at a time. Huge graphs
+// have millions of nodes, and allocating them individually makes the
+// garbage collector track, and repeatedly scan, millions of small
+// objects. Chunks start small, so that the graphs of small packages
+// don't waste memory, and double in size up to a limit.
+const (
+	minChunk = 64
+	maxChunk = 4096
+)
+
+type nodeAllocator struct {
+	// the unused rest of the current chunk
+	chunk []Node
+	// the size of the current chunk
+	size int
+	nodeArena
+}
+
+func (a *nodeAllocator) alloc() *Node {
+	if len(a.chunk) == 0 {
+		if a.size == 0 {
+			a.size = minChunk
+		} else if a.size < maxChunk {
+			a.size *= 2
+		}
+		a.chunk = a.makeChunk(a.size)
+	}
+	n := &a.chunk[0]
+	a.chunk = a.chunk[1:]
+	return n
+}
+
+func (a *nodeAllocator) free() {
+	a.chunk = nil
+	a.freeChunks()
+}
+
+// Approximate sizes of the parts of a graph, in bytes. Edges are
+// stored in slices that grow by doubling, which wastes a quarter of
+// their capacity on average, and nodes are also stored in maps,
+// which cost about twice the size of their entries.
+const (
+	nodeSize  = int(unsafe.Sizeof(Node{}))
+	edgeSize  = int(unsafe.Sizeof(Edge{}))
+	entrySize = 2 * int(unsafe.Sizeof(interface{}(nil))+unsafe.Sizeof((*Node)(nil)))
+)
+
+// EstimateMemory returns the approximate number of bytes that a graph
+// with the given numbers of nodes and edges occupies, not counting the
+// objects and types that the nodes stand for, nor recorded positions.
+func EstimateMemory(nodes, edges uint64) uint64 {
+	return nodes*uint64(nodeSize+entrySize) + edges*uint64(edgeSize)*4/3
+}
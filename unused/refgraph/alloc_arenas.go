@@ -0,0 +1,26 @@
+//go:build goexperiment.arenas
+
+package refgraph
+
+import "arena"
+
+// With GOEXPERIMENT=arenas, chunks are allocated in an arena, which
+// Graph.Free releases at once, without waiting for the garbage
+// collector.
+type nodeArena struct {
+	arena *arena.Arena
+}
+
+func (a *nodeArena) makeChunk(n int) []Node {
+	if a.arena == nil {
+		a.arena = arena.NewArena()
+	}
+	return arena.MakeSlice[Node](a.arena, n, n)
+}
+
+func (a *nodeArena) freeChunks() {
+	if a.arena != nil {
+		a.arena.Free()
+		a.arena = nil
+	}
+}
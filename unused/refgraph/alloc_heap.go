@@ -0,0 +1,10 @@
+//go:build !goexperiment.arenas
+
+package refgraph
+
+// Without arenas, chunks are allocated on the heap and collected once
+// the graph is no longer referenced.
+type nodeArena struct{}
+
+func (*nodeArena) makeChunk(n int) []Node { return make([]Node, n) }
+func (*nodeArena) freeChunks()            {}
@@ -0,0 +1,89 @@
+package refgraph
+
+//go:generate go run golang.org/x/tools/cmd/stringer@master -type EdgeKind
+
+// An EdgeKind describes why one node of a graph uses another. Edges
+// that exist for several reasons have several kinds, combined with
+// bitwise or.
+type EdgeKind uint64
+
+// Is reports whether e includes any of the kinds in o.
+func (e EdgeKind) Is(o EdgeKind) bool {
+	return e&o != 0
+}
+
+// Kinds returns the individual kinds that e combines, in the order of
+// their values.
+func (e EdgeKind) Kinds() []EdgeKind {
+	var out []EdgeKind
+	for i := 0; i < 64; i++ {
+		if k := EdgeKind(1) << i; e.Is(k) {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+const (
+	EdgeAlias EdgeKind = 1 << iota
+	EdgeBlankField
+	EdgeAnonymousStruct
+	EdgeCgoExported
+	EdgeConstGroup
+	EdgeElementType
+	EdgeEmbeddedInterface
+	EdgeExportedConstant
+	EdgeExportedField
+	EdgeExportedFunction
+	EdgeExportedMethod
+	EdgeExportedType
+	EdgeExportedVariable
+	EdgeExtendsExportedFields
+	EdgeExtendsExportedMethodSet
+	EdgeFieldAccess
+	EdgeFunctionArgument
+	EdgeFunctionResult
+	EdgeFunctionSignature
+	EdgeImplements
+	EdgeInstructionOperand
+	EdgeInterfaceCall
+	EdgeInterfaceMethod
+	EdgeKeyType
+	EdgeLinkname
+	EdgeMainFunction
+	EdgeNamedType
+	EdgeNetRPCRegister
+	EdgeNoCopySentinel
+	EdgeProvidesMethod
+	EdgeReceiver
+	EdgeRuntimeFunction
+	EdgeSignature
+	EdgeStructConversion
+	EdgeTestSink
+	EdgeTupleElement
+	EdgeType
+	EdgeTypeName
+	EdgeUnderlyingType
+	EdgePointerType
+	EdgeUnsafeConversion
+	EdgeUsedConstant
+	EdgeVarDecl
+	EdgeIgnored
+	EdgeSamePointer
+	EdgeTypeParam
+	EdgeTypeArg
+	EdgeUnionTerm
+	EdgeSideEffects
+	EdgeKeep
+	EdgeSnippets
+	EdgeDocLink
+	EdgeComparison
+	EdgeExportTo
+	EdgeSkimmed
+	EdgeInterfaceAssertion
+	EdgeProvided
+	EdgeFatalPath
+	EdgeUnsafeLayout
+	EdgeGeneratedRegistration
+	EdgeGoGenerate
+)
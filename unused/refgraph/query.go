@@ -0,0 +1,148 @@
+package refgraph
+
+import (
+	"go/token"
+	"sort"
+)
+
+// Queries
+//
+// Building a graph, with See, Use, NewPointer and Color, isn't safe
+// for concurrent use. Once built, a graph must not be modified
+// anymore, and is then safe for concurrent use by any number of
+// queries: Lookup, WhyUsed, Reasons, SimulateRemoval and
+// ReachableWithout only read the graph, keeping their state to
+// themselves. In particular, they don't rely on Node.Seen, which
+// reflects how the unused check colored the graph, including its
+// special treatment of some nodes, rather than plain reachability
+// from the root.
+
+// A Step is an edge on a path through a graph, along with the node
+// that the edge starts at. Pos is the position of the reference that
+// the edge stands for, if the graph records positions.
+type Step struct {
+	From *Node
+	Edge Edge
+	Pos  token.Pos
+}
+
+// WhyUsed returns a shortest path from the root to the node of obj,
+// explaining why obj is used. It returns nil if the root doesn't
+// reach obj.
+func (g *Graph) WhyUsed(obj interface{}) []Step {
+	target, ok := g.Lookup(obj)
+	if !ok {
+		return nil
+	}
+	parents := g.parents(target)
+	if _, ok := parents[target]; !ok {
+		return nil
+	}
+	var path []Step
+	for n := target; n != g.Root; {
+		step := parents[n]
+		path = append(path, step)
+		n = step.From
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// Reasons returns, for every node that the root reaches, the kinds of
+// all edges that lead to the node from the root or from other nodes
+// that the root reaches, combined with bitwise or. They are the direct
+// reasons for the node being used. Edges from unreachable nodes don't
+// count, as they don't keep anything alive.
+func (g *Graph) Reasons() map[*Node]EdgeKind {
+	reachable := g.reachable(nil, 0)
+	out := map[*Node]EdgeKind{}
+	add := func(n *Node) {
+		for _, e := range n.Uses {
+			out[e.Node] |= e.Kind
+		}
+	}
+	add(g.Root)
+	for n := range reachable {
+		add(n)
+	}
+	return out
+}
+
+// parents searches the graph breadth-first, starting at the root,
+// until it reaches target, or everything if target is nil. It returns
+// the steps that first reached the visited nodes, other than the
+// root.
+func (g *Graph) parents(target *Node) map[*Node]Step {
+	parents := map[*Node]Step{}
+	visited := map[*Node]bool{g.Root: true}
+	queue := []*Node{g.Root}
+	for len(queue) > 0 && (target == nil || !visited[target]) {
+		n := queue[0]
+		queue = queue[1:]
+		for _, e := range n.Uses {
+			if visited[e.Node] {
+				continue
+			}
+			visited[e.Node] = true
+			parents[e.Node] = Step{From: n, Edge: e, Pos: g.Position(n, e.Node)}
+			queue = append(queue, e.Node)
+		}
+	}
+	return parents
+}
+
+// SimulateRemoval returns the nodes that the root reaches, but
+// wouldn't reach anymore if objs were removed from the graph: the
+// nodes of objs themselves, and the nodes that only they keep alive.
+// The nodes are sorted in the order they were added to the graph.
+func (g *Graph) SimulateRemoval(objs ...interface{}) []*Node {
+	removed := map[*Node]bool{}
+	for _, obj := range objs {
+		if n, ok := g.Lookup(obj); ok {
+			removed[n] = true
+		}
+	}
+	before := g.reachable(nil, 0)
+	after := g.reachable(removed, 0)
+	var out []*Node
+	for n := range before {
+		if !after[n] {
+			out = append(out, n)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// ReachableWithout returns the nodes that the root reaches without
+// following edges that only exist for reasons of the given kinds.
+// Nodes that the root reaches, but that aren't in the result, are
+// only kept alive by such edges.
+func (g *Graph) ReachableWithout(kinds EdgeKind) map[*Node]bool {
+	return g.reachable(nil, kinds)
+}
+
+// reachable returns the nodes that the root reaches without passing
+// through the skipped nodes, nor following edges that only have
+// kinds in skipKinds.
+func (g *Graph) reachable(skip map[*Node]bool, skipKinds EdgeKind) map[*Node]bool {
+	visited := map[*Node]bool{g.Root: true}
+	stack := []*Node{g.Root}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, e := range n.Uses {
+			if visited[e.Node] || skip[e.Node] || (skipKinds != 0 && e.Kind&^skipKinds == 0) {
+				continue
+			}
+			visited[e.Node] = true
+			stack = append(stack, e.Node)
+		}
+	}
+	delete(visited, g.Root)
+	return visited
+}
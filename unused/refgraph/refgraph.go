@@ -0,0 +1,405 @@
+// Package refgraph provides the types of graphs of references between
+// Go objects and types, and queries of them.
+//
+// A graph has a node for every object and type of interest, and an
+// edge from a node to every node it uses, labeled with why it uses
+// it. A synthetic root node uses everything that is used
+// unconditionally, such as exported objects or the main function.
+//
+// The package only holds graphs; it doesn't know about Go code. The
+// builder that walks packages and decides which nodes and edges their
+// code gives rise to remains part of the unused check, which reports
+// the objects that aren't reachable from the root. Other analyses get
+// the graphs from the check's result rather than building their own.
+// See the documentation of the unused package for how Go constructs
+// map onto nodes and edges.
+package refgraph
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"io"
+
+	"honnef.co/go/tools/go/types/typeutil"
+
+	"golang.org/x/exp/typeparams"
+)
+
+// A Graph is a graph of references.
+type Graph struct {
+	// Root uses everything that is used unconditionally. Its Obj is
+	// nil.
+	Root *Node
+
+	// Nodes of types, keyed by type.
+	TypeNodes map[types.Type]*Node
+	// Nodes of objects and synthetic values, keyed by them.
+	Nodes map[interface{}]*Node
+
+	// Size limits, zero means unlimited. Adding more nodes or edges
+	// panics with a BudgetError.
+	MaxNodes uint64
+	MaxEdges uint64
+
+	// Positions enables recording the source positions of the
+	// references that edges stand for, as passed to UseAt, at the
+	// cost of memory. Fset is used for printing them.
+	Positions bool
+	Fset      *token.FileSet
+	positions map[edgeKey]token.Pos
+
+	// Mapping of types T to canonical *T
+	pointers map[types.Type]*types.Pointer
+
+	nodeCounter uint64
+	edgeCounter uint64
+	alloc       nodeAllocator
+}
+
+// An edgeKey identifies the edges from one node to another.
+type edgeKey struct {
+	by, used *Node
+}
+
+// An Edge points to a used node.
+type Edge struct {
+	Node *Node
+	Kind EdgeKind
+}
+
+// A Node is an object, a type or a synthetic value in a graph.
+type Node struct {
+	// Obj is a types.Object, a types.Type or a synthetic value.
+	Obj interface{}
+	// ID identifies the node, in the order nodes were added.
+	ID uint64
+
+	// OPT(dh): evaluate using a map instead of a slice to avoid
+	// duplicate edges.
+	Uses []Edge
+
+	// Seen is set by Graph.Color if the node is reachable.
+	Seen bool
+}
+
+// A BudgetError is the panic value of adding more nodes or edges to a
+// graph than its limits allow.
+type BudgetError struct {
+	What  string
+	Limit uint64
+}
+
+func (err BudgetError) Error() string {
+	return fmt.Sprintf("graph exceeds the limit of %d %s", err.Limit, err.What)
+}
+
+func assert(b bool) {
+	if !b {
+		panic("failed assertion")
+	}
+}
+
+// New returns an empty graph.
+func New() *Graph {
+	g := &Graph{
+		Nodes:     map[interface{}]*Node{},
+		TypeNodes: map[types.Type]*Node{},
+		pointers:  map[types.Type]*types.Pointer{},
+	}
+	g.Root = g.newNode(nil)
+	return g
+}
+
+// canonical returns the object or type that represents obj in the
+// graph. Methods of instantiated types are represented by their
+// generic origins, aliases by the types they denote, and instantiated
+// types by their generic origins.
+func canonical(obj interface{}) interface{} {
+	if fn, ok := obj.(*types.Func); ok {
+		obj = typeparams.OriginMethod(fn)
+	}
+	if t, ok := obj.(types.Type); ok {
+		obj = typeutil.Unalias(t)
+	}
+	if t, ok := obj.(*types.Named); ok {
+		obj = t.Origin()
+	}
+	return obj
+}
+
+// Lookup returns the node of obj, if there is one.
+func (g *Graph) Lookup(obj interface{}) (*Node, bool) {
+	obj = canonical(obj)
+	if t, ok := obj.(types.Type); ok {
+		n, ok := g.TypeNodes[t]
+		return n, ok
+	}
+	n, ok := g.Nodes[obj]
+	return n, ok
+}
+
+func (g *Graph) node(obj interface{}) (n *Node, new bool) {
+	switch obj := obj.(type) {
+	case types.Type:
+		if v := g.TypeNodes[obj]; v != nil {
+			return v, false
+		}
+		n = g.newNode(obj)
+		g.TypeNodes[obj] = n
+		return n, true
+	default:
+		if node, ok := g.Nodes[obj]; ok {
+			return node, false
+		}
+
+		n = g.newNode(obj)
+		g.Nodes[obj] = n
+		return n, true
+	}
+}
+
+func (g *Graph) newNode(obj interface{}) *Node {
+	g.nodeCounter++
+	if g.MaxNodes != 0 && g.nodeCounter > g.MaxNodes {
+		panic(BudgetError{"nodes", g.MaxNodes})
+	}
+	n := g.alloc.alloc()
+	n.Obj = obj
+	n.ID = g.nodeCounter
+	return n
+}
+
+// Size returns the numbers of nodes and edges in the graph, including
+// the root and duplicate edges.
+func (g *Graph) Size() (nodes, edges uint64) {
+	return g.nodeCounter, g.edgeCounter
+}
+
+// Free releases the memory of the graph's nodes. Neither the graph nor
+// any of its nodes may be used afterwards. When built with
+// GOEXPERIMENT=arenas, the nodes live in an arena, whose memory Free
+// returns right away, and using them afterwards faults. Otherwise,
+// Free merely drops the graph's references to its nodes.
+func (g *Graph) Free() {
+	g.Root = nil
+	g.Nodes = nil
+	g.TypeNodes = nil
+	g.positions = nil
+	g.alloc.free()
+}
+
+func (n *Node) use(n2 *Node, kind EdgeKind) {
+	assert(n2 != nil)
+	n.Uses = append(n.Uses, Edge{Node: n2, Kind: kind})
+}
+
+// NewPointer returns the canonical pointer to typ, adding it to the
+// graph if necessary.
+func (g *Graph) NewPointer(typ types.Type) *types.Pointer {
+	if p, ok := g.pointers[typ]; ok {
+		return p
+	} else {
+		p := types.NewPointer(typ)
+		g.pointers[typ] = p
+		g.See(p)
+		return p
+	}
+}
+
+// See adds obj to the graph, unless it is irrelevant, and returns its
+// node.
+func (g *Graph) See(obj interface{}) *Node {
+	if IsIrrelevant(obj) {
+		return nil
+	}
+
+	assert(obj != nil)
+	obj = canonical(obj)
+
+	// add new node to graph
+	node, _ := g.node(obj)
+
+	if p, ok := obj.(*types.Pointer); ok {
+		if pt, ok := g.pointers[p.Elem()]; ok {
+			// We've used Graph.NewPointer before we saw this pointer; add an edge that marks the two pointers as being
+			// identical
+			if p != pt {
+				g.Use(p, pt, EdgeSamePointer)
+				g.Use(pt, p, EdgeSamePointer)
+			}
+		} else {
+			g.pointers[p.Elem()] = p
+		}
+	}
+
+	return node
+}
+
+// Use adds an edge of the given kind from by to used, both of which
+// must have been seen. A nil by stands for the root.
+func (g *Graph) Use(used, by interface{}, kind EdgeKind) {
+	g.UseAt(used, by, kind, token.NoPos)
+}
+
+// UseAt is like Use, but also records pos as the position of the
+// reference if Positions is set. Of several references from by to
+// used, the first one with a valid position is recorded.
+func (g *Graph) UseAt(used, by interface{}, kind EdgeKind, pos token.Pos) {
+	if IsIrrelevant(used) {
+		return
+	}
+
+	assert(used != nil)
+	used = canonical(used)
+	by = canonical(by)
+
+	g.edgeCounter++
+	if g.MaxEdges != 0 && g.edgeCounter > g.MaxEdges {
+		panic(BudgetError{"edges", g.MaxEdges})
+	}
+
+	usedNode, new := g.node(used)
+	assert(!new)
+	byNode := g.Root
+	if by != nil {
+		byNode, new = g.node(by)
+		assert(!new)
+	}
+	byNode.use(usedNode, kind)
+	if g.Positions && pos.IsValid() {
+		k := edgeKey{byNode, usedNode}
+		if g.positions == nil {
+			g.positions = map[edgeKey]token.Pos{}
+		}
+		if _, ok := g.positions[k]; !ok {
+			g.positions[k] = pos
+		}
+	}
+}
+
+// Position returns the recorded position of the reference from by to
+// used, or token.NoPos if there is none.
+func (g *Graph) Position(by, used *Node) token.Pos {
+	return g.positions[edgeKey{by, used}]
+}
+
+// Color sets Seen for all nodes reachable from root.
+func (g *Graph) Color(root *Node) {
+	if root.Seen {
+		return
+	}
+	root.Seen = true
+	for _, e := range root.Uses {
+		g.Color(e.Node)
+	}
+}
+
+// WriteDot writes the graph in Graphviz's dot format to w, coloring
+// nodes green or red depending on whether they have been seen.
+func (g *Graph) WriteDot(w io.Writer) {
+	writeNode := func(n *Node) {
+		if n.Obj == nil {
+			fmt.Fprintf(w, "n%d [label=\"Root\"];\n", n.ID)
+		} else {
+			color := "red"
+			if n.Seen {
+				color = "green"
+			}
+			fmt.Fprintf(w, "n%d [label=%q, color=%q];\n", n.ID, fmt.Sprintf("(%T) %s", n.Obj, n.Obj), color)
+		}
+		for _, e := range n.Uses {
+			var at string
+			if pos := g.Position(n, e.Node); pos.IsValid() && g.Fset != nil {
+				at = "\n" + g.Fset.Position(pos).String()
+			}
+			for i := EdgeKind(1); i < 64; i++ {
+				if e.Kind.Is(1 << i) {
+					fmt.Fprintf(w, "n%d -> n%d [label=%q];\n", n.ID, e.Node.ID, EdgeKind(1<<i).String()+at)
+				}
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "digraph{\n")
+	writeNode(g.Root)
+	for _, v := range g.Nodes {
+		writeNode(v)
+	}
+	for _, node := range g.TypeNodes {
+		writeNode(node)
+	}
+	fmt.Fprintf(w, "}\n")
+}
+
+// IsIrrelevant reports whether an object's presence in the graph is
+// of any relevance. A lot of objects will never have outgoing edges,
+// nor meaningful incoming ones. Examples are basic types and empty
+// signatures, among many others.
+//
+// Dropping these objects should have no effect on correctness, but
+// may improve performance. It also helps with debugging, as it
+// greatly reduces the size of the graph.
+func IsIrrelevant(obj interface{}) bool {
+	if obj, ok := obj.(types.Object); ok {
+		switch obj := obj.(type) {
+		case *types.Var:
+			if obj.IsField() {
+				// We need to track package fields
+				return false
+			}
+			if obj.Pkg() != nil && obj.Parent() == obj.Pkg().Scope() {
+				// We need to track package-level variables
+				return false
+			}
+			return IsIrrelevant(obj.Type())
+		default:
+			return false
+		}
+	}
+	if T, ok := obj.(types.Type); ok {
+		switch T := typeutil.Unalias(T).(type) {
+		case *types.Array:
+			return IsIrrelevant(T.Elem())
+		case *types.Slice:
+			return IsIrrelevant(T.Elem())
+		case *types.Basic:
+			return true
+		case *types.Tuple:
+			for i := 0; i < T.Len(); i++ {
+				if !IsIrrelevant(T.At(i).Type()) {
+					return false
+				}
+			}
+			return true
+		case *types.Signature:
+			if T.Recv() != nil {
+				return false
+			}
+			for i := 0; i < T.Params().Len(); i++ {
+				if !IsIrrelevant(T.Params().At(i)) {
+					return false
+				}
+			}
+			for i := 0; i < T.Results().Len(); i++ {
+				if !IsIrrelevant(T.Results().At(i)) {
+					return false
+				}
+			}
+			return true
+		case *types.Interface:
+			return T.NumMethods() == 0 && T.NumEmbeddeds() == 0
+		case *types.Pointer:
+			return IsIrrelevant(T.Elem())
+		case *types.Map:
+			return IsIrrelevant(T.Key()) && IsIrrelevant(T.Elem())
+		case *types.Struct:
+			return T.NumFields() == 0
+		case *types.Chan:
+			return IsIrrelevant(T.Elem())
+		default:
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,268 @@
+package refgraph
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestGraph(t *testing.T) {
+	pkg := types.NewPackage("example.com/pkg", "pkg")
+	named := func(name string) *types.TypeName {
+		obj := types.NewTypeName(token.NoPos, pkg, name, nil)
+		types.NewNamed(obj, types.NewStruct([]*types.Var{types.NewField(token.NoPos, pkg, "f", types.Typ[types.Int], false)}, nil), nil)
+		return obj
+	}
+	a, b, c := named("A"), named("B"), named("C")
+
+	g := New()
+	for _, obj := range []types.Object{a, b, c} {
+		g.See(obj)
+	}
+	if n := g.See(types.Typ[types.Int]); n != nil {
+		t.Errorf("basic types are irrelevant, but got node %v", n.Obj)
+	}
+	g.Use(a, nil, EdgeExportedType)
+	g.Use(b, a, EdgeFieldAccess)
+	g.Use(b, a, EdgeNamedType)
+	// Cycles, reachable and unreachable ones, mustn't make Color
+	// recurse forever.
+	g.Use(a, b, EdgeNamedType)
+	g.Use(c, c, EdgeFieldAccess)
+
+	na, _ := g.Lookup(a)
+	if len(na.Uses) != 2 || !na.Uses[0].Kind.Is(EdgeFieldAccess) || na.Uses[1].Kind.Is(EdgeFieldAccess) {
+		t.Errorf("got edges %v, want a field access and a named type", na.Uses)
+	}
+
+	g.Color(g.Root)
+	for obj, want := range map[types.Object]bool{a: true, b: true, c: false} {
+		n, ok := g.Lookup(obj)
+		if !ok {
+			t.Fatalf("no node for %s", obj.Name())
+		}
+		if n.Seen != want {
+			t.Errorf("%s: got Seen = %t, want %t", obj.Name(), n.Seen, want)
+		}
+	}
+
+	// Pointers created by the graph and seen later are identical.
+	p := g.NewPointer(a.Type())
+	if q := g.NewPointer(a.Type()); p != q {
+		t.Errorf("got distinct pointers to %s", a.Name())
+	}
+	other := types.NewPointer(a.Type())
+	g.See(other)
+	n, _ := g.Lookup(other)
+	if len(n.Uses) != 1 || n.Uses[0].Kind != EdgeSamePointer {
+		t.Errorf("got edges %v, want an edge to the canonical pointer", n.Uses)
+	}
+}
+
+func TestBudget(t *testing.T) {
+	g := New()
+	g.MaxNodes = 2
+	defer func() {
+		err, ok := recover().(BudgetError)
+		if !ok || err.What != "nodes" {
+			t.Errorf("got panic %v, want a BudgetError for nodes", err)
+		}
+	}()
+	pkg := types.NewPackage("example.com/pkg", "pkg")
+	for i := 0; i < 3; i++ {
+		g.See(types.NewField(token.NoPos, pkg, "f", types.Typ[types.Int], false))
+	}
+	t.Error("expected a panic")
+}
+
+func TestKinds(t *testing.T) {
+	k := EdgeInterfaceCall | EdgeAlias | EdgeUnsafeLayout
+	if got, want := k.Kinds(), []EdgeKind{EdgeAlias, EdgeInterfaceCall, EdgeUnsafeLayout}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestQueries(t *testing.T) {
+	pkg := types.NewPackage("example.com/pkg", "pkg")
+	fn := func(name string) *types.Func {
+		return types.NewFunc(token.NoPos, pkg, name, types.NewSignature(nil, nil, nil, false))
+	}
+	main, a, b, c, d, dead := fn("main"), fn("a"), fn("b"), fn("c"), fn("d"), fn("dead")
+
+	// main uses a and b, a and b both use c, and only b uses d.
+	g := New()
+	for _, obj := range []types.Object{main, a, b, c, d, dead} {
+		g.See(obj)
+	}
+	g.Use(main, nil, EdgeMainFunction)
+	g.Use(a, main, EdgeInstructionOperand)
+	g.Use(b, main, EdgeInstructionOperand)
+	g.Use(c, a, EdgeInstructionOperand)
+	g.Use(c, b, EdgeInterfaceCall)
+	g.Use(d, b, EdgeInstructionOperand)
+	g.Use(dead, dead, EdgeInstructionOperand)
+	g.Use(c, dead, EdgeAlias)
+
+	names := func(nodes []*Node) []string {
+		var out []string
+		for _, n := range nodes {
+			out = append(out, n.Obj.(types.Object).Name())
+		}
+		return out
+	}
+	check := func() {
+		path := g.WhyUsed(d)
+		var got []*Node
+		for _, step := range path {
+			got = append(got, step.Edge.Node)
+		}
+		if got, want := names(got), []string{"main", "b", "d"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got path %v, want %v", got, want)
+		}
+		if len(path) == 0 {
+			return
+		}
+		if path[0].From != g.Root || path[0].Edge.Kind != EdgeMainFunction {
+			t.Errorf("got path starting at %v with %s, want the root with %s", path[0].From.Obj, path[0].Edge.Kind, EdgeMainFunction)
+		}
+		if path := g.WhyUsed(dead); path != nil {
+			t.Errorf("got path %v for unreachable object", path)
+		}
+		if got, want := names(g.SimulateRemoval(b)), []string{"b", "d"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v for removing b, want %v", got, want)
+		}
+		if got, want := names(g.SimulateRemoval(a, b)), []string{"a", "b", "c", "d"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v for removing a and b, want %v", got, want)
+		}
+		if got := g.SimulateRemoval(dead); len(got) != 0 {
+			t.Errorf("got %v for removing an unreachable object, want nothing", names(got))
+		}
+		if got := g.ReachableWithout(EdgeInstructionOperand); len(got) != 1 {
+			t.Errorf("got %d nodes reachable without operands, want only main", len(got))
+		} else if mainn, _ := g.Lookup(main); !got[mainn] {
+			t.Errorf("main isn't reachable without operands")
+		}
+		reasons := g.Reasons()
+		if dn, _ := g.Lookup(d); reasons[dn] != path[len(path)-1].Edge.Kind {
+			t.Errorf("got reason %s for d, want the kind of the last step of its path", reasons[dn])
+		}
+		// The edge from dead doesn't count, as dead is unreachable.
+		if cn, _ := g.Lookup(c); reasons[cn] != EdgeInstructionOperand|EdgeInterfaceCall {
+			t.Errorf("got reasons %v for c, want the kinds of its edges from a and b", reasons[cn].Kinds())
+		}
+		if deadn, _ := g.Lookup(dead); len(reasons) != 5 || reasons[deadn] != 0 {
+			t.Errorf("got %d reasons, want one for each of the 5 reachable nodes", len(reasons))
+		}
+	}
+
+	// Queries are safe for concurrent use, which the race detector
+	// checks.
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			check()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestPositions(t *testing.T) {
+	pkg := types.NewPackage("example.com/pkg", "pkg")
+	a := types.NewFunc(token.NoPos, pkg, "a", types.NewSignature(nil, nil, nil, false))
+	b := types.NewFunc(token.NoPos, pkg, "b", types.NewSignature(nil, nil, nil, false))
+
+	for _, enabled := range []bool{false, true} {
+		g := New()
+		g.Positions = enabled
+		g.See(a)
+		g.See(b)
+		g.Use(a, nil, EdgeExportedFunction)
+		g.UseAt(b, a, EdgeInstructionOperand, 10)
+		g.UseAt(b, a, EdgeInstructionOperand, 20)
+
+		na, _ := g.Lookup(a)
+		nb, _ := g.Lookup(b)
+		want := token.Pos(10)
+		if !enabled {
+			want = token.NoPos
+		}
+		if got := g.Position(na, nb); got != want {
+			t.Errorf("Positions = %t: got position %d, want %d", enabled, got, want)
+		}
+		if got := g.Position(g.Root, na); got != token.NoPos {
+			t.Errorf("Positions = %t: got position %d for an edge without one", enabled, got)
+		}
+	}
+}
+
+// funcs returns n functions, for adding to graphs.
+func funcs(n int) []*types.Func {
+	pkg := types.NewPackage("example.com/pkg", "pkg")
+	sig := types.NewSignature(nil, nil, nil, false)
+	out := make([]*types.Func, n)
+	for i := range out {
+		out[i] = types.NewFunc(token.NoPos, pkg, fmt.Sprintf("f%d", i), sig)
+	}
+	return out
+}
+
+func TestAlloc(t *testing.T) {
+	// Enough nodes to fill several chunks, including ones of the
+	// maximum size.
+	fns := funcs(3 * maxChunk)
+	g := New()
+	seen := map[*Node]bool{g.Root: true}
+	for i, fn := range fns {
+		n := g.See(fn)
+		if seen[n] {
+			t.Fatalf("got the same node for two objects")
+		}
+		seen[n] = true
+		if n.ID != uint64(i+2) || n.Obj != fn {
+			t.Fatalf("got node %d for %v, want node %d for %s", n.ID, n.Obj, i+2, fn.Name())
+		}
+		if i > 0 {
+			g.Use(fn, fns[i-1], EdgeInstructionOperand)
+		}
+	}
+	if nodes, edges := g.Size(); nodes != uint64(len(fns)+1) || edges != uint64(len(fns)-1) {
+		t.Errorf("got %d nodes and %d edges, want %d and %d", nodes, edges, len(fns)+1, len(fns)-1)
+	}
+	if est := EstimateMemory(g.Size()); est < uint64(len(fns))*uint64(nodeSize+edgeSize) {
+		t.Errorf("estimated %d bytes, which is less than the nodes and edges take up", est)
+	}
+	g.Free()
+	if g.Root != nil || g.Nodes != nil {
+		t.Errorf("the freed graph still refers to its nodes")
+	}
+}
+
+// BenchmarkGraph measures building and coloring graphs of the size of
+// small, large and huge packages. Run it with and without
+// GOEXPERIMENT=arenas to compare the allocation of nodes.
+func BenchmarkGraph(b *testing.B) {
+	for _, size := range []int{1_000, 100_000, 1_000_000} {
+		fns := funcs(size)
+		b.Run(fmt.Sprint(size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				g := New()
+				for j, fn := range fns {
+					g.See(fn)
+					if j%2 == 0 {
+						g.Use(fn, nil, EdgeExportedFunction)
+					} else {
+						g.Use(fn, fns[j-1], EdgeInstructionOperand)
+					}
+				}
+				g.Color(g.Root)
+				g.Free()
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package unused
+
+import (
+	"go/token"
+	"go/types"
+
+	"honnef.co/go/tools/unused/refgraph"
+)
+
+// registrations stands for the init functions and package-level
+// variables of the package's generated files, if the generated policy
+// is strict. Code generators often register constructors and handlers
+// this way, as in func init() { Register(NewFoo) }. It uses what they
+// refer to on behalf of the package.
+type registrations struct{}
+
+func (*registrations) String() string { return "generated registrations" }
+
+// isRegistration reports whether the instruction at pos, of a
+// function owned by fnObj, is part of the generated registrations:
+// whether it is in a generated file, and belongs to an init function
+// or to the initializer of a package-level variable that isn't owned
+// by the variable.
+func (g *graph) isRegistration(fnObj types.Object, pos token.Pos) bool {
+	if fnObj == nil {
+		if !pos.IsValid() {
+			return false
+		}
+		_, ok := generator(g.pkg.Fset, g.pkg.Generated, pos)
+		return ok
+	}
+	fn, ok := fnObj.(*types.Func)
+	if !ok || fn.Name() != "init" || fn.Type().(*types.Signature).Recv() != nil {
+		return false
+	}
+	return g.isGenerated(fn)
+}
+
+// registrationsOf returns the generated registrations of the package,
+// adding them to the graph as a use of the root.
+func (g *graph) registrationsOf() *registrations {
+	if g.registrations != nil {
+		return g.registrations
+	}
+	g.registrations = &registrations{}
+	g.see(g.registrations)
+	g.use(g.registrations, nil, refgraph.EdgeGeneratedRegistration)
+	return g.registrations
+}
+
+// generatedOnly finds the package-level objects and methods of the
+// package, outside of generated files, that only the generated
+// registrations use, directly or indirectly. It colors the graph
+// without the registrations, leaving these objects unseen, and then
+// marks everything else that the registrations use as seen, such as
+// the objects of the generated files themselves. It has to run before
+// results, with the registrations marked as seen.
+func (g *graph) generatedOnly() map[types.Object]bool {
+	if g.registrations == nil {
+		return nil
+	}
+	g.Color(g.Root)
+
+	out := map[types.Object]bool{}
+	var rest []*refgraph.Node
+	visited := map[*refgraph.Node]bool{}
+	var walk func(n *refgraph.Node)
+	walk = func(n *refgraph.Node) {
+		for _, e := range n.Uses {
+			if visited[e.Node] || e.Node.Seen {
+				continue
+			}
+			visited[e.Node] = true
+			if obj, ok := e.Node.Obj.(types.Object); ok && g.isGeneratedCandidate(obj) {
+				out[obj] = true
+			} else {
+				rest = append(rest, e.Node)
+			}
+			walk(e.Node)
+		}
+	}
+	n, _ := g.Lookup(g.registrations)
+	walk(n)
+	// Everything that the walk reached has been accounted for, so
+	// there's no need to color what the rest uses.
+	for _, n := range rest {
+		n.Seen = true
+	}
+	return out
+}
+
+// isGeneratedCandidate reports whether obj is a package-level object
+// or method of the package, declared outside of generated files,
+// which generatedOnly may flag.
+func (g *graph) isGeneratedCandidate(obj types.Object) bool {
+	if obj.Pkg() != g.pkg.Pkg || g.isGenerated(obj) {
+		return false
+	}
+	if obj.Parent() == obj.Pkg().Scope() {
+		return true
+	}
+	fn, ok := obj.(*types.Func)
+	return ok && fn.Type().(*types.Signature).Recv() != nil
+}
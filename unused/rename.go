@@ -0,0 +1,80 @@
+package unused
+
+import (
+	"fmt"
+	"go/types"
+
+	"honnef.co/go/tools/unused/refgraph"
+)
+
+// renameHazards describes the uses by the root that stand for
+// consumers that don't refer to objects in Go code, and that
+// therefore break silently when objects get renamed or deleted.
+var renameHazards = []struct {
+	kind   refgraph.EdgeKind
+	reason string
+}{
+	{refgraph.EdgeLinkname, "it is the target of a go:linkname directive"},
+	{refgraph.EdgeCgoExported, "it is exported to C via cgo"},
+	{refgraph.EdgeRuntimeFunction, "the compiler or assembly may call it by name"},
+	{refgraph.EdgeNetRPCRegister, "it is registered with net/rpc, which calls it via reflection"},
+	{refgraph.EdgeKeep, "it matches the keep patterns, such as for objects used via reflection"},
+	{refgraph.EdgeExportTo, "it is a member of a type that a //lint:export-to directive applies to"},
+}
+
+// CanRename reports whether obj can be renamed or deleted without
+// breaking consumers that don't refer to it in Go code, such as
+// assembly, C code, linknames and reflection. If it can't, it returns
+// the reasons why, for refactoring tools to show to their users.
+//
+// References in Go code are left for the type checker to find: those
+// in the package are for the tool to update, and other packages may
+// refer to exported objects anyway. Packages linking to obj via
+// go:linkname are only known if obj's own package has a directive
+// for it, too; see Linknames for the other direction. If the
+// package's graph exceeded the size limits, nothing is known, and
+// CanRename reports false.
+func (res Result) CanRename(obj types.Object) (bool, []string) {
+	if res.Graph == nil {
+		return false, []string{"the package's reference graph exceeded the size limits"}
+	}
+	n, ok := res.Graph.Lookup(obj)
+	if !ok {
+		return true, nil
+	}
+	var kinds refgraph.EdgeKind
+	for _, e := range res.Graph.Root.Uses {
+		if e.Node == n {
+			kinds |= e.Kind
+		}
+	}
+	var reasons []string
+	for _, h := range renameHazards {
+		if !kinds.Is(h.kind) {
+			continue
+		}
+		if h.kind == refgraph.EdgeExportTo {
+			if exp, ok := res.exportOf(obj); ok {
+				reason := fmt.Sprintf("it is exported to %s", exp.Consumer)
+				if exp.Reason != "" {
+					reason += ": " + exp.Reason
+				}
+				reasons = append(reasons, reason)
+				continue
+			}
+		}
+		reasons = append(reasons, h.reason)
+	}
+	return len(reasons) == 0, reasons
+}
+
+// exportOf returns the export of obj, if a //lint:export-to directive
+// applies to obj itself rather than to its type.
+func (res Result) exportOf(obj types.Object) (Export, bool) {
+	for _, exp := range res.Exports {
+		if exp.Object == obj {
+			return exp, true
+		}
+	}
+	return Export{}, false
+}
@@ -0,0 +1,236 @@
+package unused
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"honnef.co/go/tools/analysis/code"
+	"honnef.co/go/tools/go/ast/astutil"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// A Route is the registration of a function of the package as the
+// handler of an HTTP route.
+type Route struct {
+	Handler types.Object
+	// Pattern is the pattern that the handler is registered for.
+	Pattern string
+	// Registration is the position of the call that registers the
+	// handler.
+	Registration token.Pos
+	// Live is set if the handler is referenced by more than its
+	// registrations, if tests of the package mention the route, or if
+	// the route matches the unused.routes option.
+	Live bool
+}
+
+// Functions and methods of routers whose first argument is a pattern
+// and whose second argument is a handler. Handlers registered in other
+// ways, such as with other routers, are simply referenced, and thus
+// used, by their registrations.
+var routeRegistrations = map[string]bool{
+	"net/http.Handle":                              true,
+	"net/http.HandleFunc":                          true,
+	"(*net/http.ServeMux).Handle":                  true,
+	"(*net/http.ServeMux).HandleFunc":              true,
+	"(*github.com/gorilla/mux.Router).Handle":      true,
+	"(*github.com/gorilla/mux.Router).HandleFunc":  true,
+	"(github.com/go-chi/chi/v5.Router).Handle":     true,
+	"(github.com/go-chi/chi/v5.Router).HandleFunc": true,
+	"(github.com/go-chi/chi/v5.Router).Get":        true,
+	"(github.com/go-chi/chi/v5.Router).Post":       true,
+	"(github.com/go-chi/chi/v5.Router).Put":        true,
+	"(github.com/go-chi/chi/v5.Router).Patch":      true,
+	"(github.com/go-chi/chi/v5.Router).Delete":     true,
+	"(*github.com/labstack/echo/v4.Echo).GET":      true,
+	"(*github.com/labstack/echo/v4.Echo).POST":     true,
+	"(*github.com/labstack/echo/v4.Echo).PUT":      true,
+	"(*github.com/labstack/echo/v4.Echo).PATCH":    true,
+	"(*github.com/labstack/echo/v4.Echo).DELETE":   true,
+}
+
+// routes returns the routes that the package registers handlers for,
+// in the order of their registrations. A route is live if any of the
+// patterns in live matches it.
+func routes(pass *analysis.Pass, live []string) []Route {
+	var out []Route
+	// identifiers that refer to handlers as part of registrations
+	registrations := map[*ast.Ident]bool{}
+	for _, f := range pass.Files {
+		ast.Inspect(f, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok || len(call.Args) < 2 || !routeRegistrations[code.CallName(pass, call)] {
+				return true
+			}
+			tv := pass.TypesInfo.Types[call.Args[0]]
+			if tv.Value == nil || tv.Value.Kind() != constant.String {
+				return true
+			}
+			id, fn := handler(pass, call.Args[1])
+			if fn == nil {
+				return true
+			}
+			registrations[id] = true
+			out = append(out, Route{
+				Handler:      fn,
+				Pattern:      constant.StringVal(tv.Value),
+				Registration: call.Pos(),
+			})
+			return true
+		})
+	}
+	if len(out) == 0 {
+		return nil
+	}
+
+	referenced := map[types.Object]bool{}
+	for id, obj := range pass.TypesInfo.Uses {
+		if !registrations[id] {
+			referenced[obj] = true
+		}
+	}
+	var literals []string
+	for _, f := range testFiles(pass) {
+		ast.Inspect(f, func(node ast.Node) bool {
+			if lit, ok := node.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+				if s, err := strconv.Unquote(lit.Value); err == nil {
+					literals = append(literals, s)
+				}
+			}
+			return true
+		})
+	}
+	for i := range out {
+		r := &out[i]
+		r.Live = referenced[r.Handler] || requested(r.Pattern, literals) || matchesRoute(live, r.Pattern)
+	}
+	return out
+}
+
+// testFiles returns the syntax of the package's tests, including
+// those of its external test package, which isn't part of the pass.
+// Test files that the pass doesn't include get parsed from the
+// package's directory, so that routes are live no matter which
+// variant of the package gets checked, and whether tests do.
+func testFiles(pass *analysis.Pass) []*ast.File {
+	var out []*ast.File
+	seen := map[string]bool{}
+	var dir string
+	for _, f := range pass.Files {
+		// The files that cgo generates have line directives pointing
+		// at the package's directory.
+		name := pass.Fset.PositionFor(f.Package, true).Filename
+		seen[name] = true
+		if dir == "" {
+			dir = filepath.Dir(name)
+		}
+		if strings.HasSuffix(name, "_test.go") {
+			out = append(out, f)
+		}
+	}
+	if dir == "" {
+		return out
+	}
+	names, err := filepath.Glob(filepath.Join(dir, "*_test.go"))
+	if err != nil {
+		return out
+	}
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), name, nil, 0)
+		if err != nil {
+			continue
+		}
+		if pkg := f.Name.Name; pkg == pass.Pkg.Name() || pkg == pass.Pkg.Name()+"_test" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// handler returns the function of the package that expr refers to,
+// looking through conversions such as http.HandlerFunc(fn), as well
+// as the identifier that refers to it.
+func handler(pass *analysis.Pass, expr ast.Expr) (*ast.Ident, *types.Func) {
+	expr = astutil.Unparen(expr)
+	if call, ok := expr.(*ast.CallExpr); ok && len(call.Args) == 1 && pass.TypesInfo.Types[call.Fun].IsType() {
+		expr = astutil.Unparen(call.Args[0])
+	}
+	fn := localFunc(pass, expr)
+	if fn == nil {
+		return nil, nil
+	}
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return expr, fn
+	case *ast.SelectorExpr:
+		return expr.Sel, fn
+	default:
+		return nil, nil
+	}
+}
+
+// routePath returns the path of a route's pattern, dropping the
+// method and host that patterns may start with, such as in
+// "GET example.com/users/{id}".
+func routePath(pattern string) string {
+	if i := strings.LastIndexByte(pattern, ' '); i >= 0 {
+		pattern = pattern[i+1:]
+	}
+	if i := strings.IndexByte(pattern, '/'); i >= 0 {
+		pattern = pattern[i:]
+	}
+	return pattern
+}
+
+// staticPrefix returns the part of a route's path that precedes the
+// first wildcard.
+func staticPrefix(pattern string) string {
+	pattern = routePath(pattern)
+	if i := strings.IndexAny(pattern, "{:*"); i >= 0 {
+		pattern = pattern[:i]
+	}
+	return pattern
+}
+
+// requested reports whether any of the string literals looks like a
+// request of the route, by containing the route's static prefix.
+// Routes whose prefix is / need a literal that is exactly /.
+func requested(pattern string, literals []string) bool {
+	prefix := staticPrefix(pattern)
+	for _, lit := range literals {
+		if prefix == "/" {
+			if lit == "/" {
+				return true
+			}
+		} else if prefix != "" && strings.Contains(lit, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesRoute reports whether any of the patterns, as understood by
+// path.Match, matches the route's path, or the route's pattern
+// verbatim.
+func matchesRoute(patterns []string, route string) bool {
+	for _, p := range patterns {
+		if p == route {
+			return true
+		}
+		if ok, _ := path.Match(p, routePath(route)); ok {
+			return true
+		}
+	}
+	return false
+}
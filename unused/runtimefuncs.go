@@ -0,0 +1,274 @@
+package unused
+
+// A runtimeFunc is a function defined in the Go runtime that may be
+// called through compiler magic or via assembly, in the Go releases
+// from since up to but excluding until. A since of zero means the
+// oldest release that the table was generated from, and an until of
+// zero means that the function still exists in the newest one.
+type runtimeFunc struct {
+	name  string
+	since int
+	until int
+}
+
+// legacyRuntimeFuncs are runtime functions of releases older than the
+// ones that runtimeFuncTable was generated from. They were collected
+// by hand from the compiler's runtimeDecls and the assembly code of
+// the standard library.
+var legacyRuntimeFuncs = []string{
+	"abort",
+	"aeshashbody",
+	"args",
+	"asminit",
+	"assertE2I",
+	"assertE2I2",
+	"assertI2I",
+	"assertI2I2",
+	"badctxt",
+	"badmcall",
+	"badmcall2",
+	"badmorestackg0",
+	"badmorestackgsignal",
+	"badsignal2",
+	"block",
+	"callCfunction",
+	"callbackasm1",
+	"cgocallback_gofunc",
+	"cgocallbackg",
+	"chanrecv1",
+	"chanrecv2",
+	"chansend1",
+	"check",
+	"checkgoarm",
+	"closechan",
+	"cmpstring",
+	"complex128div",
+	"concatstring2",
+	"concatstring3",
+	"concatstring4",
+	"concatstring5",
+	"concatstrings",
+	"convI2I",
+	"convT16",
+	"convT2E",
+	"convT2Enoptr",
+	"convT2I",
+	"convT2Inoptr",
+	"convT32",
+	"convT64",
+	"convTslice",
+	"convTstring",
+	"countrunes",
+	"debugCallCheck",
+	"debugCallWrap",
+	"decoderune",
+	"efaceeq",
+	"emptyfunc",
+	"entersyscall",
+	"exit",
+	"exits",
+	"exitsyscall",
+	"externalthreadhandler",
+	"fastrand",
+	"findnull",
+	"float64toint64",
+	"float64touint32",
+	"float64touint64",
+	"goexit1",
+	"gopanic",
+	"gorecover",
+	"goschedguarded",
+	"gostring",
+	"growslice",
+	"i386_set_ldt",
+	"ifaceeq",
+	"init_thread_tls",
+	"_initcgo",
+	"int64div",
+	"int64mod",
+	"int64tofloat64",
+	"intstring",
+	"ldt0setup",
+	"libpreinit",
+	"load_g",
+	"makechan",
+	"makechan64",
+	"makemap",
+	"makemap64",
+	"makemap_small",
+	"makeslice",
+	"makeslice64",
+	"mapaccess1",
+	"mapaccess1_fast32",
+	"mapaccess1_fast64",
+	"mapaccess1_faststr",
+	"mapaccess1_fat",
+	"mapaccess2",
+	"mapaccess2_fast32",
+	"mapaccess2_fast64",
+	"mapaccess2_faststr",
+	"mapaccess2_fat",
+	"mapassign",
+	"mapassign_fast32",
+	"mapassign_fast32ptr",
+	"mapassign_fast64",
+	"mapassign_fast64ptr",
+	"mapassign_faststr",
+	"mapclear",
+	"mapdelete",
+	"mapdelete_fast32",
+	"mapdelete_fast64",
+	"mapdelete_faststr",
+	"mapiterinit",
+	"mapiternext",
+	"memclrHasPointers",
+	"memclrNoHeapPointers",
+	"memequal",
+	"memequal128",
+	"memequal16",
+	"memequal32",
+	"memequal64",
+	"memequal8",
+	"memmove",
+	"morestack",
+	"msanread",
+	"msanwrite",
+	"mstart",
+	"nacl_sysinfo",
+	"nanotime",
+	"nanotimeQPC",
+	"newobject",
+	"newosproc0",
+	"newproc",
+	"newstack",
+	"noted",
+	"nowQPC",
+	"osinit",
+	"panicdivide",
+	"panicdottypeE",
+	"panicdottypeI",
+	"panicindex",
+	"panicmakeslicelen",
+	"panicnildottype",
+	"panicslice",
+	"panicwrap",
+	"printbool",
+	"printcomplex",
+	"printeface",
+	"printf",
+	"printfloat",
+	"printhex",
+	"printiface",
+	"printint",
+	"printlock",
+	"printnl",
+	"printpointer",
+	"printslice",
+	"printsp",
+	"printstring",
+	"printuint",
+	"printunlock",
+	"racecallback",
+	"racefuncenter",
+	"racefuncenterfp",
+	"racefuncexit",
+	"raceread",
+	"racereadrange",
+	"racewrite",
+	"racewriterange",
+	"reflectcallmove",
+	"reginit",
+	"rt0_go",
+	"save_g",
+	"schedinit",
+	"selectgo",
+	"selectnbrecv",
+	"selectnbrecv2",
+	"selectnbsend",
+	"selectsetpc",
+	"setldt",
+	"settls",
+	"sighandler",
+	"sigprofNonGo",
+	"_sigtramp",
+	"sigtramp",
+	"sigtrampgo",
+	"slicebytetostring",
+	"slicebytetostringtmp",
+	"slicecopy",
+	"slicerunetostring",
+	"slicestringcopy",
+	"stackcheck",
+	"stringtoslicebyte",
+	"stringtoslicerune",
+	"syscall_chdir",
+	"syscall_chroot",
+	"syscall_close",
+	"syscall_dup2",
+	"syscall_execve",
+	"syscall_exit",
+	"syscall_fcntl",
+	"syscall_forkx",
+	"syscall_gethostname",
+	"syscall_getpid",
+	"syscall_ioctl",
+	"syscall_pipe",
+	"syscall_rawSyscall6",
+	"syscall_RawSyscall",
+	"syscall_rawsyscall",
+	"syscall_rawsyscall6",
+	"syscall_rawsysvicall6",
+	"syscall_setgid",
+	"syscall_setgroups",
+	"syscall_setpgid",
+	"syscall_setsid",
+	"syscall_setuid",
+	"syscall_Syscall",
+	"syscall_syscall",
+	"syscall_syscall6",
+	"syscall_sysvicall6",
+	"syscall_wait4",
+	"syscall_write",
+	"throwinit",
+	"traceback",
+	"tstart",
+	"typedmemclr",
+	"typedmemmove",
+	"typedslicecopy",
+	"uint32tofloat64",
+	"uint64div",
+	"uint64mod",
+	"uint64tofloat64",
+	"usplitR0",
+	"wbBufFlush",
+	"write",
+}
+
+// runtimeFuncs returns the set of runtime functions for the Go
+// release with the given minor version. Releases between the ones
+// that the table was generated from use the entries of the next
+// older one, and releases newer than all of them use the entries of
+// the newest one. A version of zero means the newest release.
+func runtimeFuncs(version int, extra []string) map[string]bool {
+	out := map[string]bool{}
+	oldest := runtimeFuncsVersions[0]
+	newest := runtimeFuncsVersions[len(runtimeFuncsVersions)-1]
+	switch {
+	case version == 0 || version > newest:
+		version = newest
+	case version < oldest:
+		for _, name := range legacyRuntimeFuncs {
+			out[name] = true
+		}
+		version = oldest
+	}
+	for _, fn := range runtimeFuncTable {
+		if fn.since <= version && (fn.until == 0 || version < fn.until) {
+			out[fn.name] = true
+		}
+	}
+	for _, name := range extra {
+		out[name] = true
+	}
+	return out
+}
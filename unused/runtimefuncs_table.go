@@ -0,0 +1,453 @@
+// Code generated by internal/cmd/runtimefuncs. DO NOT EDIT.
+
+package unused
+
+// runtimeFuncsVersions are the minor versions of the Go releases that
+// runtimeFuncTable was generated from.
+var runtimeFuncsVersions = []int{19, 20, 21, 22, 23, 24, 25, 26, 27}
+
+var runtimeFuncTable = []runtimeFunc{
+	{"KeepAlive", 26, 0},
+	{"_GetStdHandle", 0, 21},
+	{"_NtWaitForSingleObject", 0, 22},
+	{"_SetWaitableTimer", 0, 21},
+	{"_SwitchToThread", 0, 22},
+	{"_TlsAlloc", 0, 0},
+	{"_WriteFile", 0, 21},
+	{"_initcgo", 0, 0},
+	{"abort", 0, 0},
+	{"addCovMeta", 20, 0},
+	{"aeskeysched", 0, 27},
+	{"args", 0, 0},
+	{"arm64UseAlignedLoads", 0, 0},
+	{"armPublicationBarrier", 0, 0},
+	{"asanread", 0, 0},
+	{"asanregisterglobals", 22, 0},
+	{"asanwrite", 0, 0},
+	{"asmcgocall", 0, 0},
+	{"asmcgocall_landingpad", 22, 0},
+	{"asminit", 0, 0},
+	{"asmstdcall", 22, 26},
+	{"asmsyscall6", 0, 0},
+	{"assertE2I", 0, 0},
+	{"assertE2I2", 0, 0},
+	{"assertI2I", 0, 22},
+	{"assertI2I2", 0, 22},
+	{"badmcall", 0, 0},
+	{"badmcall2", 0, 0},
+	{"badmorestackg0", 0, 0},
+	{"badmorestackgsignal", 0, 0},
+	{"badreflectcall", 0, 0},
+	{"badsignal", 0, 0},
+	{"badsignal2", 0, 0},
+	{"badsignallen", 0, 21},
+	{"badsignalmsg", 0, 21},
+	{"badsystemstack", 0, 0},
+	{"block", 0, 0},
+	{"c128equal", 0, 0},
+	{"c128hash", 0, 0},
+	{"c64equal", 0, 0},
+	{"c64hash", 0, 0},
+	{"call1024", 0, 0},
+	{"call1048576", 0, 0},
+	{"call1073741824", 0, 0},
+	{"call128", 0, 0},
+	{"call131072", 0, 0},
+	{"call134217728", 0, 0},
+	{"call16", 0, 0},
+	{"call16384", 0, 0},
+	{"call16777216", 0, 0},
+	{"call2048", 0, 0},
+	{"call2097152", 0, 0},
+	{"call256", 0, 0},
+	{"call262144", 0, 0},
+	{"call268435456", 0, 0},
+	{"call32", 0, 0},
+	{"call32768", 0, 0},
+	{"call33554432", 0, 0},
+	{"call4096", 0, 0},
+	{"call4194304", 0, 0},
+	{"call512", 0, 0},
+	{"call524288", 0, 0},
+	{"call536870912", 0, 0},
+	{"call64", 0, 0},
+	{"call65536", 0, 0},
+	{"call67108864", 0, 0},
+	{"call8192", 0, 0},
+	{"call8388608", 0, 0},
+	{"callbackasm", 0, 0},
+	{"callbackasm1", 0, 0},
+	{"callbacks", 0, 0},
+	{"cbctxts", 0, 21},
+	{"cgoSigtramp", 21, 27},
+	{"cgoTraceback", 0, 0},
+	{"cgocallback", 0, 0},
+	{"cgocallbackg", 0, 0},
+	{"chancap", 23, 0},
+	{"chanlen", 23, 0},
+	{"chanrecv1", 0, 0},
+	{"chanrecv2", 0, 0},
+	{"chansend1", 0, 0},
+	{"check", 0, 0},
+	{"checkS390xCPU", 22, 0},
+	{"checkgoarm", 0, 0},
+	{"checkptrAlignment", 0, 0},
+	{"checkptrArithmetic", 0, 0},
+	{"closechan", 0, 0},
+	{"cmpstring", 0, 0},
+	{"complex128div", 0, 0},
+	{"concatbyte2", 24, 0},
+	{"concatbyte3", 24, 0},
+	{"concatbyte4", 24, 0},
+	{"concatbyte5", 24, 0},
+	{"concatbytes", 24, 0},
+	{"concatstring2", 0, 0},
+	{"concatstring3", 0, 0},
+	{"concatstring4", 0, 0},
+	{"concatstring5", 0, 0},
+	{"concatstrings", 0, 0},
+	{"controlWord64", 0, 0},
+	{"controlWord64trunc", 0, 0},
+	{"convI2I", 0, 22},
+	{"convT", 0, 0},
+	{"convT16", 0, 0},
+	{"convT32", 0, 0},
+	{"convT64", 0, 0},
+	{"convTnoptr", 0, 0},
+	{"convTslice", 0, 0},
+	{"convTstring", 0, 0},
+	{"countrunes", 0, 0},
+	{"debugCallCheck", 0, 0},
+	{"debugCallV2", 0, 0},
+	{"debugCallWrap", 0, 0},
+	{"debugPinnerV1", 23, 0},
+	{"decoderune", 0, 0},
+	{"deferrangefunc", 22, 0},
+	{"dropm", 0, 0},
+	{"efaceeq", 0, 0},
+	{"emptyfunc", 0, 0},
+	{"entersyscall", 0, 0},
+	{"exceptionhandler", 0, 21},
+	{"exit", 0, 0},
+	{"exits", 0, 0},
+	{"exitsyscall", 0, 0},
+	{"f32equal", 0, 0},
+	{"f32hash", 0, 0},
+	{"f64equal", 0, 0},
+	{"f64hash", 0, 0},
+	{"fastrand", 0, 22},
+	{"findnull", 0, 0},
+	{"firstcontinuehandler", 0, 21},
+	{"float64toint64", 0, 0},
+	{"float64touint32", 0, 0},
+	{"float64touint64", 0, 0},
+	{"g0", 0, 0},
+	{"gcWriteBarrier", 0, 21},
+	{"gcrash", 22, 0},
+	{"getcallerpc", 0, 24},
+	{"getcallersp", 0, 24},
+	{"goPanicExtendIndex", 0, 26},
+	{"goPanicExtendIndexU", 0, 26},
+	{"goPanicExtendSlice3Acap", 0, 26},
+	{"goPanicExtendSlice3AcapU", 0, 26},
+	{"goPanicExtendSlice3Alen", 0, 26},
+	{"goPanicExtendSlice3AlenU", 0, 26},
+	{"goPanicExtendSlice3B", 0, 26},
+	{"goPanicExtendSlice3BU", 0, 26},
+	{"goPanicExtendSlice3C", 0, 26},
+	{"goPanicExtendSlice3CU", 0, 26},
+	{"goPanicExtendSliceAcap", 0, 26},
+	{"goPanicExtendSliceAcapU", 0, 26},
+	{"goPanicExtendSliceAlen", 0, 26},
+	{"goPanicExtendSliceAlenU", 0, 26},
+	{"goPanicExtendSliceB", 0, 26},
+	{"goPanicExtendSliceBU", 0, 26},
+	{"goPanicIndex", 0, 0},
+	{"goPanicIndexU", 0, 0},
+	{"goPanicSlice3Acap", 0, 0},
+	{"goPanicSlice3AcapU", 0, 0},
+	{"goPanicSlice3Alen", 0, 0},
+	{"goPanicSlice3AlenU", 0, 0},
+	{"goPanicSlice3B", 0, 0},
+	{"goPanicSlice3BU", 0, 0},
+	{"goPanicSlice3C", 0, 0},
+	{"goPanicSlice3CU", 0, 0},
+	{"goPanicSliceAcap", 0, 0},
+	{"goPanicSliceAcapU", 0, 0},
+	{"goPanicSliceAlen", 0, 0},
+	{"goPanicSliceAlenU", 0, 0},
+	{"goPanicSliceB", 0, 0},
+	{"goPanicSliceBU", 0, 0},
+	{"goPanicSliceConvert", 0, 0},
+	{"goarm", 0, 0},
+	{"goarmsoftfp", 22, 0},
+	{"goexit1", 0, 0},
+	{"gopanic", 0, 0},
+	{"gorecover", 0, 0},
+	{"goschedguarded", 0, 0},
+	{"gostring", 0, 0},
+	{"growslice", 0, 0},
+	{"growsliceBuf", 26, 0},
+	{"growsliceBufNoAlias", 26, 0},
+	{"growsliceNoAlias", 26, 0},
+	{"handleEvent", 0, 0},
+	{"ifaceeq", 0, 0},
+	{"int64div", 0, 0},
+	{"int64mod", 0, 0},
+	{"int64tofloat32", 0, 0},
+	{"int64tofloat64", 0, 0},
+	{"interequal", 0, 0},
+	{"interfaceSwitch", 22, 0},
+	{"interhash", 0, 0},
+	{"intstring", 0, 0},
+	{"isIntel", 0, 0},
+	{"isarchive", 0, 0},
+	{"iscgo", 0, 0},
+	{"lastcontinuehandler", 0, 21},
+	{"lastmoduledatap", 0, 0},
+	{"libInit", 27, 0},
+	{"libfuzzerHookEqualFold", 0, 0},
+	{"libfuzzerHookStrCmp", 0, 0},
+	{"libfuzzerTraceCmp1", 0, 0},
+	{"libfuzzerTraceCmp2", 0, 0},
+	{"libfuzzerTraceCmp4", 0, 0},
+	{"libfuzzerTraceCmp8", 0, 0},
+	{"libfuzzerTraceConstCmp1", 0, 0},
+	{"libfuzzerTraceConstCmp2", 0, 0},
+	{"libfuzzerTraceConstCmp4", 0, 0},
+	{"libfuzzerTraceConstCmp8", 0, 0},
+	{"libpreinit", 0, 27},
+	{"load_g", 0, 0},
+	{"m0", 0, 0},
+	{"main", 0, 0},
+	{"mainPC", 0, 0},
+	{"makechan", 0, 0},
+	{"makechan64", 0, 0},
+	{"makemap", 0, 0},
+	{"makemap64", 0, 0},
+	{"makemap_small", 0, 0},
+	{"makeslice", 0, 0},
+	{"makeslice64", 0, 0},
+	{"makeslicecopy", 0, 0},
+	{"mallocgc", 0, 0},
+	{"mapIterNext", 24, 0},
+	{"mapIterStart", 24, 0},
+	{"mapaccess1", 0, 0},
+	{"mapaccess1_fast32", 0, 0},
+	{"mapaccess1_fast64", 0, 0},
+	{"mapaccess1_faststr", 0, 0},
+	{"mapaccess1_fat", 0, 0},
+	{"mapaccess2", 0, 0},
+	{"mapaccess2_fast32", 0, 0},
+	{"mapaccess2_fast64", 0, 0},
+	{"mapaccess2_faststr", 0, 0},
+	{"mapaccess2_fat", 0, 0},
+	{"mapassign", 0, 0},
+	{"mapassign_fast32", 0, 0},
+	{"mapassign_fast32ptr", 0, 0},
+	{"mapassign_fast64", 0, 0},
+	{"mapassign_fast64ptr", 0, 0},
+	{"mapassign_faststr", 0, 0},
+	{"mapclear", 0, 0},
+	{"mapdelete", 0, 0},
+	{"mapdelete_fast32", 0, 0},
+	{"mapdelete_fast64", 0, 0},
+	{"mapdelete_faststr", 0, 0},
+	{"mapiterinit", 0, 26},
+	{"mapiternext", 0, 26},
+	{"memclrHasPointers", 0, 0},
+	{"memclrNoHeapPointers", 0, 0},
+	{"memequal", 0, 0},
+	{"memequal0", 0, 0},
+	{"memequal128", 0, 0},
+	{"memequal16", 0, 0},
+	{"memequal32", 0, 0},
+	{"memequal64", 0, 0},
+	{"memequal8", 0, 0},
+	{"memhash", 0, 0},
+	{"memhash0", 0, 0},
+	{"memhash128", 0, 0},
+	{"memhash16", 0, 0},
+	{"memhash32", 0, 0},
+	{"memhash32Fallback", 0, 27},
+	{"memhash64", 0, 0},
+	{"memhash64Fallback", 0, 27},
+	{"memhash8", 0, 0},
+	{"memhashFallback", 0, 27},
+	{"memmove", 0, 0},
+	{"memmoveBits", 24, 0},
+	{"morestack", 0, 0},
+	{"moveSlice", 26, 0},
+	{"moveSliceNoCap", 26, 0},
+	{"moveSliceNoCapNoScan", 26, 0},
+	{"moveSliceNoScan", 26, 0},
+	{"msanmove", 0, 0},
+	{"msanread", 0, 0},
+	{"msanwrite", 0, 0},
+	{"mstart", 0, 0},
+	{"mstart0", 0, 0},
+	{"mulUintptr", 0, 22},
+	{"nanotime1", 0, 25},
+	{"nanotimeQPC", 0, 22},
+	{"needAndBindM", 21, 0},
+	{"needm", 0, 21},
+	{"newobject", 0, 0},
+	{"newosproc", 0, 27},
+	{"newosproc0", 0, 27},
+	{"newproc", 0, 0},
+	{"newstack", 0, 0},
+	{"nilinterequal", 0, 0},
+	{"nilinterhash", 0, 0},
+	{"notInitialized1", 24, 0},
+	{"noted", 0, 0},
+	{"nowQPC", 0, 22},
+	{"osinit", 0, 0},
+	{"panicBounds32", 26, 0},
+	{"panicBounds32X", 26, 0},
+	{"panicBounds64", 26, 0},
+	{"panicdivide", 0, 0},
+	{"panicdottypeE", 0, 0},
+	{"panicdottypeI", 0, 0},
+	{"panicmakeslicecap", 0, 0},
+	{"panicmakeslicelen", 0, 0},
+	{"panicnildottype", 0, 0},
+	{"panicrangeexit", 22, 23},
+	{"panicrangestate", 23, 0},
+	{"panicshift", 0, 0},
+	{"panicunsafeslicelen", 0, 0},
+	{"panicunsafeslicenilptr", 0, 0},
+	{"panicunsafestringlen", 20, 0},
+	{"panicunsafestringnilptr", 20, 0},
+	{"panicwrap", 0, 0},
+	{"printbool", 0, 0},
+	{"printcomplex", 0, 26},
+	{"printcomplex128", 26, 0},
+	{"printcomplex64", 26, 0},
+	{"printeface", 0, 0},
+	{"printfloat", 0, 26},
+	{"printfloat32", 26, 0},
+	{"printfloat64", 26, 0},
+	{"printhex", 0, 0},
+	{"printiface", 0, 0},
+	{"printint", 0, 0},
+	{"printlock", 0, 0},
+	{"printnl", 0, 0},
+	{"printpointer", 0, 0},
+	{"printquoted", 26, 0},
+	{"printslice", 0, 0},
+	{"printsp", 0, 0},
+	{"printstring", 0, 0},
+	{"printuint", 0, 0},
+	{"printuintptr", 0, 0},
+	{"printunlock", 0, 0},
+	{"processorVersionInfo", 0, 0},
+	{"racearenaend", 0, 0},
+	{"racearenastart", 0, 0},
+	{"racecallback", 0, 0},
+	{"racedataend", 0, 0},
+	{"racedatastart", 0, 0},
+	{"racefuncenter", 0, 0},
+	{"racefuncexit", 0, 0},
+	{"raceread", 0, 0},
+	{"racereadrange", 0, 0},
+	{"racewrite", 0, 0},
+	{"racewriterange", 0, 0},
+	{"rand", 24, 0},
+	{"rand32", 22, 0},
+	{"reflectcallmove", 0, 0},
+	{"reginit", 0, 0},
+	{"rt0LibGoDesc", 27, 0},
+	{"rt0_go", 0, 0},
+	{"rt0_lib_go", 27, 0},
+	{"save_g", 0, 0},
+	{"schedinit", 0, 0},
+	{"secretEraseRegistersMcall", 26, 0},
+	{"sehhandler", 22, 0},
+	{"selectgo", 0, 0},
+	{"selectnbrecv", 0, 0},
+	{"selectnbsend", 0, 0},
+	{"selectsetpc", 0, 0},
+	{"setldt", 0, 0},
+	{"setsigsegv", 0, 27},
+	{"settls", 0, 0},
+	{"sighandler", 0, 0},
+	{"sigpanic", 0, 0},
+	{"sigprofCallers", 0, 0},
+	{"sigprofCallersUse", 0, 0},
+	{"sigprofNonGo", 0, 0},
+	{"sigprofNonGoWrapper", 0, 0},
+	{"sigtramp", 0, 0},
+	{"sigtrampgo", 0, 0},
+	{"slicebytetostring", 0, 0},
+	{"slicebytetostringtmp", 0, 0},
+	{"slicecopy", 0, 0},
+	{"slicerunetostring", 0, 0},
+	{"spillArgs", 0, 0},
+	{"stackcheck", 0, 0},
+	{"strequal", 0, 0},
+	{"strhash", 0, 0},
+	{"strhashFallback", 0, 27},
+	{"stringtoslicebyte", 0, 0},
+	{"stringtoslicerune", 0, 0},
+	{"syscall_RawSyscall", 0, 0},
+	{"syscall_Syscall", 0, 0},
+	{"syscall_chdir", 0, 0},
+	{"syscall_chroot", 0, 0},
+	{"syscall_close", 0, 0},
+	{"syscall_dup2", 0, 0},
+	{"syscall_execve", 0, 0},
+	{"syscall_exit", 0, 0},
+	{"syscall_fcntl", 0, 0},
+	{"syscall_forkx", 0, 0},
+	{"syscall_gethostname", 0, 0},
+	{"syscall_getpid", 0, 0},
+	{"syscall_ioctl", 0, 0},
+	{"syscall_rawSyscall6", 0, 0},
+	{"syscall_rawsyscall", 0, 0},
+	{"syscall_rawsyscall6", 0, 0},
+	{"syscall_rawsysvicall6", 0, 0},
+	{"syscall_setgid", 0, 0},
+	{"syscall_setgroups", 0, 0},
+	{"syscall_setpgid", 0, 0},
+	{"syscall_setrlimit", 0, 0},
+	{"syscall_setsid", 0, 0},
+	{"syscall_setuid", 0, 0},
+	{"syscall_syscall", 0, 0},
+	{"syscall_syscall6", 0, 0},
+	{"syscall_sysvicall6", 0, 0},
+	{"syscall_wait4", 0, 0},
+	{"syscall_write", 0, 0},
+	{"systemstack_switch", 0, 0},
+	{"throwinit", 0, 0},
+	{"tls_entry_number", 0, 0},
+	{"tls_g", 0, 0},
+	{"tlsoffset", 0, 0},
+	{"tstart", 0, 0},
+	{"typeAssert", 22, 0},
+	{"typedmemclr", 0, 0},
+	{"typedmemmove", 0, 0},
+	{"typedslicecopy", 0, 0},
+	{"udiv", 0, 0},
+	{"uint32tofloat64", 0, 0},
+	{"uint64div", 0, 0},
+	{"uint64mod", 0, 0},
+	{"uint64tofloat32", 0, 0},
+	{"uint64tofloat64", 0, 0},
+	{"unsafeslicecheckptr", 0, 0},
+	{"unsafestringcheckptr", 20, 0},
+	{"unspillArgs", 0, 0},
+	{"useAVXmemmove", 0, 24},
+	{"useAeshash", 0, 27},
+	{"useQPCTime", 0, 22},
+	{"usplitR0", 0, 0},
+	{"vdsoCall", 20, 0},
+	{"vdsoClockgettimeSym", 0, 0},
+	{"vdsoGetrandomSym", 24, 0},
+	{"vdsoGettimeofdaySym", 0, 0},
+	{"wasmExit", 0, 0},
+	{"wasmMove", 0, 20},
+	{"wasmStack", 0, 0},
+	{"wbBufFlush", 0, 0},
+	{"wintls", 0, 0},
+	{"write", 0, 0},
+}
@@ -0,0 +1,109 @@
+package unused
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"honnef.co/go/tools/analysis/facts/generated"
+	"honnef.co/go/tools/go/ir"
+	"honnef.co/go/tools/go/types/typeutil"
+	"honnef.co/go/tools/unused/refgraph"
+
+	"golang.org/x/exp/typeparams"
+)
+
+// skimmedFiles returns the names of the generated files that have at
+// least g.skimGenerated lines. Files generated by cgo are never
+// skimmed, as they contain the user's code.
+func (g *graph) skimmedFiles() map[string]bool {
+	if g.skimGenerated <= 0 {
+		return nil
+	}
+	out := map[string]bool{}
+	for _, f := range g.pkg.Files {
+		tf := g.pkg.Fset.File(f.Pos())
+		gen, ok := g.pkg.Generated[tf.Name()]
+		if !ok || gen == generated.Cgo {
+			continue
+		}
+		if tf.LineCount() >= g.skimGenerated {
+			out[tf.Name()] = true
+		}
+	}
+	return out
+}
+
+// inSkimmedFile reports whether pos is in a skimmed file.
+func (g *graph) inSkimmedFile(pos token.Pos) bool {
+	if len(g.skimmed) == 0 || !pos.IsValid() {
+		return false
+	}
+	return g.skimmed[g.pkg.Fset.PositionFor(pos, false).Filename]
+}
+
+// skim adds the uses of the body of fn, which is declared in a skimmed
+// file or has no IR, without walking its instructions. fn uses all
+// objects that identifiers in its body refer to, and all fields and
+// methods of the package's named types whose values its body handles.
+// This is coarser than walking the instructions, but functions in
+// skimmed files are always used anyway, and functions without IR have
+// no instructions to walk.
+func (g *graph) skim(fn *ir.Function) {
+	decl, ok := fn.Source().(*ast.FuncDecl)
+	if !ok || decl.Body == nil {
+		return
+	}
+	by := fn.Object()
+	members := map[*types.TypeName]struct{}{}
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		expr, ok := n.(ast.Expr)
+		if !ok {
+			return true
+		}
+		if ident, ok := expr.(*ast.Ident); ok {
+			if obj := g.skimmedUse(g.pkg.TypesInfo.Uses[ident]); obj != nil {
+				g.seeAndUse(obj, by, refgraph.EdgeSkimmed)
+			}
+		}
+		T := g.pkg.TypesInfo.TypeOf(expr)
+		if T == nil {
+			return true
+		}
+		named, ok := typeutil.Unalias(typeutil.Dereference(T)).(*types.Named)
+		if !ok {
+			return true
+		}
+		obj := named.Origin().Obj()
+		if obj.Pkg() != g.pkg.Pkg {
+			return true
+		}
+		if _, ok := members[obj]; !ok {
+			members[obj] = struct{}{}
+			g.seeAndUse(obj, by, refgraph.EdgeSkimmed)
+			g.typ(named.Origin(), nil)
+			g.useMembers(obj, refgraph.EdgeSkimmed)
+		}
+		return true
+	})
+}
+
+// skimmedUse returns the object of a use in the body of a skimmed
+// function that the function should use, or nil.
+func (g *graph) skimmedUse(obj types.Object) types.Object {
+	switch obj := obj.(type) {
+	case *types.Func:
+		return typeparams.OriginMethod(obj)
+	case *types.Var:
+		if obj.IsField() || (obj.Pkg() != nil && obj.Parent() == obj.Pkg().Scope()) {
+			return obj
+		}
+	case *types.TypeName:
+		if obj.Pkg() != nil && obj.Parent() == obj.Pkg().Scope() {
+			return obj
+		}
+	case *types.Const:
+		return obj
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package unused
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"go/types"
+	"io"
+	"sync"
+)
+
+// SkipLog, if not nil, receives a log of the constructs that the
+// analysis ignores, such as objects belonging to other packages. Each
+// event is written as a single line of JSON. This is useful for
+// investigating false negatives.
+//
+// Packages whose results are cached don't get analyzed and thus don't
+// produce events.
+var SkipLog io.Writer
+
+var skipLogMu sync.Mutex
+
+// A SkipEvent describes a construct that the analysis ignored.
+type SkipEvent struct {
+	Package  string `json:"package"`
+	Position string `json:"position,omitempty"`
+	Object   string `json:"object,omitempty"`
+	Reason   string `json:"reason"`
+}
+
+type skipKey struct {
+	obj    interface{}
+	reason string
+}
+
+// skip logs that obj, which may be a types.Object or a types.Type,
+// was ignored for the given reason. Each event is only logged once
+// per package.
+func (g *graph) skip(obj interface{}, reason string) {
+	if SkipLog == nil {
+		return
+	}
+	key := skipKey{obj, reason}
+	if _, ok := g.skipped[key]; ok {
+		return
+	}
+	g.skipped[key] = struct{}{}
+
+	ev := SkipEvent{
+		Package: g.pkg.Pkg.Path(),
+		Reason:  reason,
+	}
+	switch obj := obj.(type) {
+	case types.Object:
+		ev.Object = obj.String()
+		if obj.Pos().IsValid() && g.pkg.Fset.File(obj.Pos()) != nil {
+			ev.Position = g.pkg.Fset.Position(obj.Pos()).String()
+		}
+	case *types.Named:
+		ev.Object = obj.String()
+		if pos := obj.Obj().Pos(); pos.IsValid() && g.pkg.Fset.File(pos) != nil {
+			ev.Position = g.pkg.Fset.Position(pos).String()
+		}
+	case types.Type:
+		ev.Object = obj.String()
+	case token.Pos:
+		ev.Position = g.pkg.Fset.Position(obj).String()
+	case nil:
+	default:
+		ev.Object = fmt.Sprintf("%v", obj)
+	}
+
+	b, err := json.Marshal(ev)
+	if err != nil {
+		panic(err)
+	}
+	skipLogMu.Lock()
+	defer skipLogMu.Unlock()
+	SkipLog.Write(append(b, '\n'))
+}
@@ -7,7 +7,7 @@ type t2 struct{} //@ used(false)
 type t3 struct{} //@ used(true)
 
 type alias1 = t1  //@ used(true)
-type alias2 = t2  //@ used(false)
+type alias2 = t2  //@ quiet()
 type alias3 = t3  //@ used(true)
 type alias4 = int //@ used(true)
 
@@ -32,3 +32,18 @@ type alias6 = http.Server //@ used(true)
 type alias7 = struct { //@ used(true)
 	x int //@ used(true)
 }
+
+// Unused aliases of unused types are reported as part of the types.
+type t5 struct{} //@ used(false)
+
+type alias8 = t5     //@ quiet()
+type alias9 = alias8 //@ quiet()
+
+type t6[T any] struct{} //@ used(false)
+
+type alias10 = t6[int] //@ quiet()
+
+// Aliases of pointers to unused types are reported on their own.
+type t7 struct{} //@ used(false)
+
+type alias11 = *t7 //@ used(false)
@@ -0,0 +1,40 @@
+package pkg
+
+import "io"
+
+// reader is only kept alive by its interface assertion.
+type reader struct{} //@ used(false)
+
+func (*reader) Read(p []byte) (int, error) { return 0, nil } //@ used(false)
+
+func (*reader) helper() {} //@ used(false)
+
+var _ io.Reader = (*reader)(nil)
+
+type closer struct{} //@ used(false)
+
+func (closer) Close() error { return nil } //@ used(false)
+
+var (
+	_ io.Closer = closer{}
+	_ io.Closer = &closer{}
+)
+
+type writer struct{} //@ used(true)
+
+func (writer) Write(p []byte) (int, error) { return len(p), nil } //@ used(true)
+
+var _ io.Writer = new(writer)
+
+// iface is only used by the assertion of its implementation.
+type iface interface { //@ used(false)
+	m() //@ quiet()
+}
+
+type impl struct{} //@ used(false)
+
+func (impl) m() {} //@ used(false)
+
+var _ iface = impl{}
+
+func NewWriter() io.Writer { return writer{} } //@ used(true)
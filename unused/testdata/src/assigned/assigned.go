@@ -0,0 +1,22 @@
+package pkg
+
+var assignedInInit int     //@ used(false)
+var assignedInFn string    //@ used(false)
+var assignedInClosure bool //@ used(false)
+var initialized = 1        //@ used(false)
+var read int               //@ used(true)
+var incremented int        //@ used(true)
+
+func init() { //@ used(true)
+	assignedInInit = 1
+}
+
+func Fn() int { //@ used(true)
+	assignedInFn = "foo"
+	func() {
+		assignedInClosure = true
+	}()
+	read = 2
+	incremented++
+	return read
+}
@@ -0,0 +1,7 @@
+package pkg // want `skipped unused code analysis: graph exceeds the limit of 3 nodes`
+
+type t1 struct{} //@ used(true)
+
+func fn1() {} //@ used(true)
+func fn2() {} //@ used(true)
+func fn3() {} //@ used(true)
@@ -0,0 +1,37 @@
+package pkg
+
+/*
+#include <stdlib.h>
+
+static int add(int a, int b) { return a + b; }
+
+extern void goCallback(int);
+
+static void callGo(void) { goCallback(1); }
+*/
+import "C"
+
+import "unsafe"
+
+//export goCallback
+func goCallback(x C.int) { //@ used(true)
+	helper()
+}
+
+func helper() {} //@ used(true)
+
+func Add(a, b int) int { //@ used(true)
+	return int(C.add(C.int(a), C.int(b)))
+}
+
+func Call() { //@ used(true)
+	C.callGo()
+}
+
+func free(p unsafe.Pointer) { //@ used(false)
+	C.free(p)
+}
+
+type cType C.int //@ used(false)
+
+func unused() {} //@ used(false)
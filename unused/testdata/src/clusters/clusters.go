@@ -0,0 +1,30 @@
+package pkg
+
+// exporter is what's left of the legacy exporter.
+type exporter struct { //@ used(false)
+	out []record //@ quiet()
+}
+
+type record struct{} //@ used(false)
+
+func newExporter() *exporter { return &exporter{} } //@ used(false)
+
+func (e *exporter) add(r record) { //@ used(false)
+	e.out = append(e.out, r)
+}
+
+func (e *exporter) flush() {} //@ used(false)
+
+var (
+	a, //@ used(false)
+	b = 1, 2 //@ used(false)
+)
+
+func sum() int { return a + b } //@ used(false)
+
+func alone() {} //@ used(false)
+
+func helper() {} //@ used(true)
+
+// Fn is used, and so is what it calls.
+func Fn() { helper() } //@ used(true)
@@ -0,0 +1,49 @@
+package pkg
+
+import "reflect"
+
+type t1 struct { //@ used(true)
+	a int   //@ used(true)
+	b t2    //@ used(true)
+	c [2]t3 //@ used(true)
+	d *t4   //@ used(true)
+}
+
+type t2 struct { //@ used(true)
+	x int //@ used(true)
+}
+
+type t3 struct { //@ used(true)
+	y int //@ used(true)
+}
+
+// == doesn't follow pointers
+type t4 struct { //@ used(true)
+	z int //@ used(false)
+}
+
+func Fn1(a, b t1) bool { //@ used(true)
+	return a == b
+}
+
+type t5 struct { //@ used(true)
+	a []*t6 //@ used(true)
+}
+
+type t6 struct { //@ used(true)
+	b map[string]t7 //@ used(true)
+}
+
+type t7 struct { //@ used(true)
+	c int //@ used(true)
+}
+
+func Fn2(a, b t5) bool { //@ used(true)
+	return reflect.DeepEqual(a, b)
+}
+
+type t8 struct { //@ used(true)
+	a int //@ used(false)
+}
+
+func Fn3(a t8) t8 { return a } //@ used(true)
@@ -0,0 +1,49 @@
+package pkg
+
+type number interface { //@ used(false)
+	~int | ~float64
+}
+
+// Nothing refers to stringish, so T doesn't need str for implementing
+// it.
+type stringish interface { //@ used(false)
+	~struct{}
+	str() string //@ quiet()
+}
+
+type T struct{} //@ used(true)
+
+func (T) str() string { return "" } //@ used(false)
+
+type base interface { //@ used(true)
+	comparable
+	name() string //@ used(true)
+}
+
+type named interface { //@ used(true)
+	base
+}
+
+func names[V named](vs []V) { //@ used(true)
+	for _, v := range vs {
+		v.name()
+	}
+}
+
+type U struct{} //@ used(true)
+
+func (U) name() string { return "" } //@ used(true)
+
+// Ordinary interfaces keep the methods that implement them alive, as
+// values may get converted to them.
+type labeler interface { //@ used(false)
+	label() string //@ quiet()
+}
+
+func (T) label() string { return "" } //@ used(true)
+
+func Fn() { //@ used(true)
+	names([]U{})
+	var t T
+	_ = t
+}
@@ -0,0 +1,84 @@
+package pkg
+
+import (
+	"github.com/google/wire"
+	"go.uber.org/dig"
+	"go.uber.org/fx"
+)
+
+// The injector gets replaced by code that wire generates.
+func initServer() *server { //@ used(false)
+	panic(wire.Build(newServer, wire.Struct(new(options), "*"), wire.Bind(new(greeter), new(*english))))
+}
+
+type server struct { //@ used(true)
+	opts *options //@ used(true)
+}
+
+func newServer(opts *options, g greeter) *server { return &server{opts: opts} } //@ used(true)
+
+func (*server) serve() {} //@ used(true)
+
+type options struct { //@ used(true)
+	addr string //@ used(true)
+}
+
+type greeter interface { //@ used(true)
+	greet() //@ used(true)
+}
+
+type english struct{} //@ used(true)
+
+func (*english) greet() {} //@ used(true)
+
+func Main() { //@ used(true)
+	fx.New(
+		fx.Provide(newClient, fx.Annotate(newCache)),
+		fx.Invoke(register),
+	)
+	c := dig.New()
+	c.Provide(newStore)
+}
+
+type client struct { //@ used(true)
+	timeout int //@ used(true)
+}
+
+func newClient() *client { return nil } //@ used(true)
+
+func (client) close() {} //@ used(true)
+
+type cache struct{} //@ used(true)
+
+func newCache() cache { return cache{} } //@ used(true)
+
+func (cache) flush() {} //@ used(true)
+
+type params struct { //@ used(true)
+	fx.In //@ used(true)
+
+	Client *client //@ used(true)
+	cache  cache   //@ used(true)
+}
+
+func register(p params) {} //@ used(true)
+
+type store struct { //@ used(true)
+	rows int //@ used(true)
+}
+
+func newStore() *store { return nil } //@ used(true)
+
+func unused() {} //@ used(false)
+
+type config struct { //@ used(false)
+	port int //@ quiet()
+}
+
+func newConfig() *config { return nil } //@ used(false)
+
+func (*config) validate() {} //@ used(false)
+
+func dead() { //@ used(false)
+	fx.Provide(newConfig)
+}
@@ -0,0 +1,47 @@
+// Package pkg is documented with [t1] and [pkg.fn1].
+//
+// [t1]: https://example.com
+package pkg
+
+type t1 struct{} //@ used(true)
+
+func fn1() {} //@ used(true)
+
+// Fn2 calls [fn3], [*t2] and [t3.method].
+func Fn2() {} //@ used(true)
+
+func fn3() {} //@ used(true)
+
+type t2 struct{} //@ used(true)
+
+type t3 struct{} //@ used(true)
+
+func (t3) method() {} //@ used(true)
+func (t3) other()  {} //@ used(false)
+
+// Links to [fn4] from unused objects don't count.
+func unused() {} //@ used(false)
+
+func fn4() {} //@ used(false)
+
+// Not links: [fn5]: x, a[fn5], [fn5]b and `[undefined]`.
+func Fn6() {} //@ used(true)
+
+func fn5() {} //@ used(false)
+
+type T4 struct { //@ used(true)
+	// Field is like [t5.field].
+	Field int //@ used(true)
+}
+
+type t5 struct { //@ used(true)
+	field int //@ used(true)
+}
+
+// Group of [fn6].
+var (
+	V1 = 1 //@ used(true)
+	v2 = 2 //@ used(false)
+)
+
+func fn6() {} //@ used(true)
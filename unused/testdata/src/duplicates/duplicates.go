@@ -0,0 +1,68 @@
+package pkg
+
+import "strings"
+
+func Parse(s string) []string { return split(s) } //@ used(true)
+
+func split(s string) []string { //@ used(true)
+	var out []string
+	for _, field := range strings.Split(s, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			out = append(out, field)
+		}
+	}
+	return out
+}
+
+// splitFields was copied from split, with different names.
+func splitFields(input string) []string { //@ used(false)
+	var fields []string
+	for _, f := range strings.Split(input, ",") {
+		// skip empty fields
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+func splitSemicolons(s string) []string { //@ used(false)
+	var out []string
+	for _, field := range strings.Split(s, ";") {
+		if field = strings.TrimSpace(field); field != "" {
+			out = append(out, field)
+		}
+	}
+	return out
+}
+
+func Nil() error { return none() } //@ used(true)
+
+func none() error { return nil } //@ used(true)
+
+func none2() error { return nil } //@ used(false)
+
+func Describe(v interface{}) string { return describe(v) } //@ used(true)
+
+func describe(v interface{}) string { //@ used(true)
+	switch x := v.(type) {
+	case string:
+		return "string " + x
+	case error:
+		return "error " + x.Error()
+	default:
+		return "unknown"
+	}
+}
+
+// describeValue only renames the variable of the type switch.
+func describeValue(val interface{}) string { //@ used(false)
+	switch y := val.(type) {
+	case string:
+		return "string " + y
+	case error:
+		return "error " + y.Error()
+	default:
+		return "unknown"
+	}
+}
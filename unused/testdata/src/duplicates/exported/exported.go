@@ -0,0 +1,35 @@
+package exported
+
+func init() { sum(nil) } //@ used(true)
+
+func sum(values []int) int { //@ used(true)
+	total := 0
+	for _, v := range values {
+		if v > 0 && v < 100 {
+			total += v
+		}
+	}
+	return total
+}
+
+func sumPositive(xs []int) int { //@ used(false)
+	n := 0
+	for _, x := range xs {
+		if x > 0 && x < 100 {
+			n += x
+		}
+	}
+	return n
+}
+
+// Sum isn't used by its own package, but in whole-program mode, other
+// packages may use it.
+func Sum(xs []int) int { //@ used(false)
+	total := 0
+	for _, x := range xs {
+		if x > 0 && x < 100 {
+			total += x
+		}
+	}
+	return total
+}
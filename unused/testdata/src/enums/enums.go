@@ -0,0 +1,37 @@
+package pkg
+
+type kind int //@ used(true)
+
+const (
+	kindNone kind = iota //@ used(false)
+	kindA                //@ used(true)
+	kindB                //@ used(false)
+	kindC                //@ used(true)
+	kindD                //@ used(false)
+	kindE                //@ used(false)
+)
+
+const (
+	flagA = 1 << iota //@ used(false)
+	flagB             //@ used(true)
+)
+
+// Not an enumeration, so the const groups rule still applies.
+const (
+	x1 = 1 //@ used(true)
+	x2 = 2 //@ used(true)
+)
+
+// The values of later constants don't depend on iota.
+const (
+	y1 = iota //@ used(false)
+	y2 = 5    //@ used(true)
+)
+
+func Fn() { //@ used(true)
+	_ = kindA
+	_ = kindC
+	_ = flagB
+	_ = x1
+	_ = y2
+}
@@ -0,0 +1,35 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+)
+
+var errUnused = errors.New("unused")         //@ used(false)
+var errUnused2 = fmt.Errorf("unused: %d", 1) //@ used(false)
+var errUsed = errors.New("used")             //@ used(true)
+var ErrExported = errors.New("exported")     //@ used(true)
+var errPtr = &ptrError{}                     //@ used(false)
+var notAnError = "not an error"              //@ used(false)
+
+type valueError struct{} //@ used(false)
+
+func (valueError) Error() string { return "" } //@ used(false)
+
+type ptrError struct{} //@ used(true)
+
+func (*ptrError) Error() string { return "" } //@ used(true)
+
+type notError struct{} //@ used(false)
+
+type errorIface interface { //@ used(false)
+	error
+}
+
+func Fn() error { //@ used(true)
+	var err error
+	if errors.Is(err, errUsed) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,70 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+)
+
+var errIs = errors.New("is")             //@ used(true)
+var errWrapped = errors.New("wrapped")   //@ used(true)
+var errWrappedV = errors.New("wrappedV") //@ used(true)
+var errUnused = errors.New("unused")     //@ used(false)
+
+type asError struct{} //@ used(true)
+
+func (*asError) Error() string { return "" } //@ used(true)
+
+type asNewError struct{} //@ used(true)
+
+func (asNewError) Error() string { return "" } //@ used(true)
+
+type assertedError struct{} //@ used(true)
+
+func (assertedError) Error() string { return "" } //@ used(true)
+
+type switchedError struct{} //@ used(true)
+
+func (switchedError) Error() string { return "" } //@ used(true)
+
+type unusedError struct{} //@ used(false)
+
+func (unusedError) Error() string { return "" } //@ used(false)
+
+func Is(err error) bool { //@ used(true)
+	return errors.Is(err, errIs)
+}
+
+func As(err error) bool { //@ used(true)
+	var target *asError
+	return errors.As(err, &target)
+}
+
+func AsNew(err error) bool { //@ used(true)
+	return errors.As(err, new(asNewError))
+}
+
+func Wrap() error { //@ used(true)
+	return fmt.Errorf("context: %w", errWrapped)
+}
+
+func WrapVariadic() error { //@ used(true)
+	args := []interface{}{errWrappedV}
+	return fmt.Errorf("context: %w", args...)
+}
+
+// isAsserted is a helper shim that is only used via a function value.
+func isAsserted(err error) bool { //@ used(true)
+	_, ok := err.(assertedError)
+	return ok
+}
+
+func isSwitched(err error) bool { //@ used(true)
+	switch err.(type) {
+	case switchedError:
+		return true
+	default:
+		return false
+	}
+}
+
+var Checks = []func(error) bool{isAsserted, isSwitched} //@ used(true)
@@ -0,0 +1,74 @@
+package pkg
+
+import "fmt"
+
+// Values of t1 never leave the package, so nobody can call Exported.
+type t1 struct{} //@ used(true)
+
+func (t1) Exported() {} //@ used(false)
+func (t1) Used()     {} //@ used(true)
+
+func Fn1() { //@ used(true)
+	var x t1
+	x.Used()
+}
+
+// Returned by an exported function.
+type t2 struct{} //@ used(true)
+
+func (t2) Exported() {} //@ used(true)
+
+func Fn2() *t2 { return nil } //@ used(true)
+
+// Converted to an interface.
+type t3 struct{} //@ used(true)
+
+func (t3) String() string { return "" } //@ used(true)
+
+func Fn3() { //@ used(true)
+	fmt.Println(t3{})
+}
+
+// Embedded in an exported struct, which promotes its methods.
+type t4 struct{} //@ used(true)
+
+func (t4) Exported() {} //@ used(true)
+
+type T5 struct { //@ used(true)
+	t4 //@ used(true)
+}
+
+// Reachable via an exported field of an escaping type.
+type t6 struct { //@ used(true)
+	F t7 //@ used(true)
+}
+
+type t7 struct{} //@ used(true)
+
+func (t7) Exported() {} //@ used(true)
+
+func (t6) Get() []t6 { return nil } //@ used(true)
+
+var V t6 //@ used(true)
+
+// Used as a type argument.
+type t8 struct{} //@ used(true)
+
+func (t8) String() string { return "" } //@ used(true)
+
+func generic[T fmt.Stringer]() {} //@ used(true)
+
+func Fn8() { //@ used(true)
+	generic[t8]()
+}
+
+// Unexported fields don't let values escape.
+type t9 struct{} //@ used(true)
+
+func (t9) Exported() {} //@ used(false)
+
+type T10 struct { //@ used(true)
+	f t9 //@ used(true)
+}
+
+func (t T10) Fn() { _ = t.f } //@ used(true)
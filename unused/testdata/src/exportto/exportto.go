@@ -0,0 +1,38 @@
+package pkg
+
+//lint:export-to billing called via RPC reflection by the billing service
+func handler() {} //@ used(true)
+
+func unrelated() {} //@ used(false)
+
+//lint:export-to plugins
+type plugin struct { //@ used(true)
+	name string //@ used(true)
+}
+
+func (plugin) run() {} //@ used(true)
+
+var (
+	//lint:export-to templates
+	tmplFuncs = 1 //@ used(true)
+	other     = 2 //@ used(false)
+)
+
+//lint:export-to config
+var (
+	a int //@ used(true)
+	b int //@ used(true)
+)
+
+type settings struct { //@ used(true)
+	//lint:export-to encoding/json populated by reflection
+	field int //@ used(true)
+	unset int //@ used(false)
+}
+
+var _ settings
+
+// Directives without a consumer don't do anything.
+//
+//lint:export-to
+func noConsumer() {} //@ used(false)
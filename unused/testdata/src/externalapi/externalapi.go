@@ -0,0 +1,11 @@
+// Package pkg is used by other repositories, so its exported objects
+// are used even in whole-program mode.
+package pkg
+
+func Exported() {} //@ used(true)
+
+func unexported() {} //@ used(false)
+
+type T struct{} //@ used(true)
+
+func (T) Method() {} //@ used(true)
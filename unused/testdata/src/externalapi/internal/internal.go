@@ -0,0 +1,7 @@
+// Package internal is exempted from the external API of its parent,
+// so its exported objects have to be used by analyzed packages.
+package internal
+
+func Exported() {} //@ used(false)
+
+var Var int //@ used(false)
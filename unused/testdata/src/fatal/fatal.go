@@ -0,0 +1,58 @@
+package pkg
+
+import (
+	"errors"
+	"log"
+	"os"
+)
+
+var errClosed = errors.New("closed") //@ used(true)
+
+type state struct { //@ used(true)
+	open bool //@ used(true)
+}
+
+// describe is only used to format the message of a fatal error.
+func describe(s *state) string { return "broken" } //@ used(false)
+
+func legacyDump(d dump) { d.write() } //@ used(false)
+
+type dump struct{} //@ used(false)
+
+func (dump) write() {} //@ used(false)
+
+func helper() int { return 1 } //@ used(true)
+
+func cleanup() {} //@ used(true)
+
+func Check(s *state) int { //@ used(true)
+	if !s.open {
+		log.Fatal(describe(s))
+	}
+	if s == nil {
+		legacyDump(dump{})
+		panic(errClosed)
+	}
+	if s.open && helper() > 1 {
+		// helper is also used here
+		cleanup()
+		os.Exit(1)
+	}
+	return helper()
+}
+
+// usage always exits, which makes its call sites fatal, but the
+// calls themselves are uses.
+func usage() { //@ used(true)
+	os.Stderr.WriteString(format())
+	os.Exit(2)
+}
+
+func format() string { return "" } //@ used(true)
+
+func Run(ok bool) { //@ used(true)
+	if !ok {
+		usage()
+	}
+	cleanup()
+}
@@ -58,8 +58,8 @@ type t16 struct { //@ used(true)
 	f161 int //@ used(true)
 }
 type t17 struct { //@ used(false)
-	f171 int
-	f172 int
+	f171 int //@ quiet()
+	f172 int //@ quiet()
 }
 type t18 struct { //@ used(true)
 	f181 int //@ used(true)
@@ -82,8 +82,8 @@ type t21 struct { //@ used(true)
 	f212 int //@ used(true)
 }
 type t22 struct { //@ used(false)
-	f221 int
-	f222 int
+	f221 int //@ quiet()
+	f222 int //@ quiet()
 }
 
 func foo() { //@ used(true)
@@ -124,6 +124,6 @@ func init() { foo() } //@ used(true)
 
 func superUnused() { //@ used(false)
 	var _ struct {
-		x int
+		x int //@ quiet()
 	}
 }
@@ -0,0 +1,55 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	// strings is only used by fn1
+	"strings"
+)
+
+import "os"
+
+// fn1 is unused.
+func fn1() { //@ used(false)
+	_ = strings.ToUpper("")
+}
+
+func fn2() { //@ used(false)
+	fmt.Println()
+}
+
+func Fn3() { //@ used(true)
+	fmt.Println()
+}
+
+// t1 is unused, and so are its methods.
+type t1 struct{} //@ used(false)
+
+func (t1) fn() { //@ used(false)
+	_ = bytes.NewBuffer
+}
+
+func (*t1) fn2() { //@ used(false)
+	_ = os.Args
+}
+
+// t2 is unused, and so is its alias, which gets deleted with it.
+type t2 struct{} //@ used(false)
+
+type t2Alias = t2 //@ quiet()
+
+var (
+	v1 = 1 //@ used(false)
+	V2 = 2 //@ used(true)
+)
+
+const c1 = 1 //@ used(false)
+
+const (
+	c2 = iota //@ used(false)
+	c3        //@ used(false)
+)
+
+// Declarations of multiple objects can't be deleted as a whole.
+var v3, //@ used(false)
+	v4 int //@ used(false)
@@ -0,0 +1,30 @@
+package pkg
+
+import "forbid/internal"
+
+type Handler struct { // want `forbidden reference to internal\.Conn \(use the repository\): Handler → internal\.Conn`
+	conns []*internal.Conn
+}
+
+func (h *Handler) ServeHTTP() {
+	h.serve()
+}
+
+func (h *Handler) serve() { // want `forbidden reference to internal\.Query \(use the repository\): Handler → Handler\.ServeHTTP → Handler\.serve → internal\.Query`
+	internal.Query()
+	internal.Allowed()
+}
+
+func unused() { // want `forbidden reference to internal\.Query \(use the repository\): unused → internal\.Query`
+	internal.Query()
+}
+
+func Fine() {
+	internal.Allowed()
+}
+
+var query = internal.Query // want `forbidden reference to internal\.Query \(use the repository\): query → internal\.Query`
+
+type Batch [internal.QueryLimit]int // want `forbidden reference to internal\.QueryLimit \(use the repository\): Batch → internal\.QueryLimit`
+
+var limit = internal.QueryLimit // want `forbidden reference to internal\.QueryLimit \(use the repository\): limit → internal\.QueryLimit`
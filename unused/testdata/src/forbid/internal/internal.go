@@ -0,0 +1,11 @@
+package internal
+
+type Conn struct{}
+
+func (Conn) Exec() {}
+
+func Query() {}
+
+func Allowed() {}
+
+const QueryLimit = 10
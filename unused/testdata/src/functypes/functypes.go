@@ -0,0 +1,41 @@
+package pkg
+
+type handler func(int) error    //@ used(true)
+type unusedHandler func(string) //@ used(false)
+
+type callback = func(int) error //@ used(true)
+type unusedCallback = func()    //@ used(false)
+
+// documentedCallback is only mentioned in the documentation of
+// Register, which doesn't count as a use.
+type documentedCallback = func(string) //@ used(false)
+
+type fieldCallback = func() bool //@ used(true)
+type resultCallback = func(int)  //@ used(true)
+type varCallback = func()        //@ used(true)
+
+type options struct { //@ used(true)
+	onDone fieldCallback //@ used(true)
+}
+
+var global varCallback //@ used(true)
+
+// Register registers fn. fn has to be usable as a documentedCallback.
+func Register(h handler, fn callback) resultCallback { //@ used(true)
+	_ = options{}.onDone
+	_ = global
+	return nil
+}
+
+type mapper[T any] func(T) T     //@ used(true)
+type unusedMapper[T any] func(T) //@ used(false)
+
+type reducer[T, U any] func(U, T) U //@ used(true)
+
+func Apply(m mapper[int], r reducer[int, string]) {} //@ used(true)
+
+type elem struct{} //@ used(true)
+
+type elemCallback = func(elem) //@ used(true)
+
+func Each(fn elemCallback) {} //@ used(true)
@@ -0,0 +1,16 @@
+package pkg
+
+import "net/http"
+
+// Handler is configuration that nothing refers to.
+var Handler = func() {} //@ used(false)
+
+// Now can be replaced by tests, and the package calls it.
+var Now = func() int64 { return 0 } //@ used(true)
+
+var HandlerFunc http.HandlerFunc = func(http.ResponseWriter, *http.Request) {} //@ used(false)
+
+// Exported functions and other variables are still used.
+func Exported() int64 { return Now() } //@ used(true)
+
+var Config = struct{}{} //@ used(true)
@@ -0,0 +1,15 @@
+package wire
+
+type ProviderSet struct{}
+
+func NewSet(...interface{}) ProviderSet { return ProviderSet{} }
+
+func Build(...interface{}) string { return "" }
+
+type Binding struct{}
+
+func Bind(iface, to interface{}) Binding { return Binding{} }
+
+type StructProvider struct{}
+
+func Struct(structType interface{}, fieldNames ...string) StructProvider { return StructProvider{} }
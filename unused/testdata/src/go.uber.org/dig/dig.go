@@ -0,0 +1,11 @@
+package dig
+
+type Container struct{}
+
+func New() *Container { return nil }
+
+func (c *Container) Provide(constructor interface{}) error { return nil }
+
+func (c *Container) Invoke(function interface{}) error { return nil }
+
+type In struct{}
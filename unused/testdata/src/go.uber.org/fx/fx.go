@@ -0,0 +1,17 @@
+package fx
+
+type Option interface{}
+
+func Provide(constructors ...interface{}) Option { return nil }
+
+func Invoke(funcs ...interface{}) Option { return nil }
+
+func Annotate(t interface{}, anns ...interface{}) interface{} { return t }
+
+type In struct{}
+
+type Out struct{}
+
+type App struct{}
+
+func New(opts ...Option) *App { return nil }
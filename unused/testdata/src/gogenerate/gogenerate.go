@@ -0,0 +1,27 @@
+package pkg
+
+//go:generate go run ./cmd/gen -impl fooImpl -type=kind,color
+//go:generate mockgen -destination=mock_store.go . store
+//go:generate stringer -output state_string.go state
+
+type fooImpl struct{} //@ used(false)
+
+func (fooImpl) run() {} //@ used(false)
+
+type kind int //@ used(false)
+
+type color int //@ used(false)
+
+type store interface { //@ used(false)
+	get() //@ quiet()
+}
+
+type state int //@ used(false)
+
+// The flags of the directives don't mention impl, output and
+// destination.
+func impl() {} //@ used(false)
+
+func output() {} //@ used(false)
+
+func destination() {} //@ used(false)
@@ -0,0 +1,17 @@
+package pkg
+
+//lint:ignore U1000 consider yourself used
+type t10 struct{} //@ used(true)
+
+//line ignored5.go:40
+type t11 struct{} //@ used(false)
+
+//lint:ignore U1000 consider yourself used
+type t12 struct{} //@ used(true)
+
+/*line ignored5.go:60:1*/ type t13 struct{} //@ used(false)
+
+//lint:ignore U1000 consider yourself used
+/*line ignored5.go:70:1*/ type t14 struct{} //@ used(true)
+
+func (t14) fn1() {} //@ used(true)
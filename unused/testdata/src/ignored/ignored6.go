@@ -0,0 +1,10 @@
+package pkg
+
+// usedAnyway doesn't need its ignore directive.
+//
+//lint:ignore U1000 used anyway
+func usedAnyway() {} //@ used(true)
+
+func init() { //@ used(true)
+	usedAnyway()
+}
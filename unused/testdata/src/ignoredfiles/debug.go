@@ -0,0 +1,11 @@
+//go:build ignore
+// +build ignore
+
+package pkg
+
+func unused() {}
+
+func main() {
+	helper(config{verbose: true})
+	unused()
+}
@@ -0,0 +1,12 @@
+//go:build ignore
+
+package main
+
+import (
+	pkg "ignoredfiles"
+)
+
+func main() {
+	var t pkg.T
+	println(t.Render())
+}
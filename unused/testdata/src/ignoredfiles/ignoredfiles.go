@@ -0,0 +1,17 @@
+package pkg
+
+type T struct { //@ used(true)
+	Name string //@ used(true)
+}
+
+func (T) Render() string { return "" } //@ used(true)
+
+type config struct { //@ used(false)
+	verbose bool //@ quiet()
+}
+
+func helper(c config) { step() } //@ used(false)
+
+func step() {} //@ used(false)
+
+func unused() {} //@ used(false)
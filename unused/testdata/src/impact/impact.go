@@ -0,0 +1,17 @@
+package pkg
+
+import "impact/lib"
+
+func Caller() { //@ used(true)
+	lib.OldFunc()
+	lib.NewFunc()
+	var t lib.T
+	t.Method()
+	_ = t.Field
+}
+
+func dead() { //@ used(false)
+	_ = lib.OldConst
+}
+
+var _ = lib.OldFunc
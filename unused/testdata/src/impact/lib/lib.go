@@ -0,0 +1,17 @@
+package lib
+
+func OldFunc() {}
+func NewFunc() {}
+
+const OldConst = 1
+
+type T struct {
+	Field int
+}
+
+func (T) Method() {}
+
+func Helper() {
+	// references within the package don't count
+	OldFunc()
+}
@@ -0,0 +1,30 @@
+package pkg
+
+func a() {} //@ used(false)
+func b() {} //@ used(true)
+
+var table = map[string]func(){ //@ used(false)
+	"a": a,
+}
+
+var live = map[string]func(){ //@ used(true)
+	"b": b,
+}
+
+func Fn() { live["b"]() } //@ used(true)
+
+type entry struct{} //@ used(false)
+
+var entries = []interface{}{entry{}} //@ used(false)
+
+func c() {} //@ used(false)
+
+var closure = func() { c() } //@ used(false)
+
+func d() {} //@ used(false)
+
+var ref = d //@ used(false)
+
+func compute() int { return 0 } //@ used(true)
+
+var computed = compute() //@ used(false)
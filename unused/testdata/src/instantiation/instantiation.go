@@ -0,0 +1,48 @@
+//go:build go1.18
+
+package pkg
+
+type t1 struct{} //@ used(true)
+type t2 struct{} //@ used(true)
+type t3 struct{} //@ used(true)
+type t4 struct{} //@ used(true)
+type t5 struct{} //@ used(true)
+type t6 struct{} //@ used(true)
+type t7 struct { //@ used(true)
+	field int //@ used(false)
+}
+type t8 struct{} //@ used(true)
+
+func mapFn[K, V any](k K) (v V) { return v } //@ used(true)
+func id[T any](x T) T           { return x } //@ used(true)
+func inLiteral[T any]()         {}           //@ used(true)
+func inConversion[T any](T)     {}           //@ used(true)
+func inStruct[T any]()          {}           //@ used(true)
+func inMap[T any]()             {}           //@ used(true)
+func withField[T any]()         {}           //@ used(true)
+func unusedGeneric[T any]()     {}           //@ used(false)
+
+type box[T any] struct{} //@ used(true)
+
+func Fn() { //@ used(true)
+	f := mapFn[int, t1]
+	_ = f
+	g := id[t2]
+	_ = g
+	_ = []func(){inLiteral[t3]}
+	_ = (func(t4))(inConversion[t4])
+	_ = struct{ f func() }{inStruct[t5]} //@ used(true)
+	_ = map[string]func(){"": inMap[box[t6]]}
+	_ = withField[box[t7]]
+	_ = id[func(t8)]
+}
+
+func (box[T]) method() {} //@ used(false)
+
+type t9 struct{} //@ used(false)
+
+// Instantiations in unused functions don't use their type arguments.
+func uncalled() { //@ used(false)
+	_ = id[t9]
+	_ = mapFn[t9, int]
+}
@@ -23,8 +23,8 @@ type I2 interface { //@ used(true)
 }
 
 type i3 interface { //@ used(false)
-	foo()
-	bar()
+	foo() //@ quiet()
+	bar() //@ quiet()
 }
 
 type t1 struct{} //@ used(true)
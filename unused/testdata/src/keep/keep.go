@@ -0,0 +1,22 @@
+package pkg
+
+func keptFn() {} //@ used(true)
+func fn()     {} //@ used(false)
+
+var keptVar int //@ used(true)
+
+type t struct{} //@ used(true)
+
+func (t) m1() {} //@ used(false)
+func (t) m2() {} //@ used(true)
+
+var _ = t{}
+
+const (
+	c1 = 1 //@ used(true)
+	c2 = 2 //@ used(false)
+)
+
+func Fn() { //@ used(true)
+	_ = c1
+}
@@ -0,0 +1,52 @@
+package pkg
+
+import "unsafe"
+
+type header struct { //@ used(true)
+	flags uint32  //@ used(true)
+	size  uint32  //@ used(true)
+	data  uintptr //@ used(true)
+}
+
+// The offset of data depends on all fields before it.
+var DataOffset = unsafe.Offsetof(header{}.data) //@ used(true)
+
+type inner struct { //@ used(true)
+	a byte //@ used(true)
+	b byte //@ used(true)
+}
+
+type outer struct { //@ used(true)
+	x     int64 //@ used(true)
+	inner       //@ used(true)
+}
+
+func offsetOfB() uintptr { //@ used(true)
+	var o outer
+	return unsafe.Offsetof(o.b)
+}
+
+type sized struct { //@ used(true)
+	arr [4]elem //@ used(true)
+	ptr *other  //@ used(true)
+}
+
+type elem struct { //@ used(true)
+	v int16 //@ used(true)
+}
+
+// The layout of other doesn't matter to the size of sized.
+type other struct { //@ used(true)
+	unused int //@ used(false)
+}
+
+type plain struct { //@ used(true)
+	used   int //@ used(true)
+	unused int //@ used(false)
+}
+
+func Sizes() uintptr { //@ used(true)
+	_ = offsetOfB()
+	p := plain{}
+	return unsafe.Sizeof(sized{}) + unsafe.Alignof(header{}) + uintptr(p.used)
+}
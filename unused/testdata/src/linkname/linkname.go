@@ -25,3 +25,17 @@ var (
 var ol int //@ used(true)
 
 //go:linkname doesnotexist other5
+
+//go:linkname single
+func single() {} //@ used(true)
+
+//go:linkname pulled linkname.target
+func pulled() //@ used(true)
+
+func target() {} //@ used(true)
+
+//go:linkname remote example.com/other.fn
+func remote() //@ used(true)
+
+//go:linkname remoteMethod example.com/other.(*T).m
+func remoteMethod() //@ used(true)
@@ -0,0 +1,36 @@
+package pkg
+
+type noCompare struct{} //@ used(false)
+
+type noCopy struct{} //@ used(false)
+
+func (*noCopy) Lock()   {} //@ used(false)
+func (*noCopy) Unlock() {} //@ used(false)
+
+// Embedded by a used type, whose sentinel field keeps it alive.
+type usedNoCopy struct{} //@ used(true)
+
+func (*usedNoCopy) Lock()   {} //@ used(true)
+func (*usedNoCopy) Unlock() {} //@ used(true)
+
+// Also referred to by an unused function, so it isn't just a marker.
+type tag struct{} //@ used(false)
+
+type dead struct { //@ used(false)
+	noCompare     //@ quiet()
+	noCopy        //@ quiet()
+	tag           //@ quiet()
+	x         int //@ quiet()
+}
+
+type live struct { //@ used(true)
+	noCompare      //@ used(false)
+	usedNoCopy     //@ used(true)
+	y          int //@ used(true)
+}
+
+func newTag() tag { return tag{} } //@ used(false)
+
+var L live //@ used(true)
+
+func F() int { return L.y } //@ used(true)
@@ -4,7 +4,7 @@ type myNoCopy1 struct{}  //@ used(true)
 type myNoCopy2 struct{}  //@ used(true)
 type locker struct{}     //@ used(false)
 type someStruct struct { //@ used(false)
-	x int
+	x int //@ quiet()
 }
 
 func (myNoCopy1) Lock()      {} //@ used(true)
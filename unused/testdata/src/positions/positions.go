@@ -0,0 +1,9 @@
+package pkg
+
+var limit = 10 //@ used(true)
+
+func helper() int { return limit } //@ used(true)
+
+func Fn() { //@ used(true)
+	helper()
+}
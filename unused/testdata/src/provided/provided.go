@@ -0,0 +1,16 @@
+package pkg
+
+// The test's edge provider uses newStore, and makes newStore use
+// configure and newCache use warm.
+
+type store struct{} //@ used(true)
+
+func newStore() *store { return nil } //@ used(true)
+
+func configure() {} //@ used(true)
+
+type cache struct{} //@ used(false)
+
+func newCache() *cache { return nil } //@ used(false)
+
+func warm() {} //@ used(false)
@@ -1,7 +1,7 @@
 package pkg
 
 type iface interface { //@ used(false)
-	foo()
+	foo() //@ quiet()
 }
 
 type t1 struct{} //@ used(false)
@@ -16,8 +16,8 @@ func init() { //@ used(true)
 }
 
 type t3 struct { //@ used(false)
-	a int
-	b int
+	a int //@ quiet()
+	b int //@ quiet()
 }
 
 type T struct{} //@ used(true)
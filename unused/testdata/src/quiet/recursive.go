@@ -0,0 +1,24 @@
+package pkg
+
+// Recursive types own themselves through their fields and methods.
+// Marking their members quiet mustn't recurse forever.
+
+type list struct { //@ used(false)
+	next *list //@ quiet()
+	val  int   //@ quiet()
+}
+
+type tree struct { //@ used(false)
+	children []tree          //@ quiet()
+	parent   *forest         //@ quiet()
+	index    map[string]tree //@ quiet()
+}
+
+type forest struct { //@ used(false)
+	trees []*tree //@ quiet()
+}
+
+type node interface { //@ used(false)
+	next() node      //@ quiet()
+	walk(func(node)) //@ quiet()
+}
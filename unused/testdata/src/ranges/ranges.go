@@ -0,0 +1,75 @@
+package pkg
+
+// Ranging over a value uses its type, and with it the types of its
+// keys and elements, even if the key and the value are blank.
+
+type mapKey struct{}            //@ used(true)
+type mapValue struct{}          //@ used(true)
+type sliceElem struct{}         //@ used(true)
+type arrayElem struct{}         //@ used(true)
+type ptrElem struct{}           //@ used(true)
+type chanElem struct{}          //@ used(true)
+type str string                 //@ used(true)
+type deadElem struct{}          //@ used(false)
+type localElem struct{}         //@ used(true)
+type localMap map[int]localElem //@ used(true)
+
+func Maps(m map[mapKey]mapValue) { //@ used(true)
+	for range m {
+	}
+	for _, _ = range m {
+	}
+}
+
+func Slices(s []sliceElem, a [2]arrayElem, p *[2]ptrElem) { //@ used(true)
+	for range s {
+	}
+	for _, _ = range a {
+	}
+	for i := range p {
+		_ = i
+	}
+}
+
+func Chans(ch chan chanElem) { //@ used(true)
+	for range ch {
+	}
+}
+
+func Strings(s str) { //@ used(true)
+	for range s {
+	}
+}
+
+func Locals() { //@ used(true)
+	var m localMap
+	for _, _ = range m {
+	}
+}
+
+func dead(s []deadElem) { //@ used(false)
+	for range s {
+	}
+}
+
+// Ranging over maps and strings makes the IR builder use iterator
+// types, which mustn't trip up checking which types implement the
+// package's interfaces.
+
+type sizer interface { //@ used(true)
+	size() int //@ used(true)
+}
+
+type sized struct{} //@ used(true)
+
+func (sized) size() int { return 0 } //@ used(true)
+func (sized) unused()   {}           //@ used(false)
+
+func Sizes(m map[string]sized, s str) sizer { //@ used(true)
+	for range s {
+	}
+	for _, v := range m {
+		return v
+	}
+	return nil
+}
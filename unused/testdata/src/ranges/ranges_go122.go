@@ -0,0 +1,15 @@
+//go:build go1.22
+
+package pkg
+
+type count int //@ used(true)
+
+func Ints(n count) int { //@ used(true)
+	sum := 0
+	for i := range n {
+		sum += int(i)
+	}
+	for range 3 {
+	}
+	return sum
+}
@@ -0,0 +1,51 @@
+//go:build go1.23
+
+package pkg
+
+// Iterator functions are used by the loops that range over them.
+
+type item struct{}       //@ used(true)
+type key struct{}        //@ used(true)
+type value struct{}      //@ used(true)
+type unusedItem struct{} //@ used(false)
+
+func items(yield func(item) bool)             {} //@ used(true)
+func pairs(yield func(key, value) bool)       {} //@ used(true)
+func unusedItems(yield func(unusedItem) bool) {} //@ used(false)
+
+type list struct{} //@ used(true)
+
+func (list) all(yield func(int) bool)       {} //@ used(true)
+func (list) unusedAll(yield func(int) bool) {} //@ used(false)
+
+func Funcs() int { //@ used(true)
+	for range items {
+	}
+	for _, _ = range pairs {
+	}
+	var l list
+	for x := range l.all {
+		if x > 0 {
+			return x
+		}
+	}
+	return 0
+}
+
+// Ranging over functions makes up iterator types, which have to get
+// along with the interfaces of the package.
+
+type stringer interface { //@ used(true)
+	String() string //@ used(true)
+}
+
+type name struct{} //@ used(true)
+
+func (name) String() string { return "" } //@ used(true)
+func (name) unusedMethod()  {}            //@ used(false)
+
+func Names() stringer { //@ used(true)
+	for range items {
+	}
+	return name{}
+}
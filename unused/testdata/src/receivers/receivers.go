@@ -0,0 +1,17 @@
+package pkg
+
+type T struct{} //@ used(true)
+
+func (t T) Fn1() {} //@ used(true) // want `receiver t is unused`
+
+func (t *T) Fn2() {} //@ used(true) // want `receiver t is unused`
+
+func (t T) Fn3() T { return t } //@ used(true)
+
+func (_ T) Fn4() {} //@ used(true)
+
+func (T) Fn5() {} //@ used(true)
+
+func (t T) Fn6() { //@ used(true)
+	_ = func() T { return t }
+}
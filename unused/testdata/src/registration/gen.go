@@ -0,0 +1,15 @@
+// Code generated by regen. DO NOT EDIT.
+
+package pkg
+
+func init() { //@ used(true)
+	Register("foo", newFoo)
+	Register("baz", newBaz)
+}
+
+var _ = register("bar", newBar)
+
+func register(name string, fn func() handler) bool { //@ used(true)
+	Register(name, fn)
+	return true
+}
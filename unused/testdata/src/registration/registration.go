@@ -0,0 +1,35 @@
+package pkg
+
+type handler interface { //@ used(true)
+	serve() //@ used(true)
+}
+
+var handlers = map[string]func() handler{} //@ used(true)
+
+func Register(name string, fn func() handler) { //@ used(true)
+	handlers[name] = fn
+}
+
+type foo struct{} //@ used(false)
+
+func newFoo() handler { return foo{} } //@ used(false)
+
+func (foo) serve() {} //@ used(false)
+
+type bar struct{} //@ used(false)
+
+func newBar() handler { return bar{} } //@ used(false)
+
+func (bar) serve() {} //@ used(false)
+
+type baz struct{} //@ used(true)
+
+func newBaz() handler { return baz{} } //@ used(true)
+
+func (baz) serve() {} //@ used(true)
+
+func Default() handler { //@ used(true)
+	return newBaz()
+}
+
+func unused() {} //@ used(false)
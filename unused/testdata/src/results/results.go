@@ -0,0 +1,39 @@
+package pkg
+
+func fn1() (x int) { return 1 } //@ used(true) // want `named result x is unused`
+
+func fn2() (x, y int, err error) { return 1, 2, nil } //@ used(true) // want `named result x is unused` `named result y is unused` `named result err is unused`
+
+func fn3() (x int, err error) { //@ used(true) // want `named result x is unused`
+	err = nil
+	return 1, err
+}
+
+func fn4() (x int) { return } //@ used(true) // want `named result x is unused`
+
+func fn5() (err error) { //@ used(true)
+	defer func() {
+		if r := recover(); r != nil {
+			err = nil
+		}
+	}()
+	return nil
+}
+
+func fn6() (_ int) { return 1 } //@ used(true)
+
+func fn7() (x int) { //@ used(true) // want `named result x is unused`
+	f := func() (y int) { return 2 } // want `named result y is unused`
+	_ = f
+	return 1
+}
+
+func init() { //@ used(true)
+	fn1()
+	fn2()
+	fn3()
+	fn4()
+	fn5()
+	fn6()
+	fn7()
+}
@@ -0,0 +1,30 @@
+package pkg
+
+import "net/http"
+
+type server struct{} //@ used(true), used_test(true)
+
+func (*server) handleUsers(w http.ResponseWriter, r *http.Request) {} //@ used(true), used_test(true)
+
+func (*server) handleLegacy(w http.ResponseWriter, r *http.Request) {} //@ used(true), used_test(true)
+
+func handleMetrics(w http.ResponseWriter, r *http.Request) {} //@ used(true), used_test(true)
+
+func handleHealth(w http.ResponseWriter, r *http.Request) {} //@ used(true), used_test(true)
+
+func handleIndex(w http.ResponseWriter, r *http.Request) {} //@ used(true), used_test(true)
+
+func Routes(mux *http.ServeMux) { //@ used(true), used_test(true)
+	s := &server{}
+	mux.HandleFunc("/users/{id}", s.handleUsers)
+	mux.Handle("GET /legacy", http.HandlerFunc(s.handleLegacy))
+	http.HandleFunc("/metrics", handleMetrics)
+	http.HandleFunc("/healthz", handleHealth)
+	http.HandleFunc("/", handleIndex)
+	http.HandleFunc("/closure", func(w http.ResponseWriter, r *http.Request) {})
+}
+
+// The health check gets called directly, too.
+func Check() { //@ used(true), used_test(true)
+	handleHealth(nil, nil)
+}
@@ -0,0 +1,10 @@
+package pkg
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUsers(t *testing.T) { //@ used_test(true)
+	httptest.NewRequest("GET", "/users/42", nil)
+}
@@ -0,0 +1,10 @@
+package pkg_test
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLegacy(t *testing.T) { //@ used_test(true)
+	httptest.NewRequest("GET", "/legacy", nil)
+}
@@ -0,0 +1,15 @@
+package pkg
+
+import "strings"
+
+func mustLoad() int { return 0 } //@ used(true)
+func compute() int  { return 0 } //@ used(true)
+
+var v1 = mustLoad()                  //@ used(true)
+var v2 = compute()                   //@ used(false)
+var v3 = 1                           //@ used(false)
+var v4 = func() int { return 0 }     //@ used(false)
+var v5 = int64(1)                    //@ used(false)
+var v6 = strings.Repeat("x", 2)      //@ used(false)
+var v7 = len(strings.Repeat("x", 2)) //@ used(false)
+var v8 = []int{compute()}            //@ used(false)
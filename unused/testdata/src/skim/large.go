@@ -0,0 +1,27 @@
+// Code generated by hand. DO NOT EDIT.
+
+package pkg
+
+// The functions in this file are skimmed, and all of its objects are
+// used.
+
+func largeFn() { //@ used(true)
+	usedBySkimmed()
+	_ = T{1, 2}
+	_ = usedBySkimmedVar + usedBySkimmedConst
+	f := func() {
+		var _ *onlyGenerated
+	}
+	f()
+	largeHelper()
+}
+
+func largeHelper() {} //@ used(true)
+
+type largeType struct { //@ used(true)
+	field int //@ used(false)
+}
+
+func (largeType) largeMethod() {} //@ used(true)
+
+// padding to reach the threshold
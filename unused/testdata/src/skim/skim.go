@@ -0,0 +1,24 @@
+package pkg
+
+type T struct { //@ used(true)
+	a int //@ used(true)
+	b int //@ used(true)
+}
+
+func (T) m() {} //@ used(true)
+
+type onlyGenerated struct{} //@ used(true)
+
+func usedBySkimmed() {} //@ used(true)
+
+func usedBySmall() {} //@ used(false)
+
+var usedBySkimmedVar int //@ used(true)
+
+const usedBySkimmedConst = 0 //@ used(true)
+
+type unrelated struct { //@ used(false)
+	x int //@ quiet()
+}
+
+func unused() {} //@ used(false)
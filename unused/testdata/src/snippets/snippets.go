@@ -0,0 +1,27 @@
+// Package pkg is a collection of snippets.
+//
+//lint:package-mode snippets
+package pkg
+
+func snippet1() { //@ used(true)
+	helper()
+}
+
+func helper() {} //@ used(true)
+
+func snippet2() {} //@ used(true)
+
+type t1 struct { //@ used(true)
+	used   int //@ used(true)
+	unused int //@ used(false)
+}
+
+func (t t1) method() int { return t.used } //@ used(true)
+
+var v1 int //@ used(true)
+
+const c1 = 1 //@ used(true)
+
+func snippet3() { //@ used(true)
+	type local struct{} //@ used(false)
+}
@@ -0,0 +1,16 @@
+package pkg
+
+import "strings"
+
+// parse is only used by its own tests.
+func parse(s string) string { //@ used(false), used_test(false)
+	return strings.TrimSpace(s)
+}
+
+func helper() {} //@ used(false), used_test(true)
+
+func format() {} //@ used(false), used_test(true)
+
+func Used() { shared() } //@ used(true), used_test(true)
+
+func shared() {} //@ used(true), used_test(true)
@@ -0,0 +1,20 @@
+package pkg
+
+import "testing"
+
+func TestParse(t *testing.T) { //@ used_test(true)
+	helper()
+	_ = parse(" x ")
+}
+
+func BenchmarkParse(b *testing.B) { //@ used_test(true)
+	_ = parse("x")
+}
+
+func TestOther(t *testing.T) { //@ used_test(true)
+	format()
+}
+
+func TestShared(t *testing.T) { //@ used_test(true)
+	shared()
+}
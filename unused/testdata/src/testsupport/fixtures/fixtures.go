@@ -0,0 +1,6 @@
+// Package fixtures matches the configured test support packages.
+package fixtures
+
+func Load(name string) string { //@ used(true)
+	return name
+}
@@ -0,0 +1,6 @@
+// Package other is neither configured nor detected as a test support
+// package, so its exported objects have to be used by analyzed
+// packages.
+package other
+
+func Exported() {} //@ used(false)
@@ -0,0 +1,14 @@
+// Package pkg is only imported by the tests of other packages, which
+// its import of the testing package gives away.
+package pkg
+
+import "testing"
+
+func Helper(t testing.TB) { //@ used(true)
+	t.Helper()
+	setup()
+}
+
+func setup() {} //@ used(true)
+
+func unused() {} //@ used(false)
@@ -0,0 +1,86 @@
+//go:build go1.18
+
+package pkg
+
+// Methods called via the method sets of type parameters are used by
+// the types that instantiate them.
+
+type stringer interface { //@ used(true)
+	str() string //@ used(true)
+}
+
+type cm1 struct{} //@ used(true)
+
+func (cm1) str() string { return "" } //@ used(true)
+func (cm1) other()      {}            //@ used(false)
+
+func callStr[T stringer](x T) string { return x.str() } //@ used(true)
+
+// inline constraint with a type term
+type cm2 struct{} //@ used(true)
+
+func (cm2) m()     {} //@ used(true)
+func (*cm2) ptrM() {} //@ used(false)
+
+func callM[T interface { //@ used(true)
+	cm2
+	m() //@ used(true)
+}](x T) {
+	x.m()
+}
+
+// pointer receivers via a pointer-typed constraint
+type cm3 struct{} //@ used(true)
+
+func (*cm3) set() {} //@ used(true)
+
+func callSet[T any, PT interface { //@ used(true)
+	*T
+	set() //@ used(true)
+}]() {
+	var x T
+	PT(&x).set()
+}
+
+// generic types calling methods of their type arguments
+type cm4 struct{} //@ used(true)
+
+func (cm4) close() {} //@ used(true)
+
+type closer interface { //@ used(true)
+	close() //@ used(true)
+}
+
+type holder[T closer] struct { //@ used(true)
+	v T //@ used(true)
+}
+
+func (h holder[T]) shutdown() { h.v.close() } //@ used(true)
+
+// instantiated generic constraints
+type getter[T any] interface { //@ used(true)
+	get() T //@ used(true)
+}
+
+type cm5 struct{} //@ used(true)
+
+func (cm5) get() int { return 0 } //@ used(true)
+
+func callGet[G getter[int]](g G) int { return g.get() } //@ used(true)
+
+// fields of instantiated types
+type box[T any] struct { //@ used(true)
+	val  T //@ used(true)
+	rest T //@ used(false)
+}
+
+func Fn13() { //@ used(true)
+	var b box[int]
+	_ = b.val
+
+	_ = callStr(cm1{})
+	callM(cm2{})
+	callSet[cm3]()
+	holder[cm4]{}.shutdown()
+	_ = callGet(cm5{})
+}
@@ -0,0 +1,57 @@
+package pkg
+
+type shape interface { //@ used(true)
+	isShape() //@ used(true)
+}
+
+type circle struct { //@ used(true)
+	r float64 //@ used(true)
+}
+
+type square struct { //@ used(true)
+	side float64 //@ used(true)
+}
+
+// triangle is never constructed, but type switches still check for
+// it.
+type triangle struct { //@ used(true)
+	base   float64 //@ used(true)
+	height float64 //@ used(false)
+}
+
+type hexagon struct{} //@ used(false)
+
+func (circle) isShape()   {} //@ used(true)
+func (*square) isShape()  {} //@ used(true)
+func (triangle) isShape() {} //@ used(true)
+func (hexagon) isShape()  {} //@ used(false)
+
+func Area(s shape) float64 { //@ used(true)
+	switch s := s.(type) {
+	case circle:
+		return 3 * s.r * s.r
+	case *square:
+		return s.side * s.side
+	case triangle:
+		return s.base
+	case nil:
+		return 0
+	default:
+		_ = s
+		return -1
+	}
+}
+
+type kind int //@ used(true)
+
+func Kind(v interface{}) int { //@ used(true)
+	switch v.(type) {
+	case kind, *triangle:
+		return 1
+	}
+	return 0
+}
+
+func Shapes() []shape { //@ used(true)
+	return []shape{circle{}, &square{}}
+}
@@ -0,0 +1,37 @@
+package pkg
+
+import "errors"
+
+// HTTPClient is a client that nothing uses.
+type HTTPClient struct{} //@ used(false)
+
+// Do sends a request.
+func (c *HTTPClient) Do() error { return nil } //@ used(false)
+
+// NewHTTPClient returns a new HTTPClient.
+func NewHTTPClient() *HTTPClient { return &HTTPClient{} } //@ used(false)
+
+// ID identifies nothing.
+const ID = 1 //@ used(false)
+
+// Errors would clash with the import of errors.
+var Errors = errors.New("errors") //@ used(false)
+
+// Type would become a keyword.
+func Type() {} //@ used(false)
+
+// Shadowed would be shadowed where it's called.
+func Shadowed() {} //@ used(false)
+
+func caller() { //@ used(false)
+	shadowed := 1
+	_ = shadowed
+	Shadowed()
+}
+
+// Helper is used, and stays exported.
+func Helper() {} //@ used(true)
+
+func init() { //@ used(true)
+	Helper()
+}
@@ -0,0 +1,74 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Values of state are only ever compared, never formatted.
+type state int //@ used(true)
+
+const (
+	idle    state = iota //@ used(true)
+	running              //@ used(true)
+)
+
+func (s state) String() string { //@ used(false)
+	if s == idle {
+		return "idle"
+	}
+	return "running"
+}
+
+type color int //@ used(true)
+
+func (c color) String() string { return "red" } //@ used(true)
+
+type parseError struct { //@ used(true)
+	line int //@ used(true)
+}
+
+func (e *parseError) Error() string { return fmt.Sprint("line ", e.line) } //@ used(true)
+
+type lexError struct{} //@ used(true)
+
+// Error is only ever called directly.
+func (e lexError) Error() string { return "lex error" } //@ used(true)
+
+type node struct { //@ used(true)
+	kind string //@ used(true)
+}
+
+func (n node) String() string { return n.kind } //@ used(false)
+
+// Not a formatting method, because of its signature.
+func (n node) Error(line int) string { return n.kind } //@ used(true)
+
+type wrapper struct { //@ used(true)
+	label //@ used(true)
+}
+
+type label string //@ used(true)
+
+func (l label) String() string { return string(l) } //@ used(true)
+
+type Exported int //@ used(true)
+
+func (Exported) String() string { return "exported" } //@ used(true)
+
+func Run() error { //@ used(true)
+	s := running
+	if s == idle {
+		return nil
+	}
+	fmt.Println(color(1))
+	var e lexError
+	_ = e.Error()
+	n := node{kind: "leaf"}
+	_ = n.Error(1)
+	fmt.Println(wrapper{"x"})
+	if s != running {
+		return errors.New(e.Error())
+	}
+	return &parseError{line: 1}
+}
@@ -0,0 +1,9 @@
+package pkg
+
+import "fmt"
+
+func a() { b() } //@ used(false)
+
+func b() {} //@ used(false)
+
+func c() { fmt.Println() } //@ used(false)
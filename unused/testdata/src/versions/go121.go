@@ -0,0 +1,11 @@
+//go:build go1.21
+
+package pkg
+
+type number int       //@ used(true)
+type unusedNumber int //@ used(false)
+
+func Builtins(m map[string]int) int { //@ used(true)
+	clear(m)
+	return int(min(number(1), max(2, 3)))
+}
@@ -0,0 +1,11 @@
+//go:build go1.22
+
+package pkg
+
+func RangeInt() { //@ used(true)
+	for i := range 10 {
+		rangeHelper(i)
+	}
+}
+
+func rangeHelper(int) {} //@ used(true)
@@ -0,0 +1,19 @@
+//go:build go1.23
+
+package pkg
+
+import "iter"
+
+func seq() iter.Seq[int] { //@ used(true)
+	return func(yield func(int) bool) {
+		yield(1)
+	}
+}
+
+func unusedSeq() iter.Seq[int] { return nil } //@ used(false)
+
+func Iterate() { //@ used(true)
+	for v := range seq() {
+		_ = v
+	}
+}
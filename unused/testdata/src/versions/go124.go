@@ -0,0 +1,13 @@
+//go:build go1.24
+
+package pkg
+
+type vec[T any] = []T //@ used(true)
+
+func Sum(v vec[int]) int { //@ used(true)
+	var n int
+	for _, x := range v {
+		n += x
+	}
+	return n
+}
@@ -0,0 +1,13 @@
+// Package pkg is tested with all supported versions of Go. Files
+// using newer language features are guarded by build constraints.
+package pkg
+
+type set[T comparable] map[T]struct{} //@ used(true)
+
+func (s set[T]) add(v T)    { s[v] = struct{}{} } //@ used(true)
+func (s set[T]) remove(v T) { delete(s, v) }      //@ used(false)
+
+func Generic() { //@ used(true)
+	s := set[int]{}
+	s.add(1)
+}
@@ -0,0 +1,11 @@
+package mock
+
+import "wholeprogram"
+
+var _ pkg.Iface = (*MockIface)(nil)
+
+type MockIface struct{} //@ used(true)
+
+func (*MockIface) Method() {} //@ used(true)
+
+func (*MockIface) unused() {} //@ used(false)
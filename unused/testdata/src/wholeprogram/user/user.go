@@ -0,0 +1,17 @@
+package main
+
+import "wholeprogram"
+
+type sayer interface { //@ used(true)
+	Say() //@ used(true)
+}
+
+func main() { //@ used(true)
+	pkg.Helper()
+	var s sayer = pkg.Speaker{}
+	s.Say()
+}
+
+func Unused() { //@ used(false)
+	pkg.Indirect()
+}
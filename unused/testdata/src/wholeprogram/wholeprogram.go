@@ -0,0 +1,33 @@
+package pkg
+
+type Iface interface { //@ used(false), used_test(false)
+	Method() //@ quiet()
+}
+
+type T struct{} //@ used(true), used_test(true)
+
+func (T) Exported()   {} //@ used(true), used_test(true)
+func (T) unexported() {} //@ used(false), used_test(false)
+
+func Fn() {} //@ used(false), used_test(true)
+
+func Helper() T { return T{} } //@ used(true), used_test(true)
+
+func Unused() {} //@ used(false), used_test(false)
+
+// Indirect is only used by an unused function of another package.
+func Indirect() {} //@ used(false), used_test(false)
+
+var Var int //@ used(false), used_test(false)
+
+const Const = 0 //@ used(false), used_test(false)
+
+func init() { //@ used(true), used_test(true)
+	Helper()
+}
+
+// Speaker is only used by another package, which converts it to an
+// interface declared there. Say is kept by way of Speaker.
+type Speaker struct{} //@ used(false), used_test(false)
+
+func (Speaker) Say() {} //@ used(false), used_test(false)
@@ -0,0 +1,7 @@
+package pkg
+
+import "testing"
+
+func TestFn(t *testing.T) { //@ used_test(true)
+	Fn()
+}
@@ -0,0 +1,116 @@
+package unused
+
+import (
+	"go/types"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"honnef.co/go/tools/unused/refgraph"
+)
+
+var testPrefixes = []string{"Test", "Benchmark", "Fuzz", "Example"}
+
+// testSubject returns the name of the function that the test,
+// benchmark, fuzz test or example with the given name is named after,
+// such as Foo for TestFoo, TestFoo_bar and Test_Foo.
+func testSubject(name string) (string, bool) {
+	for _, prefix := range testPrefixes {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		rest := name[len(prefix):]
+		if strings.HasPrefix(rest, "_") {
+			rest = rest[1:]
+		} else if r, _ := utf8.DecodeRuneInString(rest); unicode.IsLower(r) {
+			// Testfoo isn't a test
+			return "", false
+		}
+		if idx := strings.Index(rest, "_"); idx > -1 {
+			rest = rest[:idx]
+		}
+		return rest, rest != ""
+	}
+	return "", false
+}
+
+// lowerFirst returns s with its first letter lowercased.
+func lowerFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[size:]
+}
+
+// isTestFile reports whether obj is declared in a _test.go file.
+func (g *graph) isTestFile(obj types.Object) bool {
+	return strings.HasSuffix(g.pkg.Fset.PositionFor(obj.Pos(), false).Filename, "_test.go")
+}
+
+// testedOnly finds functions that are only used by their own tests,
+// benchmarks, fuzz tests and examples, which are named after them,
+// and returns these tests for each such function. It colors the graph
+// accordingly, leaving the functions unseen and the tests seen, and
+// has to run before results.
+func (g *graph) testedOnly() map[types.Object][]types.Object {
+	scope := g.pkg.Pkg.Scope()
+	ownTests := map[*refgraph.Node]*refgraph.Node{}
+	for _, e := range g.Root.Uses {
+		testNode := e.Node
+		test, ok := testNode.Obj.(*types.Func)
+		if !ok || test.Parent() != scope || !g.isTestFile(test) {
+			continue
+		}
+		name, ok := testSubject(test.Name())
+		if !ok {
+			continue
+		}
+		for _, name := range []string{name, lowerFirst(name)} {
+			fn, ok := scope.Lookup(name).(*types.Func)
+			if !ok || g.isTestFile(fn) {
+				continue
+			}
+			n, ok := g.Lookup(fn)
+			if !ok {
+				continue
+			}
+			for _, e := range testNode.Uses {
+				if e.Node == n {
+					ownTests[testNode] = n
+				}
+			}
+			break
+		}
+	}
+	if len(ownTests) == 0 {
+		return nil
+	}
+
+	// Color the graph without the tests, then color what the tests
+	// use other than the functions they are named after. Functions
+	// that are still unseen aren't used by anything else.
+	for test := range ownTests {
+		test.Seen = true
+	}
+	g.Color(g.Root)
+	for test, fn := range ownTests {
+		for _, e := range test.Uses {
+			if e.Node != fn {
+				g.Color(e.Node)
+			}
+		}
+	}
+
+	out := map[types.Object][]types.Object{}
+	for test, fn := range ownTests {
+		if !fn.Seen {
+			obj := fn.Obj.(types.Object)
+			out[obj] = append(out[obj], test.Obj.(types.Object))
+		}
+	}
+	for _, tests := range out {
+		sort.Slice(tests, func(i, j int) bool {
+			return tests[i].Pos() < tests[j].Pos()
+		})
+	}
+	return out
+}
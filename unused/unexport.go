@@ -0,0 +1,150 @@
+package unused
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"honnef.co/go/tools/analysis/edit"
+	"honnef.co/go/tools/analysis/facts/ownership"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// unexportedName returns the unexported form of an exported name,
+// lowering its leading initialism as a whole, such as HTTPClient
+// becoming httpClient and ID becoming id.
+func unexportedName(name string) string {
+	runes := []rune(name)
+	n := 0
+	for n < len(runes) && unicode.IsUpper(runes[n]) {
+		n++
+	}
+	plural := n < len(runes) && runes[n] == 's' && (n+1 == len(runes) || unicode.IsUpper(runes[n+1]))
+	if n > 1 && n < len(runes) && unicode.IsLower(runes[n]) && !plural {
+		// The last upper case letter starts the next word, unless
+		// the initialism is plural, as in URLs.
+		n--
+	}
+	for i := 0; i < n; i++ {
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
+// unexports computes suggested fixes that rename unused exported
+// package-level objects to unexported names, as an alternative to
+// deleting them, for shrinking APIs step by step. The fixes update
+// the objects' references in the package and the doc comments that
+// start with their names. Objects whose new names would clash with
+// other names are skipped.
+func (g *graph) unexports(unused []types.Object) map[types.Object]analysis.SuggestedFix {
+	var out map[types.Object]analysis.SuggestedFix
+	for _, obj := range unused {
+		if !obj.Exported() || obj.Parent() != g.pkg.Pkg.Scope() {
+			continue
+		}
+		if fix, ok := g.unexport(obj); ok {
+			if out == nil {
+				out = map[types.Object]analysis.SuggestedFix{}
+			}
+			out[obj] = fix
+		}
+	}
+	return out
+}
+
+func (g *graph) unexport(obj types.Object) (analysis.SuggestedFix, bool) {
+	name := unexportedName(obj.Name())
+	if token.IsKeyword(name) || types.Universe.Lookup(name) != nil || g.pkg.Pkg.Scope().Lookup(name) != nil {
+		return analysis.SuggestedFix{}, false
+	}
+	d, ok := g.pkg.Ownership.Decl(obj)
+	if !ok || !declares(d, obj) || g.inGeneratedFile(d.File) {
+		return analysis.SuggestedFix{}, false
+	}
+
+	var ids []*ast.Ident
+	for _, f := range g.pkg.Files {
+		if g.pkg.TypesInfo.Scopes[f].Lookup(name) != nil {
+			// A package-level object can't have the name of an
+			// import.
+			return analysis.SuggestedFix{}, false
+		}
+		var refs []*ast.Ident
+		ast.Inspect(f, func(node ast.Node) bool {
+			id, ok := node.(*ast.Ident)
+			if ok && g.pkg.TypesInfo.ObjectOf(id) == obj {
+				refs = append(refs, id)
+			}
+			return true
+		})
+		if len(refs) == 0 {
+			continue
+		}
+		if g.inGeneratedFile(f) {
+			return analysis.SuggestedFix{}, false
+		}
+		for _, id := range refs {
+			scope := g.pkg.Pkg.Scope().Innermost(id.Pos())
+			if scope == nil {
+				continue
+			}
+			if _, other := scope.LookupParent(name, id.Pos()); other != nil {
+				// The new name is shadowed here.
+				return analysis.SuggestedFix{}, false
+			}
+		}
+		ids = append(ids, refs...)
+	}
+
+	var edits []analysis.TextEdit
+	for _, id := range ids {
+		edits = append(edits, edit.ReplaceWithString(id, name))
+	}
+	if doc := declDoc(d); doc != nil && strings.HasPrefix(doc.List[0].Text, "//") {
+		// Doc comments conventionally start with the name of what
+		// they document.
+		first := doc.List[0]
+		text := strings.TrimLeft(first.Text[2:], " \t")
+		if rest := strings.TrimPrefix(text, obj.Name()); rest != text {
+			if r, _ := utf8.DecodeRuneInString(rest); rest == "" || !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+				pos := first.Pos() + token.Pos(len(first.Text)-len(text))
+				edits = append(edits, edit.ReplaceWithString(edit.Range{pos, pos + token.Pos(len(obj.Name()))}, name))
+			}
+		}
+	}
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].Pos < edits[j].Pos
+	})
+	return edit.Fix(fmt.Sprintf("Unexport %s %s as %s", typString(obj), obj.Name(), name), edits...), true
+}
+
+// declDoc returns the doc comment of a declaration of a single
+// object.
+func declDoc(d ownership.Decl) *ast.CommentGroup {
+	switch spec := d.Spec.(type) {
+	case nil:
+		return d.Decl.(*ast.FuncDecl).Doc
+	case *ast.TypeSpec:
+		if spec.Doc != nil {
+			return spec.Doc
+		}
+	case *ast.ValueSpec:
+		if len(spec.Names) != 1 {
+			return nil
+		}
+		if spec.Doc != nil {
+			return spec.Doc
+		}
+	}
+	if gen := d.Decl.(*ast.GenDecl); len(gen.Specs) == 1 {
+		return gen.Doc
+	}
+	return nil
+}
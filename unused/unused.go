@@ -4,30 +4,53 @@ package unused
 // TODO(dh): don't add instantiated types/methods to the graph. add the origin types/methods.
 
 import (
+	"context"
 	"fmt"
 	"go/ast"
 	"go/token"
 	"go/types"
 	"io"
+	"path"
 	"reflect"
+	"sort"
 	"strings"
 
 	"honnef.co/go/tools/analysis/code"
+	"honnef.co/go/tools/analysis/facts/cancellation"
 	"honnef.co/go/tools/analysis/facts/directives"
 	"honnef.co/go/tools/analysis/facts/generated"
+	"honnef.co/go/tools/analysis/facts/ownership"
 	"honnef.co/go/tools/analysis/lint"
 	"honnef.co/go/tools/analysis/report"
+	"honnef.co/go/tools/config"
 	"honnef.co/go/tools/go/ast/astutil"
 	"honnef.co/go/tools/go/ir"
+	"honnef.co/go/tools/go/ir/irutil"
 	"honnef.co/go/tools/go/types/typeutil"
 	"honnef.co/go/tools/internal/passes/buildir"
+	"honnef.co/go/tools/unused/refgraph"
 
-	"golang.org/x/exp/typeparams"
 	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/objectpath"
 )
 
 var Debug io.Writer
 
+// QuickScan enables skipping the bodies of functions that are
+// unreachable. Instead of walking all functions up front, bodies are
+// walked once their functions become reachable from the roots. This
+// saves time on packages with a lot of dead code. It doesn't change
+// which objects get reported, but quiet objects nested in dead code
+// aren't tracked.
+var QuickScan bool
+
+// EdgePositions enables recording the source positions of the
+// references that the edges of the graph stand for, such as the calls
+// of functions, for pointing at the lines that keep objects alive. It
+// costs memory, and positions are only known for references in
+// function bodies and some package-level ones.
+var EdgePositions bool
+
 // The graph we construct omits nodes along a path that do not
 // contribute any new information to the solution. For example, the
 // full graph for a function with a receiver would be Func ->
@@ -47,20 +70,78 @@ var Debug io.Writer
 
 - packages use:
   - (1.1) exported named types
-  - (1.2) exported functions
-  - (1.3) exported variables
+  - (1.2) exported functions. If so configured, the tests,
+    benchmarks, fuzz tests and examples in them don't use the
+    functions they are named after, such as Foo for TestFoo;
+    functions that nothing else uses are reported as only being
+    exercised by these tests.
+  - (1.3) exported variables. If so configured, exported variables of
+    function types, such as var Handler = func() {}, aren't used
+    merely by being exported.
   - (1.4) exported constants
-  - (1.5) init functions
+  - (1.5) init functions. If the generated policy is strict, the
+    init functions and package-level variables of generated files
+    use what they refer to via the package's generated registrations
+    instead, and objects that only these use are reported as only
+    used by generated code.
   - (1.6) functions exported to cgo
   - (1.7) the main function iff in the main package
-  - (1.8) symbols linked via go:linkname
+  - (1.8) symbols linked via go:linkname, in both its one and two
+    argument forms. Symbols of other packages that we link to are
+    reported in Result.Linknames, for the driver to mark them used.
+  - (1.9) variables whose initializers call functions matching the
+    configured side effect patterns. Other variables whose
+    initializers call functions are reported with low confidence.
+  - (1.10) package-level objects and methods matching the configured
+    keep patterns
+  - (1.11) package-level objects and methods declared in generated
+    files, if so configured, and in skimmed generated files. The
+    bodies of functions declared in skimmed files aren't walked.
+    Instead, the functions use all objects their bodies refer to, as
+    well as all fields and methods of the package's named types
+    whose values the bodies handle.
+  - (1.12) all package-level objects and methods, if the package is
+    in snippets mode (//lint:package-mode snippets). Only objects
+    that are unreachable from any declaration, such as unused fields,
+    get reported.
+  - (1.13) objects that package comments link to, such as [Name],
+    if so configured. Doc links in the comments of other
+    declarations are uses by the declared objects instead.
+  - (1.14) objects whose declarations have //lint:export-to
+    directives, which name the external consumers of the objects,
+    such as services calling them via reflection. Unlike ignore
+    directives, they are recorded in Result.Exports.
+  - (1.15) interface assertions, such as var _ io.Reader = (*T)(nil),
+    which use the interface and the asserted type. If so configured,
+    objects that only interface assertions use are reported as such,
+    and removed together with the assertions.
+  - (1.16) objects that registered edge providers (see
+    RegisterEdgeProvider) use, either by themselves or by other
+    objects.
+  - (1.17) objects that the command lines of //go:generate directives
+    mention by name, such as Foo in -type=Foo, if so configured.
+    Generators may look these objects up by reflection. Alternatively,
+    unused objects that they mention are reported as such, without
+    fixes.
+
+  In whole-program mode, (1.1) to (1.4) only apply to objects declared
+  in tests, and to the objects of packages that the
+  unused.external_api option marks as used by unanalyzed code, such
+  as other repositories. All other exported objects have to be used
+  by one of the analyzed packages. Uses by mock packages don't count.
 
 - named types use:
-  - (2.1) exported methods
+  - (2.1) exported methods. If so configured, unexported types only
+    use them if values of the types escape the package, via its API,
+    conversions to interfaces or type arguments.
   - (2.2) the type they're based on
   - (2.3) all their aliases. we can't easily track uses of aliases
     because go/types turns them into uses of the aliased types. assume
     that if a type is used, so are all of its aliases.
+    Aliases of function types that don't refer to any named types,
+    such as callback types, aren't aliases of named types. They are
+    used by the functions that refer to them by name, or by the
+    package if referred to outside of functions.
   - (2.4) the pointer type. this aids with eagerly implementing
     interfaces. if a method that implements an interface is defined on
     a pointer receiver, and the pointer type is never used, but the
@@ -85,13 +166,37 @@ var Debug io.Writer
   - (4.8) types of all instructions
   - (4.9) package-level variables they assign to iff in tests (sinks for benchmarks)
   - (4.10) all their type parameters. See 2.5 for reasoning.
-
-- conversions use:
+  - (4.11) the origins and type arguments of the generic functions
+    and methods they instantiate, whether they call the instances or
+    use them as values, such as in f := Map[int, T]. The generic
+    function itself doesn't use the type arguments.
+  - (4.12) if their IR couldn't be built, everything their bodies
+    refer to, and the fields and methods of the types their bodies
+    handle, like the functions of skimmed files (see 1.11)
+  - (4.13) the types in the cases of their type switches, even if the
+    switches don't bind a variable that would be converted to them
+  - (4.14) if so configured, what the blocks that always panic or
+    exit the program refer to, via the function's fatal paths.
+    Functions, methods and types that only fatal paths use are
+    reported as such.
+
+- conversions and comparisons use:
   - (5.1) when converting between two equivalent structs, the fields in
     either struct use each other. the fields are relevant for the
     conversion, but only if the fields are also accessed outside the
     conversion.
   - (5.2) when converting to or from unsafe.Pointer, mark all fields as used.
+  - (5.3) when comparing structs with == or !=, all of their fields,
+    including those of nested structs and arrays, if so configured.
+    reflect.DeepEqual also compares the values that pointers, slices
+    and maps refer to.
+  - (5.4) unsafe.Offsetof, unsafe.Sizeof and unsafe.Alignof use all
+    fields of the structs whose layouts their results depend on,
+    including nested structs, as well as the types of their
+    arguments. The compiler turns them into constants, so their
+    arguments leave no trace in the IR. Reordering or deleting any of
+    these fields would change the results, which is why the named
+    types are recorded in Result.LayoutSensitive.
 
 - structs use:
   - (6.1) fields of type NoCopy sentinel
@@ -107,7 +212,7 @@ var Debug io.Writer
   - (8.1) We do not technically care about interfaces that only consist of
     exported methods. Exported methods on concrete types are always
     marked as used.
-  - Any concrete type implements all known interfaces. Even if it isn't
+  - (8.2) Any concrete type implements all known interfaces. Even if it isn't
     assigned to any interfaces in our code, the user may receive a value
     of the type and expect to pass it back to us through an interface.
 
@@ -116,6 +221,14 @@ var Debug io.Writer
     way, types aren't incorrectly marked reachable through the edge
     from method to type.
 
+  - (8.7) If so configured, unexported types don't use their String
+    and Error methods, neither via (2.1) nor via (8.2), unless values
+    of the types escape the package, via its API, conversions to
+    interfaces or type arguments. Values that never do can't reach
+    fmt or anything else that formats values via fmt.Stringer or
+    error, so the methods are only used if they are called directly.
+    Such methods are reported as such.
+
   - (8.3) All interface methods are marked as used, even if they never get
     called. This is to accommodate sum types (unexported interface
     method that must exist but never gets called.)
@@ -125,6 +238,19 @@ var Debug io.Writer
     interfaces because in a chain C->B->A, B wouldn't be marked as
     used by 8.3 just because it contributes A's methods to C.
 
+  - (8.5) Instantiated interfaces, such as constraints of type
+    parameters, count as known interfaces, too. Calling a method on
+    a value whose type is a type parameter calls the method of the
+    constraint, which via (8.0) uses the methods of the type arguments
+    that implement it.
+
+  - (8.6) Interfaces that can only be used as constraints, because
+    they contain type terms or comparable, don't count as known
+    interfaces if they are unexported and nothing refers to them.
+    Unlike other interfaces, they can never be converted to, so such
+    constraints are dead, and their methods mustn't keep the methods
+    of the types that would satisfy them alive.
+
 - Inherent uses:
   - thunks and other generated wrappers call the real function
   - (9.2) variables use their types
@@ -133,7 +259,13 @@ var Debug io.Writer
   - (9.5) instructions use their operands
   - (9.6) instructions use their operands' types
   - (9.7) variable _reads_ use variables, writes do not, except in tests
-  - (9.8) runtime functions that may be called from user code via the compiler
+  - (9.8) runtime functions that may be called from user code via the
+    compiler or assembly, as of the targeted Go release, and the ones
+    listed by the unused.runtime_functions option
+  - (9.9) initializers of package-level variables that don't call any
+    functions use what they refer to on behalf of the variable, not
+    the package. Objects that are only referred to by unused
+    variables' initializers are reported as such.
 
 - const groups:
   (10.1) if one constant out of a block of constants is used, mark all
@@ -141,6 +273,11 @@ var Debug io.Writer
   of completeness. See also
   https://github.com/dominikh/go-tools/issues/365
 
+  (10.2) if so configured, const declarations that use iota are
+  exempt from (10.1). Unused constants in them whose removal would
+  change the values of later, used constants are reported as gaps
+  in the enumeration, and their fixes replace their names with _.
+
 
 - (11.1) anonymous struct types use all their fields. we cannot
   deduplicate struct types, as that leads to order-dependent
@@ -155,254 +292,8 @@ var Debug io.Writer
 
 */
 
-func assert(b bool) {
-	if !b {
-		panic("failed assertion")
-	}
-}
-
 // /usr/lib/go/src/runtime/proc.go:433:6: func badmorestackg0 is unused (U1000)
 
-// Functions defined in the Go runtime that may be called through
-// compiler magic or via assembly.
-var runtimeFuncs = map[string]bool{
-	// The first part of the list is copied from
-	// cmd/compile/internal/gc/builtin.go, var runtimeDecls
-	"newobject":            true,
-	"panicindex":           true,
-	"panicslice":           true,
-	"panicdivide":          true,
-	"panicmakeslicelen":    true,
-	"throwinit":            true,
-	"panicwrap":            true,
-	"gopanic":              true,
-	"gorecover":            true,
-	"goschedguarded":       true,
-	"printbool":            true,
-	"printfloat":           true,
-	"printint":             true,
-	"printhex":             true,
-	"printuint":            true,
-	"printcomplex":         true,
-	"printstring":          true,
-	"printpointer":         true,
-	"printiface":           true,
-	"printeface":           true,
-	"printslice":           true,
-	"printnl":              true,
-	"printsp":              true,
-	"printlock":            true,
-	"printunlock":          true,
-	"concatstring2":        true,
-	"concatstring3":        true,
-	"concatstring4":        true,
-	"concatstring5":        true,
-	"concatstrings":        true,
-	"cmpstring":            true,
-	"intstring":            true,
-	"slicebytetostring":    true,
-	"slicebytetostringtmp": true,
-	"slicerunetostring":    true,
-	"stringtoslicebyte":    true,
-	"stringtoslicerune":    true,
-	"slicecopy":            true,
-	"slicestringcopy":      true,
-	"decoderune":           true,
-	"countrunes":           true,
-	"convI2I":              true,
-	"convT16":              true,
-	"convT32":              true,
-	"convT64":              true,
-	"convTstring":          true,
-	"convTslice":           true,
-	"convT2E":              true,
-	"convT2Enoptr":         true,
-	"convT2I":              true,
-	"convT2Inoptr":         true,
-	"assertE2I":            true,
-	"assertE2I2":           true,
-	"assertI2I":            true,
-	"assertI2I2":           true,
-	"panicdottypeE":        true,
-	"panicdottypeI":        true,
-	"panicnildottype":      true,
-	"ifaceeq":              true,
-	"efaceeq":              true,
-	"fastrand":             true,
-	"makemap64":            true,
-	"makemap":              true,
-	"makemap_small":        true,
-	"mapaccess1":           true,
-	"mapaccess1_fast32":    true,
-	"mapaccess1_fast64":    true,
-	"mapaccess1_faststr":   true,
-	"mapaccess1_fat":       true,
-	"mapaccess2":           true,
-	"mapaccess2_fast32":    true,
-	"mapaccess2_fast64":    true,
-	"mapaccess2_faststr":   true,
-	"mapaccess2_fat":       true,
-	"mapassign":            true,
-	"mapassign_fast32":     true,
-	"mapassign_fast32ptr":  true,
-	"mapassign_fast64":     true,
-	"mapassign_fast64ptr":  true,
-	"mapassign_faststr":    true,
-	"mapiterinit":          true,
-	"mapdelete":            true,
-	"mapdelete_fast32":     true,
-	"mapdelete_fast64":     true,
-	"mapdelete_faststr":    true,
-	"mapiternext":          true,
-	"mapclear":             true,
-	"makechan64":           true,
-	"makechan":             true,
-	"chanrecv1":            true,
-	"chanrecv2":            true,
-	"chansend1":            true,
-	"closechan":            true,
-	"writeBarrier":         true,
-	"typedmemmove":         true,
-	"typedmemclr":          true,
-	"typedslicecopy":       true,
-	"selectnbsend":         true,
-	"selectnbrecv":         true,
-	"selectnbrecv2":        true,
-	"selectsetpc":          true,
-	"selectgo":             true,
-	"block":                true,
-	"makeslice":            true,
-	"makeslice64":          true,
-	"growslice":            true,
-	"memmove":              true,
-	"memclrNoHeapPointers": true,
-	"memclrHasPointers":    true,
-	"memequal":             true,
-	"memequal8":            true,
-	"memequal16":           true,
-	"memequal32":           true,
-	"memequal64":           true,
-	"memequal128":          true,
-	"int64div":             true,
-	"uint64div":            true,
-	"int64mod":             true,
-	"uint64mod":            true,
-	"float64toint64":       true,
-	"float64touint64":      true,
-	"float64touint32":      true,
-	"int64tofloat64":       true,
-	"uint64tofloat64":      true,
-	"uint32tofloat64":      true,
-	"complex128div":        true,
-	"racefuncenter":        true,
-	"racefuncenterfp":      true,
-	"racefuncexit":         true,
-	"raceread":             true,
-	"racewrite":            true,
-	"racereadrange":        true,
-	"racewriterange":       true,
-	"msanread":             true,
-	"msanwrite":            true,
-	"x86HasPOPCNT":         true,
-	"x86HasSSE41":          true,
-	"arm64HasATOMICS":      true,
-
-	// The second part of the list is extracted from assembly code in
-	// the standard library, with the exception of the runtime package itself
-	"abort":                 true,
-	"aeshashbody":           true,
-	"args":                  true,
-	"asminit":               true,
-	"badctxt":               true,
-	"badmcall2":             true,
-	"badmcall":              true,
-	"badmorestackg0":        true,
-	"badmorestackgsignal":   true,
-	"badsignal2":            true,
-	"callbackasm1":          true,
-	"callCfunction":         true,
-	"cgocallback_gofunc":    true,
-	"cgocallbackg":          true,
-	"checkgoarm":            true,
-	"check":                 true,
-	"debugCallCheck":        true,
-	"debugCallWrap":         true,
-	"emptyfunc":             true,
-	"entersyscall":          true,
-	"exit":                  true,
-	"exits":                 true,
-	"exitsyscall":           true,
-	"externalthreadhandler": true,
-	"findnull":              true,
-	"goexit1":               true,
-	"gostring":              true,
-	"i386_set_ldt":          true,
-	"_initcgo":              true,
-	"init_thread_tls":       true,
-	"ldt0setup":             true,
-	"libpreinit":            true,
-	"load_g":                true,
-	"morestack":             true,
-	"mstart":                true,
-	"nacl_sysinfo":          true,
-	"nanotimeQPC":           true,
-	"nanotime":              true,
-	"newosproc0":            true,
-	"newproc":               true,
-	"newstack":              true,
-	"noted":                 true,
-	"nowQPC":                true,
-	"osinit":                true,
-	"printf":                true,
-	"racecallback":          true,
-	"reflectcallmove":       true,
-	"reginit":               true,
-	"rt0_go":                true,
-	"save_g":                true,
-	"schedinit":             true,
-	"setldt":                true,
-	"settls":                true,
-	"sighandler":            true,
-	"sigprofNonGo":          true,
-	"sigtrampgo":            true,
-	"_sigtramp":             true,
-	"sigtramp":              true,
-	"stackcheck":            true,
-	"syscall_chdir":         true,
-	"syscall_chroot":        true,
-	"syscall_close":         true,
-	"syscall_dup2":          true,
-	"syscall_execve":        true,
-	"syscall_exit":          true,
-	"syscall_fcntl":         true,
-	"syscall_forkx":         true,
-	"syscall_gethostname":   true,
-	"syscall_getpid":        true,
-	"syscall_ioctl":         true,
-	"syscall_pipe":          true,
-	"syscall_rawsyscall6":   true,
-	"syscall_rawSyscall6":   true,
-	"syscall_rawsyscall":    true,
-	"syscall_RawSyscall":    true,
-	"syscall_rawsysvicall6": true,
-	"syscall_setgid":        true,
-	"syscall_setgroups":     true,
-	"syscall_setpgid":       true,
-	"syscall_setsid":        true,
-	"syscall_setuid":        true,
-	"syscall_syscall6":      true,
-	"syscall_syscall":       true,
-	"syscall_Syscall":       true,
-	"syscall_sysvicall6":    true,
-	"syscall_wait4":         true,
-	"syscall_write":         true,
-	"traceback":             true,
-	"tstart":                true,
-	"usplitR0":              true,
-	"wbBufFlush":            true,
-	"write":                 true,
-}
-
 type pkg struct {
 	Fset       *token.FileSet
 	Files      []*ast.File
@@ -412,38 +303,389 @@ type pkg struct {
 	IR         *ir.Package
 	SrcFuncs   []*ir.Function
 	Directives []lint.Directive
+	Generated  map[string]generated.Generator
+	Ownership  *ownership.Index
+	// IgnoredFiles are the files of the package's directory that the
+	// build excludes, see ignoredFileNames.
+	IgnoredFiles []string
+	// GoVersion is the version of Go the package targets, such as
+	// "go1.21", for type-checking modified copies of it. It is empty
+	// if unknown.
+	GoVersion string
 }
 
 // TODO(dh): should we return a map instead of two slices?
 type Result struct {
 	Used   []types.Object
 	Unused []types.Object
+	// Quiet contains unused objects that don't get reported because
+	// their owners are unused, such as the fields of unused struct
+	// types and the aliases of unused types.
+	Quiet []types.Object
+	// Aliases maps unused types to their unused aliases, which are
+	// reported as part of the types, sorted by position.
+	Aliases map[*types.TypeName][]*types.TypeName
+	// Skipped is set if the package's graph exceeded the configured
+	// size limits. In that case, all of the package's objects are
+	// considered used.
+	Skipped bool
+	// Errors describes the internal failures of the analysis. In
+	// that case, too, all of the package's objects are considered
+	// used, and only the parts of the result that don't depend on
+	// the graph, such as References and Declarations, are set.
+	Errors []AnalysisError
+	// Fixes maps unused objects to suggested fixes that delete them.
+	Fixes map[types.Object][]analysis.SuggestedFix
+	// Unexports maps unused exported package-level objects to
+	// suggested fixes that rename them to unexported names, as an
+	// alternative to deleting them. The fixes only update the
+	// references in the objects' own packages.
+	Unexports map[types.Object]analysis.SuggestedFix
+	// FixErrors maps unused objects to the reasons why their fixes
+	// were dropped, if fix verification is enabled.
+	FixErrors map[types.Object]string
+	// LowConfidence contains unused variables whose initializers may
+	// have side effects.
+	LowConfidence map[types.Object]bool
+	// Categories classifies some of the unused objects.
+	Categories map[types.Object]Category
+	// Tested maps functions that are only used by their own tests to
+	// these tests.
+	Tested map[types.Object][]types.Object
+	// Duplicates maps unused unexported functions to used functions
+	// with the same signatures and bodies, if RuleDuplicateFunctions
+	// is enabled.
+	Duplicates map[types.Object]types.Object
+	// Metrics maps unused functions and methods to their size and
+	// complexity.
+	Metrics map[types.Object]FuncMetrics
+	// Clusters groups the unused objects into sets of objects that
+	// can only be deleted together, largest first.
+	Clusters []Cluster
+	// LayoutSensitive lists the named types of the package whose
+	// layouts calls of unsafe.Offsetof, unsafe.Sizeof or
+	// unsafe.Alignof depend on, sorted by position. All of their
+	// fields are used, and none of them may be reordered or deleted.
+	LayoutSensitive []*types.TypeName
+	// Ignored lists the objects that ignore directives for U1000
+	// apply to.
+	Ignored []Ignored
+	// Exports lists the objects that //lint:export-to directives
+	// apply to, sorted by position.
+	Exports []Export
+	// References lists the package's references to objects of other
+	// packages that match the configured impact patterns, sorted by
+	// position.
+	References []Reference
+	// Declarations is the declaration tree of the package, if the
+	// unused.Ownership option is set.
+	Declarations []Declaration
+	// Reasons maps used objects to the kinds of all edges that lead
+	// to them from the root and from other reachable nodes, if the
+	// unused.Reasons option is set. Objects that the root only
+	// reaches via the special treatment of some nodes have no
+	// reasons.
+	Reasons map[types.Object]refgraph.EdgeKind
+	// Routes lists the HTTP routes that the package registers its
+	// functions as handlers of, if RuleDeadRoutes is enabled.
+	Routes []Route
+	// Conversions lists the conversions between struct types that
+	// couple the fields of the types matching the
+	// unused.Conversions option, sorted by position.
+	Conversions []Conversion
+	// Forbidden lists the references that the unused.Forbid rules
+	// forbid, which ForbiddenAnalyzer reports, sorted by the
+	// positions of the objects making them.
+	Forbidden []ForbiddenReference
+	// Linknames contains the symbols of other packages, such as
+	// example.com/pkg.fn, that this package links to via go:linkname.
+	// Facts only flow from dependencies to their dependents, so it is
+	// up to the driver to mark these symbols as used in their
+	// packages.
+	Linknames []string
+	// Dependencies records, in whole-program mode, which objects are
+	// used by objects that are unused within this package. Other
+	// packages may use the latter, in which case the driver has to
+	// consider the former used, too.
+	Dependencies []Dependency
+	// Profile is the profile of rules that the package was analyzed
+	// with, and Rules the rules that it resolved to, including the
+	// rules set explicitly, for reproducing the analysis.
+	Profile string
+	Rules   map[string]bool
+	// Root is set if the package is used regardless of whether the
+	// analyzed packages import it: if it is a main package, or if its
+	// exported objects are used merely by being exported, which is
+	// always the case outside of whole-program mode.
+	Root bool
+	// Graph is the package's reference graph. It is nil if the graph
+	// exceeded the size limits. It must not be modified, which makes
+	// it safe for concurrent queries, such as refgraph.Graph.WhyUsed.
+	// Drivers may free it with refgraph.Graph.Free once no analyzer
+	// can query it anymore, after setting Graph to nil in the result
+	// that they store, so that the result never refers to freed memory.
+	Graph *refgraph.Graph
+	// Stats contains statistics about the analysis.
+	Stats Stats
 }
 
+// Stats contains statistics about the analysis of a package.
+type Stats struct {
+	// ImplementsChecks is the number of times we checked whether a
+	// type implements an interface, and ImplementsCacheHits how many
+	// of these checks were answered by earlier ones.
+	ImplementsChecks    int
+	ImplementsCacheHits int
+	// Nodes and Edges are the size of the package's graph. See
+	// refgraph.EstimateMemory for the memory they take up.
+	Nodes uint64
+	Edges uint64
+}
+
+// An Ignored object is one that an ignore directive for U1000 applies
+// to, which makes it used.
+type Ignored struct {
+	Object types.Object
+	// Directive is the position of the directive's comment.
+	Directive token.Pos
+	// KeptAlive is set if the object would be unused without the
+	// directive.
+	KeptAlive bool
+}
+
+// A Dependency records that From uses To.
+type Dependency struct {
+	From types.Object
+	To   types.Object
+}
+
+// A Category classifies unused objects, allowing for more specific
+// reports.
+type Category string
+
+const (
+	// CategoryError is used for error sentinels, i.e. package-level
+	// variables holding errors, and for types implementing the error
+	// interface.
+	CategoryError Category = "error"
+	// CategoryAssigned is used for package-level variables that are
+	// assigned to but never read.
+	CategoryAssigned Category = "assigned"
+	// CategoryInitializer is used for objects that are only referred
+	// to by the initializers of unused package-level variables, such
+	// as functions stored in unused tables.
+	CategoryInitializer Category = "initializer"
+	// CategoryEnum is used for constants in declarations using iota
+	// whose removal would change the values of later constants that
+	// are used. Their fixes replace their names with _ instead of
+	// deleting them.
+	CategoryEnum Category = "enum"
+	// CategoryTested is used for functions that are only used by
+	// their own tests, benchmarks, fuzz tests and examples. Their
+	// fixes delete these tests, too.
+	CategoryTested Category = "tested"
+	// CategoryAsserted is used for objects that are only used by
+	// interface assertions, if RuleInterfaceAssertions is enabled.
+	CategoryAsserted Category = "asserted"
+	// CategoryFatal is used for functions, methods and types that are
+	// only used on code paths that always panic or exit the program,
+	// if RuleFatalPaths is enabled.
+	CategoryFatal Category = "fatal"
+	// CategoryIgnoredFiles is used for objects that are only used by
+	// files excluded with //go:build ignore, if RuleIgnoredFiles is
+	// enabled.
+	CategoryIgnoredFiles Category = "ignored_files"
+	// CategoryGenerated is used for objects that are only used by the
+	// init functions and package-level variables of generated files,
+	// if the generated policy is strict.
+	CategoryGenerated Category = "generated"
+	// CategoryUnformatted is used for String and Error methods of
+	// types whose values are never formatted, if
+	// RuleUnformattedMethods is enabled.
+	CategoryUnformatted Category = "unformatted"
+	// CategoryMarker is used for named struct types without fields
+	// that are only embedded by unused types and fields, such as
+	// type noCompare struct{}, and for their methods.
+	CategoryMarker Category = "marker"
+	// CategoryGoGenerate is used for objects that //go:generate
+	// directives mention by name, if unused.go_generate is set to
+	// mention. Generators may still look them up by reflection, so
+	// they aren't deleted by fixes.
+	CategoryGoGenerate Category = "go_generate"
+)
+
 type SerializedResult struct {
-	Used   []SerializedObject
-	Unused []SerializedObject
+	Used      []SerializedObject
+	Unused    []SerializedObject
+	Quiet     []SerializedObject
+	Skipped   bool
+	Errors    []AnalysisError
+	Linknames []string
+
+	Dependencies []SerializedDependency
+	Ignored      []SerializedIgnored
+	Exports      []SerializedExport
+	References   []SerializedReference
+	Declarations []SerializedDeclaration
+	Routes       []SerializedRoute
+	Conversions  []SerializedConversion
+
+	Profile string
+	Rules   map[string]bool
+	Root    bool
+	Stats   Stats
+}
+
+type SerializedIgnored struct {
+	Object SerializedObject
+	// Directive is the position of the //lint:ignore or
+	// //lint:file-ignore comment.
+	Directive token.Position
+	KeptAlive bool
+}
+
+type SerializedExport struct {
+	Object   SerializedObject
+	Consumer string
+	Reason   string
+	// Directive is the position of the //lint:export-to comment.
+	Directive token.Position
+}
+
+type SerializedReference struct {
+	Target SerializedObject
+	// From is the zero value if the reference isn't part of a
+	// package-level declaration or method.
+	From     SerializedObject
+	Position token.Position
+}
+
+type SerializedDeclaration struct {
+	Object SerializedObject
+	// Owner is the zero value for package-level objects.
+	Owner SerializedObject
+	// Position and End delimit the declaration in the file that was
+	// compiled, ignoring line directives.
+	Position token.Position
+	End      token.Position
+}
+
+type SerializedRoute struct {
+	Handler SerializedObject
+	Pattern string
+	// Registration is the position of the call that registers the
+	// handler.
+	Registration token.Position
+	Live         bool
+}
+
+type SerializedConversion struct {
+	// From and To are the converted struct types, qualified by the
+	// paths of packages other than the converting one.
+	From     string
+	To       string
+	Position token.Position
+	Fields   []SerializedCoupledFields
+}
+
+type SerializedCoupledFields struct {
+	From          SerializedObject
+	To            SerializedObject
+	Used          bool
+	FromKeptAlive bool
+	ToKeptAlive   bool
+}
+
+type SerializedDependency struct {
+	From SerializedObject
+	To   SerializedObject
+}
+
+type SerializedFix struct {
+	Message string
+	Edits   []SerializedEdit
+	// Rename is set for fixes that unexport the object instead of
+	// deleting it. They don't update references in other packages.
+	Rename bool
+}
+
+type SerializedEdit struct {
+	Position token.Position
+	End      token.Position
+	NewText  []byte
 }
 
 var Analyzer = &lint.Analyzer{
 	Doc: &lint.Documentation{
 		Title: "Unused code",
 	},
-	Analyzer: &analysis.Analyzer{
+	Analyzer: NewAnalyzer(config.Analyzer),
+}
+
+// NewAnalyzer returns an analyzer like Analyzer.Analyzer that gets its
+// configuration from cfg, which is config.Analyzer or an analyzer
+// returned by config.NewAnalyzer.
+func NewAnalyzer(cfg *analysis.Analyzer) *analysis.Analyzer {
+	a := &analysis.Analyzer{
 		Name:       "U1000",
 		Doc:        "Unused code",
 		Run:        run,
-		Requires:   []*analysis.Analyzer{buildir.Analyzer, generated.Analyzer, directives.Analyzer},
+		Requires:   []*analysis.Analyzer{buildir.PartialAnalyzer, generated.Analyzer, directives.Analyzer, cfg, ownership.Analyzer, cancellation.Analyzer},
 		ResultType: reflect.TypeOf(Result{}),
-	},
+	}
+	a.Flags.Var(lint.NewVersionFlag(), "go", "Target Go version")
+	return a
 }
 
 type SerializedObject struct {
-	Name            string
-	Position        token.Position
+	Name    string
+	PkgPath string
+	// ObjectPath is the object's path in its package, as computed by
+	// objectpath.For. It is only set for unused objects, the objects
+	// making references and, if Reason is set, used objects, and is
+	// empty for objects that don't have paths, such as local
+	// variables.
+	ObjectPath string
+	// Position is the position of the object in the file that was
+	// compiled, ignoring line directives.
+	Position token.Position
+	// AdjustedPosition is the position of the object as adjusted by
+	// line directives.
+	AdjustedPosition token.Position
+	// DisplayPosition is the position that gets reported, as
+	// selected by the unused.positions option.
 	DisplayPosition token.Position
 	Kind            string
 	InGenerated     bool
+	LowConfidence   bool
+	Category        Category
+	Fixes           []SerializedFix
+	// FixError is the reason why the object's fix was dropped, if
+	// any.
+	FixError string
+	// Tests are the names of the tests that are the only users of
+	// the object, if its category is CategoryTested.
+	Tests []string
+	// DuplicateOf is the name of a used function that has the same
+	// signature and body as the object, if any. Only set if
+	// RuleDuplicateFunctions is enabled, or, in whole-program mode,
+	// for unused copies of used constants.
+	DuplicateOf string
+	// Aliases are the names of the unused aliases of the unused
+	// type, which don't get reported on their own.
+	Aliases []string
+	// Metrics are the size and complexity of unused functions and
+	// methods, if known.
+	Metrics *FuncMetrics
+	// Value is the exact value of package-level constants, such as
+	// 42 or "text", for finding the constants that packages
+	// duplicate in whole-program mode.
+	Value string
+	// Reason is why the used object is used, as the kinds of all
+	// edges that lead to it from the root and from other reachable
+	// nodes, such as "InterfaceCall", separated by commas. It is only
+	// set if the unused.Reasons option is set.
+	Reason string
 }
 
 func typString(obj types.Object) string {
@@ -479,18 +721,142 @@ func Serialize(pass *analysis.Pass, res Result, fset *token.FileSet) SerializedR
 	// returning Result.
 
 	out := SerializedResult{
-		Used:   make([]SerializedObject, len(res.Used)),
-		Unused: make([]SerializedObject, len(res.Unused)),
+		Used:      make([]SerializedObject, len(res.Used)),
+		Unused:    make([]SerializedObject, len(res.Unused)),
+		Quiet:     make([]SerializedObject, len(res.Quiet)),
+		Skipped:   res.Skipped,
+		Errors:    res.Errors,
+		Linknames: res.Linknames,
+		Profile:   res.Profile,
+		Rules:     res.Rules,
+		Root:      res.Root,
+		Stats:     res.Stats,
+	}
+	for i, obj := range res.Quiet {
+		out.Quiet[i] = serializeObject(pass, fset, obj)
+	}
+	for _, ig := range res.Ignored {
+		out.Ignored = append(out.Ignored, SerializedIgnored{
+			Object:    serializeObject(pass, fset, ig.Object),
+			Directive: report.DisplayPosition(fset, ig.Directive),
+			KeptAlive: ig.KeptAlive,
+		})
+	}
+	for _, exp := range res.Exports {
+		out.Exports = append(out.Exports, SerializedExport{
+			Object:    serializeObject(pass, fset, exp.Object),
+			Consumer:  exp.Consumer,
+			Reason:    exp.Reason,
+			Directive: report.DisplayPosition(fset, exp.Directive),
+		})
+	}
+	for _, ref := range res.References {
+		sref := SerializedReference{
+			Target:   serializeObject(pass, fset, ref.Target),
+			Position: report.DisplayPosition(fset, ref.Pos),
+		}
+		if ref.From != nil {
+			sref.From = serializeObject(pass, fset, ref.From)
+			sref.From.ObjectPath = objectPath(ref.From)
+		}
+		out.References = append(out.References, sref)
+	}
+	for _, decl := range res.Declarations {
+		sdecl := SerializedDeclaration{
+			Object:   serializeObject(pass, fset, decl.Object),
+			Position: fset.PositionFor(decl.Pos, false),
+			End:      fset.PositionFor(decl.End, false),
+		}
+		if decl.Owner != nil {
+			sdecl.Owner = serializeObject(pass, fset, decl.Owner)
+		}
+		out.Declarations = append(out.Declarations, sdecl)
+	}
+	for _, r := range res.Routes {
+		sr := SerializedRoute{
+			Handler:      serializeObject(pass, fset, r.Handler),
+			Pattern:      r.Pattern,
+			Registration: report.DisplayPosition(fset, r.Registration),
+			Live:         r.Live,
+		}
+		sr.Handler.ObjectPath = objectPath(r.Handler)
+		out.Routes = append(out.Routes, sr)
+	}
+	for _, c := range res.Conversions {
+		qf := types.RelativeTo(pass.Pkg)
+		sc := SerializedConversion{
+			From:     types.TypeString(c.From, qf),
+			To:       types.TypeString(c.To, qf),
+			Position: report.DisplayPosition(fset, c.Pos),
+		}
+		for _, f := range c.Fields {
+			sc.Fields = append(sc.Fields, SerializedCoupledFields{
+				From:          serializeObject(pass, fset, f.From),
+				To:            serializeObject(pass, fset, f.To),
+				Used:          f.Used,
+				FromKeptAlive: f.FromKeptAlive,
+				ToKeptAlive:   f.ToKeptAlive,
+			})
+		}
+		out.Conversions = append(out.Conversions, sc)
+	}
+	for _, dep := range res.Dependencies {
+		out.Dependencies = append(out.Dependencies, SerializedDependency{
+			From: serializeObject(pass, fset, dep.From),
+			To:   serializeObject(pass, fset, dep.To),
+		})
 	}
 	for i, obj := range res.Used {
 		out.Used[i] = serializeObject(pass, fset, obj)
+		if kind, ok := res.Reasons[obj]; ok {
+			out.Used[i].ObjectPath = objectPath(obj)
+			out.Used[i].Reason = reasonString(kind)
+		}
 	}
 	for i, obj := range res.Unused {
 		out.Unused[i] = serializeObject(pass, fset, obj)
+		out.Unused[i].ObjectPath = objectPath(obj)
+		out.Unused[i].LowConfidence = res.LowConfidence[obj]
+		out.Unused[i].Category = res.Categories[obj]
+		out.Unused[i].FixError = res.FixErrors[obj]
+		if orig, ok := res.Duplicates[obj]; ok {
+			out.Unused[i].DuplicateOf = serializeObject(pass, fset, orig).Name
+		}
+		if m, ok := res.Metrics[obj]; ok {
+			out.Unused[i].Metrics = &m
+		}
+		for _, test := range res.Tested[obj] {
+			out.Unused[i].Tests = append(out.Unused[i].Tests, test.Name())
+		}
+		if tname, ok := obj.(*types.TypeName); ok {
+			for _, alias := range res.Aliases[tname] {
+				out.Unused[i].Aliases = append(out.Unused[i].Aliases, alias.Name())
+			}
+		}
+		for _, fix := range res.Fixes[obj] {
+			out.Unused[i].Fixes = append(out.Unused[i].Fixes, serializeFix(fset, fix))
+		}
+		if fix, ok := res.Unexports[obj]; ok {
+			sfix := serializeFix(fset, fix)
+			sfix.Rename = true
+			out.Unused[i].Fixes = append(out.Unused[i].Fixes, sfix)
+		}
 	}
 	return out
 }
 
+func serializeFix(fset *token.FileSet, fix analysis.SuggestedFix) SerializedFix {
+	sfix := SerializedFix{Message: fix.Message}
+	for _, e := range fix.TextEdits {
+		sfix.Edits = append(sfix.Edits, SerializedEdit{
+			Position: report.DisplayPosition(fset, e.Pos),
+			End:      report.DisplayPosition(fset, e.End),
+			NewText:  e.NewText,
+		})
+	}
+	return sfix
+}
+
 func serializeObject(pass *analysis.Pass, fset *token.FileSet, obj types.Object) SerializedObject {
 	name := obj.Name()
 	if sig, ok := obj.Type().(*types.Signature); ok && sig.Recv() != nil {
@@ -504,23 +870,135 @@ func serializeObject(pass *analysis.Pass, fset *token.FileSet, obj types.Object)
 			}
 		}
 	}
+	var pkgPath string
+	var value string
+	if obj.Pkg() != nil {
+		pkgPath = obj.Pkg().Path()
+		if c, ok := obj.(*types.Const); ok && c.Parent() == c.Pkg().Scope() {
+			value = c.Val().ExactString()
+		}
+	}
 	return SerializedObject{
-		Name:            name,
-		Position:        fset.PositionFor(obj.Pos(), false),
-		DisplayPosition: report.DisplayPosition(fset, obj.Pos()),
-		Kind:            typString(obj),
-		InGenerated:     code.IsGenerated(pass, obj.Pos()),
+		Name:             name,
+		PkgPath:          pkgPath,
+		Position:         fset.PositionFor(obj.Pos(), false),
+		AdjustedPosition: fset.PositionFor(obj.Pos(), true),
+		DisplayPosition:  displayPosition(fset, obj.Pos(), config.For(pass).Unused.Positions),
+		Kind:             typString(obj),
+		InGenerated:      inGenerated(pass, obj.Pos()),
+		Value:            value,
 	}
 }
 
+// reasonString returns the names of the kinds of kind, without their
+// Edge prefixes.
+func reasonString(kind refgraph.EdgeKind) string {
+	var names []string
+	for _, k := range kind.Kinds() {
+		names = append(names, strings.TrimPrefix(k.String(), "Edge"))
+	}
+	return strings.Join(names, ",")
+}
+
+// objectPath returns the path of obj in its package, or the empty
+// string if it has none.
+func objectPath(obj types.Object) string {
+	// objectpath.For searches the whole package for objects that
+	// aren't package-level, which is why we only compute paths for
+	// the objects that need them.
+	path, err := objectpath.For(obj)
+	if err != nil {
+		return ""
+	}
+	return string(path)
+}
+
+// displayPosition returns the position of pos that gets reported,
+// according to mode, which is one of the values of the
+// unused.positions option.
+func displayPosition(fset *token.FileSet, pos token.Pos, mode string) token.Position {
+	switch mode {
+	case config.PositionsRaw:
+		return fset.PositionFor(pos, false)
+	case config.PositionsAdjusted:
+		return fset.PositionFor(pos, true)
+	default:
+		return report.DisplayPosition(fset, pos)
+	}
+}
+
+// inGenerated reports whether pos is in generated code, not counting
+// user code rewritten by cgo.
+func inGenerated(pass *analysis.Pass, pos token.Pos) bool {
+	_, ok := generator(pass.Fset, pass.ResultOf[generated.Analyzer].(map[string]generated.Generator), pos)
+	return ok
+}
+
 func debugf(f string, v ...interface{}) {
 	if Debug != nil {
 		fmt.Fprintf(Debug, f, v...)
 	}
 }
 
+// Message returns the message that an unused object gets reported
+// with.
+func (obj SerializedObject) Message() string {
+	kind := obj.Kind
+	if obj.Category == CategoryError {
+		kind = "error " + kind
+	}
+	msg := fmt.Sprintf("%s %s is unused", kind, obj.Name)
+	switch obj.Category {
+	case CategoryAssigned:
+		msg = fmt.Sprintf("%s %s is assigned but never read", kind, obj.Name)
+	case CategoryInitializer:
+		msg = fmt.Sprintf("%s %s is only used by the initializers of unused variables", kind, obj.Name)
+	case CategoryEnum:
+		msg = fmt.Sprintf("%s %s is unused, but removing it would change the values of the constants that follow it", kind, obj.Name)
+	case CategoryTested:
+		msg = fmt.Sprintf("%s %s is only exercised by %s", kind, obj.Name, strings.Join(obj.Tests, ", "))
+	case CategoryAsserted:
+		msg = fmt.Sprintf("%s %s is only kept alive by interface assertions", kind, obj.Name)
+	case CategoryFatal:
+		msg = fmt.Sprintf("%s %s is only used on code paths that panic or exit the program", kind, obj.Name)
+	case CategoryIgnoredFiles:
+		msg = fmt.Sprintf("%s %s is only used by files excluded with //go:build ignore", kind, obj.Name)
+	case CategoryGenerated:
+		msg = fmt.Sprintf("%s %s is only used by generated code", kind, obj.Name)
+	case CategoryGoGenerate:
+		msg = fmt.Sprintf("%s %s is unused, but a go:generate directive mentions it", kind, obj.Name)
+	case CategoryMarker:
+		if obj.Kind == "func" {
+			msg = fmt.Sprintf("%s %s belongs to a marker type that is only embedded in unused code", kind, obj.Name)
+		} else {
+			msg = fmt.Sprintf("%s %s is only embedded as a marker in unused code", kind, obj.Name)
+		}
+	case CategoryUnformatted:
+		msg = fmt.Sprintf("%s %s only exists to implement fmt.Stringer or error, but values of its type are never formatted", kind, obj.Name)
+	}
+	if obj.LowConfidence {
+		msg += " (its initializer may have side effects)"
+	}
+	if obj.DuplicateOf != "" {
+		msg += fmt.Sprintf(" (possible duplicate of %s)", obj.DuplicateOf)
+	}
+	if len(obj.Aliases) != 0 {
+		msg += fmt.Sprintf(" (and so are its aliases %s)", strings.Join(obj.Aliases, ", "))
+	}
+	return msg
+}
+
+// FixErrorMessage returns the explanation of why the object's fix was
+// dropped, or the empty string if it wasn't.
+func (obj SerializedObject) FixErrorMessage() string {
+	if obj.FixError == "" {
+		return ""
+	}
+	return "no fix is suggested because it would break the build: " + obj.FixError
+}
+
 func run(pass *analysis.Pass) (interface{}, error) {
-	irpkg := pass.ResultOf[buildir.Analyzer].(*buildir.IR)
+	irpkg := pass.ResultOf[buildir.PartialAnalyzer].(*buildir.IR)
 	dirs := pass.ResultOf[directives.Analyzer].([]lint.Directive)
 	pkg := &pkg{
 		Fset:       pass.Fset,
@@ -531,369 +1009,987 @@ func run(pass *analysis.Pass) (interface{}, error) {
 		IR:         irpkg.Pkg,
 		SrcFuncs:   irpkg.SrcFuncs,
 		Directives: dirs,
+		Generated:  pass.ResultOf[generated.Analyzer].(map[string]generated.Generator),
+		Ownership:  pass.ResultOf[ownership.Analyzer].(*ownership.Index),
+
+		IgnoredFiles: pass.IgnoredFiles,
+		GoVersion:    fmt.Sprintf("go1.%d", code.GoVersion(pass)),
+	}
+
+	for _, err := range irpkg.Pkg.BuildErrors {
+		// The function's body only gets skimmed, which may consider
+		// more objects used than necessary.
+		report.Report(pass, err.Func.Source(), fmt.Sprintf("incomplete unused code analysis of %s: %v", err.Func.Name(), err.Value), report.ShortRange())
 	}
 
+	cfg := config.For(pass).Unused
 	g := newGraph()
-	g.entry(pkg)
-	used, unused := results(g)
+	g.MaxNodes = uint64(cfg.MaxNodes)
+	g.MaxEdges = uint64(cfg.MaxEdges)
+	g.sideEffects = cfg.SideEffectFunctions
+	g.keep = cfg.Keep
+	g.keepGenerated = cfg.Generated == config.GeneratedKeep
+	g.strictGenerated = cfg.Generated == config.GeneratedStrict
+	g.goGenerate = cfg.GoGenerate
+	if cfg.Generated != config.GeneratedReport {
+		g.skimGenerated = cfg.SkimGenerated
+	}
+	g.rules = cfg.Rules
+	g.wholeProgram = cfg.WholeProgram
+	g.Positions = EdgePositions
+	g.Fset = pass.Fset
+	g.ctx = cancellation.For(pass)
+	g.provided = providedEdges(pass, cfg.Rules)
+	g.conversionPatterns = cfg.Conversions
+	if pass.Pkg.Path() == "runtime" {
+		g.runtimeFuncs = runtimeFuncs(code.GoVersion(pass), cfg.RuntimeFunctions)
+	}
+	forbidden := forbiddenRules(cfg.Forbid, pass.Pkg.Path())
+	// Forbidden references in dead code are still forbidden.
+	g.quick = QuickScan && len(forbidden) == 0
+	g.mock = cfg.WholeProgram && isMock(pass.Pkg.Path(), cfg.MockPackages)
+	g.externalAPI = isExternalAPI(pass.Pkg.Path(), cfg.ExternalAPI)
+	g.testSupport = cfg.WholeProgram && isTestSupport(pass.Pkg.Path(), cfg.TestSupportPackages)
+	root := !cfg.WholeProgram || g.externalAPI || g.testSupport || pass.Pkg.Name() == "main"
+	if cfg.Rules[config.RuleReceiverNames] || cfg.Rules[config.RuleNamedResults] {
+		checkNames(pass, cfg)
+	}
+	var refs []Reference
+	if len(cfg.Impact) > 0 {
+		refs = references(pkg, cfg.Impact)
+	}
+	res, err := g.run(pkg)
+	if err != nil && err == g.ctx.Err() {
+		// The run has been cancelled; whatever we'd report would be
+		// incomplete.
+		return nil, err
+	}
+	if aerr, ok := err.(AnalysisError); ok {
+		// The graph is incomplete, so we conservatively consider
+		// everything used, but still return what doesn't depend on
+		// the graph.
+		if len(pass.Files) > 0 {
+			report.Report(pass, pass.Files[0], fmt.Sprintf("incomplete unused code analysis: %s", aerr), report.ShortRange())
+		}
+		res = Result{Used: definedObjects(pkg), Errors: []AnalysisError{aerr}, References: refs, Profile: cfg.Profile, Rules: cfg.Rules, Root: root}
+		if cfg.Ownership {
+			res.Declarations = declarations(pkg)
+		}
+		return res, nil
+	} else if err != nil {
+		// Rather than running out of memory on huge (usually
+		// generated) packages, we give up and conservatively consider
+		// everything used.
+		if len(pass.Files) > 0 {
+			report.Report(pass, pass.Files[0], fmt.Sprintf("skipped unused code analysis: %s", err), report.ShortRange())
+		}
+		return Result{Used: definedObjects(pkg), Skipped: true, References: refs, Profile: cfg.Profile, Rules: cfg.Rules, Root: root}, nil
+	}
+	res.References = refs
+	res.Profile = cfg.Profile
+	res.Rules = cfg.Rules
+	res.Root = root
+	if cfg.Ownership {
+		res.Declarations = declarations(pkg)
+	}
+	if cfg.Reasons {
+		res.Reasons = g.reasons(res.Used)
+	}
+	if cfg.Rules[config.RuleDeadRoutes] {
+		res.Routes = routes(pass, cfg.Routes)
+	}
+	res.Conversions = g.couplings()
+	if len(forbidden) > 0 {
+		res.Forbidden = g.forbiddenReferences(forbidden)
+	}
 
 	if Debug != nil {
-		debugNode := func(n *node) {
-			if n.obj == nil {
-				debugf("n%d [label=\"Root\"];\n", n.id)
-			} else {
-				color := "red"
-				if n.seen {
-					color = "green"
-				}
-				debugf("n%d [label=%q, color=%q];\n", n.id, fmt.Sprintf("(%T) %s", n.obj, n.obj), color)
-			}
-			for _, e := range n.used {
-				for i := edgeKind(1); i < 64; i++ {
-					if e.kind.is(1 << i) {
-						debugf("n%d -> n%d [label=%q];\n", n.id, e.node.id, edgeKind(1<<i))
-					}
-				}
-			}
-		}
+		g.WriteDot(Debug)
+	}
+	res.Graph = g.Graph
+	if err := g.ctx.Err(); err != nil {
+		return nil, err
+	}
 
-		debugf("digraph{\n")
-		debugNode(g.Root)
-		for _, v := range g.Nodes {
-			debugNode(v)
+	res.LowConfidence = map[types.Object]bool{}
+	res.Categories = map[types.Object]Category{}
+	initUses := g.initializerUses()
+	markers := g.markers(res.Unused)
+	for _, obj := range res.Unused {
+		if g.lowConfidence[obj] {
+			res.LowConfidence[obj] = true
 		}
-		for _, node := range g.TypeNodes {
-			debugNode(node)
+		if g.assigned[obj] {
+			res.Categories[obj] = CategoryAssigned
+			// Deleting the variable would break the assignments.
+			delete(res.Fixes, obj)
+		} else if initUses[obj] {
+			res.Categories[obj] = CategoryInitializer
+			// Deleting the object would break the initializer.
+			delete(res.Fixes, obj)
+		} else if g.gaps[obj] {
+			res.Categories[obj] = CategoryEnum
+		} else if len(g.tested[obj]) > 0 {
+			res.Categories[obj] = CategoryTested
+		} else if len(g.asserted[obj]) > 0 {
+			res.Categories[obj] = CategoryAsserted
+		} else if g.fatal[obj] {
+			res.Categories[obj] = CategoryFatal
+			// Deleting the object would break the fatal paths.
+			delete(res.Fixes, obj)
+		} else if g.ignoredFileUses[obj] {
+			res.Categories[obj] = CategoryIgnoredFiles
+			// Deleting the object would break the ignored files.
+			delete(res.Fixes, obj)
+		} else if g.generatedUses[obj] {
+			res.Categories[obj] = CategoryGenerated
+			// Deleting the object would break the generated code.
+			delete(res.Fixes, obj)
+		} else if g.generateMentioned[obj] {
+			res.Categories[obj] = CategoryGoGenerate
+			// The generator may look the object up.
+			delete(res.Fixes, obj)
+		} else if markers[obj] {
+			res.Categories[obj] = CategoryMarker
+		} else if g.unformatted[obj] {
+			res.Categories[obj] = CategoryUnformatted
+		} else if isError(obj) {
+			res.Categories[obj] = CategoryError
 		}
-
-		debugf("}\n")
+	}
+	if cfg.VerifyFixes {
+		res.FixErrors = g.verifyFixes(res.Fixes)
 	}
 
-	return Result{Used: used, Unused: unused}, nil
+	return res, nil
 }
 
-func results(g *graph) (used, unused []types.Object) {
-	g.color(g.Root)
-	for _, node := range g.TypeNodes {
-		if node.seen {
-			continue
+var errorType = types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+
+// isError reports whether obj is an error sentinel or an error type.
+func isError(obj types.Object) bool {
+	switch obj := obj.(type) {
+	case *types.Var:
+		if obj.IsField() || obj.Parent() != obj.Pkg().Scope() {
+			return false
 		}
-		switch obj := node.obj.(type) {
-		case *types.Struct:
-			for i := 0; i < obj.NumFields(); i++ {
-				if node, ok := g.nodeMaybe(obj.Field(i)); ok {
-					node.quiet = true
-				}
-			}
-		case *types.Interface:
-			for i := 0; i < obj.NumExplicitMethods(); i++ {
-				m := obj.ExplicitMethod(i)
-				if node, ok := g.nodeMaybe(m); ok {
-					node.quiet = true
+		return types.Implements(obj.Type(), errorType)
+	case *types.TypeName:
+		if obj.IsAlias() {
+			return false
+		}
+		if _, ok := obj.Type().Underlying().(*types.Interface); ok {
+			return false
+		}
+		return types.Implements(obj.Type(), errorType) || types.Implements(types.NewPointer(obj.Type()), errorType)
+	default:
+		return false
+	}
+}
+
+// initializer inspects the initializer of a package-level variable
+// declaration for function calls. Calls to functions matching the
+// side effect patterns mark the declared variables as used (1.9); any
+// other calls make reports of the variables less certain, as the
+// variables may exist for the side effects of their initialization.
+func (g *graph) initializer(spec *ast.ValueSpec) {
+	var calls, sideEffects bool
+	for _, val := range spec.Values {
+		ast.Inspect(val, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncLit:
+				// function literals don't run as part of the initialization
+				return false
+			case *ast.CallExpr:
+				switch callee := typeutil.Callee(g.pkg.TypesInfo, n).(type) {
+				case *types.Func:
+					calls = true
+					if g.hasSideEffects(callee) {
+						sideEffects = true
+					}
+				case *types.Builtin:
+				default:
+					if tv, ok := g.pkg.TypesInfo.Types[n.Fun]; !ok || !tv.IsType() {
+						// dynamic function call
+						calls = true
+					}
 				}
 			}
+			return true
+		})
+	}
+
+	for i, name := range spec.Names {
+		obj := g.pkg.TypesInfo.Defs[name]
+		if obj == nil || name.Name == "_" {
+			continue
+		}
+		if sideEffects {
+			g.seeAndUse(obj, nil, refgraph.EdgeSideEffects)
+		} else if calls {
+			g.lowConfidence[obj] = true
+		} else if len(spec.Values) == len(spec.Names) {
+			// The initializer merely computes data, so whatever it
+			// refers to is only needed if the variable is.
+			g.initializers[obj] = spec.Values[i]
+			g.see(obj)
 		}
 	}
+}
 
-	// OPT(dh): can we find meaningful initial capacities for the used and unused slices?
+// initializerOwner returns the variable whose initializer contains
+// pos, if any.
+func (g *graph) initializerOwner(pos token.Pos) types.Object {
+	if !pos.IsValid() {
+		return nil
+	}
+	for obj, expr := range g.initializers {
+		if pos >= expr.Pos() && pos < expr.End() {
+			return obj
+		}
+	}
+	return nil
+}
 
-	for _, n := range g.Nodes {
-		if obj, ok := n.obj.(types.Object); ok {
-			switch obj := obj.(type) {
-			case *types.Var:
-				// don't report unnamed variables (interface embedding)
-				if obj.Name() == "" && obj.IsField() {
+// initializerOwners maps the instructions of the package initializer
+// fn to the variables whose values they compute, for all variables in
+// g.initializers. Instructions whose values flow anywhere else, or
+// into more than one variable, are owned by the package.
+func (g *graph) initializerOwners(fn *ir.Function) map[ir.Instruction]types.Object {
+	targets := map[ir.Value]types.Object{}
+	var target func(v ir.Value) types.Object
+	var addrTarget func(addr ir.Value) types.Object
+	addrTarget = func(addr ir.Value) types.Object {
+		switch addr := addr.(type) {
+		case *ir.Global:
+			if _, ok := g.initializers[addr.Object()]; ok {
+				return addr.Object()
+			}
+			return nil
+		case *ir.IndexAddr:
+			return addrTarget(addr.X)
+		case *ir.FieldAddr:
+			return addrTarget(addr.X)
+		default:
+			// a value that gets stored into, such as an allocation
+			return target(addr)
+		}
+	}
+	// target returns the variable that v flows into.
+	target = func(v ir.Value) types.Object {
+		if obj, ok := targets[v]; ok {
+			return obj
+		}
+		// Guard against cycles
+		targets[v] = nil
+
+		var out types.Object
+		refs := v.Referrers()
+		if refs == nil {
+			return nil
+		}
+		for _, ref := range *refs {
+			var obj types.Object
+			switch ref := ref.(type) {
+			case *ir.DebugRef:
+				continue
+			case *ir.Store:
+				if ref.Val != v {
+					// storing into v doesn't make v flow anywhere
 					continue
 				}
-			case types.Object:
-				if obj.Name() == "_" {
+				obj = addrTarget(ref.Addr)
+			case *ir.MapUpdate:
+				if ref.Map == v {
 					continue
 				}
-			}
-
-			if obj.Pkg() != nil {
-				if n.seen {
-					used = append(used, obj)
-				} else if !n.quiet {
-					if obj.Pkg() != g.pkg.Pkg {
-						continue
-					}
-					unused = append(unused, obj)
+				obj = target(ref.Map)
+			case *ir.IndexAddr:
+				if ref.X == v {
+					continue
+				}
+				return nil
+			case *ir.FieldAddr:
+				continue
+			case *ir.Call:
+				if _, ok := ref.Call.Value.(*ir.Builtin); !ok {
+					// v escapes into a function
+					return nil
 				}
+				obj = target(ref)
+			case ir.Value:
+				obj = target(ref)
+			default:
+				return nil
+			}
+			if obj == nil || (out != nil && out != obj) {
+				return nil
 			}
+			out = obj
 		}
+		targets[v] = out
+		return out
 	}
 
-	return used, unused
+	owners := map[ir.Instruction]types.Object{}
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			var obj types.Object
+			switch instr := instr.(type) {
+			case *ir.Store:
+				obj = addrTarget(instr.Addr)
+			case *ir.MapUpdate:
+				obj = target(instr.Map)
+			case ir.Value:
+				obj = target(instr)
+			}
+			if obj != nil {
+				owners[instr] = obj
+			}
+		}
+	}
+	return owners
 }
 
-type graph struct {
-	Root *node
-
-	// Mapping of types T to canonical *T
-	pointers map[types.Type]*types.Pointer
-
-	seenTypes map[types.Type]struct{}
-
-	TypeNodes map[types.Type]*node
-	Nodes     map[interface{}]*node
-
-	// context
-	pkg         *pkg
-	seenFns     map[*ir.Function]struct{}
-	nodeCounter uint64
+// owner returns the object that uses the code of fn. This is usually
+// the function's owning object, but code in the package initializer
+// and function literals in initializers are owned by the variables
+// they initialize.
+func (g *graph) owner(fn *ir.Function) types.Object {
+	if obj := owningObject(fn); obj != nil {
+		return obj
+	}
+	return g.initializerOwner(fn.Pos())
 }
 
-func newGraph() *graph {
-	g := &graph{
-		Nodes:     map[interface{}]*node{},
-		seenFns:   map[*ir.Function]struct{}{},
-		seenTypes: map[types.Type]struct{}{},
-		TypeNodes: map[types.Type]*node{},
-		pointers:  map[types.Type]*types.Pointer{},
+// initializerUses returns the unused objects referred to by the
+// initializers of unused variables.
+func (g *graph) initializerUses() map[types.Object]bool {
+	out := map[types.Object]bool{}
+	for obj, expr := range g.initializers {
+		if n, ok := g.Lookup(obj); !ok || n.Seen {
+			continue
+		}
+		ast.Inspect(expr, func(n ast.Node) bool {
+			ident, ok := n.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			obj := g.pkg.TypesInfo.Uses[ident]
+			if obj == nil || obj.Pkg() != g.pkg.Pkg || obj.Parent() != obj.Pkg().Scope() {
+				return true
+			}
+			if n, ok := g.Lookup(obj); ok && !n.Seen {
+				out[obj] = true
+			}
+			return true
+		})
 	}
-	g.Root = g.newNode(nil)
-	return g
+	return out
 }
 
-func (g *graph) newPointer(typ types.Type) *types.Pointer {
-	if p, ok := g.pointers[typ]; ok {
-		return p
-	} else {
-		p := types.NewPointer(typ)
-		g.pointers[typ] = p
-		g.see(p)
-		return p
-	}
+// hasSideEffects reports whether fn matches any of the side effect
+// patterns.
+func (g *graph) hasSideEffects(fn *types.Func) bool {
+	return matchesAny(g.sideEffects, fn)
 }
 
-func (g *graph) color(root *node) {
-	if root.seen {
-		return
+// matchesAny reports whether obj matches any of the patterns, either
+// by its name or its fully qualified name.
+func matchesAny(patterns []string, obj types.Object) bool {
+	var full string
+	if fn, ok := obj.(*types.Func); ok {
+		full = fn.FullName()
+	} else {
+		full = obj.Pkg().Path() + "." + obj.Name()
 	}
-	root.seen = true
-	for _, e := range root.used {
-		g.color(e.node)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, obj.Name()); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, full); ok {
+			return true
+		}
 	}
+	return false
 }
 
-type constGroup struct {
-	// give the struct a size to get unique pointers
-	_ byte
+// isMock reports whether the package at path contains mocks.
+func isMock(pkgPath string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, pkgPath); ok {
+			return true
+		}
+	}
+	return false
 }
 
-func (constGroup) String() string { return "const group" }
-
-type edge struct {
-	node *node
-	kind edgeKind
+// isFuncVar reports whether the variable is of a function type.
+func isFuncVar(obj types.Object) bool {
+	_, ok := obj.Type().Underlying().(*types.Signature)
+	return ok
 }
 
-type node struct {
-	obj interface{}
-	id  uint64
-
-	// OPT(dh): evaluate using a map instead of a slice to avoid
-	// duplicate edges.
-	used []edge
+// isExternalAPI reports whether the package is used by code that
+// isn't analyzed, according to the longest of the patterns that
+// matches it. Of equally long patterns, the ones set to true win.
+func isExternalAPI(pkgPath string, patterns map[string]bool) bool {
+	longest := -1
+	var external bool
+	for pattern, v := range patterns {
+		if len(pattern) < longest || (len(pattern) == longest && !v) {
+			continue
+		}
+		if config.MatchTree(pattern, pkgPath) {
+			longest, external = len(pattern), v
+		}
+	}
+	return external
+}
 
-	// set during final graph walk if node is reachable
-	seen  bool
-	quiet bool
+// isTestSupport reports whether the package at pkgPath only exists for
+// the tests of other packages, according to the test support patterns.
+// If RuleTestSupportImports is enabled, the runner adds the packages
+// that only _test.go files import to the patterns, see
+// loader.TestSupport.
+func isTestSupport(pkgPath string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if config.MatchTree(pattern, pkgPath) {
+			return true
+		}
+	}
+	return false
 }
 
-func (g *graph) nodeMaybe(obj types.Object) (*node, bool) {
-	if node, ok := g.Nodes[obj]; ok {
-		return node, true
+// exportedIsUsed reports whether the exported package-level object obj
+// is used merely by being exported. In whole-program mode, only
+// exported objects in tests, in test support packages and in packages
+// that are used by unanalyzed code are; everything else has to be
+// used by one of the analyzed packages.
+func (g *graph) exportedIsUsed(obj types.Object) bool {
+	if !g.wholeProgram || g.externalAPI || g.testSupport {
+		return true
 	}
-	return nil, false
+	return strings.HasSuffix(g.pkg.Fset.PositionFor(obj.Pos(), false).Filename, "_test.go")
 }
 
-func (g *graph) node(obj interface{}) (n *node, new bool) {
-	switch obj := obj.(type) {
-	case types.Type:
-		if v := g.TypeNodes[obj]; v != nil {
-			return v, false
-		}
-		n = g.newNode(obj)
-		g.TypeNodes[obj] = n
-		return n, true
-	case types.Object:
-		// OPT(dh): the types.Object and default cases are identical
-		if node, ok := g.Nodes[obj]; ok {
-			return node, false
-		}
-
-		n = g.newNode(obj)
-		g.Nodes[obj] = n
-		return n, true
-	default:
-		if node, ok := g.Nodes[obj]; ok {
-			return node, false
+// isKept reports whether obj is always used because of the
+// configuration.
+func (g *graph) isKept(obj types.Object) bool {
+	if obj.Pkg() != g.pkg.Pkg {
+		return false
+	}
+	if obj.Parent() != obj.Pkg().Scope() {
+		fn, ok := obj.(*types.Func)
+		if !ok || fn.Type().(*types.Signature).Recv() == nil {
+			// only package-level objects and methods can be kept
+			return false
 		}
-
-		n = g.newNode(obj)
-		g.Nodes[obj] = n
-		return n, true
 	}
+	if g.keepGenerated && g.isGenerated(obj) {
+		return true
+	}
+	if g.inSkimmedFile(obj.Pos()) {
+		return true
+	}
+	return matchesAny(g.keep, obj)
 }
 
-func (g *graph) newNode(obj interface{}) *node {
-	g.nodeCounter++
-	return &node{
-		obj: obj,
-		id:  g.nodeCounter,
+// run builds the graph for pkg and computes the used, unused and
+// quiet objects, as well as fixes for removing the unused objects. It
+// returns a refgraph.BudgetError if the graph exceeded its size
+// limits, an AnalysisError if the analysis failed, and the error of
+// g.ctx if the run has been cancelled.
+func (g *graph) run(pkg *pkg) (res Result, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			res, err = Result{}, recoverError(r)
+		}
+	}()
+	g.entry(pkg)
+	if g.rules[config.RuleInterfaceAssertions] {
+		// Leave what only the assertions use unseen.
+		for _, a := range g.assertions {
+			if n, ok := g.Lookup(a); ok {
+				n.Seen = true
+			}
+		}
+	}
+	if g.rules[config.RuleFatalPaths] {
+		// Leave what only the fatal paths use unseen.
+		for _, p := range g.fatalPaths {
+			if n, ok := g.Lookup(p); ok {
+				n.Seen = true
+			}
+		}
+	}
+	if g.registrations != nil {
+		// Leave what only the generated registrations use unseen.
+		if n, ok := g.Lookup(g.registrations); ok {
+			n.Seen = true
+		}
 	}
+	if g.rules[config.RuleTestedOnly] {
+		g.tested = g.testedOnly()
+		res.Tested = g.tested
+	}
+	if g.rules[config.RuleFatalPaths] {
+		g.fatal = g.fatalOnly()
+	}
+	g.generatedUses = g.generatedOnly()
+	g.checkCancelled()
+	res.Used, res.Unused, res.Quiet = results(g)
+	res.Used = g.filterCgo(res.Used)
+	res.Unused = g.filterCgo(res.Unused)
+	res.Quiet = g.filterCgo(res.Quiet)
+	res.Aliases = g.aliases
+	if g.rules[config.RuleInterfaceAssertions] {
+		g.asserted = g.assertedOnly(res.Unused)
+	}
+	if g.rules[config.RuleIgnoredFiles] {
+		g.ignoredFileUses = g.ignoredOnly(res.Unused)
+	}
+	g.gaps = g.enumGaps(res.Unused)
+	g.checkCancelled()
+	if g.rules[config.RuleDuplicateFunctions] {
+		res.Duplicates = g.duplicates(res.Unused, res.Used)
+	}
+	res.Metrics = g.metrics(res.Unused)
+	res.Clusters = g.clusters(res.Unused)
+	res.LayoutSensitive = g.layoutSensitiveTypes()
+	res.Fixes = g.fixes(res.Unused)
+	res.Unexports = g.unexports(res.Unused)
+	res.Linknames = g.linknames
+	res.Ignored = g.keptAlive()
+	res.Exports = g.exports
+	g.stats.Nodes, g.stats.Edges = g.Size()
+	res.Stats = g.stats
+	if g.wholeProgram {
+		res.Dependencies = g.dependencies()
+	}
+	return res, nil
 }
 
-func (n *node) use(n2 *node, kind edgeKind) {
-	assert(n2 != nil)
-	n.used = append(n.used, edge{node: n2, kind: kind})
+// keptAlive returns the objects that ignore directives apply to,
+// sorted by position, and sets KeptAlive for those that aren't
+// reachable without the directives.
+func (g *graph) keptAlive() []Ignored {
+	if len(g.ignored) == 0 {
+		return nil
+	}
+	reachable := map[*refgraph.Node]struct{}{}
+	var mark func(n *refgraph.Node)
+	mark = func(n *refgraph.Node) {
+		if _, ok := reachable[n]; ok {
+			return
+		}
+		reachable[n] = struct{}{}
+		for _, e := range n.Uses {
+			mark(e.Node)
+		}
+	}
+	for _, e := range g.Root.Uses {
+		if e.Kind != refgraph.EdgeIgnored {
+			mark(e.Node)
+		}
+	}
+
+	out := make([]Ignored, len(g.ignored))
+	for i, ig := range g.ignored {
+		n, _ := g.Lookup(ig.Object)
+		_, ok := reachable[n]
+		ig.KeptAlive = !ok
+		out[i] = ig
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Directive != out[j].Directive {
+			return out[i].Directive < out[j].Directive
+		}
+		return out[i].Object.Pos() < out[j].Object.Pos()
+	})
+	return out
 }
 
-// isIrrelevant reports whether an object's presence in the graph is
-// of any relevance. A lot of objects will never have outgoing edges,
-// nor meaningful incoming ones. Examples are basic types and empty
-// signatures, among many others.
+// dependencies returns the objects used by each unreachable object,
+// skipping over nodes that aren't objects, such as types. Objects
+// that are reachable are already used and aren't included.
 //
-// Dropping these objects should have no effect on correctness, but
-// may improve performance. It also helps with debugging, as it
-// greatly reduces the size of the graph.
-func isIrrelevant(obj interface{}) bool {
-	if obj, ok := obj.(types.Object); ok {
-		switch obj := obj.(type) {
-		case *types.Var:
-			if obj.IsField() {
-				// We need to track package fields
-				return false
+// The objects that a non-object node leads to are computed once per
+// strongly connected component of non-object nodes and shared by all
+// objects using them, so that types used by many objects don't get
+// walked once per object.
+func (g *graph) dependencies() []Dependency {
+	// reach maps unreachable non-object nodes to the unreachable
+	// objects they lead to without passing through other objects.
+	reach := map[*refgraph.Node][]*refgraph.Node{}
+	index := map[*refgraph.Node]int{}
+	low := map[*refgraph.Node]int{}
+	onStack := map[*refgraph.Node]bool{}
+	var stack []*refgraph.Node
+	var visit func(n *refgraph.Node)
+	visit = func(n *refgraph.Node) {
+		index[n] = len(index)
+		low[n] = index[n]
+		stack = append(stack, n)
+		onStack[n] = true
+		for _, e := range n.Uses {
+			m := e.Node
+			if _, ok := m.Obj.(types.Object); ok || m.Seen {
+				continue
 			}
-			if obj.Pkg() != nil && obj.Parent() == obj.Pkg().Scope() {
-				// We need to track package-level variables
-				return false
+			if _, ok := index[m]; !ok {
+				visit(m)
+				if low[m] < low[n] {
+					low[n] = low[m]
+				}
+			} else if onStack[m] && index[m] < low[n] {
+				low[n] = index[m]
 			}
-			return isIrrelevant(obj.Type())
-		default:
-			return false
+		}
+		if low[n] != index[n] {
+			return
+		}
+
+		// n is the root of a strongly connected component, and the
+		// components it leads to are done.
+		var scc []*refgraph.Node
+		for {
+			m := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[m] = false
+			scc = append(scc, m)
+			if m == n {
+				break
+			}
+		}
+		var objs []*refgraph.Node
+		seen := map[*refgraph.Node]struct{}{}
+		for _, m := range scc {
+			for _, e := range m.Uses {
+				if e.Node.Seen {
+					continue
+				}
+				targets := reach[e.Node]
+				if _, ok := e.Node.Obj.(types.Object); ok {
+					targets = []*refgraph.Node{e.Node}
+				}
+				for _, t := range targets {
+					if _, ok := seen[t]; !ok {
+						seen[t] = struct{}{}
+						objs = append(objs, t)
+					}
+				}
+			}
+		}
+		for _, m := range scc {
+			reach[m] = objs
 		}
 	}
-	if T, ok := obj.(types.Type); ok {
-		switch T := T.(type) {
-		case *types.Array:
-			return isIrrelevant(T.Elem())
-		case *types.Slice:
-			return isIrrelevant(T.Elem())
-		case *types.Basic:
-			return true
-		case *types.Tuple:
-			for i := 0; i < T.Len(); i++ {
-				if !isIrrelevant(T.At(i).Type()) {
-					return false
+
+	var froms []*refgraph.Node
+	for _, n := range g.Nodes {
+		if _, ok := n.Obj.(types.Object); ok && !n.Seen {
+			froms = append(froms, n)
+		}
+	}
+	sort.Slice(froms, func(i, j int) bool {
+		return froms[i].ID < froms[j].ID
+	})
+
+	var out []Dependency
+	for _, n := range froms {
+		from := n.Obj.(types.Object)
+		seen := map[*refgraph.Node]struct{}{}
+		for _, e := range n.Uses {
+			if e.Node.Seen {
+				continue
+			}
+			targets := []*refgraph.Node{e.Node}
+			if _, ok := e.Node.Obj.(types.Object); !ok {
+				if _, ok := index[e.Node]; !ok {
+					visit(e.Node)
 				}
+				targets = reach[e.Node]
 			}
-			return true
-		case *types.Signature:
-			if T.Recv() != nil {
-				return false
+			for _, t := range targets {
+				if _, ok := seen[t]; ok {
+					continue
+				}
+				seen[t] = struct{}{}
+				to := t.Obj.(types.Object)
+				if to == from {
+					continue
+				}
+				if to.Pkg() == nil || (g.mock && to.Pkg() != g.pkg.Pkg) {
+					// Uses by mocks don't count.
+					continue
+				}
+				out = append(out, Dependency{from, to})
 			}
-			for i := 0; i < T.Params().Len(); i++ {
-				if !isIrrelevant(T.Params().At(i)) {
-					return false
+		}
+	}
+	return out
+}
+
+// definedObjects returns all objects defined in the package, in a
+// deterministic order.
+func definedObjects(pkg *pkg) []types.Object {
+	var out []types.Object
+	for _, obj := range pkg.TypesInfo.Defs {
+		if obj == nil || obj.Pkg() != pkg.Pkg {
+			continue
+		}
+		out = append(out, obj)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Pos() < out[j].Pos()
+	})
+	return out
+}
+
+func results(g *graph) (used, unused, quiet []types.Object) {
+	g.Color(g.Root)
+	for _, node := range g.TypeNodes {
+		if node.Seen {
+			continue
+		}
+		switch obj := node.Obj.(type) {
+		case *types.Struct:
+			for i := 0; i < obj.NumFields(); i++ {
+				if node, ok := g.Lookup(obj.Field(i)); ok {
+					g.quiet[node] = true
+				}
+			}
+		case *types.Interface:
+			for i := 0; i < obj.NumExplicitMethods(); i++ {
+				m := obj.ExplicitMethod(i)
+				if node, ok := g.Lookup(m); ok {
+					g.quiet[node] = true
+				}
+			}
+		}
+	}
+	g.aliases = g.groupAliases()
+
+	// OPT(dh): can we find meaningful initial capacities for the used and unused slices?
+
+	for _, n := range g.Nodes {
+		if obj, ok := n.Obj.(types.Object); ok {
+			switch obj := obj.(type) {
+			case *types.Var:
+				// don't report unnamed variables (interface embedding)
+				if obj.Name() == "" && obj.IsField() {
+					g.skip(obj, "unnamed field")
+					continue
+				}
+			case types.Object:
+				if obj.Name() == "_" {
+					g.skip(obj, "blank identifier")
+					continue
 				}
 			}
-			for i := 0; i < T.Results().Len(); i++ {
-				if !isIrrelevant(T.Results().At(i)) {
-					return false
+
+			if obj.Pkg() != nil {
+				if n.Seen {
+					if g.mock && obj.Pkg() != g.pkg.Pkg {
+						g.skip(obj, "used object belongs to another package and is used by a mock")
+						continue
+					}
+					used = append(used, obj)
+				} else {
+					if obj.Pkg() != g.pkg.Pkg {
+						g.skip(obj, "unused object belongs to another package")
+						continue
+					}
+					if g.quiet[n] {
+						quiet = append(quiet, obj)
+					} else {
+						unused = append(unused, obj)
+					}
 				}
 			}
-			return true
-		case *types.Interface:
-			return T.NumMethods() == 0 && T.NumEmbeddeds() == 0
-		case *types.Pointer:
-			return isIrrelevant(T.Elem())
-		case *types.Map:
-			return isIrrelevant(T.Key()) && isIrrelevant(T.Elem())
-		case *types.Struct:
-			return T.NumFields() == 0
-		case *types.Chan:
-			return isIrrelevant(T.Elem())
-		default:
-			return false
 		}
 	}
-	return false
-}
 
-func (g *graph) see(obj interface{}) *node {
-	if isIrrelevant(obj) {
-		return nil
-	}
+	return used, unused, quiet
+}
 
-	assert(obj != nil)
+type graph struct {
+	*refgraph.Graph
 
-	if fn, ok := obj.(*types.Func); ok {
-		obj = typeparams.OriginMethod(fn)
-	}
-	if t, ok := obj.(*types.Named); ok {
-		obj = t.Origin()
-	}
+	seenTypes map[types.Type]struct{}
 
-	// add new node to graph
-	node, _ := g.node(obj)
+	// context
+	ctx     context.Context
+	pkg     *pkg
+	seenFns map[*ir.Function]struct{}
+	// Positions of uses of imported packages, per file
+	importUses map[*ast.File]map[*types.PkgName][]token.Pos
+
+	// patterns of functions that are called for their side effects
+	sideEffects []string
+	// patterns of objects that are always used
+	keep []string
+	// whether objects in generated files are always used
+	keepGenerated bool
+	// whether the generated policy is strict, see registrationsOf
+	strictGenerated bool
+	// minimum number of lines of generated files that get skimmed,
+	// or zero
+	skimGenerated int
+	// names of the files that get skimmed, see skimmedFiles
+	skimmed map[string]bool
+	// functions whose IR couldn't be built
+	failed map[*ir.Function]bool
+	// enabled rules, see config.Unused.Rules
+	rules map[string]bool
+	// variables whose initializers may have side effects
+	lowConfidence map[types.Object]bool
+	// skip events that have already been logged
+	skipped map[skipKey]struct{}
+	// package-level variables that get assigned to
+	assigned map[types.Object]bool
+	// whether exported objects need to be used by other packages
+	wholeProgram bool
+	// whether code that isn't analyzed uses the package, see
+	// config.Unused.ExternalAPI
+	externalAPI bool
+	// whether the package only exists for the tests of other
+	// packages, in whole-program mode, see isTestSupport
+	testSupport bool
+	// whether to skip the bodies of unreachable functions
+	quick bool
+	// functions whose bodies we haven't walked yet, in quick-scan
+	// mode
+	pendingFns []*ir.Function
+	// initialization expressions of package-level variables whose
+	// initializers don't call any functions
+	initializers map[types.Object]ast.Expr
+	// symbols of other packages that we link to
+	linknames []string
+	// types of other packages that we've seen in whole-program mode
+	foreignTypes map[types.Type]struct{}
+	// whether the package contains mocks, whose uses of other
+	// packages don't count in whole-program mode
+	mock bool
+	// unused constants whose removal would change the values of
+	// used constants, see enumGaps
+	gaps map[types.Object]bool
+	// functions that are only used by their own tests, see
+	// testedOnly
+	tested map[types.Object][]types.Object
+	// package-level interface assertions
+	assertions []*assertion
+	// objects that are only used by interface assertions, see
+	// assertedOnly
+	asserted map[types.Object][]*assertion
+	// underlying interfaces of unreferenced constraints, see
+	// deadConstraints
+	constraints map[*types.Interface]bool
+	// position of the reference that uses are being added for, if
+	// EdgePositions is set
+	pos token.Pos
+	// fatal paths of functions, see fatalBlocks
+	fatalPaths map[types.Object]*fatalPath
+	// objects that are only used by fatal paths, see fatalOnly
+	fatal map[types.Object]bool
+	// objects that are only used by files excluded with
+	// //go:build ignore, see ignoredOnly
+	ignoredFileUses map[types.Object]bool
+	// registrations of generated files, if the generated policy is
+	// strict, see registrationsOf
+	registrations *registrations
+	// objects that are only used by generated registrations, see
+	// generatedOnly
+	generatedUses map[types.Object]bool
+	// handling of go:generate directives, see config.Unused.GoGenerate
+	goGenerate string
+	// objects that go:generate directives mention, see useGoGenerate
+	generateMentioned map[types.Object]bool
+	// objects that ignore directives apply to
+	ignored []Ignored
+	// objects that export-to directives apply to
+	exports []Export
+	// uses returned by edge providers
+	provided []Edge
+	// runtime functions that the compiler or assembly may call, if
+	// the package is the runtime, see runtimeFuncs
+	runtimeFuncs map[string]bool
+	// unreachable fields and methods of unreachable types, which we
+	// don't report
+	quiet map[*refgraph.Node]bool
+	// unused aliases of unused types, which get reported along with
+	// the types, see groupAliases
+	aliases map[*types.TypeName][]*types.TypeName
+	// patterns of the struct types whose conversions get recorded,
+	// see recordConversion
+	conversionPatterns []string
+	conversions        []Conversion
+	// named types whose layouts matter to unsafe.Offsetof,
+	// unsafe.Sizeof or unsafe.Alignof
+	layoutSensitive map[*types.TypeName]bool
+	// unexported types whose values escape the package, if
+	// RuleEscapeAnalysis is enabled
+	escaping map[*types.TypeName]bool
+	// unexported types whose values escape the package, if
+	// RuleUnformattedMethods is enabled, see formatted
+	formatting map[*types.TypeName]bool
+	// String and Error methods that (8.7) didn't use
+	unformatted map[types.Object]bool
+	// results of implements, keyed by interface and type
+	implCache *typeutil.Map[*typeutil.Map[implResult]]
+	hasher    typeutil.Hasher
+	stats     Stats
+}
 
-	if p, ok := obj.(*types.Pointer); ok {
-		if pt, ok := g.pointers[p.Elem()]; ok {
-			// We've used graph.newPointer before we saw this pointer; add an edge that marks the two pointers as being
-			// identical
-			if p != pt {
-				g.use(p, pt, edgeSamePointer)
-				g.use(pt, p, edgeSamePointer)
-			}
-		} else {
-			g.pointers[p.Elem()] = p
-		}
-	}
+func newGraph() *graph {
+	g := &graph{
+		Graph:         refgraph.New(),
+		ctx:           context.Background(),
+		seenFns:       map[*ir.Function]struct{}{},
+		seenTypes:     map[types.Type]struct{}{},
+		importUses:    map[*ast.File]map[*types.PkgName][]token.Pos{},
+		lowConfidence: map[types.Object]bool{},
+		skipped:       map[skipKey]struct{}{},
+		assigned:      map[types.Object]bool{},
+		foreignTypes:  map[types.Type]struct{}{},
+		initializers:  map[types.Object]ast.Expr{},
+		quiet:         map[*refgraph.Node]bool{},
+	}
+	g.hasher = typeutil.MakeHasher()
+	g.implCache = &typeutil.Map[*typeutil.Map[implResult]]{}
+	g.implCache.SetHasher(g.hasher)
+	return g
+}
 
-	return node
+type constGroup struct {
+	// give the struct a size to get unique pointers
+	_ byte
 }
 
-func (g *graph) use(used, by interface{}, kind edgeKind) {
-	if isIrrelevant(used) {
-		return
+func (constGroup) String() string { return "const group" }
+
+func (g *graph) see(obj interface{}) *refgraph.Node {
+	if refgraph.IsIrrelevant(obj) {
+		if _, ok := obj.(types.Object); ok {
+			g.skip(obj, "object is irrelevant, its type is never unused")
+		}
+		return nil
 	}
+	return g.Graph.See(obj)
+}
 
-	assert(used != nil)
+func (g *graph) use(used, by interface{}, kind refgraph.EdgeKind) {
 	if obj, ok := by.(types.Object); ok && obj.Pkg() != nil {
 		if obj.Pkg() != g.pkg.Pkg {
+			g.skip(obj, "user belongs to another package")
 			return
 		}
 	}
-
-	if fn, ok := used.(*types.Func); ok {
-		used = typeparams.OriginMethod(fn)
-	}
-	if fn, ok := by.(*types.Func); ok {
-		by = typeparams.OriginMethod(fn)
-	}
-
-	if t, ok := used.(*types.Named); ok {
-		used = t.Origin()
-	}
-	if t, ok := by.(*types.Named); ok {
-		by = t.Origin()
-	}
-
-	usedNode, new := g.node(used)
-	assert(!new)
-	if by == nil {
-		g.Root.use(usedNode, kind)
-	} else {
-		byNode, new := g.node(by)
-		assert(!new)
-		byNode.use(usedNode, kind)
-	}
+	g.Graph.UseAt(used, by, kind, g.pos)
 }
 
-func (g *graph) seeAndUse(used, by interface{}, kind edgeKind) *node {
+func (g *graph) seeAndUse(used, by interface{}, kind refgraph.EdgeKind) *refgraph.Node {
 	n := g.see(used)
 	g.use(used, by, kind)
 	return n
@@ -901,6 +1997,24 @@ func (g *graph) seeAndUse(used, by interface{}, kind edgeKind) *node {
 
 func (g *graph) entry(pkg *pkg) {
 	g.pkg = pkg
+	g.skimmed = g.skimmedFiles()
+	for _, err := range pkg.IR.BuildErrors {
+		if g.failed == nil {
+			g.failed = map[*ir.Function]bool{}
+		}
+		g.failed[err.Func] = true
+	}
+	if g.rules[config.RuleEscapeAnalysis] {
+		g.escaping = g.escapingTypes()
+	}
+	if g.rules[config.RuleUnformattedMethods] {
+		if g.escaping != nil {
+			g.formatting = g.escaping
+		} else {
+			g.formatting = g.escapingTypes()
+		}
+		g.unformatted = map[types.Object]bool{}
+	}
 	scopes := map[*types.Scope]*ir.Function{}
 	for _, fn := range pkg.SrcFuncs {
 		if fn.Object() != nil {
@@ -910,6 +2024,7 @@ func (g *graph) entry(pkg *pkg) {
 	}
 
 	for _, f := range pkg.Files {
+		g.checkCancelled()
 		for _, cg := range f.Comments {
 			for _, c := range cg.List {
 				if strings.HasPrefix(c.Text, "//go:linkname ") {
@@ -919,20 +2034,25 @@ func (g *graph) entry(pkg *pkg) {
 					// only look at top-level comments.
 
 					// (1.8) packages use symbols linked via go:linkname
+					//
+					// The single-argument form makes a symbol
+					// available to other packages' linknames. The
+					// two-argument form either pushes a local
+					// definition to a remote name, or pulls a remote
+					// definition into a local declaration. Either way,
+					// the local symbol is used, and so is the remote
+					// one if it is ours.
 					fields := strings.Fields(c.Text)
+					if len(fields) != 2 && len(fields) != 3 {
+						continue
+					}
+					g.useLinkname(fields[1])
 					if len(fields) == 3 {
-						if m, ok := pkg.IR.Members[fields[1]]; ok {
-							var obj types.Object
-							switch m := m.(type) {
-							case *ir.Global:
-								obj = m.Object()
-							case *ir.Function:
-								obj = m.Object()
-							default:
-								panic(fmt.Sprintf("unhandled type: %T", m))
-							}
-							assert(obj != nil)
-							g.seeAndUse(obj, nil, edgeLinkname)
+						path, name := splitLinkname(fields[2])
+						if path == pkg.Pkg.Path() {
+							g.useLinkname(name)
+						} else if path != "" {
+							g.linknames = append(g.linknames, fields[2])
 						}
 					}
 				}
@@ -963,17 +2083,23 @@ func (g *graph) entry(pkg *pkg) {
 		case *types.Const:
 			g.see(obj)
 			fn := surroundingFunc(obj)
-			if fn == nil && obj.Exported() {
+			if fn == nil && obj.Exported() && g.exportedIsUsed(obj) {
 				// (1.4) packages use exported constants
-				g.use(obj, nil, edgeExportedConstant)
+				g.use(obj, nil, refgraph.EdgeExportedConstant)
 			}
 			g.typ(obj.Type(), nil)
-			g.seeAndUse(obj.Type(), obj, edgeType)
+			g.seeAndUse(obj.Type(), obj, refgraph.EdgeType)
+		}
+		if obj != nil && g.isKept(obj) {
+			// (1.10) packages use objects matching the keep patterns
+			// (1.11) packages use objects in generated files, if so configured
+			g.seeAndUse(obj, nil, refgraph.EdgeKeep)
 		}
 	}
 
 	// Find constants being used inside functions, find sinks in tests
 	for _, fn := range pkg.SrcFuncs {
+		g.checkCancelled()
 		if fn.Object() != nil {
 			g.see(fn.Object())
 		}
@@ -990,7 +2116,9 @@ func (g *graph) entry(pkg *pkg) {
 				}
 				switch obj := obj.(type) {
 				case *types.Const:
-					g.seeAndUse(obj, owningObject(fn), edgeUsedConstant)
+					g.pos = n.Pos()
+					g.seeAndUse(obj, owningObject(fn), refgraph.EdgeUsedConstant)
+					g.pos = token.NoPos
 				}
 			case *ast.AssignStmt:
 				for _, expr := range n.Lhs {
@@ -1003,14 +2131,14 @@ func (g *graph) entry(pkg *pkg) {
 						continue
 					}
 					path := pkg.Fset.File(obj.Pos()).Name()
-					if strings.HasSuffix(path, "_test.go") {
+					if strings.HasSuffix(path, "_test.go") && g.rules[config.RuleTestSinks] {
 						if obj.Parent() != nil && obj.Parent().Parent() != nil && obj.Parent().Parent().Parent() == nil {
 							// object's scope is the package, whose
 							// parent is the file, whose parent is nil
 
 							// (4.9) functions use package-level variables they assign to iff in tests (sinks for benchmarks)
 							// (9.7) variable _reads_ use variables, writes do not, except in tests
-							g.seeAndUse(obj, owningObject(fn), edgeTestSink)
+							g.seeAndUse(obj, owningObject(fn), refgraph.EdgeTestSink)
 						}
 					}
 				}
@@ -1025,13 +2153,14 @@ func (g *graph) entry(pkg *pkg) {
 		if !ok {
 			continue
 		}
-		g.seeAndUse(obj, nil, edgeUsedConstant)
+		g.seeAndUse(obj, nil, refgraph.EdgeUsedConstant)
 	}
 
 	var fns []*types.Func
 	var fn *types.Func
 	var stack []ast.Node
 	for _, f := range pkg.Files {
+		g.checkCancelled()
 		ast.Inspect(f, func(n ast.Node) bool {
 			if n == nil {
 				pop := stack[len(stack)-1]
@@ -1048,6 +2177,37 @@ func (g *graph) entry(pkg *pkg) {
 			}
 			stack = append(stack, n)
 			switch n := n.(type) {
+			case *ast.Ident:
+				switch obj := pkg.TypesInfo.Uses[n].(type) {
+				case *types.PkgName:
+					// Record where imports are used, so that fixes can
+					// remove imports that are no longer needed.
+					uses := g.importUses[f]
+					if uses == nil {
+						uses = map[*types.PkgName][]token.Pos{}
+						g.importUses[f] = uses
+					}
+					uses[obj] = append(uses[obj], n.Pos())
+				case *types.TypeName:
+					if isFuncAlias(obj) {
+						// (2.3) aliases of function types are used
+						// by the identifiers referring to them.
+						if fn != nil {
+							g.seeAndUse(obj, fn, refgraph.EdgeAlias)
+						} else {
+							g.seeAndUse(obj, nil, refgraph.EdgeAlias)
+						}
+					}
+				}
+			case *ast.CallExpr:
+				// (5.4) unsafe.Offsetof, unsafe.Sizeof and
+				// unsafe.Alignof use the fields of the structs
+				// whose layouts they depend on
+				if fn != nil {
+					g.unsafeLayout(n, fn)
+				} else {
+					g.unsafeLayout(n, nil)
+				}
 			case *ast.FuncDecl:
 				fn = pkg.TypesInfo.ObjectOf(n.Name).(*types.Func)
 				fns = append(fns, fn)
@@ -1055,17 +2215,19 @@ func (g *graph) entry(pkg *pkg) {
 			case *ast.GenDecl:
 				switch n.Tok {
 				case token.CONST:
+					// (10.2) if so configured, enumerations are exempt from const groups
+					enum := g.rules[config.RuleIotaEnums] && g.iotaSpecs(n) != nil
 					groups := astutil.GroupSpecs(pkg.Fset, n.Specs)
 					for _, specs := range groups {
-						if len(specs) > 1 {
+						if len(specs) > 1 && g.rules[config.RuleConstGroups] && !enum {
 							cg := &constGroup{}
 							g.see(cg)
 							for _, spec := range specs {
 								for _, name := range spec.(*ast.ValueSpec).Names {
 									obj := pkg.TypesInfo.ObjectOf(name)
 									// (10.1) const groups
-									g.seeAndUse(obj, cg, edgeConstGroup)
-									g.use(cg, obj, edgeConstGroup)
+									g.seeAndUse(obj, cg, refgraph.EdgeConstGroup)
+									g.use(cg, obj, refgraph.EdgeConstGroup)
 								}
 							}
 						}
@@ -1073,20 +2235,27 @@ func (g *graph) entry(pkg *pkg) {
 				case token.VAR:
 					for _, spec := range n.Specs {
 						v := spec.(*ast.ValueSpec)
+						if fn == nil && g.isAssertion(v) {
+							g.assertion(f, n, v)
+							continue
+						}
 						for _, name := range v.Names {
 							T := pkg.TypesInfo.TypeOf(name)
 							if fn != nil {
-								g.seeAndUse(T, fn, edgeVarDecl)
+								g.seeAndUse(T, fn, refgraph.EdgeVarDecl)
 							} else {
 								// TODO(dh): we likely want to make
 								// the type used by the variable, not
 								// the package containing the
 								// variable. But then we have to take
 								// special care of blank identifiers.
-								g.seeAndUse(T, nil, edgeVarDecl)
+								g.seeAndUse(T, nil, refgraph.EdgeVarDecl)
 							}
 							g.typ(T, nil)
 						}
+						if fn == nil {
+							g.initializer(v)
+						}
 					}
 				case token.TYPE:
 					for _, spec := range n.Specs {
@@ -1101,23 +2270,34 @@ func (g *graph) entry(pkg *pkg) {
 						obj := pkg.TypesInfo.ObjectOf(v.Name)
 						g.see(obj)
 						g.see(T)
-						g.use(T, obj, edgeType)
+						g.use(T, obj, refgraph.EdgeType)
 						g.typ(obj.Type(), nil)
 						g.typ(T, nil)
 
 						if v.Assign != 0 {
 							aliasFor := obj.(*types.TypeName).Type()
 							// (2.3) named types use all their aliases. we can't easily track uses of aliases
-							if isIrrelevant(aliasFor) {
+							if isFuncAlias(obj.(*types.TypeName)) && obj.Parent() == pkg.Pkg.Scope() && v.TypeParams == nil {
+								// (2.3) aliases of function types are used
+								// by the identifiers referring to them.
+								g.see(obj)
+							} else if refgraph.IsIrrelevant(aliasFor) {
 								// We do not track the type this is an
 								// alias for (for example builtins), so
 								// just mark the alias used.
 								//
 								// FIXME(dh): what about aliases declared inside functions?
-								g.use(obj, nil, edgeAlias)
+								g.use(obj, nil, refgraph.EdgeAlias)
+							} else if v.TypeParams != nil {
+								// Generic aliases (Go 1.24) get
+								// instantiated, and the instances
+								// don't refer to the generic type. We
+								// can't track their uses, so mark
+								// them used.
+								g.use(obj, nil, refgraph.EdgeAlias)
 							} else {
 								g.see(aliasFor)
-								g.seeAndUse(obj, aliasFor, edgeAlias)
+								g.seeAndUse(obj, aliasFor, refgraph.EdgeAlias)
 							}
 						}
 					}
@@ -1134,9 +2314,9 @@ func (g *graph) entry(pkg *pkg) {
 		case *ir.Global:
 			if m.Object() != nil {
 				g.see(m.Object())
-				if m.Object().Exported() {
+				if m.Object().Exported() && g.exportedIsUsed(m.Object()) && (g.rules[config.RuleExportedFuncVars] || !isFuncVar(m.Object())) {
 					// (1.3) packages use exported variables
-					g.use(m.Object(), nil, edgeExportedVariable)
+					g.use(m.Object(), nil, refgraph.EdgeExportedVariable)
 				}
 			}
 		case *ir.Function:
@@ -1153,17 +2333,17 @@ func (g *graph) entry(pkg *pkg) {
 				// be owned by the package.
 			}
 			// This branch catches top-level functions, not methods.
-			if m.Object() != nil && m.Object().Exported() {
+			if m.Object() != nil && m.Object().Exported() && g.exportedIsUsed(m.Object()) {
 				// (1.2) packages use exported functions
-				g.use(mObj, nil, edgeExportedFunction)
+				g.use(mObj, nil, refgraph.EdgeExportedFunction)
 			}
 			if m.Name() == "main" && pkg.Pkg.Name() == "main" {
 				// (1.7) packages use the main function iff in the main package
-				g.use(mObj, nil, edgeMainFunction)
+				g.use(mObj, nil, refgraph.EdgeMainFunction)
 			}
-			if pkg.Pkg.Path() == "runtime" && runtimeFuncs[m.Name()] {
+			if g.runtimeFuncs[m.Name()] {
 				// (9.8) runtime functions that may be called from user code via the compiler
-				g.use(mObj, nil, edgeRuntimeFunction)
+				g.use(mObj, nil, refgraph.EdgeRuntimeFunction)
 			}
 			if m.Source() != nil {
 				doc := m.Source().(*ast.FuncDecl).Doc
@@ -1171,7 +2351,7 @@ func (g *graph) entry(pkg *pkg) {
 					for _, cmt := range doc.List {
 						if strings.HasPrefix(cmt.Text, "//go:cgo_export_") {
 							// (1.6) packages use functions exported to cgo
-							g.use(mObj, nil, edgeCgoExported)
+							g.use(mObj, nil, refgraph.EdgeCgoExported)
 						}
 					}
 				}
@@ -1179,49 +2359,57 @@ func (g *graph) entry(pkg *pkg) {
 			g.function(m)
 		case *ir.Type:
 			g.see(m.Object())
-			if m.Object().Exported() {
+			if m.Object().Exported() && g.exportedIsUsed(m.Object()) {
 				// (1.1) packages use exported named types
-				g.use(m.Object(), nil, edgeExportedType)
+				g.use(m.Object(), nil, refgraph.EdgeExportedType)
 			}
 			g.typ(m.Type(), nil)
 		default:
-			panic(fmt.Sprintf("unreachable: %T", m))
+			g.fail(ErrorUnexpectedConstruct, fmt.Sprintf("%T", m), token.NoPos, "unexpected package member %s", m.Name())
 		}
 	}
 
-	// OPT(dh): can we find meaningful initial capacities for these slices?
-	var ifaces []*types.Interface
-	var notIfaces []types.Type
-
-	for t := range g.seenTypes {
-		switch t := t.(type) {
-		case *types.Interface:
-			// OPT(dh): (8.1) we only need interfaces that have unexported methods
-			ifaces = append(ifaces, t)
-		default:
-			if _, ok := t.Underlying().(*types.Interface); !ok {
-				notIfaces = append(notIfaces, t)
-			}
-		}
-	}
+	g.implementations()
 
-	// (8.0) handle interfaces
-	for _, t := range notIfaces {
-		ms := pkg.IR.Prog.MethodSets.MethodSet(t)
-		for _, iface := range ifaces {
-			if sels, ok := g.implements(t, iface, ms); ok {
-				for _, sel := range sels {
-					g.useMethod(t, sel, t, edgeImplements)
+	if isSnippets(pkg) {
+		for _, name := range pkg.Pkg.Scope().Names() {
+			obj := pkg.Pkg.Scope().Lookup(name)
+			// (1.12) packages in snippets mode use all package-level objects and methods
+			g.seeAndUse(obj, nil, refgraph.EdgeSnippets)
+			if obj, ok := obj.(*types.TypeName); ok && !obj.IsAlias() {
+				if typ, ok := obj.Type().(*types.Named); ok {
+					for i := 0; i < typ.NumMethods(); i++ {
+						g.seeAndUse(typ.Method(i), nil, refgraph.EdgeSnippets)
+					}
 				}
 			}
 		}
 	}
 
+	// Directives and objects are matched on both their raw positions and
+	// the positions adjusted by //line directives, so that an ignore
+	// directive applies to the object that follows it in either view of
+	// the file. File names are normalized so that matching is
+	// unaffected by case and path separators on platforms that don't
+	// distinguish them.
 	type ignoredKey struct {
-		file string
-		line int
+		file     string
+		line     int
+		adjusted bool
+	}
+	ignoredKeys := func(pos token.Pos, line bool) [2]ignoredKey {
+		var keys [2]ignoredKey
+		for i, adjusted := range [2]bool{false, true} {
+			position := pkg.Fset.PositionFor(pos, adjusted)
+			keys[i] = ignoredKey{report.NormalizeFilename(position.Filename), -1, adjusted}
+			if line {
+				keys[i].line = position.Line
+			}
+		}
+		return keys
 	}
-	ignores := map[ignoredKey]struct{}{}
+	// ignores maps keys to the positions of the directives' comments
+	ignores := map[ignoredKey]token.Pos{}
 	for _, dir := range pkg.Directives {
 		if dir.Command != "ignore" && dir.Command != "file-ignore" {
 			continue
@@ -1231,22 +2419,9 @@ func (g *graph) entry(pkg *pkg) {
 		}
 		for _, check := range strings.Split(dir.Arguments[0], ",") {
 			if check == "U1000" {
-				pos := pkg.Fset.PositionFor(dir.Node.Pos(), false)
-				var key ignoredKey
-				switch dir.Command {
-				case "ignore":
-					key = ignoredKey{
-						pos.Filename,
-						pos.Line,
-					}
-				case "file-ignore":
-					key = ignoredKey{
-						pos.Filename,
-						-1,
-					}
+				for _, key := range ignoredKeys(dir.Node.Pos(), dir.Command == "ignore") {
+					ignores[key] = dir.Directive.Pos()
 				}
-
-				ignores[key] = struct{}{}
 				break
 			}
 		}
@@ -1256,69 +2431,239 @@ func (g *graph) entry(pkg *pkg) {
 		// all objects annotated with a //lint:ignore U1000 are considered used
 		for obj := range g.Nodes {
 			if obj, ok := obj.(types.Object); ok {
-				pos := pkg.Fset.PositionFor(obj.Pos(), false)
-				key1 := ignoredKey{
-					pos.Filename,
-					pos.Line,
-				}
-				key2 := ignoredKey{
-					pos.Filename,
-					-1,
-				}
-				_, ok := ignores[key1]
-				if !ok {
-					_, ok = ignores[key2]
+				ok := false
+				seen := map[token.Pos]bool{}
+				for _, line := range [2]bool{true, false} {
+					for _, key := range ignoredKeys(obj.Pos(), line) {
+						if dir, found := ignores[key]; found {
+							ok = true
+							if !seen[dir] {
+								seen[dir] = true
+								g.ignored = append(g.ignored, Ignored{Object: obj, Directive: dir})
+							}
+						}
+					}
 				}
 				if ok {
-					g.use(obj, nil, edgeIgnored)
+					g.use(obj, nil, refgraph.EdgeIgnored)
 
 					// use methods and fields of ignored types
 					if obj, ok := obj.(*types.TypeName); ok {
-						if obj.IsAlias() {
-							if typ, ok := obj.Type().(*types.Named); ok && typ.Obj().Pkg() != obj.Pkg() {
-								// This is an alias of a named type in another package.
-								// Don't walk its fields or methods; we don't have to,
-								// and it breaks an assertion in graph.use because we're using an object that we haven't seen before.
-								//
-								// For aliases to types in the same package, we do want to ignore the fields and methods,
-								// because ignoring the alias should ignore the aliased type.
-								continue
-							}
-						}
-						if typ, ok := obj.Type().(*types.Named); ok {
-							for i := 0; i < typ.NumMethods(); i++ {
-								g.use(typ.Method(i), nil, edgeIgnored)
-							}
-						}
-						if typ, ok := obj.Type().Underlying().(*types.Struct); ok {
-							for i := 0; i < typ.NumFields(); i++ {
-								g.use(typ.Field(i), nil, edgeIgnored)
-							}
-						}
+						g.useMembers(obj, refgraph.EdgeIgnored)
+					}
+				}
+			}
+		}
+	}
+
+	// (1.14) objects exported to external consumers via
+	// //lint:export-to directives
+	g.exportTo(pkg)
+
+	// (1.16) objects that registered edge providers use
+	g.useProvided()
+
+	if g.rules[config.RuleDocLinks] {
+		// (1.13) packages use objects their doc comments link to
+		g.useDocLinks()
+	}
+
+	// (1.17) packages use objects that go:generate directives mention,
+	// if so configured
+	g.useGoGenerate()
+
+	if g.quick {
+		// Walking bodies may make more types and functions reachable,
+		// which in turn may need more methods for implementing
+		// interfaces.
+		for g.walkReachable() {
+			g.implementations()
+		}
+	}
+}
+
+// implementations makes all seen types use the methods they need for
+// implementing the seen interfaces.
+func (g *graph) implementations() {
+	// OPT(dh): can we find meaningful initial capacities for these slices?
+	var ifaces []*types.Interface
+	var notIfaces []types.Type
+
+	for t := range g.seenTypes {
+		if isMadeUpType(t) {
+			continue
+		}
+		switch t := t.(type) {
+		case *types.Interface:
+			// OPT(dh): (8.1) we only need interfaces that have unexported methods
+			if !g.deadConstraints()[t] {
+				ifaces = append(ifaces, t)
+			}
+		default:
+			if iface, ok := t.Underlying().(*types.Interface); !ok {
+				notIfaces = append(notIfaces, t)
+			} else if named, ok := t.(*types.Named); ok && named.Origin() != named {
+				// (8.5) We only walk the underlying types of generic
+				// origins, but types implement instantiated
+				// interfaces, such as the constraint getter[int].
+				ifaces = append(ifaces, iface)
+			}
+		}
+	}
+
+	// (8.0) handle interfaces
+	for _, t := range notIfaces {
+		ms := g.pkg.IR.Prog.MethodSets.MethodSet(t)
+		for _, iface := range ifaces {
+			if sels, ok := g.implementsCached(t, iface, ms); ok {
+				for _, sel := range sels {
+					if g.unformattedMethod(sel.Obj().(*types.Func)) {
+						// (8.7) values that are never formatted don't
+						// need their String and Error methods
+						continue
 					}
+					g.useMethod(t, sel, t, refgraph.EdgeImplements)
 				}
 			}
 		}
 	}
 }
 
-func (g *graph) useMethod(t types.Type, sel *types.Selection, by interface{}, kind edgeKind) {
+// isMadeUpType reports whether T is one of the types that the IR
+// builder makes up for range loops and multiple return values, or a
+// pointer to one. They have no methods and can't be stored in
+// interfaces, and type-keyed maps can't hash them.
+func isMadeUpType(T types.Type) bool {
+	if ptr, ok := T.(*types.Pointer); ok {
+		T = ptr.Elem()
+	}
+	switch T.(type) {
+	case *typeutil.Iterator, *types.Tuple:
+		return true
+	default:
+		return false
+	}
+}
+
+// originField returns the field at index idx of the struct type T,
+// which may be an instance of a generic type. The fields of instances
+// are distinct objects, so we use the fields of the generic origins
+// instead.
+func originField(T types.Type, idx int) *types.Var {
+	if named, ok := typeutil.Unalias(T).(*types.Named); ok {
+		T = named.Origin()
+	}
+	return typeutil.CoreType(T).(*types.Struct).Field(idx)
+}
+
+// useLinkname marks the package-level function or variable called
+// name as used by a go:linkname directive.
+func (g *graph) useLinkname(name string) {
+	m, ok := g.pkg.IR.Members[name]
+	if !ok {
+		return
+	}
+	var obj types.Object
+	switch m := m.(type) {
+	case *ir.Global:
+		obj = m.Object()
+	case *ir.Function:
+		obj = m.Object()
+	default:
+		return
+	}
+	if obj == nil {
+		g.fail(ErrorAssertion, "go:linkname directive", token.NoPos, "package member %s has no object", name)
+	}
+	g.seeAndUse(obj, nil, refgraph.EdgeLinkname)
+}
+
+// splitLinkname splits a linker symbol such as example.com/pkg.fn or
+// example.com/pkg.(*T).m into its import path and name.
+func splitLinkname(sym string) (path, name string) {
+	slash := strings.LastIndex(sym, "/")
+	dot := strings.Index(sym[slash+1:], ".")
+	if dot == -1 {
+		return "", sym
+	}
+	dot += slash + 1
+	return sym[:dot], sym[dot+1:]
+}
+
+// useMembers uses the methods and fields of the named type obj, on
+// behalf of directives that apply to it.
+func (g *graph) useMembers(obj *types.TypeName, kind refgraph.EdgeKind) {
+	if obj.IsAlias() {
+		if typ, ok := obj.Type().(*types.Named); ok && typ.Obj().Pkg() != obj.Pkg() {
+			// This is an alias of a named type in another package.
+			// Don't walk its fields or methods; we don't have to,
+			// and it breaks an assertion in graph.use because we're using an object that we haven't seen before.
+			//
+			// For aliases to types in the same package, we do want to ignore the fields and methods,
+			// because ignoring the alias should ignore the aliased type.
+			return
+		}
+	}
+	if typ, ok := obj.Type().(*types.Named); ok {
+		for i := 0; i < typ.NumMethods(); i++ {
+			g.use(typ.Method(i), nil, kind)
+		}
+	}
+	if typ, ok := obj.Type().Underlying().(*types.Struct); ok {
+		for i := 0; i < typ.NumFields(); i++ {
+			g.use(typ.Field(i), nil, kind)
+		}
+	}
+}
+
+// isSnippets reports whether the package is in snippets mode, which
+// is enabled by a //lint:package-mode snippets directive in the
+// package's documentation. Packages of snippets, such as collections
+// of examples, intentionally contain unreferenced code.
+func isSnippets(pkg *pkg) bool {
+	for _, dir := range pkg.Directives {
+		if dir.Command != "package-mode" || len(dir.Arguments) == 0 {
+			continue
+		}
+		if _, ok := dir.Node.(*ast.File); ok && dir.Arguments[0] == "snippets" {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *graph) useMethod(t types.Type, sel *types.Selection, by interface{}, kind refgraph.EdgeKind) {
 	obj := sel.Obj().(*types.Func)
 	path := sel.Index()
-	assert(obj != nil)
+	g.assert(obj != nil, "method selection", token.NoPos)
 	if len(path) > 1 {
 		base := typeutil.Dereference(t).Underlying().(*types.Struct)
 		for _, idx := range path[:len(path)-1] {
 			next := base.Field(idx)
 			// (6.3) structs use embedded fields that help implement interfaces
 			g.see(base)
-			g.seeAndUse(next, base, edgeProvidesMethod)
+			g.seeAndUse(next, base, refgraph.EdgeProvidesMethod)
 			base, _ = typeutil.Dereference(next.Type()).Underlying().(*types.Struct)
 		}
 	}
 	g.seeAndUse(obj, by, kind)
 }
 
+// isFuncAlias reports whether obj is an alias of a function type that
+// doesn't refer to any named types. Such aliases, commonly used for
+// callbacks, don't get used by any named types and have to be tracked
+// by the identifiers referring to them instead.
+func isFuncAlias(obj *types.TypeName) bool {
+	if !obj.IsAlias() {
+		return false
+	}
+	T := obj.Type()
+	if _, ok := typeutil.Unalias(T).(*types.Signature); !ok {
+		return false
+	}
+	return refgraph.IsIrrelevant(T)
+}
+
 func owningObject(fn *ir.Function) types.Object {
 	if fn.Object() != nil {
 		return fn.Object()
@@ -1330,7 +2675,7 @@ func owningObject(fn *ir.Function) types.Object {
 }
 
 func (g *graph) function(fn *ir.Function) {
-	assert(fn != nil)
+	g.assert(fn != nil, "function", token.NoPos)
 	if fn.Package() != nil && fn.Package() != g.pkg.IR {
 		return
 	}
@@ -1339,9 +2684,64 @@ func (g *graph) function(fn *ir.Function) {
 		return
 	}
 	g.seenFns[fn] = struct{}{}
+	g.checkCancelled()
 
 	// (4.1) functions use all their arguments, return parameters and receivers
-	g.signature(fn.Signature, owningObject(fn))
+	g.signature(fn.Signature, g.owner(fn))
+	if fn.Synthetic == 0 && fn.Object() != nil && g.inSkimmedFile(fn.Pos()) {
+		// (1.11) functions in skimmed files use what their bodies
+		// refer to
+		g.skim(fn)
+		return
+	}
+	if g.failed[fn] {
+		// (4.12) functions whose IR couldn't be built use what their
+		// bodies refer to
+		g.skim(fn)
+		return
+	}
+	if g.quick && owningObject(fn) != nil {
+		// We'll walk the body once we know that the function is
+		// reachable.
+		g.pendingFns = append(g.pendingFns, fn)
+		return
+	}
+	g.body(fn)
+}
+
+// walkReachable walks the bodies of all pending functions that are
+// reachable from the roots. It reports whether it walked any.
+func (g *graph) walkReachable() bool {
+	reachable := map[*refgraph.Node]struct{}{}
+	var mark func(n *refgraph.Node)
+	mark = func(n *refgraph.Node) {
+		if _, ok := reachable[n]; ok {
+			return
+		}
+		reachable[n] = struct{}{}
+		for _, e := range n.Uses {
+			mark(e.Node)
+		}
+	}
+	mark(g.Root)
+
+	pending := g.pendingFns
+	g.pendingFns = nil
+	walked := false
+	for _, fn := range pending {
+		n, ok := g.Lookup(owningObject(fn))
+		if _, isReachable := reachable[n]; ok && isReachable {
+			g.body(fn)
+			walked = true
+		} else {
+			g.pendingFns = append(g.pendingFns, fn)
+		}
+	}
+	return walked
+}
+
+// body adds the uses of a function's body to the graph.
+func (g *graph) body(fn *ir.Function) {
 	g.instructions(fn)
 	for _, anon := range fn.AnonFuncs {
 		// (4.2) functions use anonymous functions defined beneath them
@@ -1354,18 +2754,32 @@ func (g *graph) function(fn *ir.Function) {
 }
 
 func (g *graph) typ(t types.Type, parent types.Type) {
+	// Aliases are transparent, we track uses of the types they refer to.
+	t = typeutil.Unalias(t)
+	if parent != nil {
+		parent = typeutil.Unalias(parent)
+	}
 	if _, ok := g.seenTypes[t]; ok {
 		return
 	}
 
 	if t, ok := t.(*types.Named); ok && t.Obj().Pkg() != nil {
 		if t.Obj().Pkg() != g.pkg.Pkg {
+			if _, ok := g.foreignTypes[t]; !ok && g.wholeProgram {
+				// In whole-program mode, the other package needs to
+				// learn that we use its type, even though we don't
+				// analyze the type itself.
+				g.foreignTypes[t] = struct{}{}
+				g.see(t)
+				g.seeAndUse(t.Obj(), t, refgraph.EdgeTypeName)
+			}
+			g.skip(t, "type belongs to another package")
 			return
 		}
 	}
 
 	g.seenTypes[t] = struct{}{}
-	if isIrrelevant(t) {
+	if refgraph.IsIrrelevant(t) {
 		return
 	}
 
@@ -1376,15 +2790,15 @@ func (g *graph) typ(t types.Type, parent types.Type) {
 			g.see(t.Field(i))
 			if t.Field(i).Exported() {
 				// (6.2) structs use exported fields
-				g.use(t.Field(i), t, edgeExportedField)
+				g.use(t.Field(i), t, refgraph.EdgeExportedField)
 			} else if t.Field(i).Name() == "_" {
-				g.use(t.Field(i), t, edgeBlankField)
+				g.use(t.Field(i), t, refgraph.EdgeBlankField)
 			} else if isNoCopyType(t.Field(i).Type()) {
 				// (6.1) structs use fields of type NoCopy sentinel
-				g.use(t.Field(i), t, edgeNoCopySentinel)
+				g.use(t.Field(i), t, refgraph.EdgeNoCopySentinel)
 			} else if parent == nil {
 				// (11.1) anonymous struct types use all their fields.
-				g.use(t.Field(i), t, edgeAnonymousStruct)
+				g.use(t.Field(i), t, refgraph.EdgeAnonymousStruct)
 			}
 			if t.Field(i).Anonymous() {
 				// does the embedded field contribute exported methods to the method set?
@@ -1392,13 +2806,13 @@ func (g *graph) typ(t types.Type, parent types.Type) {
 				if _, ok := T.Underlying().(*types.Pointer); !ok {
 					// An embedded field is addressable, so check
 					// the pointer type to get the full method set
-					T = g.newPointer(T)
+					T = g.NewPointer(T)
 				}
 				ms := g.pkg.IR.Prog.MethodSets.MethodSet(T)
 				for j := 0; j < ms.Len(); j++ {
 					if ms.At(j).Obj().Exported() {
 						// (6.4) structs use embedded fields that have exported methods (recursively)
-						g.use(t.Field(i), t, edgeExtendsExportedMethodSet)
+						g.use(t.Field(i), t, refgraph.EdgeExtendsExportedMethodSet)
 						break
 					}
 				}
@@ -1428,7 +2842,7 @@ func (g *graph) typ(t types.Type, parent types.Type) {
 				// does the embedded field contribute exported fields?
 				if hasExportedField(t.Field(i).Type()) {
 					// (6.5) structs use embedded structs that have exported fields (recursively)
-					g.use(t.Field(i), t, edgeExtendsExportedFields)
+					g.use(t.Field(i), t, refgraph.EdgeExtendsExportedFields)
 				}
 
 			}
@@ -1439,37 +2853,43 @@ func (g *graph) typ(t types.Type, parent types.Type) {
 	case *types.Named:
 		// (9.3) types use their underlying and element types
 		origin := t.Origin()
-		g.seeAndUse(origin.Underlying(), t, edgeUnderlyingType)
-		g.seeAndUse(t.Obj(), t, edgeTypeName)
-		g.seeAndUse(t, t.Obj(), edgeNamedType)
+		g.seeAndUse(origin.Underlying(), t, refgraph.EdgeUnderlyingType)
+		g.seeAndUse(t.Obj(), t, refgraph.EdgeTypeName)
+		g.seeAndUse(t, t.Obj(), refgraph.EdgeNamedType)
 
 		// (2.4) named types use the pointer type
 		if _, ok := t.Underlying().(*types.Interface); !ok && t.NumMethods() > 0 {
-			g.seeAndUse(g.newPointer(origin), t, edgePointerType)
+			g.seeAndUse(g.NewPointer(origin), t, refgraph.EdgePointerType)
 		}
 
 		// (2.5) named types use their type parameters
 
 		for i := 0; i < t.TypeParams().Len(); i++ {
 			tparam := t.TypeParams().At(i)
-			g.seeAndUse(tparam, t, edgeTypeParam)
+			g.seeAndUse(tparam, t, refgraph.EdgeTypeParam)
 			g.typ(tparam, nil)
 		}
 
 		// (2.6) named types use their type arguments
 		for i := 0; i < t.TypeArgs().Len(); i++ {
 			targ := t.TypeArgs().At(i)
-			g.seeAndUse(targ, t, edgeTypeArg)
-			g.typ(t, nil)
+			g.seeAndUse(targ, t, refgraph.EdgeTypeArg)
+			g.typ(targ, nil)
 		}
 
 		for i := 0; i < t.NumMethods(); i++ {
 			g.see(t.Method(i))
 			// don't use trackExportedIdentifier here, we care about
 			// all exported methods, even in package main or in tests.
-			if t.Method(i).Exported() {
+			//
+			// This applies in whole-program mode, too. Types may
+			// implement interfaces declared in other packages, which
+			// we can't see, so their exported methods are kept by
+			// the types, and the driver keeps the types if other
+			// packages use them.
+			if t.Method(i).Exported() && g.methodsEscape(t.Obj()) && !g.unformattedMethod(t.Method(i)) {
 				// (2.1) named types use exported methods
-				g.use(t.Method(i), t, edgeExportedMethod)
+				g.use(t.Method(i), t, refgraph.EdgeExportedMethod)
 			}
 			g.function(g.pkg.IR.Prog.FuncValue(t.Method(i)))
 		}
@@ -1477,13 +2897,13 @@ func (g *graph) typ(t types.Type, parent types.Type) {
 		g.typ(origin.Underlying(), t)
 	case *types.Slice:
 		// (9.3) types use their underlying and element types
-		g.seeAndUse(t.Elem(), t, edgeElementType)
+		g.seeAndUse(t.Elem(), t, refgraph.EdgeElementType)
 		g.typ(t.Elem(), nil)
 	case *types.Map:
 		// (9.3) types use their underlying and element types
-		g.seeAndUse(t.Elem(), t, edgeElementType)
+		g.seeAndUse(t.Elem(), t, refgraph.EdgeElementType)
 		// (9.3) types use their underlying and element types
-		g.seeAndUse(t.Key(), t, edgeKeyType)
+		g.seeAndUse(t.Key(), t, refgraph.EdgeKeyType)
 		g.typ(t.Elem(), nil)
 		g.typ(t.Key(), nil)
 	case *types.Signature:
@@ -1492,101 +2912,184 @@ func (g *graph) typ(t types.Type, parent types.Type) {
 		for i := 0; i < t.NumMethods(); i++ {
 			m := t.Method(i)
 			// (8.3) All interface methods are marked as used
-			g.seeAndUse(m, t, edgeInterfaceMethod)
-			g.seeAndUse(m.Type().(*types.Signature), m, edgeSignature)
+			g.seeAndUse(m, t, refgraph.EdgeInterfaceMethod)
+			g.seeAndUse(m.Type().(*types.Signature), m, refgraph.EdgeSignature)
 			g.signature(m.Type().(*types.Signature), nil)
 		}
 		for i := 0; i < t.NumEmbeddeds(); i++ {
 			tt := t.EmbeddedType(i)
 			// (8.4) All embedded interfaces are marked as used
 			g.typ(tt, nil)
-			g.seeAndUse(tt, t, edgeEmbeddedInterface)
+			g.seeAndUse(tt, t, refgraph.EdgeEmbeddedInterface)
 		}
 	case *types.Array:
 		// (9.3) types use their underlying and element types
-		g.seeAndUse(t.Elem(), t, edgeElementType)
+		g.seeAndUse(t.Elem(), t, refgraph.EdgeElementType)
 		g.typ(t.Elem(), nil)
 	case *types.Pointer:
 		// (9.3) types use their underlying and element types
-		g.seeAndUse(t.Elem(), t, edgeElementType)
+		g.seeAndUse(t.Elem(), t, refgraph.EdgeElementType)
 		g.typ(t.Elem(), nil)
 	case *types.Chan:
 		// (9.3) types use their underlying and element types
-		g.seeAndUse(t.Elem(), t, edgeElementType)
+		g.seeAndUse(t.Elem(), t, refgraph.EdgeElementType)
 		g.typ(t.Elem(), nil)
 	case *types.Tuple:
 		for i := 0; i < t.Len(); i++ {
 			// (9.3) types use their underlying and element types
-			g.seeAndUse(t.At(i).Type(), t, edgeTupleElement|edgeType)
+			g.seeAndUse(t.At(i).Type(), t, refgraph.EdgeTupleElement|refgraph.EdgeType)
 			g.typ(t.At(i).Type(), nil)
 		}
 	case *typeutil.Iterator:
 		// (9.3) types use their underlying and element types
-		g.seeAndUse(t.Elem(), t, edgeElementType)
+		g.seeAndUse(t.Elem(), t, refgraph.EdgeElementType)
 		g.typ(t.Elem(), nil)
 	case *types.TypeParam:
 		// (9.3) types use their underlying and element types
 
-		g.seeAndUse(t.Obj(), t, edgeTypeName)
-		g.seeAndUse(t, t.Obj(), edgeNamedType)
-		g.seeAndUse(t.Constraint(), t, edgeElementType)
+		g.seeAndUse(t.Obj(), t, refgraph.EdgeTypeName)
+		g.seeAndUse(t, t.Obj(), refgraph.EdgeNamedType)
+		g.seeAndUse(t.Constraint(), t, refgraph.EdgeElementType)
 		g.typ(t.Constraint(), t)
 	case *types.Union:
 		for i := 0; i < t.Len(); i++ {
-			g.seeAndUse(t.Term(i).Type(), t, edgeUnionTerm)
+			g.seeAndUse(t.Term(i).Type(), t, refgraph.EdgeUnionTerm)
 			g.typ(t.Term(i).Type(), nil)
 		}
 	default:
-		panic(fmt.Sprintf("unreachable: %T", t))
+		g.fail(ErrorUnexpectedConstruct, fmt.Sprintf("%T", t), token.NoPos, "unexpected type %s", t)
 	}
 }
 
 func (g *graph) variable(v *types.Var) {
 	// (9.2) variables use their types
-	g.seeAndUse(v.Type(), v, edgeType)
+	g.seeAndUse(v.Type(), v, refgraph.EdgeType)
 	g.typ(v.Type(), nil)
 }
 
-func (g *graph) signature(sig *types.Signature, fn types.Object) {
-	var user interface{} = fn
+func (g *graph) signature(sig *types.Signature, fn interface{}) {
+	user := fn
 	if fn == nil {
 		user = sig
 		g.see(sig)
 	}
 	if sig.Recv() != nil {
-		g.seeAndUse(sig.Recv().Type(), user, edgeReceiver|edgeType)
+		g.seeAndUse(sig.Recv().Type(), user, refgraph.EdgeReceiver|refgraph.EdgeType)
 		g.typ(sig.Recv().Type(), nil)
 	}
 	for i := 0; i < sig.Params().Len(); i++ {
 		param := sig.Params().At(i)
-		g.seeAndUse(param.Type(), user, edgeFunctionArgument|edgeType)
+		g.seeAndUse(param.Type(), user, refgraph.EdgeFunctionArgument|refgraph.EdgeType)
 		g.typ(param.Type(), nil)
 	}
 	for i := 0; i < sig.Results().Len(); i++ {
 		param := sig.Results().At(i)
-		g.seeAndUse(param.Type(), user, edgeFunctionResult|edgeType)
+		g.seeAndUse(param.Type(), user, refgraph.EdgeFunctionResult|refgraph.EdgeType)
 		g.typ(param.Type(), nil)
 	}
 	for i := 0; i < sig.RecvTypeParams().Len(); i++ {
 		// We track the type parameter's constraint, not the type parameter itself.
 		// We never want to flag an unused type parameter.
 		param := sig.RecvTypeParams().At(i).Constraint()
-		g.seeAndUse(param, user, edgeFunctionArgument|edgeType)
+		g.seeAndUse(param, user, refgraph.EdgeFunctionArgument|refgraph.EdgeType)
 		g.typ(param, nil)
 	}
 	for i := 0; i < sig.TypeParams().Len(); i++ {
 		// We track the type parameter's constraint, not the type parameter itself.
 		// We never want to flag an unused type parameter.
 		param := sig.TypeParams().At(i).Constraint()
-		g.seeAndUse(param, user, edgeFunctionArgument|edgeType)
+		g.seeAndUse(param, user, refgraph.EdgeFunctionArgument|refgraph.EdgeType)
 		g.typ(param, nil)
 	}
 }
 
+// instantiation adds the uses of an instantiated generic function or
+// method, such as Map[int, T] used as a value, to by. The instance is
+// a wrapper that calls the generic function with the type arguments.
+func (g *graph) instantiation(fn *ir.Function, by interface{}) {
+	g.signature(fn.Signature, by)
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			call, ok := instr.(ir.CallInstruction)
+			if !ok {
+				continue
+			}
+			for _, targ := range call.Common().TypeArgs {
+				g.seeAndUse(targ, by, refgraph.EdgeTypeArg)
+				g.typ(targ, nil)
+			}
+			if callee := call.Common().StaticCallee(); callee != nil {
+				g.function(callee)
+			}
+		}
+	}
+}
+
+// compared marks the fields of structs in T as used by by, because
+// they get compared. If deep is set, it follows pointers, slices and
+// maps like reflect.DeepEqual does.
+func (g *graph) compared(T types.Type, by interface{}, deep bool, seen map[types.Type]struct{}) {
+	if _, ok := seen[T]; ok {
+		return
+	}
+	seen[T] = struct{}{}
+
+	switch T := typeutil.CoreType(T).(type) {
+	case *types.Struct:
+		for i := 0; i < T.NumFields(); i++ {
+			g.seeAndUse(T.Field(i), by, refgraph.EdgeComparison)
+			g.compared(T.Field(i).Type(), by, deep, seen)
+		}
+	case *types.Array:
+		g.compared(T.Elem(), by, deep, seen)
+	case *types.Pointer:
+		if deep {
+			g.compared(T.Elem(), by, deep, seen)
+		}
+	case *types.Slice:
+		if deep {
+			g.compared(T.Elem(), by, deep, seen)
+		}
+	case *types.Map:
+		if deep {
+			g.compared(T.Key(), by, deep, seen)
+			g.compared(T.Elem(), by, deep, seen)
+		}
+	}
+}
+
 func (g *graph) instructions(fn *ir.Function) {
-	fnObj := owningObject(fn)
+	// Walking the instructions may walk other functions, such as
+	// closures.
+	defer func(pos token.Pos) { g.pos = pos }(g.pos)
+	fnObj := g.owner(fn)
+	var owners map[ir.Instruction]types.Object
+	var skip map[ir.Instruction]bool
+	var fatal map[*ir.BasicBlock]bool
+	if fn.Synthetic == ir.SyntheticPackageInitializer {
+		owners = g.initializerOwners(fn)
+		skip = g.assertionInstrs(fn)
+	} else if g.rules[config.RuleFatalPaths] && fnObj != nil {
+		fatal = fatalBlocks(fn)
+	}
 	for _, b := range fn.Blocks {
 		for _, instr := range b.Instrs {
+			if skip[instr] {
+				continue
+			}
+			var user interface{} = fnObj
+			g.pos = instr.Pos()
+			if obj := owners[instr]; obj != nil {
+				user = obj
+			} else if isFatal(instr, fatal) {
+				// (4.14) fatal paths use what they refer to on behalf
+				// of the function
+				user = g.fatalPathOf(fnObj)
+			} else if g.strictGenerated && g.isRegistration(fnObj, instr.Pos()) {
+				// (1.5) generated registrations use what they refer to
+				// on behalf of the package
+				user = g.registrationsOf()
+			}
 			ops := instr.Operands(nil)
 			switch instr.(type) {
 			case *ir.Store:
@@ -1604,17 +3107,23 @@ func (g *graph) instructions(fn *ir.Function) {
 						// (9.5) instructions use their operands
 						// (4.4) functions use functions they return. we assume that someone else will call the returned function
 						if owningObject(v) != nil {
-							g.seeAndUse(owningObject(v), fnObj, edgeInstructionOperand)
+							g.seeAndUse(owningObject(v), user, refgraph.EdgeInstructionOperand)
+						}
+						if v.Synthetic == ir.SyntheticGeneric {
+							// (4.11) functions use the type arguments
+							// of the functions they instantiate
+							g.instantiation(v, user)
+						} else {
+							g.function(v)
 						}
-						g.function(v)
 					case *ir.Const:
 						// (9.6) instructions use their operands' types
-						g.seeAndUse(v.Type(), fnObj, edgeType)
+						g.seeAndUse(v.Type(), user, refgraph.EdgeType)
 						g.typ(v.Type(), nil)
 					case *ir.Global:
 						if v.Object() != nil {
 							// (9.5) instructions use their operands
-							g.seeAndUse(v.Object(), fnObj, edgeInstructionOperand)
+							g.seeAndUse(v.Object(), user, refgraph.EdgeInstructionOperand)
 						}
 					}
 				})
@@ -1625,7 +3134,7 @@ func (g *graph) instructions(fn *ir.Function) {
 
 					// (4.8) instructions use their types
 					// (9.4) conversions use the type they convert to
-					g.seeAndUse(v.Type(), fnObj, edgeType)
+					g.seeAndUse(v.Type(), user, refgraph.EdgeType)
 					g.typ(v.Type(), nil)
 				}
 			}
@@ -1633,30 +3142,46 @@ func (g *graph) instructions(fn *ir.Function) {
 			case *ir.Field:
 				// Can't access fields via generics, for now.
 
-				st := instr.X.Type().Underlying().(*types.Struct)
-				field := st.Field(instr.Field)
+				field := originField(instr.X.Type(), instr.Field)
 				// (4.7) functions use fields they access
-				g.seeAndUse(field, fnObj, edgeFieldAccess)
+				g.seeAndUse(field, user, refgraph.EdgeFieldAccess)
 			case *ir.FieldAddr:
 				// User code can't access fields on type parameters, but composite literals are still possible, which
 				// compile to FieldAddr + Store.
 
-				st := typeutil.CoreType(typeutil.Dereference(instr.X.Type())).(*types.Struct)
-				field := st.Field(instr.Field)
+				field := originField(typeutil.Dereference(instr.X.Type()), instr.Field)
 				// (4.7) functions use fields they access
-				g.seeAndUse(field, fnObj, edgeFieldAccess)
+				g.seeAndUse(field, user, refgraph.EdgeFieldAccess)
 			case *ir.Store:
-				// nothing to do, handled generically by operands
+				// reads are handled generically by operands, but we
+				// track writes to package-level variables outside of
+				// their initializers.
+				if glob, ok := instr.Addr.(*ir.Global); ok && glob.Object() != nil && fn.Synthetic != ir.SyntheticPackageInitializer {
+					g.assigned[glob.Object()] = true
+				}
 			case ir.CallInstruction:
 				c := instr.Common()
 				for _, targ := range c.TypeArgs {
-					g.seeAndUse(targ, fnObj, edgeTypeArg)
+					// (4.11) functions use the type arguments of
+					// the functions they instantiate
+					g.seeAndUse(targ, user, refgraph.EdgeTypeArg)
+					g.typ(targ, nil)
 				}
 				if !c.IsInvoke() {
 					// handled generically as an instruction operand
+					if g.rules[config.RuleComparisons] && irutil.IsCallTo(c, "reflect.DeepEqual") {
+						seen := map[types.Type]struct{}{}
+						for _, arg := range c.Args {
+							if mi, ok := arg.(*ir.MakeInterface); ok {
+								// (5.3) comparisons use the fields of
+								// the compared structs
+								g.compared(mi.X.Type(), user, true, seen)
+							}
+						}
+					}
 				} else {
 					// (4.5) functions use functions/interface methods they call
-					g.seeAndUse(c.Method, fnObj, edgeInterfaceCall)
+					g.seeAndUse(c.Method, user, refgraph.EdgeInterfaceCall)
 				}
 			case *ir.Return:
 				// nothing to do, handled generically by operands
@@ -1671,7 +3196,9 @@ func (g *graph) instructions(fn *ir.Function) {
 					// fields are also used outside of the conversion.
 					// Mark fields as used by each other.
 
-					assert(s1.NumFields() == s2.NumFields())
+					if s1.NumFields() != s2.NumFields() {
+						g.fail(ErrorUnexpectedCount, "struct conversion", instr.Pos(), "converting between structs with %d and %d fields", s1.NumFields(), s2.NumFields())
+					}
 					for i := 0; i < s1.NumFields(); i++ {
 						g.see(s1.Field(i))
 						g.see(s2.Field(i))
@@ -1679,9 +3206,10 @@ func (g *graph) instructions(fn *ir.Function) {
 						// either struct use each other. the fields are relevant for the
 						// conversion, but only if the fields are also accessed outside the
 						// conversion.
-						g.seeAndUse(s1.Field(i), s2.Field(i), edgeStructConversion)
-						g.seeAndUse(s2.Field(i), s1.Field(i), edgeStructConversion)
+						g.seeAndUse(s1.Field(i), s2.Field(i), refgraph.EdgeStructConversion)
+						g.seeAndUse(s2.Field(i), s1.Field(i), refgraph.EdgeStructConversion)
 					}
+					g.recordConversion(instr.X.Type(), instr.Type(), instr.Pos())
 				}
 			case *ir.MakeInterface:
 				// nothing to do, handled generically by operands
@@ -1696,7 +3224,7 @@ func (g *graph) instructions(fn *ir.Function) {
 						if st, ok := ptr.Elem().Underlying().(*types.Struct); ok {
 							for i := 0; i < st.NumFields(); i++ {
 								// (5.2) when converting to or from unsafe.Pointer, mark all fields as used.
-								g.seeAndUse(st.Field(i), fnObj, edgeUnsafeConversion)
+								g.seeAndUse(st.Field(i), user, refgraph.EdgeUnsafeConversion)
 							}
 						}
 					}
@@ -1707,7 +3235,7 @@ func (g *graph) instructions(fn *ir.Function) {
 						if st, ok := ptr.Elem().Underlying().(*types.Struct); ok {
 							for i := 0; i < st.NumFields(); i++ {
 								// (5.2) when converting to or from unsafe.Pointer, mark all fields as used.
-								g.seeAndUse(st.Field(i), fnObj, edgeUnsafeConversion)
+								g.seeAndUse(st.Field(i), user, refgraph.EdgeUnsafeConversion)
 							}
 						}
 					}
@@ -1724,7 +3252,11 @@ func (g *graph) instructions(fn *ir.Function) {
 			case *ir.UnOp:
 				// nothing to do
 			case *ir.BinOp:
-				// nothing to do
+				if (instr.Op == token.EQL || instr.Op == token.NEQ) && g.rules[config.RuleComparisons] {
+					// (5.3) comparisons use the fields of the
+					// compared structs
+					g.compared(instr.X.Type(), user, false, map[types.Type]struct{}{})
+				}
 			case *ir.If:
 				// nothing to do
 			case *ir.Jump:
@@ -1784,7 +3316,12 @@ func (g *graph) instructions(fn *ir.Function) {
 			case *ir.Recv:
 				// nothing to do
 			case *ir.TypeSwitch:
-				// nothing to do
+				for _, T := range instr.Conds {
+					// (4.13) functions use the types in the cases of
+					// their type switches
+					g.seeAndUse(T, user, refgraph.EdgeType)
+					g.typ(T, nil)
+				}
 			case *ir.ConstantSwitch:
 				// nothing to do
 			case *ir.SliceToArrayPointer:
@@ -1793,7 +3330,7 @@ func (g *graph) instructions(fn *ir.Function) {
 				if t, ok := typeutil.CoreType(instr.Type()).(*types.Struct); ok {
 					for i := 0; i < len(instr.Values); i++ {
 						if instr.Bitmap.Bit(i) == 1 {
-							g.seeAndUse(t.Field(i), fnObj, edgeFieldAccess)
+							g.seeAndUse(t.Field(i), user, refgraph.EdgeFieldAccess)
 						}
 					}
 				}
@@ -1,14 +1,31 @@
 package unused
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
 	"go/types"
+	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
 	"strings"
 	"testing"
 
+	"honnef.co/go/tools/config"
+	"honnef.co/go/tools/go/ir"
+	"honnef.co/go/tools/go/ir/irutil"
+	"honnef.co/go/tools/go/types/typeutil"
+
+	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/analysistest"
 	"golang.org/x/tools/go/expect"
+	"golang.org/x/tools/txtar"
 )
 
 type expectation bool
@@ -40,6 +57,7 @@ func (k key) String() string {
 
 func check(t *testing.T, res *analysistest.Result) {
 	want := map[key]expectation{}
+	wantQuiet := map[key]struct{}{}
 	files := map[string]struct{}{}
 
 	isTest := false
@@ -61,7 +79,7 @@ func check(t *testing.T, res *analysistest.Result) {
 			t.Fatal(err)
 		}
 		for _, note := range notes {
-			posn := res.Pass.Fset.PositionFor(note.Pos, false)
+			posn := res.Pass.Fset.Position(note.Pos)
 			switch note.Name {
 			case "used":
 				if !isTest {
@@ -71,6 +89,8 @@ func check(t *testing.T, res *analysistest.Result) {
 				if isTest {
 					want[key{posn.Filename, posn.Line}] = expectation(note.Args[0].(bool))
 				}
+			case "quiet":
+				wantQuiet[key{posn.Filename, posn.Line}] = struct{}{}
 			}
 		}
 	}
@@ -113,6 +133,22 @@ func check(t *testing.T, res *analysistest.Result) {
 	checkObjs(ures.Used, shouldBeUsed)
 	checkObjs(ures.Unused, shouldBeUnused)
 
+	for _, obj := range ures.Quiet {
+		posn := res.Pass.Fset.Position(obj.Pos())
+		if _, ok := files[posn.Filename]; !ok {
+			continue
+		}
+		k := key{posn.Filename, posn.Line}
+		if _, ok := wantQuiet[k]; !ok {
+			t.Errorf("unexpected quiet object %q at %s", obj, posn)
+			continue
+		}
+		delete(wantQuiet, k)
+	}
+	for key := range wantQuiet {
+		t.Errorf("did not see expected quiet object %s", key)
+	}
+
 	for key, b := range want {
 		var exp string
 		if b {
@@ -124,17 +160,1307 @@ func check(t *testing.T, res *analysistest.Result) {
 	}
 }
 
-func TestAll(t *testing.T) {
+// buildPackage type-checks package pkg, made of a file for each of
+// srcs, and builds its IR, for tests that need to run the analysis on
+// code of their own.
+func buildPackage(t *testing.T, srcs ...string) *pkg {
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for i, src := range srcs {
+		f, err := parser.ParseFile(fset, fmt.Sprintf("file%d.go", i), src, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, f)
+	}
+	irpkg, info, err := irutil.BuildPackage(&types.Config{}, fset, types.NewPackage("pkg", "pkg"), files, ir.GlobalDebug)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &pkg{
+		Fset:      fset,
+		Files:     files,
+		Pkg:       irpkg.Pkg,
+		TypesInfo: info,
+		IR:        irpkg,
+		SrcFuncs:  irpkg.Functions,
+	}
+}
+
+func TestQuickScan(t *testing.T) {
+	// Skipping the bodies of unreachable functions mustn't change
+	// which objects are used. It does change which quiet objects we
+	// find, as we never learn about the objects in dead code, but
+	// those don't get reported, anyway.
+	collect := func() map[string]bool {
+		out := map[string]bool{}
+		for _, res := range analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, testDirs(t)...) {
+			add := func(objs []types.Object, used bool) {
+				for _, obj := range objs {
+					if _, ok := obj.Type().(*types.TypeParam); ok {
+						// we don't report type parameters
+						continue
+					}
+					posn := res.Pass.Fset.Position(obj.Pos())
+					out[fmt.Sprintf("%s: %s", posn, obj)] = used
+				}
+			}
+			ures := res.Result.(Result)
+			add(ures.Used, true)
+			add(ures.Unused, false)
+		}
+		return out
+	}
+
+	full := collect()
+	QuickScan = true
+	defer func() { QuickScan = false }()
+	quick := collect()
+
+	for k, used := range full {
+		if qused, ok := quick[k]; !ok {
+			t.Errorf("quick scan didn't find %s", k)
+		} else if qused != used {
+			t.Errorf("%s: used is %t in quick scan, %t in full scan", k, qused, used)
+		}
+	}
+	for k := range quick {
+		if _, ok := full[k]; !ok {
+			t.Errorf("quick scan found extra object %s", k)
+		}
+	}
+}
+
+// testDirs returns the names of all test packages.
+func testDirs(t *testing.T) []string {
 	dirs, err := filepath.Glob(filepath.Join(analysistest.TestData(), "src", "*"))
 	if err != nil {
 		t.Fatal(err)
 	}
-	for i, dir := range dirs {
-		dirs[i] = filepath.Base(dir)
+	out := dirs[:0]
+	for _, dir := range dirs {
+		dir = filepath.Base(dir)
+		if strings.Contains(dir, ".") {
+			// stubs of third-party packages, such as github.com/google/wire
+			continue
+		}
+		if dir == "forbid" {
+			// checked by TestForbidden, as its problems belong to U1001
+			continue
+		}
+		out = append(out, dir)
 	}
+	return out
+}
 
+func TestAll(t *testing.T) {
+	dirs := testDirs(t)
 	results := analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, dirs...)
 	for _, res := range results {
 		check(t, res)
 	}
 }
+
+func TestForbidden(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), ForbiddenAnalyzer.Analyzer, "forbid")
+}
+
+// applyEdits applies edits to the file they're in and formats the
+// result. Identical edits are only applied once.
+func applyEdits(t *testing.T, fset *token.FileSet, edits []analysis.TextEdit) []byte {
+	type edit struct {
+		pos, end int
+		text     string
+	}
+
+	var name string
+	seen := map[edit]struct{}{}
+	var sorted []edit
+	for _, e := range edits {
+		file := fset.File(e.Pos)
+		name = file.Name()
+		ed := edit{file.Offset(e.Pos), file.Offset(e.End), string(e.NewText)}
+		if _, ok := seen[ed]; ok {
+			continue
+		}
+		seen[ed] = struct{}{}
+		sorted = append(sorted, ed)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].pos > sorted[j].pos
+	})
+
+	src, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, e := range sorted {
+		if i > 0 && e.end > sorted[i-1].pos {
+			t.Fatalf("%s: overlapping edits at offsets %d and %d", name, e.pos, sorted[i-1].pos)
+		}
+		src = append(src[:e.pos:e.pos], append([]byte(e.text), src[e.end:]...)...)
+	}
+	out, err := format.Source(src)
+	if err != nil {
+		t.Fatalf("%s: fixed file doesn't parse: %s\n%s", name, err, src)
+	}
+	return out
+}
+
+func TestFixes(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "fixes", "enums")
+	for _, res := range results {
+		// Apply the fixes of all unused objects at once. Fixes of
+		// different objects may contain identical edits, for example
+		// when deleting a type as well as one of its methods.
+		edits := map[string][]analysis.TextEdit{}
+		for _, fixes := range res.Result.(Result).Fixes {
+			for _, fix := range fixes {
+				for _, e := range fix.TextEdits {
+					name := res.Pass.Fset.File(e.Pos).Name()
+					edits[name] = append(edits[name], e)
+				}
+			}
+		}
+
+		for name, fileEdits := range edits {
+			got := applyEdits(t, res.Pass.Fset, fileEdits)
+			want, err := os.ReadFile(name + ".golden")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s: got\n%s\nwant\n%s", name, got, want)
+			}
+		}
+	}
+}
+
+func TestUnexport(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "unexport")
+	for _, res := range results {
+		edits := map[string][]analysis.TextEdit{}
+		for _, fix := range res.Result.(Result).Unexports {
+			for _, e := range fix.TextEdits {
+				name := res.Pass.Fset.File(e.Pos).Name()
+				edits[name] = append(edits[name], e)
+			}
+		}
+		if len(edits) == 0 {
+			t.Error("got no fixes")
+		}
+		for name, fileEdits := range edits {
+			got := applyEdits(t, res.Pass.Fset, fileEdits)
+			want, err := os.ReadFile(name + ".golden")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s: got\n%s\nwant\n%s", name, got, want)
+			}
+		}
+	}
+
+	for name, want := range map[string]string{"HTTPClient": "httpClient", "ID": "id", "URLs": "urls", "Foo": "foo", "FOO": "foo", "X": "x"} {
+		if got := unexportedName(name); got != want {
+			t.Errorf("unexportedName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestTested(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "tested")
+	for _, res := range results {
+		ures := res.Result.(Result)
+		isTest := false
+		for _, f := range res.Pass.Files {
+			if strings.HasSuffix(res.Pass.Fset.Position(f.Pos()).Filename, "_test.go") {
+				isTest = true
+			}
+		}
+		if !isTest {
+			// Without its tests, parse is merely unused.
+			if len(ures.Tested) != 0 {
+				t.Errorf("got tested functions %v without tests", ures.Tested)
+			}
+			continue
+		}
+
+		got := map[string][]string{}
+		for obj, tests := range ures.Tested {
+			for _, test := range tests {
+				got[obj.Name()] = append(got[obj.Name()], test.Name())
+			}
+		}
+		want := map[string][]string{"parse": {"TestParse", "BenchmarkParse"}}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got tested functions %v, want %v", got, want)
+		}
+
+		edits := map[string][]analysis.TextEdit{}
+		for obj, fixes := range ures.Fixes {
+			if obj.Name() != "parse" {
+				continue
+			}
+			for _, fix := range fixes {
+				for _, e := range fix.TextEdits {
+					name := res.Pass.Fset.File(e.Pos).Name()
+					edits[name] = append(edits[name], e)
+				}
+			}
+		}
+		if len(edits) != 2 {
+			t.Fatalf("got edits in %d files, want 2", len(edits))
+		}
+		for name, fileEdits := range edits {
+			got := applyEdits(t, res.Pass.Fset, fileEdits)
+			want, err := os.ReadFile(name + ".golden")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s: got\n%s\nwant\n%s", name, got, want)
+			}
+		}
+	}
+}
+
+func TestNameFixes(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "receivers", "results")
+	for _, res := range results {
+		// Alternative fixes are grouped by their messages, and each
+		// group is compared to a section of the golden file.
+		edits := map[string][]analysis.TextEdit{}
+		var name string
+		for _, diag := range res.Diagnostics {
+			for _, fix := range diag.SuggestedFixes {
+				edits[fix.Message] = append(edits[fix.Message], fix.TextEdits...)
+				name = res.Pass.Fset.File(diag.Pos).Name()
+			}
+		}
+
+		ar, err := txtar.ParseFile(name + ".golden")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ar.Files) != len(edits) {
+			t.Errorf("got %d fixes, but golden file has %d sections", len(edits), len(ar.Files))
+		}
+		for _, section := range ar.Files {
+			fixEdits, ok := edits[section.Name]
+			if !ok {
+				t.Errorf("no fix for section %q", section.Name)
+				continue
+			}
+			got := applyEdits(t, res.Pass.Fset, fixEdits)
+			if !bytes.Equal(got, section.Data) {
+				t.Errorf("%s: got\n%s\nwant\n%s", section.Name, got, section.Data)
+			}
+		}
+	}
+}
+
+func TestLowConfidence(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "side-effects")
+	for _, res := range results {
+		var got []string
+		for obj := range res.Result.(Result).LowConfidence {
+			got = append(got, obj.Name())
+		}
+		sort.Strings(got)
+		want := []string{"v2", "v6", "v7", "v8"}
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("got low confidence objects %v, want %v", got, want)
+		}
+	}
+}
+
+func TestCategories(t *testing.T) {
+	tests := map[string]map[string]Category{
+		"error-sentinels": {
+			"errUnused":  CategoryError,
+			"errUnused2": CategoryError,
+			"errPtr":     CategoryError,
+			"valueError": CategoryError,
+		},
+		"assigned": {
+			"assignedInInit":    CategoryAssigned,
+			"assignedInFn":      CategoryAssigned,
+			"assignedInClosure": CategoryAssigned,
+		},
+		"initializers": {
+			"a":     CategoryInitializer,
+			"c":     CategoryInitializer,
+			"entry": CategoryInitializer,
+			"d":     CategoryInitializer,
+		},
+		"enums": {
+			"kindNone": CategoryEnum,
+			"kindB":    CategoryEnum,
+			"flagA":    CategoryEnum,
+		},
+	}
+	for dir, want := range tests {
+		results := analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, dir)
+		for _, res := range results {
+			got := map[string]Category{}
+			for obj, cat := range res.Result.(Result).Categories {
+				got[obj.Name()] = cat
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: got categories %v, want %v", dir, got, want)
+			}
+			for obj := range res.Result.(Result).Fixes {
+				if cat := got[obj.Name()]; cat == CategoryAssigned || cat == CategoryInitializer {
+					t.Errorf("%s: unexpected fix for %s", dir, obj)
+				}
+			}
+		}
+	}
+}
+
+func TestCgo(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "cgo-c")
+	for _, res := range results {
+		sres := Serialize(res.Pass, res.Result.(Result), res.Pass.Fset)
+		var got []string
+		for _, obj := range sres.Unused {
+			if obj.InGenerated {
+				t.Errorf("%s shouldn't be considered generated", obj.Name)
+			}
+			if len(obj.Fixes) != 0 {
+				t.Errorf("%s shouldn't have fixes", obj.Name)
+			}
+			got = append(got, obj.Name)
+		}
+		sort.Strings(got)
+		want := []string{"cType", "free", "unused"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got unused objects %v, want %v", got, want)
+		}
+	}
+}
+
+func TestLinknames(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "linkname")
+	got := results[0].Result.(Result).Linknames
+	want := []string{"example.com/other.fn", "example.com/other.(*T).m"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got linknames %q, want %q", got, want)
+	}
+}
+
+func TestSkipLog(t *testing.T) {
+	buf := &bytes.Buffer{}
+	SkipLog = buf
+	defer func() { SkipLog = nil }()
+
+	analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "blank")
+
+	var found bool
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var ev SkipEvent
+		if err := json.Unmarshal(line, &ev); err != nil {
+			t.Fatalf("couldn't parse %q: %s", line, err)
+		}
+		if ev.Package == "blank" && ev.Reason == "blank identifier" && strings.HasSuffix(ev.Position, "blank.go:30:6") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("didn't find event for func _ in skip log:\n%s", buf.Bytes())
+	}
+}
+
+func TestWholeProgram(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "wholeprogram/user", "wholeprogram/mock")
+	foreign := map[string][]string{}
+	for _, res := range results {
+		check(t, res)
+		for _, obj := range res.Result.(Result).Used {
+			if obj.Pkg() != res.Pass.Pkg {
+				foreign[res.Pass.Pkg.Path()] = append(foreign[res.Pass.Pkg.Path()], obj.Name())
+			}
+		}
+	}
+
+	var found bool
+	for _, name := range foreign["wholeprogram/user"] {
+		if name == "Helper" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("wholeprogram/user doesn't report using Helper, only %v", foreign["wholeprogram/user"])
+	}
+	if objs := foreign["wholeprogram/mock"]; len(objs) != 0 {
+		t.Errorf("mock package reports using %v", objs)
+	}
+
+	// Unused may still be used by other packages, in which case
+	// Indirect is used, too. Drivers need to know.
+	found = false
+	for _, res := range results {
+		if res.Pass.Pkg.Path() != "wholeprogram/user" {
+			continue
+		}
+		for _, dep := range res.Result.(Result).Dependencies {
+			if dep.From.Name() == "Unused" && dep.To.Name() == "Indirect" {
+				found = true
+			}
+		}
+	}
+	if !found {
+		t.Error("wholeprogram/user doesn't report that Unused uses Indirect")
+	}
+
+	// Methods that implement interfaces of other packages are kept
+	// by their types, which other packages may use.
+	found = false
+	for _, res := range analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "wholeprogram") {
+		for _, dep := range res.Result.(Result).Dependencies {
+			if dep.From.Name() == "Speaker" && dep.To.Name() == "Say" {
+				found = true
+			}
+		}
+	}
+	if !found {
+		t.Error("wholeprogram doesn't report that Speaker uses Say")
+	}
+	found = false
+	for _, name := range foreign["wholeprogram/user"] {
+		if name == "Speaker" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("wholeprogram/user doesn't report using Speaker, only %v", foreign["wholeprogram/user"])
+	}
+}
+
+func TestDisplayPosition(t *testing.T) {
+	const src = `package pkg
+
+//line parser.y:10
+var yyVal int
+
+//line other.go:20
+var yyOther int
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "parser.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pos := func(name string) token.Pos {
+		return f.Scope.Lookup(name).Pos()
+	}
+	tests := []struct {
+		name string
+		mode string
+		want string
+	}{
+		{"yyVal", config.PositionsDisplay, "parser.go:4:5"},
+		{"yyVal", config.PositionsRaw, "parser.go:4:5"},
+		{"yyVal", config.PositionsAdjusted, "parser.y:10"},
+		{"yyOther", config.PositionsDisplay, "other.go:20"},
+		{"yyOther", config.PositionsRaw, "parser.go:7:5"},
+		{"yyOther", config.PositionsAdjusted, "other.go:20"},
+	}
+	for _, tt := range tests {
+		if got := displayPosition(fset, pos(tt.name), tt.mode).String(); got != tt.want {
+			t.Errorf("position of %s in mode %q: got %s, want %s", tt.name, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestVerifyFixes(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "verify")
+	for _, res := range results {
+		r := res.Result.(Result)
+		fixes := map[string]bool{}
+		for obj := range r.Fixes {
+			fixes[obj.Name()] = true
+		}
+		errs := map[string]string{}
+		for obj, err := range r.FixErrors {
+			errs[obj.Name()] = err
+		}
+
+		// Deleting b on its own breaks a. The type checker's message
+		// differs between Go versions, but always names b.
+		if fixes["b"] {
+			t.Error("fix for b should've been dropped")
+		}
+		if !regexp.MustCompile(`\bb\b`).MatchString(errs["b"]) {
+			t.Errorf("got error %q for b, want one that mentions b", errs["b"])
+		}
+		// Deleting c also deletes the import of fmt.
+		for _, name := range []string{"a", "c"} {
+			if !fixes[name] {
+				t.Errorf("fix for %s shouldn't have been dropped, got error %q", name, errs[name])
+			}
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "fixes")
+	for _, res := range results {
+		ures := res.Result.(Result)
+		if len(ures.Unused) == 0 {
+			t.Fatal("expected unused objects")
+		}
+
+		funcs := ures.FilterKind("func")
+		for _, obj := range funcs.Unused {
+			if _, ok := obj.(*types.Func); !ok {
+				t.Errorf("FilterKind(\"func\") kept %s", obj)
+			}
+		}
+		for obj := range funcs.Fixes {
+			if _, ok := obj.(*types.Func); !ok {
+				t.Errorf("FilterKind(\"func\") kept the fix of %s", obj)
+			}
+		}
+		if len(funcs.Used) != len(ures.Used) {
+			t.Errorf("FilterKind dropped used objects")
+		}
+
+		for _, globs := range [][]string{{"fixes.go"}, {"fix*.go"}, {"src/fixes/*.go"}} {
+			excluded, err := ures.Exclude(res.Pass.Fset, globs...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(excluded.Unused) != 0 || len(excluded.Fixes) != 0 {
+				t.Errorf("Exclude(%q) kept %d unused objects", globs, len(excluded.Unused))
+			}
+		}
+		for _, globs := range [][]string{{"other.go"}, {"other/fixes.go"}, {"/fixes/*.go"}} {
+			excluded, err := ures.Exclude(res.Pass.Fset, globs...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(excluded.Unused) != len(ures.Unused) {
+				t.Errorf("Exclude(%q) dropped unused objects", globs)
+			}
+		}
+		if _, err := ures.Exclude(res.Pass.Fset, "[x"); err == nil {
+			t.Errorf("expected an error for an invalid glob")
+		}
+	}
+}
+
+func TestFilterFields(t *testing.T) {
+	tpkg := types.NewPackage("pkg", "pkg")
+	fn := types.NewFunc(token.NoPos, tpkg, "fn", types.NewSignature(nil, nil, nil, false))
+	tname := types.NewTypeName(token.NoPos, tpkg, "t", nil)
+	alias := types.NewTypeName(token.NoPos, tpkg, "a", nil)
+	field := types.NewField(token.NoPos, tpkg, "f", types.Typ[types.Int], false)
+	res := Result{
+		Used:          []types.Object{types.NewVar(token.NoPos, tpkg, "v", types.Typ[types.Int])},
+		Unused:        []types.Object{fn, tname},
+		Quiet:         []types.Object{alias, field},
+		Aliases:       map[*types.TypeName][]*types.TypeName{tname: {alias}},
+		Fixes:         map[types.Object][]analysis.SuggestedFix{fn: {{Message: "delete"}}},
+		Unexports:     map[types.Object]analysis.SuggestedFix{fn: {Message: "unexport"}},
+		FixErrors:     map[types.Object]string{fn: "error"},
+		LowConfidence: map[types.Object]bool{fn: true},
+		Categories:    map[types.Object]Category{fn: CategoryGoGenerate},
+		Tested:        map[types.Object][]types.Object{fn: {fn}},
+		Duplicates:    map[types.Object]types.Object{fn: fn},
+		Metrics:       map[types.Object]FuncMetrics{fn: {Complexity: 1}},
+		Clusters:      []Cluster{{Objects: []types.Object{fn}, Decls: 1, Lines: 1}},
+		Forbidden:     []ForbiddenReference{{Site: fn, Target: fn}},
+		Routes:        []Route{{Handler: fn, Pattern: "/"}},
+	}
+	// The fields that Filter filters. All other fields have to be kept
+	// as they are, and have to be listed in unfiltered, so that new
+	// fields don't go unnoticed.
+	filtered := map[string]bool{
+		"Unused": true, "Quiet": true, "Aliases": true, "Fixes": true, "Unexports": true,
+		"FixErrors": true, "LowConfidence": true, "Categories": true, "Tested": true,
+		"Duplicates": true, "Metrics": true, "Clusters": true, "Forbidden": true, "Routes": true,
+	}
+	unfiltered := map[string]bool{
+		"Used": true, "Skipped": true, "Errors": true, "LayoutSensitive": true, "Ignored": true,
+		"Exports": true, "References": true, "Declarations": true, "Reasons": true,
+		"Conversions": true, "Linknames": true, "Dependencies": true, "Profile": true,
+		"Rules": true, "Root": true, "Graph": true, "Stats": true,
+	}
+
+	out := res.Filter(func(types.Object) bool { return false })
+	in, got := reflect.ValueOf(res), reflect.ValueOf(out)
+	for i := 0; i < in.NumField(); i++ {
+		name := in.Type().Field(i).Name
+		switch {
+		case filtered[name]:
+			if in.Field(i).Len() == 0 {
+				t.Errorf("field %s isn't set in the test's result", name)
+			}
+			if got.Field(i).Len() != 0 {
+				t.Errorf("Filter didn't filter field %s", name)
+			}
+		case unfiltered[name]:
+			if !reflect.DeepEqual(in.Field(i).Interface(), got.Field(i).Interface()) {
+				t.Errorf("Filter changed field %s", name)
+			}
+		default:
+			t.Errorf("field %s is neither filtered nor listed as unfiltered", name)
+		}
+	}
+
+	if out := res.Filter(func(types.Object) bool { return true }); !reflect.DeepEqual(out, res) {
+		t.Errorf("Filter changed the result when keeping all objects")
+	}
+}
+
+func TestIgnored(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "ignored")
+	for _, res := range results {
+		got := map[string]bool{}
+		for _, ig := range res.Result.(Result).Ignored {
+			if !ig.Directive.IsValid() {
+				t.Errorf("no directive position for %s", ig.Object)
+			}
+			got[ig.Object.Name()] = ig.KeptAlive
+		}
+		want := map[string]bool{
+			"t1":         true,
+			"fn":         true,
+			"usedAnyway": false,
+		}
+		for name, kept := range want {
+			if g, ok := got[name]; !ok {
+				t.Errorf("%s isn't reported as ignored", name)
+			} else if g != kept {
+				t.Errorf("%s: got KeptAlive = %t, want %t", name, g, kept)
+			}
+		}
+	}
+}
+
+func TestExports(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "exportto")
+	var got []string
+	for _, exp := range results[0].Result.(Result).Exports {
+		if !exp.Directive.IsValid() {
+			t.Errorf("no directive position for %s", exp.Object)
+		}
+		got = append(got, exp.Object.Name()+" "+exp.Consumer+": "+exp.Reason)
+	}
+	want := []string{
+		"handler billing: called via RPC reflection by the billing service",
+		"plugin plugins: ",
+		"tmplFuncs templates: ",
+		"a config: ",
+		"b config: ",
+		"field encoding/json: populated by reflection",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got exports %q, want %q", got, want)
+	}
+}
+
+func TestCanRename(t *testing.T) {
+	tests := []struct {
+		pkg     string
+		obj     string
+		reasons []string
+	}{
+		{"linkname", "foo", []string{"it is the target of a go:linkname directive"}},
+		{"linkname", "baz", nil},
+		{"cgo", "foo", []string{"it is exported to C via cgo"}},
+		{"cgo", "bar", nil},
+		{"exportto", "handler", []string{"it is exported to billing: called via RPC reflection by the billing service"}},
+		{"exportto", "plugin", []string{"it is exported to plugins"}},
+		{"exportto", "unrelated", nil},
+	}
+	results := map[string]Result{}
+	for _, tt := range tests {
+		res, ok := results[tt.pkg]
+		if !ok {
+			res = analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, tt.pkg)[0].Result.(Result)
+			results[tt.pkg] = res
+		}
+		var obj types.Object
+		for _, o := range append(append([]types.Object(nil), res.Used...), res.Unused...) {
+			if o.Name() == tt.obj && o.Parent() == o.Pkg().Scope() {
+				obj = o
+			}
+		}
+		if obj == nil {
+			t.Fatalf("%s: no object %s", tt.pkg, tt.obj)
+		}
+		ok, reasons := res.CanRename(obj)
+		if ok != (len(tt.reasons) == 0) || !reflect.DeepEqual(reasons, tt.reasons) {
+			t.Errorf("%s: CanRename(%s) = %t, %q, want reasons %q", tt.pkg, tt.obj, ok, reasons, tt.reasons)
+		}
+	}
+	// Methods are only covered by the directive of their type.
+	res := results["exportto"]
+	for _, obj := range res.Used {
+		if obj.Name() != "run" {
+			continue
+		}
+		want := []string{"it is a member of a type that a //lint:export-to directive applies to"}
+		if ok, reasons := res.CanRename(obj); ok || !reflect.DeepEqual(reasons, want) {
+			t.Errorf("CanRename(run) = %t, %q, want false, %q", ok, reasons, want)
+		}
+	}
+}
+
+func TestReferences(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "impact")
+	for _, res := range results {
+		if res.Pass.Pkg.Path() != "impact" {
+			continue
+		}
+		var got []string
+		for _, ref := range res.Result.(Result).References {
+			from := "<nil>"
+			if ref.From != nil {
+				from = ref.From.Name()
+			}
+			pos := res.Pass.Fset.Position(ref.Pos)
+			got = append(got, fmt.Sprintf("%d: %s from %s", pos.Line, ref.Target.Name(), from))
+		}
+		want := []string{
+			"6: OldFunc from Caller",
+			"9: Method from Caller",
+			"10: Field from Caller",
+			"14: OldConst from dead",
+			"17: OldFunc from <nil>",
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got references %q, want %q", got, want)
+		}
+	}
+}
+
+func TestImplementsCache(t *testing.T) {
+	// The ranges package has interfaces as well as range loops, for
+	// which the IR builder makes up iterator types that the cache
+	// can't hash.
+	for _, res := range analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "interfaces", "ranges") {
+		check(t, res)
+		if stats := res.Result.(Result).Stats; stats.ImplementsChecks == 0 {
+			t.Errorf("%s: got no implements checks", res.Pass.Pkg.Path())
+		}
+	}
+
+	const src = `package pkg
+
+type T struct{}
+
+func (T) m()  {}
+func (*T) n() {}
+
+type I interface{ m() }
+type J interface{ n() }
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "pkg.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := (&types.Config{}).Check("pkg", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lookup := func(name string) types.Type { return pkg.Scope().Lookup(name).Type() }
+	T, ptrT := lookup("T"), types.NewPointer(lookup("T"))
+	I, J := lookup("I").Underlying().(*types.Interface), lookup("J").Underlying().(*types.Interface)
+	iter := typeutil.NewIterator(types.NewTuple())
+
+	g := newGraph()
+	for _, c := range []struct {
+		V     types.Type
+		iface *types.Interface
+		want  bool
+	}{
+		{T, I, true},
+		// *T implements I because T does
+		{ptrT, I, true},
+		{ptrT, J, true},
+		{T, J, false},
+		{T, I, true},
+		// iterators and unnamed types aren't memoized
+		{iter, I, false},
+		{types.NewPointer(iter), I, false},
+		{iter, I, false},
+		{types.NewStruct(nil, nil), I, false},
+		{types.NewStruct(nil, nil), I, false},
+	} {
+		if _, ok := g.implementsCached(c.V, c.iface, types.NewMethodSet(c.V)); ok != c.want {
+			t.Errorf("%s implements %s: got %t, want %t", c.V, c.iface, ok, c.want)
+		}
+	}
+	if g.stats.ImplementsChecks != 10 || g.stats.ImplementsCacheHits != 2 {
+		t.Errorf("got %d cache hits for %d checks, want 2 for 10", g.stats.ImplementsCacheHits, g.stats.ImplementsChecks)
+	}
+}
+
+func TestAssertions(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "assertions")
+	for _, res := range results {
+		ures := res.Result.(Result)
+		got := map[string]Category{}
+		for obj, cat := range ures.Categories {
+			got[obj.Name()] = cat
+		}
+		want := map[string]Category{
+			"reader": CategoryAsserted,
+			"Read":   CategoryAsserted,
+			"closer": CategoryAsserted,
+			"Close":  CategoryAsserted,
+			"iface":  CategoryAsserted,
+			"impl":   CategoryAsserted,
+			"m":      CategoryAsserted,
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got categories %v, want %v", got, want)
+		}
+
+		var edits []analysis.TextEdit
+		var name string
+		for obj, fixes := range ures.Fixes {
+			if obj.Name() != "reader" {
+				continue
+			}
+			for _, fix := range fixes {
+				edits = append(edits, fix.TextEdits...)
+			}
+			name = res.Pass.Fset.File(obj.Pos()).Name()
+		}
+		if len(edits) == 0 {
+			t.Fatal("no fix for reader")
+		}
+		got2 := applyEdits(t, res.Pass.Fset, edits)
+		want2, err := os.ReadFile(name + ".golden")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got2, want2) {
+			t.Errorf("%s: got\n%s\nwant\n%s", name, got2, want2)
+		}
+	}
+}
+
+func TestFatalPaths(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "fatal")
+	for _, res := range results {
+		ures := res.Result.(Result)
+		got := map[string]Category{}
+		for obj, cat := range ures.Categories {
+			got[obj.Name()] = cat
+		}
+		want := map[string]Category{
+			"describe":   CategoryFatal,
+			"legacyDump": CategoryFatal,
+			"dump":       CategoryFatal,
+			"write":      CategoryFatal,
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got categories %v, want %v", got, want)
+		}
+		for obj := range ures.Categories {
+			if len(ures.Fixes[obj]) > 0 {
+				t.Errorf("got fixes for %s, want none", obj.Name())
+			}
+		}
+	}
+}
+
+func TestUnformattedMethods(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "unformatted")
+	for _, res := range results {
+		ures := res.Result.(Result)
+		var got []string
+		for obj, cat := range ures.Categories {
+			if cat == CategoryUnformatted {
+				got = append(got, obj.Name())
+			}
+		}
+		if len(got) != 2 || got[0] != "String" || got[1] != "String" {
+			t.Errorf("got unformatted methods %v, want the String methods of state and node", got)
+		}
+	}
+}
+
+func TestMarkers(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "markers")
+	for _, res := range results {
+		ures := res.Result.(Result)
+		got := map[string]Category{}
+		for obj, cat := range ures.Categories {
+			got[obj.Name()] = cat
+		}
+		want := map[string]Category{
+			"noCompare": CategoryMarker,
+			"noCopy":    CategoryMarker,
+			"Lock":      CategoryMarker,
+			"Unlock":    CategoryMarker,
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got categories %v, want %v", got, want)
+		}
+	}
+}
+
+func TestResolvedRules(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "fatal")
+	res := results[0].Result.(Result)
+	if res.Profile != config.ProfileDefault {
+		t.Errorf("got profile %q, want %q", res.Profile, config.ProfileDefault)
+	}
+	if !res.Rules[config.RuleFatalPaths] || !res.Rules[config.RuleConstGroups] {
+		t.Errorf("got rules %v, want the default ones plus %s", res.Rules, config.RuleFatalPaths)
+	}
+}
+
+func TestEdgePositions(t *testing.T) {
+	EdgePositions = true
+	defer func() { EdgePositions = false }()
+	results := analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "positions")
+	res := results[0].Result.(Result)
+	fset := results[0].Pass.Fset
+	for name, want := range map[string][]int{"helper": {0, 8}, "limit": {0, 8, 5}} {
+		obj := results[0].Pass.Pkg.Scope().Lookup(name)
+		var got []int
+		for _, step := range res.Graph.WhyUsed(obj) {
+			line := 0
+			if step.Pos.IsValid() {
+				line = fset.Position(step.Pos).Line
+			}
+			got = append(got, line)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got lines %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestIgnoredFiles(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "ignoredfiles")
+	for _, res := range results {
+		ures := res.Result.(Result)
+		got := map[string]Category{}
+		for obj, cat := range ures.Categories {
+			got[obj.Name()] = cat
+		}
+		want := map[string]Category{
+			"config": CategoryIgnoredFiles,
+			"helper": CategoryIgnoredFiles,
+			"step":   CategoryIgnoredFiles,
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got categories %v, want %v", got, want)
+		}
+		for obj := range ures.Categories {
+			if len(ures.Fixes[obj]) > 0 {
+				t.Errorf("got fixes for %s, want none", obj.Name())
+			}
+		}
+	}
+}
+
+func TestGeneratedRegistrations(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "registration")
+	for _, res := range results {
+		ures := res.Result.(Result)
+		got := map[string]Category{}
+		for obj, cat := range ures.Categories {
+			got[obj.Name()] = cat
+		}
+		want := map[string]Category{
+			"foo":    CategoryGenerated,
+			"newFoo": CategoryGenerated,
+			"bar":    CategoryGenerated,
+			"newBar": CategoryGenerated,
+			"serve":  CategoryGenerated,
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got categories %v, want %v", got, want)
+		}
+		for obj := range ures.Categories {
+			if len(ures.Fixes[obj]) > 0 {
+				t.Errorf("got fixes for %s, want none", obj.Name())
+			}
+		}
+	}
+}
+
+func TestGoGenerate(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "gogenerate")
+	for _, res := range results {
+		ures := res.Result.(Result)
+		got := map[string]Category{}
+		for obj, cat := range ures.Categories {
+			got[obj.Name()] = cat
+		}
+		want := map[string]Category{
+			"fooImpl": CategoryGoGenerate,
+			"kind":    CategoryGoGenerate,
+			"color":   CategoryGoGenerate,
+			"store":   CategoryGoGenerate,
+			"state":   CategoryGoGenerate,
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got categories %v, want %v", got, want)
+		}
+		for obj := range ures.Categories {
+			if len(ures.Fixes[obj]) > 0 {
+				t.Errorf("got fixes for %s, want none", obj.Name())
+			}
+		}
+	}
+}
+
+func TestDeclarations(t *testing.T) {
+	const src = `package pkg
+
+// T is a type.
+type T struct {
+	a int
+	b struct {
+		c string
+	}
+	*T
+}
+
+func (T) m(x int) {}
+
+type I interface {
+	n()
+}
+
+var (
+	v, w int
+	_    = 1
+)
+
+func fn() {
+	var local int
+	_ = local
+}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "pkg.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &types.Info{Defs: map[*ast.Ident]types.Object{}}
+	tpkg, err := (&types.Config{}).Check("pkg", fset, []*ast.File{f}, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decls := declarations(&pkg{Fset: fset, Files: []*ast.File{f}, Pkg: tpkg, TypesInfo: info})
+
+	var got []string
+	for _, decl := range decls {
+		owner := ""
+		if decl.Owner != nil {
+			owner = decl.Owner.Name()
+		}
+		start, end := fset.Position(decl.Pos), fset.Position(decl.End)
+		got = append(got, fmt.Sprintf("%s %s %d-%d", decl.Object.Name(), owner, start.Line, end.Line))
+	}
+	want := []string{
+		"T  3-10",
+		"a T 5-5",
+		"b T 6-8",
+		"c b 7-7",
+		"T T 9-9",
+		"m T 12-12",
+		"I  14-16",
+		"n I 15-15",
+		"v  19-19",
+		"w  19-19",
+		"fn  23-26",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func init() {
+	RegisterEdgeProvider(testProvider{})
+}
+
+// testProvider provides the edges that testdata/src/provided expects.
+type testProvider struct{}
+
+func (testProvider) Edges(pass *analysis.Pass) []Edge {
+	if pass.Pkg.Path() != "provided" {
+		return nil
+	}
+	scope := pass.Pkg.Scope()
+	return []Edge{
+		{Used: scope.Lookup("newStore")},
+		{Used: scope.Lookup("configure"), By: scope.Lookup("newStore")},
+		{Used: scope.Lookup("warm"), By: scope.Lookup("newCache")},
+		// Edges to other packages are ignored.
+		{Used: types.Universe.Lookup("error"), By: scope.Lookup("newStore")},
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "routes")
+	for _, res := range results {
+		if res.Pass.Pkg.Path() != "routes" {
+			continue
+		}
+		var got []string
+		for _, r := range res.Result.(Result).Routes {
+			got = append(got, fmt.Sprintf("%s %s %t", r.Pattern, r.Handler.Name(), r.Live))
+		}
+		// Tests request /users/{id} and, from the external test
+		// package, /legacy, no matter which variant gets checked.
+		want := []string{
+			"/users/{id} handleUsers true",
+			"GET /legacy handleLegacy true",
+			"/metrics handleMetrics true",
+			"/healthz handleHealth true",
+			"/ handleIndex false",
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got routes %q, want %q", got, want)
+		}
+	}
+}
+
+func TestDuplicates(t *testing.T) {
+	wants := map[string]map[string]string{
+		// Bodies as small as none's aren't compared.
+		"duplicates": {"splitFields": "split", "describeValue": "describe"},
+		// Sum is unused in whole-program mode, but exported.
+		"duplicates/exported": {"sumPositive": "sum"},
+	}
+	results := analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "duplicates", "duplicates/exported")
+	for _, res := range results {
+		check(t, res)
+		got := map[string]string{}
+		for obj, orig := range res.Result.(Result).Duplicates {
+			got[obj.Name()] = orig.Name()
+		}
+		if want := wants[res.Pass.Pkg.Path()]; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got duplicates %v, want %v", res.Pass.Pkg.Path(), got, want)
+		}
+	}
+}
+
+func TestClusters(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "clusters")
+	for _, res := range results {
+		type cluster struct {
+			objects []string
+			decls   int
+			lines   int
+		}
+		var got []cluster
+		for _, c := range res.Result.(Result).Clusters {
+			var names []string
+			for _, obj := range c.Objects {
+				names = append(names, obj.Name())
+			}
+			got = append(got, cluster{names, c.Decls, c.Lines})
+		}
+		want := []cluster{
+			{[]string{"exporter", "record", "newExporter", "add", "flush"}, 5, 10},
+			{[]string{"a", "b", "sum"}, 2, 3},
+			{[]string{"alone"}, 1, 1},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got clusters %v, want %v", got, want)
+		}
+	}
+}
+
+func TestLayoutSensitive(t *testing.T) {
+	results := analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "layout")
+	for _, res := range results {
+		var got []string
+		for _, obj := range res.Result.(Result).LayoutSensitive {
+			got = append(got, obj.Name())
+		}
+		want := []string{"header", "inner", "outer", "sized", "elem"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got layout-sensitive types %v, want %v", got, want)
+		}
+	}
+}
+
+func TestTestSupport(t *testing.T) {
+	for _, res := range analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "testsupport/fixtures", "testsupport/other") {
+		check(t, res)
+	}
+}
+
+func TestExternalAPI(t *testing.T) {
+	for _, res := range analysistest.Run(t, analysistest.TestData(), Analyzer.Analyzer, "externalapi/internal") {
+		check(t, res)
+	}
+
+	patterns := map[string]bool{
+		"example.com/app/api/...":          true,
+		"example.com/app/api/internal/...": false,
+		"example.com/app/*/v2":             true,
+	}
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"example.com/app/api", true},
+		{"example.com/app/api/users", true},
+		{"example.com/app/api/internal", false},
+		{"example.com/app/api/internal/db", false},
+		{"example.com/app/apiserver", false},
+		{"example.com/app/client/v2", true},
+		{"example.com/app/client/v2/sub", false},
+	}
+	for _, tt := range tests {
+		if got := isExternalAPI(tt.path, patterns); got != tt.want {
+			t.Errorf("isExternalAPI(%q) = %t, want %t", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestRuntimeFuncs(t *testing.T) {
+	tests := []struct {
+		version int
+		name    string
+		want    bool
+	}{
+		// panicindex predates the generated table.
+		{10, "panicindex", true},
+		{19, "panicindex", false},
+		{10, "goPanicIndex", true},
+		{0, "goPanicIndex", true},
+		{19, "addCovMeta", false},
+		{20, "addCovMeta", true},
+		// Versions newer than the generated ones use the entries of
+		// the newest one.
+		{99, "addCovMeta", true},
+		{0, "morestack_abi0", false},
+		// Entries cover the releases between the oldest and the
+		// newest one, too.
+		{20, "_GetStdHandle", true},
+		{21, "_GetStdHandle", false},
+		{25, "KeepAlive", false},
+		{26, "KeepAlive", true},
+	}
+	for i := 1; i < len(runtimeFuncsVersions); i++ {
+		if runtimeFuncsVersions[i] != runtimeFuncsVersions[i-1]+1 {
+			t.Fatalf("runtimeFuncsVersions %v aren't consecutive", runtimeFuncsVersions)
+		}
+	}
+	for _, tt := range tests {
+		if got := runtimeFuncs(tt.version, nil)[tt.name]; got != tt.want {
+			t.Errorf("runtimeFuncs(%d)[%q] = %t, want %t", tt.version, tt.name, got, tt.want)
+		}
+	}
+	if !runtimeFuncs(0, []string{"morestack_abi0"})["morestack_abi0"] {
+		t.Error("runtimeFuncs ignored extra functions")
+	}
+}
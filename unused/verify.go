@@ -0,0 +1,193 @@
+package unused
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"sort"
+	"strconv"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Fix verification applies suggested fixes to copies of the package's
+// files and type-checks the result, dropping fixes that would break
+// the build. This guards against mistakes in the computation of
+// fixes, such as deleting an object that is still referred to by code
+// we don't analyze, or leaving behind imports that are no longer
+// needed.
+
+// verifier type-checks modified versions of a package.
+type verifier struct {
+	fset    *token.FileSet
+	pkg     *pkg
+	sources map[*token.File][]byte
+	imports map[string]*types.Package
+}
+
+func newVerifier(pkg *pkg) *verifier {
+	v := &verifier{
+		fset:    pkg.Fset,
+		pkg:     pkg,
+		sources: map[*token.File][]byte{},
+		imports: map[string]*types.Package{},
+	}
+	// Map import paths as spelled in the source to packages. They may
+	// differ from the packages' paths, for example because of
+	// vendoring.
+	for _, f := range pkg.Files {
+		for _, spec := range f.Imports {
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				continue
+			}
+			var obj types.Object
+			if spec.Name != nil {
+				obj = pkg.TypesInfo.Defs[spec.Name]
+			} else {
+				obj = pkg.TypesInfo.Implicits[spec]
+			}
+			if pn, ok := obj.(*types.PkgName); ok {
+				v.imports[path] = pn.Imported()
+			}
+		}
+	}
+	return v
+}
+
+func (v *verifier) source(tf *token.File) ([]byte, error) {
+	if src, ok := v.sources[tf]; ok {
+		return src, nil
+	}
+	src, err := os.ReadFile(tf.Name())
+	if err != nil {
+		return nil, err
+	}
+	if len(src) != tf.Size() {
+		return nil, fmt.Errorf("%s changed on disk", tf.Name())
+	}
+	v.sources[tf] = src
+	return src, nil
+}
+
+// apply applies edits to the source of tf. Identical edits are only
+// applied once.
+func (v *verifier) apply(tf *token.File, edits []analysis.TextEdit) ([]byte, error) {
+	type edit struct {
+		pos, end int
+		text     string
+	}
+	seen := map[edit]struct{}{}
+	var sorted []edit
+	for _, e := range edits {
+		ed := edit{tf.Offset(e.Pos), tf.Offset(e.End), string(e.NewText)}
+		if _, ok := seen[ed]; ok {
+			continue
+		}
+		seen[ed] = struct{}{}
+		sorted = append(sorted, ed)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].pos > sorted[j].pos
+	})
+
+	src, err := v.source(tf)
+	if err != nil {
+		return nil, err
+	}
+	out := append([]byte(nil), src...)
+	for i, e := range sorted {
+		if i > 0 && e.end > sorted[i-1].pos {
+			return nil, errors.New("fix contains overlapping edits")
+		}
+		out = append(out[:e.pos:e.pos], append([]byte(e.text), out[e.end:]...)...)
+	}
+	return out, nil
+}
+
+// check applies the fixes and type-checks the package. It returns the
+// first error that applying or type-checking caused.
+func (v *verifier) check(fixes []analysis.SuggestedFix) error {
+	edits := map[*token.File][]analysis.TextEdit{}
+	for _, fix := range fixes {
+		for _, e := range fix.TextEdits {
+			tf := v.fset.File(e.Pos)
+			edits[tf] = append(edits[tf], e)
+		}
+	}
+
+	// Parse modified files into a new file set, so that we don't
+	// pollute the package's.
+	fset := token.NewFileSet()
+	files := make([]*ast.File, 0, len(v.pkg.Files))
+	for _, f := range v.pkg.Files {
+		tf := v.fset.File(f.Pos())
+		var src interface{} = nil
+		if fileEdits, ok := edits[tf]; ok {
+			b, err := v.apply(tf, fileEdits)
+			if err != nil {
+				return err
+			}
+			src = b
+		} else {
+			b, err := v.source(tf)
+			if err != nil {
+				return err
+			}
+			src = b
+		}
+		nf, err := parser.ParseFile(fset, tf.Name(), src, parser.ParseComments)
+		if err != nil {
+			return err
+		}
+		files = append(files, nf)
+	}
+
+	var firstErr error
+	conf := types.Config{
+		Importer: importerFunc(func(path string) (*types.Package, error) {
+			if pkg, ok := v.imports[path]; ok {
+				return pkg, nil
+			}
+			return nil, fmt.Errorf("package %q isn't imported by the original package", path)
+		}),
+		Sizes:     v.pkg.TypesSizes,
+		GoVersion: v.pkg.GoVersion,
+		Error: func(err error) {
+			if firstErr == nil {
+				firstErr = err
+			}
+		},
+	}
+	conf.Check(v.pkg.Pkg.Path(), fset, files, nil)
+	return firstErr
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
+
+// verifyFixes drops the fixes that would cause the package to no
+// longer type-check, returning the reasons. Fixes are checked one at a
+// time, as they may be applied one at a time, which type-checks the
+// package once per fixed object. For example, deleting an unused
+// function that is referred to by another unused function only works
+// if both get deleted.
+func (g *graph) verifyFixes(fixes map[types.Object][]analysis.SuggestedFix) map[types.Object]string {
+	if len(fixes) == 0 {
+		return nil
+	}
+	v := newVerifier(g.pkg)
+	errs := map[types.Object]string{}
+	for obj, fs := range fixes {
+		if err := v.check(fs); err != nil {
+			errs[obj] = err.Error()
+			delete(fixes, obj)
+		}
+	}
+	return errs
+}
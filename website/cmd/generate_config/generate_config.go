@@ -31,7 +31,7 @@ func main() {
 	do(simple.Analyzers...)
 	do(staticcheck.Analyzers...)
 	do(stylecheck.Analyzers...)
-	do(unused.Analyzer)
+	do(unused.Analyzer, unused.ForbiddenAnalyzer)
 	do(quickfix.Analyzers...)
 
 	sort.Slice(checks[1:], func(i, j int) bool {